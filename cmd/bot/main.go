@@ -9,16 +9,25 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Quiet hours need time zone data even in minimal containers
 
 	"jellyfin-telegram-bot/internal/config"
 	"jellyfin-telegram-bot/internal/database"
 	"jellyfin-telegram-bot/internal/handlers"
 	"jellyfin-telegram-bot/internal/jellyfin"
+	"jellyfin-telegram-bot/internal/metrics"
+	"jellyfin-telegram-bot/internal/seerr"
 	"jellyfin-telegram-bot/internal/telegram"
 
 	"github.com/joho/godotenv"
 )
 
+// version is reported in logs and by the health endpoints
+const version = "0.1.0"
+
 func main() {
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
@@ -36,11 +45,12 @@ func main() {
 	slog.SetDefault(logger)
 
 	// Create context with cancellation for graceful shutdown
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	// Docker and systemd stop services with SIGTERM, Ctrl+C sends SIGINT
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	slog.Info("Jellyfin Telegram Bot starting...",
-		"version", "0.1.0",
+		"version", version,
 		"port", cfg.Webhook.Port,
 		"database", cfg.Database.Path,
 	)
@@ -52,6 +62,21 @@ func main() {
 	}
 	slog.Info("Database initialized", "path", cfg.Database.Path)
 
+	// Admins from ADMIN_CHAT_IDS; more can be added from Telegram with /admins
+	if err := db.SeedAdmins(cfg.Admin.ChatIDs); err != nil {
+		log.Fatalf("Failed to seed admins: %v", err)
+	}
+
+	// Report the subscriber count on /metrics, read at scrape time
+	metrics.ActiveSubscribers.Set(func() float64 {
+		count, err := db.CountActiveSubscribers()
+		if err != nil {
+			slog.Warn("Failed to count active subscribers for metrics", "error", err)
+			return 0
+		}
+		return float64(count)
+	})
+
 	// Initialize Jellyfin API client
 	jellyfinClient := jellyfin.NewClient(cfg.Jellyfin.ServerURL, cfg.Jellyfin.APIKey)
 	slog.Info("Jellyfin client initialized", "server", cfg.Jellyfin.ServerURL)
@@ -66,34 +91,156 @@ func main() {
 	}
 	slog.Info("Telegram bot initialized")
 
-	// Create broadcaster adapter for webhook handler
-	broadcaster := telegram.NewBroadcasterAdapter(bot)
+	// Create durable notification outbox for webhook handler
+	outbox := telegram.NewOutbox(bot, db, cfg.Notification.BatchWindow)
+	outbox.SetRevisionPolicy(cfg.Notification.EditWindow, cfg.Notification.DeleteMode)
 
 	// Initialize webhook handler
 	webhookHandler := handlers.NewWebhookHandler(db, cfg.Webhook.Secret)
-	webhookHandler.SetBroadcaster(broadcaster)
+	webhookHandler.SetQueue(outbox)
+	webhookHandler.SetMetadataFetcher(jellyfinClient)
+	if cfg.Notification.EditWindow > 0 {
+		// Metadata changes and removals revise the notifications already sent
+		webhookHandler.SetReviser(outbox)
+	}
+
+	// Playback, new user, failed login and plugin events go to the admins who chose them with /events
+	adminEvents := telegram.NewAdminEvents(bot, cfg.Admin.EventWindow)
+	webhookHandler.SetAdminEventNotifier(adminEvents)
 	slog.Info("Webhook handler initialized")
 
+	// Admin commands (/stats, /ban, /resend, ...) and subscription approval or invites
+	bot.SetAdminStore(db)
+	bot.SetAccessStore(db)
+	bot.SetNotificationQueue(outbox)
+	bot.SetItemFetcher(jellyfinClient)
+
+	// Linked Jellyfin accounts limit /recent, /search and notifications to the user's libraries
+	bot.SetJellyfinAccounts(jellyfinAdapter)
+
+	// Wishlists: /wish titles are announced personally when they arrive
+	bot.SetWishStore(db)
+
+	// Title requests: /request asks the admins, approved ones go on to Overseerr or Jellyseerr if configured
+	bot.SetRequestStore(db)
+	if cfg.Requests.ForwardRequests() {
+		bot.SetRequestForwarder(seerr.NewClient(cfg.Requests.SeerrURL, cfg.Requests.SeerrAPIKey))
+		slog.Info("Forwarding approved title requests", "url", cfg.Requests.SeerrURL)
+	}
+
+	// Health (/health) covers the database, readiness (/ready) also Jellyfin and Telegram
+	health := handlers.NewHealthHandler(version)
+	health.AddLivenessCheck("database", db.Ping)
+	health.AddReadinessCheck("jellyfin", func(ctx context.Context) error {
+		_, err := jellyfinClient.GetPublicSystemInfo(ctx)
+		return err
+	})
+	health.AddReadinessCheck("telegram", bot.Ping)
+	health.SetWebhookHandler(webhookHandler)
+	health.SetBacklogCounter(db)
+
+	// Telegram updates share the webhook server in webhook mode
+	var routes []handlers.Route
+	if bot.IsWebhookMode() {
+		routes = append(routes, handlers.Route{
+			Pattern: cfg.Telegram.WebhookPath,
+			Handler: bot.WebhookHandler(),
+		})
+	}
+
+	// Posters of inline query results are served through the bot, since Jellyfin is usually not public
+	if cfg.PublicBaseURL() != "" {
+		routes = append(routes, handlers.Route{
+			Pattern: telegram.PosterPath,
+			Handler: bot.PosterHandler(),
+		})
+	}
+
+	// Background workers are tracked so shutdown can wait for in-flight work
+	var workers sync.WaitGroup
+
 	// Start webhook server in goroutine
+	workers.Add(1)
 	go func() {
-		slog.Info("Starting webhook server", "port", cfg.Webhook.Port)
+		defer workers.Done()
 		port := fmt.Sprintf("%d", cfg.Webhook.Port)
-		if err := handlers.StartWebhookServer(port, webhookHandler); err != nil {
+		if err := handlers.StartWebhookServer(ctx, port, webhookHandler, health, cfg.Webhook.ShutdownTimeout, routes...); err != nil {
 			slog.Error("Webhook server failed", "error", err)
 			cancel()
 		}
 	}()
 
-	// Start bot polling
-	go bot.Start(ctx)
+	// Start outbox worker (resumes deliveries interrupted by a previous shutdown)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		outbox.Run(ctx)
+	}()
+
+	// Start digest scheduler (daily and weekly summaries at each subscriber's local time)
+	digests := telegram.NewDigestScheduler(bot, db)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		digests.Run(ctx)
+	}()
+
+	// Summarize repeated server events for admins
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		adminEvents.Run(ctx)
+	}()
+
+	// Delete expired inline button tokens
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		bot.RunCallbackCleanup(ctx)
+	}()
+
+	// Re-key mutes created before series IDs were stored (unresolved ones are retried on the next start)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		if _, err := db.MigrateMutedSeriesIDs(ctx, jellyfinClient); err != nil {
+			slog.Warn("Failed to migrate muted series to series IDs", "error", err)
+		}
+	}()
+
+	// Start receiving Telegram updates (webhook or polling)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		bot.Start(ctx)
+	}()
 
 	slog.Info("Bot is running. Press Ctrl+C to stop.")
 
 	// Wait for shutdown signal
 	<-ctx.Done()
-	slog.Info("Received shutdown signal, shutting down gracefully...")
+	slog.Info("Received shutdown signal, shutting down gracefully...",
+		"timeout", cfg.Webhook.ShutdownTimeout)
 
-	// Cleanup happens automatically when context is cancelled
+	// Let running broadcasts finish their current send and checkpoint, remove a registered
+	// Telegram webhook and drain HTTP requests before the database is closed
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+		if err := db.Close(); err != nil {
+			slog.Error("Failed to close database", "error", err)
+		}
+	case <-time.After(cfg.Webhook.ShutdownTimeout):
+		// Work still running would fail on a closed database, so it is left open;
+		// SQLite rolls back a write the exit interrupts when the database is next opened
+		slog.Warn("Shutdown timeout exceeded, exiting with work still in progress",
+			"timeout", cfg.Webhook.ShutdownTimeout)
+	}
 
 	slog.Info("Shutdown complete")
 }
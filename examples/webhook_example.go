@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"time"
 
 	"jellyfin-telegram-bot/internal/database"
 	"jellyfin-telegram-bot/internal/handlers"
@@ -35,7 +37,7 @@ func main() {
 
 	// Start webhook server (blocking call)
 	slog.Info("Starting webhook server", "port", port)
-	if err := handlers.StartWebhookServer(port, webhookHandler); err != nil {
+	if err := handlers.StartWebhookServer(context.Background(), port, webhookHandler, handlers.NewHealthHandler("example"), 30*time.Second); err != nil {
 		slog.Error("Webhook server error", "error", err)
 		os.Exit(1)
 	}
@@ -3,17 +3,54 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// PosterPath is the path the poster proxy is served on by the webhook server
+const PosterPath = "/posters/"
+
+// reservedPaths are served by the webhook server itself and can't take Telegram updates
+var reservedPaths = []string{"/webhook", "/health", "/ready", "/metrics", PosterPath}
+
 // Config holds all application configuration
 type Config struct {
-	Telegram TelegramConfig
-	Jellyfin JellyfinConfig
-	Webhook  WebhookConfig
-	Database DatabaseConfig
-	Logger   LoggerConfig
-	Testing  TestingConfig
+	Telegram     TelegramConfig
+	Jellyfin     JellyfinConfig
+	Webhook      WebhookConfig
+	Database     DatabaseConfig
+	Notification NotificationConfig
+	Logger       LoggerConfig
+	Testing      TestingConfig
+	Admin        AdminConfig
+	Requests     RequestsConfig
+}
+
+// AdminConfig holds bot operator configuration
+type AdminConfig struct {
+	ChatIDs     []int64       // Chat IDs seeded as admins on startup; more can be added with /admins
+	AccessMode  string        // Who may subscribe, one of the AccessMode* constants
+	EventWindow time.Duration // Repeated server events of one user within this window are summarized (0 sends each)
+}
+
+// Access modes deciding who may subscribe with /start
+const (
+	AccessModeOpen     = "open"     // Anyone who finds the bot
+	AccessModeApproval = "approval" // Admins approve every new subscriber
+	AccessModeInvite   = "invite"   // Only users with an invite code from an admin
+)
+
+// RequestsConfig holds title request configuration
+type RequestsConfig struct {
+	SeerrURL    string // Overseerr or Jellyseerr address approved /request titles are forwarded to; empty disables forwarding
+	SeerrAPIKey string
+}
+
+// ForwardRequests reports whether approved title requests are forwarded to Overseerr or Jellyseerr
+func (c *RequestsConfig) ForwardRequests() bool {
+	return c.SeerrURL != ""
 }
 
 // TestingConfig holds testing and feature flag configuration
@@ -25,19 +62,45 @@ type TestingConfig struct {
 
 // TelegramConfig holds Telegram bot configuration
 type TelegramConfig struct {
-	BotToken string
+	BotToken      string
+	WebhookURL    string        // Public base URL of the webhook server; empty uses long polling
+	WebhookPath   string        // Path Telegram updates are received on
+	WebhookSecret string        // Secret token Telegram sends with every update; generated when empty
+	CallbackTTL   time.Duration // How long inline buttons carrying stored arguments keep working
+}
+
+// UseWebhook reports whether Telegram updates are received via webhook instead of long polling
+func (c *TelegramConfig) UseWebhook() bool {
+	return c.WebhookURL != ""
+}
+
+// WebhookEndpoint returns the public URL Telegram sends updates to
+func (c *TelegramConfig) WebhookEndpoint() string {
+	return strings.TrimRight(c.WebhookURL, "/") + c.WebhookPath
 }
 
 // JellyfinConfig holds Jellyfin server configuration
 type JellyfinConfig struct {
 	ServerURL string
 	APIKey    string
+	PublicURL string // Address users open Jellyfin web at; enables "Open in Jellyfin" buttons
 }
 
 // WebhookConfig holds webhook server configuration
 type WebhookConfig struct {
-	Secret string
-	Port   int
+	Secret          string
+	Port            int
+	ShutdownTimeout time.Duration // Time allowed for in-flight requests and deliveries to finish on shutdown
+	PublicURL       string        // Public base URL of this server, used for poster thumbnails
+}
+
+// PublicBaseURL returns the address Telegram reaches the bot's HTTP server at, or "" if it has none
+// It defaults to the Telegram webhook URL, which points at the same server
+func (c *Config) PublicBaseURL() string {
+	if c.Webhook.PublicURL != "" {
+		return c.Webhook.PublicURL
+	}
+	return strings.TrimRight(c.Telegram.WebhookURL, "/")
 }
 
 // DatabaseConfig holds database configuration
@@ -45,29 +108,68 @@ type DatabaseConfig struct {
 	Path string
 }
 
+// NotificationConfig holds notification delivery configuration
+type NotificationConfig struct {
+	BatchWindow     time.Duration // Episodes of one season arriving within this window are sent as one notification (0 disables)
+	DefaultTimezone string        // IANA time zone for subscribers who haven't set their own (quiet hours)
+	ShowMediaInfo   bool          // Add resolution, HDR, audio and subtitle languages to notifications
+	EditWindow      time.Duration // Notifications this recent are edited when Jellyfin updates or removes the item (0 disables)
+	DeleteMode      string        // What happens to notifications of removed items, one of the DeleteMode* constants
+}
+
+// Delete modes deciding what happens to the notifications of items removed from Jellyfin
+const (
+	DeleteModeOff      = "off"      // Leave them as they are
+	DeleteModeAnnotate = "annotate" // Mark them as removed and drop their buttons
+	DeleteModeDelete   = "delete"   // Delete them; Telegram only allows this for 48 hours, older ones are annotated
+)
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	config := &Config{
 		Telegram: TelegramConfig{
-			BotToken: getEnvRequired("TELEGRAM_BOT_TOKEN"),
+			BotToken:      getEnvRequired("TELEGRAM_BOT_TOKEN"),
+			WebhookURL:    getEnv("TELEGRAM_WEBHOOK_URL", ""),
+			WebhookPath:   getEnv("TELEGRAM_WEBHOOK_PATH", "/telegram"),
+			WebhookSecret: getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+			CallbackTTL:   getEnvDuration("CALLBACK_TOKEN_TTL", 30*24*time.Hour),
 		},
 		Jellyfin: JellyfinConfig{
 			ServerURL: getEnvRequired("JELLYFIN_SERVER_URL"),
 			APIKey:    getEnvRequired("JELLYFIN_API_KEY"),
+			PublicURL: strings.TrimRight(getEnv("JELLYFIN_PUBLIC_URL", ""), "/"),
 		},
 		Webhook: WebhookConfig{
-			Secret: getEnv("WEBHOOK_SECRET", ""),
-			Port:   getEnvInt("PORT", 8080),
+			Secret:          getEnv("WEBHOOK_SECRET", ""),
+			Port:            getEnvInt("PORT", 8080),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			PublicURL:       strings.TrimRight(getEnv("PUBLIC_URL", ""), "/"),
 		},
 		Database: DatabaseConfig{
 			Path: getEnv("DATABASE_PATH", "./bot.db"),
 		},
+		Notification: NotificationConfig{
+			BatchWindow:     getEnvDuration("NOTIFICATION_BATCH_WINDOW", 2*time.Minute),
+			DefaultTimezone: getEnv("DEFAULT_TIMEZONE", "UTC"),
+			ShowMediaInfo:   getEnvBool("NOTIFICATION_MEDIA_INFO", false),
+			EditWindow:      getEnvDuration("NOTIFICATION_EDIT_WINDOW", 48*time.Hour),
+			DeleteMode:      strings.ToLower(getEnv("NOTIFICATION_DELETE_MODE", DeleteModeOff)),
+		},
 		Logger: GetLoggerFromEnv(),
 		Testing: TestingConfig{
 			TesterChatIDs:      getEnvInt64Slice("TESTER_CHAT_IDS", []int64{}),
 			EnableBetaFeatures: getEnvBool("ENABLE_BETA_FEATURES", false),
 			NotifyOnlyTesters:  getEnvBool("NOTIFY_ONLY_TESTERS", false),
 		},
+		Admin: AdminConfig{
+			ChatIDs:     getEnvInt64Slice("ADMIN_CHAT_IDS", []int64{}),
+			AccessMode:  strings.ToLower(getEnv("ACCESS_MODE", AccessModeOpen)),
+			EventWindow: getEnvDuration("ADMIN_EVENT_WINDOW", 10*time.Minute),
+		},
+		Requests: RequestsConfig{
+			SeerrURL:    strings.TrimRight(getEnv("SEERR_URL", ""), "/"),
+			SeerrAPIKey: getEnv("SEERR_API_KEY", ""),
+		},
 	}
 
 	// Validate required fields
@@ -80,6 +182,52 @@ func LoadConfig() (*Config, error) {
 	if config.Jellyfin.APIKey == "" {
 		return nil, fmt.Errorf("JELLYFIN_API_KEY is required")
 	}
+	if config.Telegram.UseWebhook() {
+		if !strings.HasPrefix(config.Telegram.WebhookURL, "https://") {
+			return nil, fmt.Errorf("TELEGRAM_WEBHOOK_URL must be an https:// URL")
+		}
+		path := config.Telegram.WebhookPath
+		if !strings.HasPrefix(path, "/") || path == "/" || slices.Contains(reservedPaths, path) ||
+			strings.HasPrefix(path+"/", PosterPath) {
+			return nil, fmt.Errorf("TELEGRAM_WEBHOOK_PATH must start with / and not clash with /, /webhook, /health, /ready, /metrics or %s", PosterPath)
+		}
+	}
+	if config.Jellyfin.PublicURL != "" && !strings.HasPrefix(config.Jellyfin.PublicURL, "https://") &&
+		!strings.HasPrefix(config.Jellyfin.PublicURL, "http://") {
+		return nil, fmt.Errorf("JELLYFIN_PUBLIC_URL must be an http:// or https:// URL")
+	}
+	if config.Webhook.PublicURL != "" && !strings.HasPrefix(config.Webhook.PublicURL, "https://") &&
+		!strings.HasPrefix(config.Webhook.PublicURL, "http://") {
+		return nil, fmt.Errorf("PUBLIC_URL must be an http:// or https:// URL")
+	}
+	if config.Requests.ForwardRequests() {
+		if !strings.HasPrefix(config.Requests.SeerrURL, "https://") && !strings.HasPrefix(config.Requests.SeerrURL, "http://") {
+			return nil, fmt.Errorf("SEERR_URL must be an http:// or https:// URL")
+		}
+		if config.Requests.SeerrAPIKey == "" {
+			return nil, fmt.Errorf("SEERR_API_KEY is required when SEERR_URL is set")
+		}
+	}
+	if config.Telegram.CallbackTTL <= 0 {
+		return nil, fmt.Errorf("CALLBACK_TOKEN_TTL must be positive, got %s", config.Telegram.CallbackTTL)
+	}
+	switch config.Admin.AccessMode {
+	case AccessModeOpen, AccessModeApproval, AccessModeInvite:
+	default:
+		return nil, fmt.Errorf("ACCESS_MODE must be open, approval or invite, got %q", config.Admin.AccessMode)
+	}
+	switch config.Notification.DeleteMode {
+	case DeleteModeOff, DeleteModeAnnotate, DeleteModeDelete:
+	default:
+		return nil, fmt.Errorf("NOTIFICATION_DELETE_MODE must be off, annotate or delete, got %q", config.Notification.DeleteMode)
+	}
+	if config.Webhook.ShutdownTimeout <= 0 {
+		return nil, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %s", config.Webhook.ShutdownTimeout)
+	}
+
+	if _, err := time.LoadLocation(config.Notification.DefaultTimezone); err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_TIMEZONE %q: %w", config.Notification.DefaultTimezone, err)
+	}
 
 	return config, nil
 }
@@ -117,6 +265,16 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvDuration gets a duration environment variable (e.g. "90s", "2m") with a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
 // getEnvInt64Slice gets a comma-separated list of int64 values with a default
 func getEnvInt64Slice(key string, defaultValue []int64) []int64 {
 	value := os.Getenv(key)
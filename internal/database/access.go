@@ -0,0 +1,142 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// GetSubscriber returns a subscriber by chat ID, or gorm.ErrRecordNotFound if the user never subscribed
+func (db *DB) GetSubscriber(chatID int64) (*models.Subscriber, error) {
+	var subscriber models.Subscriber
+	result := db.Where("chat_id = ?", chatID).First(&subscriber)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get subscriber: %w", result.Error)
+	}
+
+	return &subscriber, nil
+}
+
+// AddPendingSubscriber records a subscription request waiting for admin approval
+func (db *DB) AddPendingSubscriber(chatID int64, username, firstName string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		subscriber := models.Subscriber{
+			ChatID:       chatID,
+			Username:     username,
+			FirstName:    firstName,
+			LanguageCode: "en",
+		}
+		if err := tx.Where(models.Subscriber{ChatID: chatID}).FirstOrCreate(&subscriber).Error; err != nil {
+			return fmt.Errorf("failed to add pending subscriber: %w", err)
+		}
+
+		// Explicit map since is_active defaults to true for zero values
+		if err := tx.Model(&models.Subscriber{}).
+			Where("chat_id = ?", chatID).
+			Updates(map[string]interface{}{"is_pending": true, "is_active": false}).Error; err != nil {
+			return fmt.Errorf("failed to add pending subscriber: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ApproveSubscriber activates a pending subscriber
+// Returns gorm.ErrRecordNotFound if the user has no pending request
+func (db *DB) ApproveSubscriber(chatID int64) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ? AND is_pending = ?", chatID, true).
+		Updates(map[string]interface{}{"is_pending": false, "is_active": true})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to approve subscriber: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RejectSubscriber deletes a pending subscription request; the user can ask again with /start
+// Returns gorm.ErrRecordNotFound if the user has no pending request
+func (db *DB) RejectSubscriber(chatID int64) error {
+	result := db.Unscoped().
+		Where("chat_id = ? AND is_pending = ?", chatID, true).
+		Delete(&models.Subscriber{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to reject subscriber: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateInvite stores a new invite code usable by maxUses users
+func (db *DB) CreateInvite(code string, maxUses int, createdBy int64) error {
+	invite := models.Invite{Code: code, MaxUses: maxUses, CreatedBy: createdBy}
+
+	if err := db.Create(&invite).Error; err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return nil
+}
+
+// RedeemInvite uses up one use of an invite code
+// Returns gorm.ErrRecordNotFound if the code doesn't exist or has no uses left
+func (db *DB) RedeemInvite(code string) error {
+	result := db.Model(&models.Invite{}).
+		Where("code = ? AND uses < max_uses", code).
+		Update("uses", gorm.Expr("uses + 1"))
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to redeem invite: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetOpenInvites returns the invites that still have uses left, newest first
+func (db *DB) GetOpenInvites() ([]models.Invite, error) {
+	var invites []models.Invite
+	result := db.Where("uses < max_uses").Order("created_at DESC").Find(&invites)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get invites: %w", result.Error)
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite deletes an invite code
+// Returns gorm.ErrRecordNotFound if the code doesn't exist
+func (db *DB) RevokeInvite(code string) error {
+	result := db.Unscoped().Where("code = ?", code).Delete(&models.Invite{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke invite: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// Test 1: Pending subscribers are inactive until approved, and rejected requests are deleted
+func TestPendingSubscriber(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.AddPendingSubscriber(100, "alice", "Alice"); err != nil {
+		t.Fatalf("Failed to add pending subscriber: %v", err)
+	}
+	if err := db.AddPendingSubscriber(200, "bob", "Bob"); err != nil {
+		t.Fatalf("Failed to add pending subscriber: %v", err)
+	}
+
+	subscriber, err := db.GetSubscriber(100)
+	if err != nil {
+		t.Fatalf("Failed to get subscriber: %v", err)
+	}
+	if !subscriber.IsPending || subscriber.IsActive {
+		t.Errorf("Expected a pending inactive subscriber, got pending=%v active=%v", subscriber.IsPending, subscriber.IsActive)
+	}
+	if active, _ := db.GetAllActiveSubscribers(); len(active) != 0 {
+		t.Errorf("Expected no active subscribers, got %v", active)
+	}
+
+	if err := db.ApproveSubscriber(100); err != nil {
+		t.Fatalf("Failed to approve subscriber: %v", err)
+	}
+	if subscribed, _ := db.IsSubscribed(100); !subscribed {
+		t.Error("Expected the approved subscriber to be active")
+	}
+	if err := db.ApproveSubscriber(100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound approving twice, got %v", err)
+	}
+
+	if err := db.RejectSubscriber(200); err != nil {
+		t.Fatalf("Failed to reject subscriber: %v", err)
+	}
+	if _, err := db.GetSubscriber(200); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected the rejected request to be deleted, got %v", err)
+	}
+	if err := db.RejectSubscriber(100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected approved subscribers not to be rejectable, got %v", err)
+	}
+}
+
+// Test 2: Invite codes can be redeemed as often as they allow
+func TestRedeemInvite(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.CreateInvite("single", 1, 100); err != nil {
+		t.Fatalf("Failed to create invite: %v", err)
+	}
+	if err := db.CreateInvite("team", 2, 100); err != nil {
+		t.Fatalf("Failed to create invite: %v", err)
+	}
+
+	if err := db.RedeemInvite("single"); err != nil {
+		t.Fatalf("Failed to redeem invite: %v", err)
+	}
+	if err := db.RedeemInvite("single"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected a used single-use invite to be rejected, got %v", err)
+	}
+	if err := db.RedeemInvite("unknown"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected an unknown invite to be rejected, got %v", err)
+	}
+	if err := db.RedeemInvite("team"); err != nil {
+		t.Fatalf("Failed to redeem invite: %v", err)
+	}
+
+	invites, err := db.GetOpenInvites()
+	if err != nil {
+		t.Fatalf("Failed to get invites: %v", err)
+	}
+	if len(invites) != 1 || invites[0].Code != "team" || invites[0].Remaining() != 1 {
+		t.Errorf("Expected only the team invite with 1 use left, got %+v", invites)
+	}
+
+	if err := db.RevokeInvite("team"); err != nil {
+		t.Fatalf("Failed to revoke invite: %v", err)
+	}
+	if err := db.RedeemInvite("team"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected a revoked invite to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeedAdmins makes the configured chat IDs admins
+// Admins no longer in the configuration stay admins but can be removed from Telegram
+func (db *DB) SeedAdmins(chatIDs []int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Admin{}).
+			Where("from_config = ?", true).
+			Update("from_config", false).Error; err != nil {
+			return fmt.Errorf("failed to reset config admins: %w", err)
+		}
+
+		for _, chatID := range chatIDs {
+			admin := models.Admin{ChatID: chatID, FromConfig: true}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "chat_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"from_config", "updated_at"}),
+			}).Create(&admin).Error; err != nil {
+				return fmt.Errorf("failed to seed admin: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddAdmin makes a chat ID an admin; adding an existing admin is a no-op
+func (db *DB) AddAdmin(chatID, addedBy int64) error {
+	admin := models.Admin{ChatID: chatID, AddedBy: addedBy}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&admin)
+	if result.Error != nil {
+		return fmt.Errorf("failed to add admin: %w", result.Error)
+	}
+
+	return nil
+}
+
+// RemoveAdmin revokes the admin role of a chat ID
+func (db *DB) RemoveAdmin(chatID int64) error {
+	result := db.Unscoped().Where("chat_id = ?", chatID).Delete(&models.Admin{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove admin: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetAdminEventEnabled subscribes an admin to a server event or unsubscribes them
+func (db *DB) SetAdminEventEnabled(chatID int64, eventType string, enabled bool) error {
+	var admin models.Admin
+	if err := db.Where("chat_id = ?", chatID).First(&admin).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return err
+		}
+		return fmt.Errorf("failed to get admin: %w", err)
+	}
+
+	updated := make([]string, 0, len(admin.EventTypes())+1)
+	for _, subscribed := range admin.EventTypes() {
+		if subscribed != eventType {
+			updated = append(updated, subscribed)
+		}
+	}
+	if enabled {
+		updated = append(updated, eventType)
+	}
+
+	result := db.Model(&models.Admin{}).
+		Where("chat_id = ?", chatID).
+		Update("events", strings.Join(updated, ","))
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set admin event subscription: %w", result.Error)
+	}
+
+	return nil
+}
+
+// IsAdmin checks if a chat ID is an admin
+func (db *DB) IsAdmin(chatID int64) (bool, error) {
+	var count int64
+	result := db.Model(&models.Admin{}).
+		Where("chat_id = ?", chatID).
+		Count(&count)
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check admin status: %w", result.Error)
+	}
+
+	return count > 0, nil
+}
+
+// GetAdmins returns all admins, config admins first
+func (db *DB) GetAdmins() ([]models.Admin, error) {
+	var admins []models.Admin
+	result := db.Order("from_config DESC, created_at ASC").Find(&admins)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get admins: %w", result.Error)
+	}
+
+	return admins, nil
+}
+
+// BanSubscriber bans a user, deactivating their subscription
+// Users who never subscribed are recorded so they can't subscribe later
+func (db *DB) BanSubscriber(chatID int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		subscriber := models.Subscriber{ChatID: chatID}
+		if err := tx.Where(models.Subscriber{ChatID: chatID}).FirstOrCreate(&subscriber).Error; err != nil {
+			return fmt.Errorf("failed to ban subscriber: %w", err)
+		}
+
+		if err := tx.Model(&models.Subscriber{}).
+			Where("chat_id = ?", chatID).
+			Updates(map[string]interface{}{"is_banned": true, "is_active": false}).Error; err != nil {
+			return fmt.Errorf("failed to ban subscriber: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// UnbanSubscriber lifts a ban; the user has to /start again to resubscribe
+func (db *DB) UnbanSubscriber(chatID int64) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ? AND is_banned = ?", chatID, true).
+		Update("is_banned", false)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to unban subscriber: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// IsBanned checks if a user is banned
+func (db *DB) IsBanned(chatID int64) (bool, error) {
+	var count int64
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ? AND is_banned = ?", chatID, true).
+		Count(&count)
+
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check ban status: %w", result.Error)
+	}
+
+	return count > 0, nil
+}
+
+// ListSubscribers returns a page of subscribers in the order they joined, and the total count
+func (db *DB) ListSubscribers(offset, limit int) ([]models.Subscriber, int64, error) {
+	var total int64
+	if err := db.Model(&models.Subscriber{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscribers: %w", err)
+	}
+
+	var subscribers []models.Subscriber
+	result := db.Order("id ASC").Offset(offset).Limit(limit).Find(&subscribers)
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("failed to list subscribers: %w", result.Error)
+	}
+
+	return subscribers, total, nil
+}
+
+// GetBotStats collects subscriber, mute and delivery statistics
+// Notifications are counted from deliveries sent since the given time
+func (db *DB) GetBotStats(since time.Time) (*models.BotStats, error) {
+	stats := &models.BotStats{Languages: make(map[string]int64)}
+
+	counts := []struct {
+		target *int64
+		query  *gorm.DB
+	}{
+		{&stats.TotalSubscribers, db.Model(&models.Subscriber{})},
+		{&stats.ActiveSubscribers, db.Model(&models.Subscriber{}).Where("is_active = ?", true)},
+		{&stats.InactiveSubscribers, db.Model(&models.Subscriber{}).
+			Where("is_active = ? AND is_banned = ? AND is_pending = ?", false, false, false)},
+		{&stats.BannedSubscribers, db.Model(&models.Subscriber{}).Where("is_banned = ?", true)},
+		{&stats.PendingSubscribers, db.Model(&models.Subscriber{}).Where("is_pending = ?", true)},
+		{&stats.MutedSeries, db.Model(&models.MutedSeries{}).Where("kind = ?", models.SeriesKindMute)},
+		{&stats.MutingSubscribers, db.Model(&models.MutedSeries{}).Where("kind = ?", models.SeriesKindMute).Distinct("chat_id")},
+		{&stats.NotificationsSent, db.Model(&models.OutboxEntry{}).
+			Where("status = ? AND sent_at >= ?", models.DeliveryStatusSent, since)},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.target).Error; err != nil {
+			return nil, fmt.Errorf("failed to get bot stats: %w", err)
+		}
+	}
+
+	var languages []struct {
+		LanguageCode string
+		Count        int64
+	}
+	if err := db.Model(&models.Subscriber{}).
+		Select("language_code, COUNT(*) AS count").
+		Where("is_active = ?", true).
+		Group("language_code").
+		Scan(&languages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bot stats: %w", err)
+	}
+	for _, l := range languages {
+		stats.Languages[l.LanguageCode] = l.Count
+	}
+
+	return stats, nil
+}
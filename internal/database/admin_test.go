@@ -0,0 +1,208 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// Test 1: Config admins are seeded and only they are marked as coming from the configuration
+func TestSeedAdmins(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.SeedAdmins([]int64{100, 200}); err != nil {
+		t.Fatalf("Failed to seed admins: %v", err)
+	}
+	if err := db.AddAdmin(300, 100); err != nil {
+		t.Fatalf("Failed to add admin: %v", err)
+	}
+	// 200 was dropped from the configuration
+	if err := db.SeedAdmins([]int64{100}); err != nil {
+		t.Fatalf("Failed to seed admins again: %v", err)
+	}
+
+	admins, err := db.GetAdmins()
+	if err != nil {
+		t.Fatalf("Failed to get admins: %v", err)
+	}
+	if len(admins) != 3 {
+		t.Fatalf("Expected 3 admins, got %d", len(admins))
+	}
+	fromConfig := make(map[int64]bool)
+	for _, admin := range admins {
+		fromConfig[admin.ChatID] = admin.FromConfig
+	}
+	if !fromConfig[100] || fromConfig[200] || fromConfig[300] {
+		t.Errorf("Unexpected config admins: %v", fromConfig)
+	}
+
+	if err := db.RemoveAdmin(300); err != nil {
+		t.Fatalf("Failed to remove admin: %v", err)
+	}
+	if isAdmin, _ := db.IsAdmin(300); isAdmin {
+		t.Error("Expected 300 to no longer be an admin")
+	}
+	if err := db.RemoveAdmin(300); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for a removed admin, got %v", err)
+	}
+	if err := db.AddAdmin(300, 100); err != nil {
+		t.Errorf("Expected a removed admin to be added again: %v", err)
+	}
+}
+
+// Test 2: Banned users are deactivated and unbanning keeps them unsubscribed
+func TestBanSubscriber(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddSubscriber(100, "user", "User")
+	if err := db.BanSubscriber(100); err != nil {
+		t.Fatalf("Failed to ban subscriber: %v", err)
+	}
+	// Users can be banned before they ever subscribe
+	if err := db.BanSubscriber(200); err != nil {
+		t.Fatalf("Failed to ban unknown user: %v", err)
+	}
+
+	for _, chatID := range []int64{100, 200} {
+		if banned, _ := db.IsBanned(chatID); !banned {
+			t.Errorf("Expected %d to be banned", chatID)
+		}
+		if subscribed, _ := db.IsSubscribed(chatID); subscribed {
+			t.Errorf("Expected %d to be unsubscribed", chatID)
+		}
+	}
+
+	if err := db.UnbanSubscriber(100); err != nil {
+		t.Fatalf("Failed to unban subscriber: %v", err)
+	}
+	if banned, _ := db.IsBanned(100); banned {
+		t.Error("Expected 100 to be unbanned")
+	}
+	if subscribed, _ := db.IsSubscribed(100); subscribed {
+		t.Error("Expected an unbanned user to stay unsubscribed")
+	}
+	if err := db.UnbanSubscriber(300); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for a user who isn't banned, got %v", err)
+	}
+}
+
+// Test 3: Stats count subscribers, languages, mutes and recent deliveries
+func TestGetBotStats(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddSubscriber(100, "a", "A")
+	db.AddSubscriber(200, "b", "B")
+	db.AddSubscriber(300, "c", "C")
+	db.SetLanguage(200, "fa")
+	db.RemoveSubscriber(300)
+	db.BanSubscriber(400)
+	db.AddMutedSeries(100, "s1", "Series 1")
+	db.AddMutedSeries(100, "s2", "Series 2")
+	db.AddMutedSeries(200, "s1", "Series 1")
+
+	db.EnqueueNotification("movie-1", "", `{}`, time.Now())
+	jobs, _ := db.GetDueJobs(time.Now())
+	db.ExpandNotificationJob(jobs[0].ID, []int64{100, 200}, nil)
+	entries, _ := db.GetPendingDeliveries(jobs[0].ID)
+	db.MarkDeliverySent(entries[0].ID, 1, false)
+
+	stats, err := db.GetBotStats(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+
+	if stats.TotalSubscribers != 4 || stats.ActiveSubscribers != 2 ||
+		stats.InactiveSubscribers != 1 || stats.BannedSubscribers != 1 {
+		t.Errorf("Unexpected subscriber counts: %+v", stats)
+	}
+	if stats.Languages["en"] != 1 || stats.Languages["fa"] != 1 {
+		t.Errorf("Unexpected languages: %v", stats.Languages)
+	}
+	if stats.MutedSeries != 3 || stats.MutingSubscribers != 2 {
+		t.Errorf("Unexpected mute counts: %+v", stats)
+	}
+	if stats.NotificationsSent != 1 {
+		t.Errorf("Expected 1 sent notification, got %d", stats.NotificationsSent)
+	}
+
+	page, total, err := db.ListSubscribers(1, 2)
+	if err != nil {
+		t.Fatalf("Failed to list subscribers: %v", err)
+	}
+	if total != 4 || len(page) != 2 || page[0].ChatID != 200 {
+		t.Errorf("Unexpected subscriber page: total %d, %+v", total, page)
+	}
+}
+
+// Test 4: Admins subscribe to server events, and the subscriptions survive seeding
+func TestSetAdminEventEnabled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.SeedAdmins([]int64{100}); err != nil {
+		t.Fatalf("Failed to seed admins: %v", err)
+	}
+
+	for _, eventType := range []string{"PlaybackStart", "UserCreated", "PlaybackStart"} {
+		if err := db.SetAdminEventEnabled(100, eventType, true); err != nil {
+			t.Fatalf("Failed to subscribe to %s: %v", eventType, err)
+		}
+	}
+	if err := db.SetAdminEventEnabled(100, "UserCreated", false); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if err := db.SeedAdmins([]int64{100}); err != nil {
+		t.Fatalf("Failed to seed admins again: %v", err)
+	}
+
+	admins, err := db.GetAdmins()
+	if err != nil {
+		t.Fatalf("Failed to get admins: %v", err)
+	}
+	if len(admins) != 1 || admins[0].Events != "PlaybackStart" {
+		t.Errorf("Expected a single PlaybackStart subscription, got %+v", admins)
+	}
+
+	if err := db.SetAdminEventEnabled(999, "PlaybackStart", true); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for a non-admin, got %v", err)
+	}
+}
+
+// Test 5: Stats count deliveries by when they were sent and keep pending users apart from inactive ones
+func TestGetBotStats_SentAtAndPending(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddSubscriber(100, "a", "A")
+	db.AddPendingSubscriber(200, "b", "B")
+
+	db.EnqueueNotification("movie-1", "", `{}`, time.Now())
+	jobs, _ := db.GetDueJobs(time.Now())
+	db.ExpandNotificationJob(jobs[0].ID, []int64{100}, nil)
+	entries, _ := db.GetPendingDeliveries(jobs[0].ID)
+	db.MarkDeliverySent(entries[0].ID, 1, false)
+
+	// Sent two days ago, edited or retracted just now
+	db.Model(&models.OutboxEntry{}).Where("id = ?", entries[0].ID).Update("sent_at", time.Now().Add(-48*time.Hour))
+	if err := db.ForgetDeliveryMessage(entries[0].ID); err != nil {
+		t.Fatalf("Failed to forget message: %v", err)
+	}
+
+	stats, err := db.GetBotStats(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.NotificationsSent != 0 {
+		t.Errorf("Expected the old delivery not to be counted again, got %d", stats.NotificationsSent)
+	}
+	if stats.PendingSubscribers != 1 || stats.InactiveSubscribers != 0 {
+		t.Errorf("Expected one pending and no inactive subscriber, got %+v", stats)
+	}
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// SaveCallbackToken stores a callback token and its payload
+func (db *DB) SaveCallbackToken(token *models.CallbackToken) error {
+	result := db.Create(token)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save callback token: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetCallbackToken returns the callback token with the given value
+// Unknown tokens return an error wrapping gorm.ErrRecordNotFound; expired ones are returned as stored
+func (db *DB) GetCallbackToken(token string) (*models.CallbackToken, error) {
+	var record models.CallbackToken
+	result := db.Where("token = ?", token).First(&record)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get callback token: %w", result.Error)
+	}
+
+	return &record, nil
+}
+
+// FindCallbackToken returns the token issued for the same action and payload that is still valid at validUntil
+// The token expiring last is returned; none returns an error wrapping gorm.ErrRecordNotFound
+func (db *DB) FindCallbackToken(action, payload string, validUntil time.Time) (*models.CallbackToken, error) {
+	var record models.CallbackToken
+	result := db.Where("action = ? AND payload = ?", action, payload).
+		Where("expires_at IS NULL OR expires_at > ?", validUntil).
+		Order("expires_at IS NULL DESC, expires_at DESC").
+		First(&record)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to find callback token: %w", result.Error)
+	}
+
+	return &record, nil
+}
+
+// DeleteExpiredCallbackTokens permanently removes the callback tokens expired before now
+func (db *DB) DeleteExpiredCallbackTokens(now time.Time) (int64, error) {
+	result := db.Unscoped().
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Delete(&models.CallbackToken{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired callback tokens: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// Test 1: Callback tokens are stored with their action and reported missing when unknown
+func TestCallbackTokens(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	expiresAt := time.Now().Add(time.Hour)
+	if err := db.SaveCallbackToken(&models.CallbackToken{
+		Token:     "abc",
+		Action:    "mute",
+		Payload:   `{"id":"1"}`,
+		ExpiresAt: &expiresAt,
+	}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	if err := db.SaveCallbackToken(&models.CallbackToken{Token: "abc", Payload: `{}`}); err == nil {
+		t.Error("Expected an error when reusing a token")
+	}
+
+	record, err := db.GetCallbackToken("abc")
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if record.Action != "mute" || record.Payload != `{"id":"1"}` || record.IsExpired(time.Now()) {
+		t.Errorf("Unexpected token: %+v", record)
+	}
+
+	if _, err := db.GetCallbackToken("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for an unknown token, got %v", err)
+	}
+}
+
+// Test 2: Only expired callback tokens are deleted
+func TestDeleteExpiredCallbackTokens(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	valid := now.Add(time.Hour)
+	for _, token := range []*models.CallbackToken{
+		{Token: "expired", Payload: `{}`, ExpiresAt: &expired},
+		{Token: "valid", Payload: `{}`, ExpiresAt: &valid},
+		{Token: "permanent", Payload: `{}`},
+	} {
+		if err := db.SaveCallbackToken(token); err != nil {
+			t.Fatalf("Failed to save token: %v", err)
+		}
+	}
+
+	deleted, err := db.DeleteExpiredCallbackTokens(now)
+	if err != nil {
+		t.Fatalf("Failed to delete expired tokens: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 deleted token, got %d", deleted)
+	}
+
+	if _, err := db.GetCallbackToken("expired"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Error("Expected the expired token to be gone")
+	}
+	for _, token := range []string{"valid", "permanent"} {
+		if _, err := db.GetCallbackToken(token); err != nil {
+			t.Errorf("Expected token %q to be kept: %v", token, err)
+		}
+	}
+}
+
+// Test 3: Tokens are found by action and payload only while valid at the given time
+func TestFindCallbackToken(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	later := now.Add(24 * time.Hour)
+	for _, token := range []*models.CallbackToken{
+		{Token: "soon", Action: "mute", Payload: `{"id":"1"}`, ExpiresAt: &soon},
+		{Token: "later", Action: "mute", Payload: `{"id":"1"}`, ExpiresAt: &later},
+		{Token: "other", Action: "unmute", Payload: `{"id":"1"}`, ExpiresAt: &later},
+	} {
+		if err := db.SaveCallbackToken(token); err != nil {
+			t.Fatalf("Failed to save token: %v", err)
+		}
+	}
+
+	record, err := db.FindCallbackToken("mute", `{"id":"1"}`, now)
+	if err != nil {
+		t.Fatalf("Failed to find token: %v", err)
+	}
+	if record.Token != "later" {
+		t.Errorf("Expected the token expiring last, got %q", record.Token)
+	}
+
+	if _, err := db.FindCallbackToken("mute", `{"id":"1"}`, now.Add(48*time.Hour)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound when no token is valid long enough, got %v", err)
+	}
+	if _, err := db.FindCallbackToken("mute", `{"id":"2"}`, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for another payload, got %v", err)
+	}
+}
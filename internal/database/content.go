@@ -2,6 +2,8 @@ package database
 
 import (
 	"fmt"
+	"time"
+
 	"jellyfin-telegram-bot/pkg/models"
 )
 
@@ -21,16 +23,33 @@ func (db *DB) IsContentNotified(jellyfinID string) (bool, error) {
 
 // MarkContentNotified marks content as notified by storing it in the cache
 func (db *DB) MarkContentNotified(jellyfinID, title, contentType string) error {
-	content := models.ContentCache{
+	return db.RecordContent(&models.ContentCache{
 		JellyfinID: jellyfinID,
 		Title:      title,
 		Type:       contentType,
-	}
+	})
+}
 
-	result := db.Create(&content)
+// RecordContent marks content as notified, keeping the metadata digests are built from
+func (db *DB) RecordContent(content *models.ContentCache) error {
+	result := db.Create(content)
 	if result.Error != nil {
 		return fmt.Errorf("failed to mark content as notified: %w", result.Error)
 	}
 
 	return nil
 }
+
+// GetContentSince returns the content recorded after the given time, oldest first
+func (db *DB) GetContentSince(since time.Time) ([]models.ContentCache, error) {
+	var content []models.ContentCache
+	result := db.Where("created_at > ?", since).
+		Order("created_at, id").
+		Find(&content)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get recent content: %w", result.Error)
+	}
+
+	return content, nil
+}
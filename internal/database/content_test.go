@@ -2,6 +2,9 @@ package database
 
 import (
 	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
 )
 
 // Test 7: Mark content as notified and check status
@@ -66,3 +69,38 @@ func TestPreventDuplicateNotifications(t *testing.T) {
 		t.Error("Expected error when marking duplicate content, got nil")
 	}
 }
+
+// Test 9: Recorded content keeps its metadata and can be listed since a point in time
+func TestGetContentSince(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.RecordContent(&models.ContentCache{JellyfinID: "old-1", Title: "Old Movie", Type: "Movie"})
+	since := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	err := db.RecordContent(&models.ContentCache{
+		JellyfinID:    "ep-1",
+		Title:         "Pilot",
+		Type:          "Episode",
+		SeriesName:    "Severance",
+		SeasonNumber:  1,
+		EpisodeNumber: 1,
+		Rating:        8.7,
+		Genres:        "Drama,Mystery",
+	})
+	if err != nil {
+		t.Fatalf("Failed to record content: %v", err)
+	}
+
+	content, err := db.GetContentSince(since)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if len(content) != 1 {
+		t.Fatalf("Expected 1 item since the cut-off, got %d", len(content))
+	}
+	if content[0].SeriesName != "Severance" || content[0].Rating != 8.7 || content[0].Genres != "Drama,Mystery" {
+		t.Errorf("Expected metadata to be kept, got %+v", content[0])
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
@@ -32,7 +33,18 @@ func NewDB(dbPath string) (*DB, error) {
 	slog.Info("Connected to database", "path", dbPath)
 
 	// Auto-migrate schema
-	if err := db.AutoMigrate(&models.Subscriber{}, &models.ContentCache{}, &models.MutedSeries{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.Subscriber{},
+		&models.ContentCache{},
+		&models.MutedSeries{},
+		&models.NotificationJob{},
+		&models.OutboxEntry{},
+		&models.CallbackToken{},
+		&models.Admin{},
+		&models.Invite{},
+		&models.Wish{},
+		&models.TitleRequest{},
+	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database schema: %w", err)
 	}
 
@@ -41,6 +53,15 @@ func NewDB(dbPath string) (*DB, error) {
 	return &DB{DB: db}, nil
 }
 
+// Ping verifies the database is usable by running a trivial query
+func (db *DB) Ping(ctx context.Context) error {
+	var one int
+	if err := db.WithContext(ctx).Raw("SELECT 1").Scan(&one).Error; err != nil {
+		return fmt.Errorf("failed to query database: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	sqlDB, err := db.DB.DB()
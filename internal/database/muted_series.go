@@ -1,84 +1,217 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"time"
 
 	"jellyfin-telegram-bot/pkg/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// AddMutedSeries adds a new muted series for a user
+// seriesKindLabels describe the kinds of series entries in logs and errors
+var seriesKindLabels = map[string]string{
+	models.SeriesKindMute:   "muted",
+	models.SeriesKindFollow: "followed",
+}
+
+// AddMutedSeries adds a new muted series for a user, replacing a follow of the series
 func (db *DB) AddMutedSeries(chatID int64, seriesID string, seriesName string) error {
-	mutedSeries := models.MutedSeries{
+	return db.addSeriesEntry(chatID, seriesID, seriesName, models.SeriesKindMute)
+}
+
+// RemoveMutedSeries removes a muted series for a user
+func (db *DB) RemoveMutedSeries(chatID int64, seriesID string) error {
+	return db.removeSeriesEntry(chatID, seriesID, models.SeriesKindMute)
+}
+
+// GetMutedSeriesByUser returns all muted series for a user
+func (db *DB) GetMutedSeriesByUser(chatID int64) ([]models.MutedSeries, error) {
+	return db.getSeriesEntries(chatID, models.SeriesKindMute)
+}
+
+// IsSeriesMuted checks if a series is muted for a user
+func (db *DB) IsSeriesMuted(chatID int64, seriesID string) (bool, error) {
+	return db.hasSeriesEntry(chatID, seriesID, models.SeriesKindMute)
+}
+
+// AddFollowedSeries adds a followed series for a user, replacing a mute of the series
+func (db *DB) AddFollowedSeries(chatID int64, seriesID string, seriesName string) error {
+	return db.addSeriesEntry(chatID, seriesID, seriesName, models.SeriesKindFollow)
+}
+
+// RemoveFollowedSeries removes a followed series for a user
+func (db *DB) RemoveFollowedSeries(chatID int64, seriesID string) error {
+	return db.removeSeriesEntry(chatID, seriesID, models.SeriesKindFollow)
+}
+
+// GetFollowedSeriesByUser returns all followed series for a user
+func (db *DB) GetFollowedSeriesByUser(chatID int64) ([]models.MutedSeries, error) {
+	return db.getSeriesEntries(chatID, models.SeriesKindFollow)
+}
+
+// IsSeriesFollowed checks if a user follows a series
+func (db *DB) IsSeriesFollowed(chatID int64, seriesID string) (bool, error) {
+	return db.hasSeriesEntry(chatID, seriesID, models.SeriesKindFollow)
+}
+
+// addSeriesEntry mutes or follows a series for a user
+// An existing entry of the series, also a removed one, takes the new kind
+func (db *DB) addSeriesEntry(chatID int64, seriesID, seriesName, kind string) error {
+	entry := models.MutedSeries{
 		ChatID:     chatID,
 		SeriesID:   seriesID,
 		SeriesName: seriesName,
+		Kind:       kind,
 	}
 
-	result := db.Create(&mutedSeries)
+	result := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chat_id"}, {Name: "series_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"kind":        kind,
+			"series_name": seriesName,
+			"deleted_at":  nil,
+			"updated_at":  time.Now(),
+		}),
+	}).Create(&entry)
 	if result.Error != nil {
-		// Handle duplicate constraint violations gracefully
-		// SQLite returns "UNIQUE constraint failed" error message
-		if errors.Is(result.Error, gorm.ErrDuplicatedKey) ||
-			strings.Contains(result.Error.Error(), "UNIQUE constraint failed") {
-			slog.Debug("Series already muted", "chat_id", chatID, "series_id", seriesID)
-			return nil
-		}
-		slog.Error("Failed to add muted series", "chat_id", chatID, "series_id", seriesID, "error", result.Error)
-		return fmt.Errorf("failed to add muted series: %w", result.Error)
+		slog.Error("Failed to add series entry", "chat_id", chatID, "series_id", seriesID, "kind", kind, "error", result.Error)
+		return fmt.Errorf("failed to add %s series: %w", seriesKindLabels[kind], result.Error)
 	}
 
-	slog.Info("Added muted series", "chat_id", chatID, "series_id", seriesID, "series_name", seriesName)
+	slog.Info("Added series entry", "chat_id", chatID, "series_id", seriesID, "series_name", seriesName, "kind", kind)
 	return nil
 }
 
-// RemoveMutedSeries removes a muted series for a user
-func (db *DB) RemoveMutedSeries(chatID int64, seriesID string) error {
-	result := db.Where("chat_id = ? AND series_id = ?", chatID, seriesID).Delete(&models.MutedSeries{})
+// removeSeriesEntry removes a mute or follow of a series for a user
+func (db *DB) removeSeriesEntry(chatID int64, seriesID, kind string) error {
+	result := db.Where("chat_id = ? AND series_id = ? AND kind = ?", chatID, seriesID, kind).Delete(&models.MutedSeries{})
 
 	if result.Error != nil {
-		slog.Error("Failed to remove muted series", "chat_id", chatID, "series_id", seriesID, "error", result.Error)
-		return fmt.Errorf("failed to remove muted series: %w", result.Error)
+		slog.Error("Failed to remove series entry", "chat_id", chatID, "series_id", seriesID, "kind", kind, "error", result.Error)
+		return fmt.Errorf("failed to remove %s series: %w", seriesKindLabels[kind], result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		slog.Debug("No muted series found to remove", "chat_id", chatID, "series_id", seriesID)
+		slog.Debug("No series entry found to remove", "chat_id", chatID, "series_id", seriesID, "kind", kind)
 		return gorm.ErrRecordNotFound
 	}
 
-	slog.Info("Removed muted series", "chat_id", chatID, "series_id", seriesID)
+	slog.Info("Removed series entry", "chat_id", chatID, "series_id", seriesID, "kind", kind)
 	return nil
 }
 
-// GetMutedSeriesByUser returns all muted series for a user
-func (db *DB) GetMutedSeriesByUser(chatID int64) ([]models.MutedSeries, error) {
-	var mutedSeries []models.MutedSeries
-	result := db.Where("chat_id = ?", chatID).Find(&mutedSeries)
+// getSeriesEntries returns the muted or followed series of a user
+func (db *DB) getSeriesEntries(chatID int64, kind string) ([]models.MutedSeries, error) {
+	var entries []models.MutedSeries
+	result := db.Where("chat_id = ? AND kind = ?", chatID, kind).Find(&entries)
 
 	if result.Error != nil {
-		slog.Error("Failed to get muted series", "chat_id", chatID, "error", result.Error)
-		return nil, fmt.Errorf("failed to get muted series: %w", result.Error)
+		slog.Error("Failed to get series entries", "chat_id", chatID, "kind", kind, "error", result.Error)
+		return nil, fmt.Errorf("failed to get %s series: %w", seriesKindLabels[kind], result.Error)
 	}
 
-	slog.Debug("Retrieved muted series", "chat_id", chatID, "count", len(mutedSeries))
-	return mutedSeries, nil
+	slog.Debug("Retrieved series entries", "chat_id", chatID, "kind", kind, "count", len(entries))
+	return entries, nil
 }
 
-// IsSeriesMuted checks if a series is muted for a user
-func (db *DB) IsSeriesMuted(chatID int64, seriesID string) (bool, error) {
+// hasSeriesEntry checks if a user muted or follows a series
+func (db *DB) hasSeriesEntry(chatID int64, seriesID, kind string) (bool, error) {
 	var count int64
 	result := db.Model(&models.MutedSeries{}).
-		Where("chat_id = ? AND series_id = ?", chatID, seriesID).
+		Where("chat_id = ? AND series_id = ? AND kind = ?", chatID, seriesID, kind).
 		Count(&count)
 
 	if result.Error != nil {
-		slog.Error("Failed to check if series is muted", "chat_id", chatID, "series_id", seriesID, "error", result.Error)
-		return false, fmt.Errorf("failed to check if series is muted: %w", result.Error)
+		slog.Error("Failed to check series entry", "chat_id", chatID, "series_id", seriesID, "kind", kind, "error", result.Error)
+		return false, fmt.Errorf("failed to check if series is %s: %w", seriesKindLabels[kind], result.Error)
 	}
 
 	return count > 0, nil
 }
+
+// SeriesResolver looks up the Jellyfin ID of a series by its name
+type SeriesResolver interface {
+	FindSeriesID(ctx context.Context, seriesName string) (string, error)
+}
+
+// MigrateMutedSeriesIDs resolves mutes stored by series name to Jellyfin series IDs
+// Earlier versions stored the name in SeriesID; rows that can't be resolved yet are kept
+// and retried on the next run, so the migration is safe to run at every start
+func (db *DB) MigrateMutedSeriesIDs(ctx context.Context, resolver SeriesResolver) (int, error) {
+	var legacy []models.MutedSeries
+	if err := db.Where("series_id = series_name").Find(&legacy).Error; err != nil {
+		return 0, fmt.Errorf("failed to get name-based muted series: %w", err)
+	}
+	if len(legacy) == 0 {
+		return 0, nil
+	}
+
+	// Several users usually mute the same series, so each name is looked up once
+	resolved := make(map[string]string)
+	migrated := 0
+
+	for _, mute := range legacy {
+		if ctx.Err() != nil {
+			return migrated, ctx.Err()
+		}
+
+		seriesID, ok := resolved[mute.SeriesName]
+		if !ok {
+			id, err := resolver.FindSeriesID(ctx, mute.SeriesName)
+			if err != nil {
+				slog.Warn("Failed to resolve muted series, keeping name-based mute",
+					"series_name", mute.SeriesName,
+					"error", err)
+			}
+			seriesID = id
+			resolved[mute.SeriesName] = id
+		}
+		if seriesID == "" {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var existing models.MutedSeries
+			err := tx.Unscoped().
+				Where("chat_id = ? AND series_id = ?", mute.ChatID, seriesID).
+				First(&existing).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			if err == nil {
+				// The user already muted or follows the series by ID, the name-based row is redundant
+				if !existing.DeletedAt.Valid {
+					return tx.Unscoped().Delete(&models.MutedSeries{}, mute.ID).Error
+				}
+				// A removed entry of the series would still clash with the unique index
+				if err := tx.Unscoped().Delete(&models.MutedSeries{}, existing.ID).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&models.MutedSeries{}).
+				Where("id = ?", mute.ID).
+				Update("series_id", seriesID).Error
+		})
+		if err != nil {
+			// Other rows are still migrated, this one is retried on the next run
+			slog.Error("Failed to migrate muted series",
+				"chat_id", mute.ChatID,
+				"series_name", mute.SeriesName,
+				"error", err)
+			continue
+		}
+		migrated++
+	}
+
+	slog.Info("Migrated name-based muted series to series IDs",
+		"migrated", migrated,
+		"remaining", len(legacy)-migrated)
+
+	return migrated, nil
+}
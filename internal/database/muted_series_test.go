@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"gorm.io/gorm"
@@ -210,3 +212,123 @@ func TestIsSeriesMuted(t *testing.T) {
 		t.Error("Expected series to be unmuted")
 	}
 }
+
+// mockSeriesResolver resolves series names from a fixed map
+type mockSeriesResolver struct {
+	ids     map[string]string
+	lookups int
+}
+
+func (m *mockSeriesResolver) FindSeriesID(ctx context.Context, seriesName string) (string, error) {
+	m.lookups++
+	id, ok := m.ids[seriesName]
+	if !ok {
+		return "", errors.New("series not found")
+	}
+	return id, nil
+}
+
+// Test 9: Name-based mutes are migrated to series IDs, unresolved ones are kept
+func TestMigrateMutedSeriesIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddMutedSeries(1, "Breaking Bad", "Breaking Bad")
+	db.AddMutedSeries(2, "Breaking Bad", "Breaking Bad")
+	db.AddMutedSeries(2, "bb-id", "Breaking Bad") // Already muted by ID as well
+	db.AddMutedSeries(3, "Lost Show", "Lost Show")
+
+	resolver := &mockSeriesResolver{ids: map[string]string{"Breaking Bad": "bb-id"}}
+	migrated, err := db.MigrateMutedSeriesIDs(context.Background(), resolver)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if migrated != 2 {
+		t.Errorf("Expected 2 migrated mutes, got %d", migrated)
+	}
+	if resolver.lookups != 2 {
+		t.Errorf("Expected each series name to be looked up once, got %d lookups", resolver.lookups)
+	}
+
+	for _, chatID := range []int64{1, 2} {
+		series, _ := db.GetMutedSeriesByUser(chatID)
+		if len(series) != 1 || series[0].SeriesID != "bb-id" || series[0].SeriesName != "Breaking Bad" {
+			t.Errorf("User %d: expected a single ID-based mute, got %+v", chatID, series)
+		}
+	}
+	if muted, _ := db.IsSeriesMuted(3, "Lost Show"); !muted {
+		t.Error("Unresolved mutes should be kept by name")
+	}
+
+	// Running again only retries the unresolved mute
+	resolver.lookups = 0
+	if migrated, _ := db.MigrateMutedSeriesIDs(context.Background(), resolver); migrated != 0 || resolver.lookups != 1 {
+		t.Errorf("Expected only the unresolved mute to be retried, got %d migrated and %d lookups", migrated, resolver.lookups)
+	}
+}
+
+// Test 10: A series is either muted or followed, and unmuting then muting again works
+func TestFollowedSeries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddMutedSeries(1, "s1", "Severance")
+	if err := db.AddFollowedSeries(1, "s1", "Severance"); err != nil {
+		t.Fatalf("Failed to follow series: %v", err)
+	}
+	if muted, _ := db.IsSeriesMuted(1, "s1"); muted {
+		t.Error("Following a muted series should remove the mute")
+	}
+	if followed, _ := db.IsSeriesFollowed(1, "s1"); !followed {
+		t.Error("Expected series to be followed")
+	}
+	if err := db.RemoveMutedSeries(1, "s1"); err != gorm.ErrRecordNotFound {
+		t.Errorf("Unmuting a followed series should find nothing, got %v", err)
+	}
+
+	followed, _ := db.GetFollowedSeriesByUser(1)
+	if len(followed) != 1 || followed[0].SeriesName != "Severance" {
+		t.Errorf("Expected one followed series, got %+v", followed)
+	}
+
+	if err := db.RemoveFollowedSeries(1, "s1"); err != nil {
+		t.Fatalf("Failed to unfollow series: %v", err)
+	}
+	if err := db.AddFollowedSeries(1, "s1", "Severance"); err != nil {
+		t.Fatalf("Failed to follow series again: %v", err)
+	}
+	if followed, _ := db.IsSeriesFollowed(1, "s1"); !followed {
+		t.Error("Expected series to be followed again after unfollowing")
+	}
+}
+
+// Test 11: Migrating a name-based mute replaces a removed ID-based entry of the series
+func TestMigrateMutedSeriesIDs_RemovedEntry(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddMutedSeries(1, "Breaking Bad", "Breaking Bad")
+	db.AddMutedSeries(1, "bb-id", "Breaking Bad")
+	if err := db.RemoveMutedSeries(1, "bb-id"); err != nil {
+		t.Fatalf("Failed to remove mute: %v", err)
+	}
+	db.AddMutedSeries(2, "Breaking Bad", "Breaking Bad")
+
+	resolver := &mockSeriesResolver{ids: map[string]string{"Breaking Bad": "bb-id"}}
+	migrated, err := db.MigrateMutedSeriesIDs(context.Background(), resolver)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if migrated != 2 {
+		t.Errorf("Expected 2 migrated mutes, got %d", migrated)
+	}
+
+	for _, chatID := range []int64{1, 2} {
+		if muted, _ := db.IsSeriesMuted(chatID, "bb-id"); !muted {
+			t.Errorf("User %d: expected the series to be muted by ID", chatID)
+		}
+		if muted, _ := db.IsSeriesMuted(chatID, "Breaking Bad"); muted {
+			t.Errorf("User %d: expected the name-based mute to be gone", chatID)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnqueueNotification stores a notification job in the outbox
+// Jobs with a batch key postpone every pending job of the same batch to availableAt,
+// so a burst of related items is only processed once the burst is over
+func (db *DB) EnqueueNotification(jellyfinID, batchKey, payload string, availableAt time.Time) error {
+	job := models.NotificationJob{
+		JellyfinID:  jellyfinID,
+		Payload:     payload,
+		Status:      models.JobStatusPending,
+		BatchKey:    batchKey,
+		AvailableAt: availableAt,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if batchKey != "" {
+			if err := tx.Model(&models.NotificationJob{}).
+				Where("batch_key = ? AND status = ?", batchKey, models.JobStatusPending).
+				Update("available_at", availableAt).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&job).Error
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	slog.Debug("Notification job enqueued",
+		"job_id", job.ID,
+		"item_id", jellyfinID,
+		"batch_key", batchKey,
+		"available_at", availableAt)
+	return nil
+}
+
+// EnqueueRevision stores a job editing or retracting the notifications of an item
+// kind is models.JobKindUpdate or models.JobKindDelete; the payload carries the new content of updates
+func (db *DB) EnqueueRevision(jellyfinID, kind, payload string) error {
+	job := models.NotificationJob{
+		JellyfinID:  jellyfinID,
+		Payload:     payload,
+		Status:      models.JobStatusPending,
+		AvailableAt: time.Now(),
+		Kind:        kind,
+	}
+
+	if err := db.Create(&job).Error; err != nil {
+		return fmt.Errorf("failed to enqueue revision: %w", err)
+	}
+
+	slog.Debug("Revision job enqueued",
+		"job_id", job.ID,
+		"item_id", jellyfinID,
+		"kind", kind)
+	return nil
+}
+
+// GetItemNotificationJobs returns the notification jobs of an item created since the given time, oldest first
+// A job merged into another job of its batch is replaced by that job, which carries the batch's notification
+func (db *DB) GetItemNotificationJobs(jellyfinID string, since time.Time) ([]models.NotificationJob, error) {
+	var jobs []models.NotificationJob
+	result := db.Where("jellyfin_id = ? AND kind = ? AND created_at >= ?", jellyfinID, "", since).
+		Order("id").
+		Find(&jobs)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get item notification jobs: %w", result.Error)
+	}
+
+	ids := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		switch {
+		case job.Status != models.JobStatusMerged:
+			ids = append(ids, job.ID)
+		case job.MergedInto != 0:
+			ids = append(ids, job.MergedInto)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	jobs = nil
+	if err := db.Where("id IN ?", ids).Order("id").Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get item notification jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// SetNotificationPayload replaces the content of a notification job, so deliveries still to come use it
+func (db *DB) SetNotificationPayload(jobID uint, payload string) error {
+	result := db.Model(&models.NotificationJob{}).
+		Where("id = ?", jobID).
+		Update("payload", payload)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set notification payload: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetSentDeliveries returns the deliveries of a job whose Telegram message can still be revised
+func (db *DB) GetSentDeliveries(jobID uint) ([]models.OutboxEntry, error) {
+	var entries []models.OutboxEntry
+	result := db.Where("job_id = ? AND status = ? AND message_id <> 0", jobID, models.DeliveryStatusSent).
+		Order("id").
+		Find(&entries)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get sent deliveries: %w", result.Error)
+	}
+
+	return entries, nil
+}
+
+// ForgetDeliveryMessage stops revising the message of a delivery once it was retracted or is gone
+func (db *DB) ForgetDeliveryMessage(entryID uint) error {
+	result := db.Model(&models.OutboxEntry{}).
+		Where("id = ?", entryID).
+		Update("message_id", 0)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to forget delivery message: %w", result.Error)
+	}
+
+	return nil
+}
+
+// CancelDeliveries cancels the deliveries of a job that weren't sent yet, including deferred ones
+func (db *DB) CancelDeliveries(jobID uint) (int64, error) {
+	result := db.Model(&models.OutboxEntry{}).
+		Where("job_id = ? AND status IN ?", jobID, []string{models.DeliveryStatusPending, models.DeliveryStatusDeferred}).
+		Update("status", models.DeliveryStatusCancelled)
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to cancel deliveries: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetDueJobs returns the notification jobs that can be worked on at the given time, oldest first
+func (db *DB) GetDueJobs(now time.Time) ([]models.NotificationJob, error) {
+	var jobs []models.NotificationJob
+	result := db.Where("status = ? OR (status = ? AND available_at <= ?)",
+		models.JobStatusExpanded, models.JobStatusPending, now).
+		Order("id").
+		Find(&jobs)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get due notification jobs: %w", result.Error)
+	}
+
+	return jobs, nil
+}
+
+// GetBatchJobs returns the pending jobs of a batch, oldest first
+func (db *DB) GetBatchJobs(batchKey string) ([]models.NotificationJob, error) {
+	var jobs []models.NotificationJob
+	result := db.Where("batch_key = ? AND status = ?", batchKey, models.JobStatusPending).
+		Order("id").
+		Find(&jobs)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get batch jobs: %w", result.Error)
+	}
+
+	return jobs, nil
+}
+
+// MergeNotificationJobs replaces the payload of the primary job with the aggregated
+// payload and marks the other jobs of the batch as merged
+func (db *DB) MergeNotificationJobs(primaryID uint, payload string, mergedIDs []uint) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.NotificationJob{}).
+			Where("id = ?", primaryID).
+			Update("payload", payload).Error; err != nil {
+			return err
+		}
+
+		if len(mergedIDs) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.NotificationJob{}).
+			Where("id IN ?", mergedIDs).
+			Updates(map[string]interface{}{
+				"status":      models.JobStatusMerged,
+				"merged_into": primaryID,
+			}).Error
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to merge notification jobs: %w", err)
+	}
+
+	return nil
+}
+
+// ExpandNotificationJob creates one pending delivery per recipient and marks the job as expanded
+// Deliveries of uncheckedIDs are flagged, so the recipient's access to the item is checked again before sending
+func (db *DB) ExpandNotificationJob(jobID uint, chatIDs []int64, uncheckedIDs []int64) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(chatIDs)+len(uncheckedIDs) > 0 {
+			entries := make([]models.OutboxEntry, 0, len(chatIDs)+len(uncheckedIDs))
+			for _, chatID := range chatIDs {
+				entries = append(entries, models.OutboxEntry{
+					JobID:  jobID,
+					ChatID: chatID,
+					Status: models.DeliveryStatusPending,
+				})
+			}
+			for _, chatID := range uncheckedIDs {
+				entries = append(entries, models.OutboxEntry{
+					JobID:           jobID,
+					ChatID:          chatID,
+					Status:          models.DeliveryStatusPending,
+					AccessUnchecked: true,
+				})
+			}
+
+			// Ignore recipients already present from a partially applied expansion
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entries).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.NotificationJob{}).
+			Where("id = ?", jobID).
+			Update("status", models.JobStatusExpanded).Error
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to expand notification job: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingDeliveries returns the deliveries of a job that have not been completed yet
+func (db *DB) GetPendingDeliveries(jobID uint) ([]models.OutboxEntry, error) {
+	var entries []models.OutboxEntry
+	result := db.Where("job_id = ? AND status = ?", jobID, models.DeliveryStatusPending).
+		Order("id").
+		Find(&entries)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get pending deliveries: %w", result.Error)
+	}
+
+	return entries, nil
+}
+
+// MarkDeliverySent marks a delivery as successfully sent and remembers its Telegram message,
+// so it can be edited or retracted later; messageID is 0 if the message can't be revised
+func (db *DB) MarkDeliverySent(entryID uint, messageID int, photo bool) error {
+	result := db.Model(&models.OutboxEntry{}).
+		Where("id = ?", entryID).
+		Updates(map[string]interface{}{
+			"status":        models.DeliveryStatusSent,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"message_id":    messageID,
+			"photo_message": photo,
+			"sent_at":       time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark delivery as sent: %w", result.Error)
+	}
+
+	return nil
+}
+
+// MarkDeliveryFailed records a failed delivery attempt
+// The delivery keeps its status (pending or deferred) for a retry at retryAt until maxAttempts is reached
+func (db *DB) MarkDeliveryFailed(entryID uint, lastError string, maxAttempts int, retryAt time.Time) error {
+	var entry models.OutboxEntry
+	if err := db.First(&entry, entryID).Error; err != nil {
+		return fmt.Errorf("failed to load delivery: %w", err)
+	}
+
+	status := entry.Status
+	if entry.Attempts+1 >= maxAttempts {
+		status = models.DeliveryStatusFailed
+	}
+
+	result := db.Model(&entry).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        entry.Attempts + 1,
+		"last_error":      lastError,
+		"next_attempt_at": retryAt,
+	})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark delivery as failed: %w", result.Error)
+	}
+
+	return nil
+}
+
+// MarkDeliveryBlocked marks a delivery as undeliverable because the recipient blocked the bot
+func (db *DB) MarkDeliveryBlocked(entryID uint, lastError string) error {
+	result := db.Model(&models.OutboxEntry{}).
+		Where("id = ?", entryID).
+		Updates(map[string]interface{}{
+			"status":     models.DeliveryStatusBlocked,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": lastError,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark delivery as blocked: %w", result.Error)
+	}
+
+	return nil
+}
+
+// CancelDelivery calls off a single delivery that wasn't sent yet
+func (db *DB) CancelDelivery(entryID uint) error {
+	result := db.Model(&models.OutboxEntry{}).
+		Where("id = ?", entryID).
+		Update("status", models.DeliveryStatusCancelled)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel delivery: %w", result.Error)
+	}
+
+	return nil
+}
+
+// DeferDelivery holds a delivery back until the given time (the end of the recipient's quiet hours)
+func (db *DB) DeferDelivery(entryID uint, until time.Time) error {
+	result := db.Model(&models.OutboxEntry{}).
+		Where("id = ?", entryID).
+		Updates(map[string]interface{}{
+			"status":        models.DeliveryStatusDeferred,
+			"deliver_after": until,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to defer delivery: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetDueDeferredDeliveries returns the deferred deliveries whose hold, and retry delay after a failure,
+// has expired at the given time
+func (db *DB) GetDueDeferredDeliveries(now time.Time) ([]models.OutboxEntry, error) {
+	var entries []models.OutboxEntry
+	result := db.Where("status = ? AND deliver_after <= ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
+		models.DeliveryStatusDeferred, now, now).
+		Order("chat_id, id").
+		Find(&entries)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get deferred deliveries: %w", result.Error)
+	}
+
+	return entries, nil
+}
+
+// GetNotificationJobs returns the notification jobs with the given IDs, oldest first
+func (db *DB) GetNotificationJobs(ids []uint) ([]models.NotificationJob, error) {
+	var jobs []models.NotificationJob
+	if len(ids) == 0 {
+		return jobs, nil
+	}
+
+	result := db.Where("id IN ?", ids).Order("id").Find(&jobs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get notification jobs: %w", result.Error)
+	}
+
+	return jobs, nil
+}
+
+// CompleteNotificationJob marks a notification job as done
+func (db *DB) CompleteNotificationJob(jobID uint) error {
+	result := db.Model(&models.NotificationJob{}).
+		Where("id = ?", jobID).
+		Update("status", models.JobStatusDone)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete notification job: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetOutboxBacklog counts the jobs and deliveries that haven't been completed yet
+func (db *DB) GetOutboxBacklog() (*models.OutboxBacklog, error) {
+	backlog := &models.OutboxBacklog{}
+
+	result := db.Model(&models.NotificationJob{}).
+		Where("status IN ?", []string{models.JobStatusPending, models.JobStatusExpanded}).
+		Count(&backlog.PendingJobs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to count pending jobs: %w", result.Error)
+	}
+
+	result = db.Model(&models.OutboxEntry{}).
+		Where("status = ?", models.DeliveryStatusPending).
+		Count(&backlog.PendingDeliveries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to count pending deliveries: %w", result.Error)
+	}
+
+	result = db.Model(&models.OutboxEntry{}).
+		Where("status = ?", models.DeliveryStatusDeferred).
+		Count(&backlog.DeferredDeliveries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to count deferred deliveries: %w", result.Error)
+	}
+
+	return backlog, nil
+}
@@ -0,0 +1,385 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Test 1: Enqueued jobs are returned as due until completed
+func TestEnqueueNotification(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.EnqueueNotification("movie-1", "", `{"ItemID":"movie-1"}`, time.Now()); err != nil {
+		t.Fatalf("Failed to enqueue notification: %v", err)
+	}
+
+	jobs, err := db.GetDueJobs(time.Now())
+	if err != nil {
+		t.Fatalf("Failed to get due jobs: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("Expected 1 due job, got %d", len(jobs))
+	}
+	if jobs[0].Status != models.JobStatusPending {
+		t.Errorf("Expected status %q, got %q", models.JobStatusPending, jobs[0].Status)
+	}
+
+	if err := db.CompleteNotificationJob(jobs[0].ID); err != nil {
+		t.Fatalf("Failed to complete job: %v", err)
+	}
+
+	jobs, err = db.GetDueJobs(time.Now())
+	if err != nil {
+		t.Fatalf("Failed to get due jobs: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("Expected 0 due jobs after completion, got %d", len(jobs))
+	}
+}
+
+// Test 2: Expanding a job creates one pending delivery per recipient
+func TestExpandNotificationJob(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.EnqueueNotification("episode-1", "", `{}`, time.Now())
+	jobs, _ := db.GetDueJobs(time.Now())
+	jobID := jobs[0].ID
+
+	if err := db.ExpandNotificationJob(jobID, []int64{111, 222, 333}, nil); err != nil {
+		t.Fatalf("Failed to expand job: %v", err)
+	}
+
+	// Expanding twice (e.g. after a crash) must not duplicate deliveries
+	if err := db.ExpandNotificationJob(jobID, []int64{111, 222, 333}, nil); err != nil {
+		t.Fatalf("Failed to expand job a second time: %v", err)
+	}
+
+	deliveries, err := db.GetPendingDeliveries(jobID)
+	if err != nil {
+		t.Fatalf("Failed to get pending deliveries: %v", err)
+	}
+	if len(deliveries) != 3 {
+		t.Errorf("Expected 3 pending deliveries, got %d", len(deliveries))
+	}
+
+	jobs, _ = db.GetDueJobs(time.Now())
+	if jobs[0].Status != models.JobStatusExpanded {
+		t.Errorf("Expected status %q, got %q", models.JobStatusExpanded, jobs[0].Status)
+	}
+}
+
+// Test 3: Delivery state transitions (sent, blocked, retried, failed)
+func TestDeliveryStatusTransitions(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.EnqueueNotification("movie-2", "", `{}`, time.Now())
+	jobs, _ := db.GetDueJobs(time.Now())
+	jobID := jobs[0].ID
+	db.ExpandNotificationJob(jobID, []int64{1, 2, 3}, nil)
+
+	deliveries, _ := db.GetPendingDeliveries(jobID)
+
+	if err := db.MarkDeliverySent(deliveries[0].ID, 1, false); err != nil {
+		t.Fatalf("Failed to mark delivery sent: %v", err)
+	}
+	if err := db.MarkDeliveryBlocked(deliveries[1].ID, "bot was blocked"); err != nil {
+		t.Fatalf("Failed to mark delivery blocked: %v", err)
+	}
+
+	// First failure keeps the delivery pending for a retry
+	if err := db.MarkDeliveryFailed(deliveries[2].ID, "timeout", 2, time.Now()); err != nil {
+		t.Fatalf("Failed to mark delivery failed: %v", err)
+	}
+	pending, _ := db.GetPendingDeliveries(jobID)
+	if len(pending) != 1 {
+		t.Fatalf("Expected 1 pending delivery after first failure, got %d", len(pending))
+	}
+	if pending[0].Attempts != 1 || pending[0].LastError != "timeout" {
+		t.Errorf("Expected 1 attempt with last error recorded, got %d / %q", pending[0].Attempts, pending[0].LastError)
+	}
+
+	// Second failure exhausts the attempts
+	db.MarkDeliveryFailed(deliveries[2].ID, "timeout", 2, time.Now())
+	pending, _ = db.GetPendingDeliveries(jobID)
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending deliveries after max attempts, got %d", len(pending))
+	}
+
+	var failed models.OutboxEntry
+	db.First(&failed, deliveries[2].ID)
+	if failed.Status != models.DeliveryStatusFailed {
+		t.Errorf("Expected status %q, got %q", models.DeliveryStatusFailed, failed.Status)
+	}
+}
+
+// Test 4: Jobs of a batch are postponed by every new job of the batch and can be merged
+func TestBatchedNotificationJobs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.EnqueueNotification("ep-1", "Dark|S1", `{}`, now.Add(time.Minute))
+	db.EnqueueNotification("ep-2", "Dark|S1", `{}`, now.Add(2*time.Minute))
+	db.EnqueueNotification("movie-1", "", `{}`, now)
+
+	// Only the movie is due; the first episode was postponed by the second
+	jobs, err := db.GetDueJobs(now.Add(90 * time.Second))
+	if err != nil {
+		t.Fatalf("Failed to get due jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].JellyfinID != "movie-1" {
+		t.Fatalf("Expected only the movie to be due, got %+v", jobs)
+	}
+
+	batch, err := db.GetBatchJobs("Dark|S1")
+	if err != nil {
+		t.Fatalf("Failed to get batch jobs: %v", err)
+	}
+	if len(batch) != 2 {
+		t.Fatalf("Expected 2 batch jobs, got %d", len(batch))
+	}
+
+	if err := db.MergeNotificationJobs(batch[0].ID, `{"EpisodeCount":2}`, []uint{batch[1].ID}); err != nil {
+		t.Fatalf("Failed to merge jobs: %v", err)
+	}
+
+	jobs, _ = db.GetDueJobs(now.Add(3 * time.Minute))
+	if len(jobs) != 2 {
+		t.Fatalf("Expected the merged job and the movie to be due, got %d jobs", len(jobs))
+	}
+	if jobs[0].ID != batch[0].ID || jobs[0].Payload != `{"EpisodeCount":2}` {
+		t.Errorf("Expected primary job to carry the aggregated payload, got %+v", jobs[0])
+	}
+}
+
+// Test 5: Deferred deliveries become due once their hold expires and survive failed attempts
+func TestDeferredDeliveries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.EnqueueNotification("movie-1", "", `{"Title":"Arrival"}`, now)
+	jobs, _ := db.GetDueJobs(now)
+	if err := db.ExpandNotificationJob(jobs[0].ID, []int64{100, 200}, nil); err != nil {
+		t.Fatalf("Failed to expand job: %v", err)
+	}
+
+	deliveries, _ := db.GetPendingDeliveries(jobs[0].ID)
+	if err := db.DeferDelivery(deliveries[1].ID, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to defer delivery: %v", err)
+	}
+
+	pending, _ := db.GetPendingDeliveries(jobs[0].ID)
+	if len(pending) != 1 || pending[0].ChatID != 100 {
+		t.Fatalf("Expected only the undeferred delivery to be pending, got %+v", pending)
+	}
+
+	due, err := db.GetDueDeferredDeliveries(now)
+	if err != nil {
+		t.Fatalf("Failed to get deferred deliveries: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("Expected no deferred delivery to be due yet, got %d", len(due))
+	}
+
+	due, _ = db.GetDueDeferredDeliveries(now.Add(2 * time.Hour))
+	if len(due) != 1 || due[0].ChatID != 200 {
+		t.Fatalf("Expected the deferred delivery to be due, got %+v", due)
+	}
+
+	// A failed attempt keeps the delivery deferred until attempts run out
+	db.MarkDeliveryFailed(due[0].ID, "timeout", 2, now.Add(3*time.Hour))
+	if due, _ = db.GetDueDeferredDeliveries(now.Add(2 * time.Hour)); len(due) != 0 {
+		t.Fatalf("Expected the failed delivery to wait for its retry, got %+v", due)
+	}
+	due, _ = db.GetDueDeferredDeliveries(now.Add(3 * time.Hour))
+	if len(due) != 1 {
+		t.Fatalf("Expected the delivery to stay deferred after one failure")
+	}
+	db.MarkDeliveryFailed(due[0].ID, "timeout", 2, now.Add(3*time.Hour))
+	due, _ = db.GetDueDeferredDeliveries(now.Add(3 * time.Hour))
+	if len(due) != 0 {
+		t.Errorf("Expected the delivery to be given up after max attempts")
+	}
+
+	loaded, err := db.GetNotificationJobs([]uint{jobs[0].ID})
+	if err != nil || len(loaded) != 1 || loaded[0].Payload != `{"Title":"Arrival"}` {
+		t.Errorf("Expected to load the job payload, got %+v (err %v)", loaded, err)
+	}
+}
+
+// Test 6: The backlog counts unfinished jobs and pending and deferred deliveries
+func TestGetOutboxBacklog(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected the database to respond, got %v", err)
+	}
+
+	now := time.Now()
+	db.EnqueueNotification("movie-1", "", `{"Title":"Arrival"}`, now)
+	db.EnqueueNotification("movie-2", "", `{"Title":"Dune"}`, now)
+	jobs, _ := db.GetDueJobs(now)
+	db.ExpandNotificationJob(jobs[0].ID, []int64{100, 200, 300}, nil)
+
+	deliveries, _ := db.GetPendingDeliveries(jobs[0].ID)
+	db.MarkDeliverySent(deliveries[0].ID, 1, false)
+	db.DeferDelivery(deliveries[1].ID, now.Add(time.Hour))
+
+	backlog, err := db.GetOutboxBacklog()
+	if err != nil {
+		t.Fatalf("Failed to get backlog: %v", err)
+	}
+	want := models.OutboxBacklog{PendingJobs: 2, PendingDeliveries: 1, DeferredDeliveries: 1}
+	if *backlog != want {
+		t.Errorf("Expected backlog %+v, got %+v", want, *backlog)
+	}
+}
+
+// Test 7: Sent messages of an item's notifications can be found for revisions until they are forgotten
+func TestNotificationRevisions(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.EnqueueNotification("movie-1", "", `{"Title":"Arrival"}`, now)
+	jobs, _ := db.GetDueJobs(now)
+	jobID := jobs[0].ID
+	db.ExpandNotificationJob(jobID, []int64{100, 200, 300}, nil)
+	deliveries, _ := db.GetPendingDeliveries(jobID)
+	db.MarkDeliverySent(deliveries[0].ID, 11, true)
+	db.MarkDeliverySent(deliveries[1].ID, 0, false) // A catch-up can't be revised
+	db.MarkDeliverySent(deliveries[2].ID, 33, false)
+
+	if err := db.EnqueueRevision("movie-1", models.JobKindUpdate, `{"Title":"Arrival (2016)"}`); err != nil {
+		t.Fatalf("Failed to enqueue revision: %v", err)
+	}
+
+	itemJobs, err := db.GetItemNotificationJobs("movie-1", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to get item notification jobs: %v", err)
+	}
+	if len(itemJobs) != 1 || itemJobs[0].ID != jobID {
+		t.Fatalf("Expected only the notification job, got %+v", itemJobs)
+	}
+	if jobs, _ := db.GetItemNotificationJobs("movie-1", now.Add(time.Minute)); len(jobs) != 0 {
+		t.Errorf("Expected jobs older than the window to be left out, got %+v", jobs)
+	}
+
+	sent, err := db.GetSentDeliveries(jobID)
+	if err != nil {
+		t.Fatalf("Failed to get sent deliveries: %v", err)
+	}
+	if len(sent) != 2 || sent[0].MessageID != 11 || !sent[0].PhotoMessage || sent[1].MessageID != 33 {
+		t.Fatalf("Expected the two revisable messages, got %+v", sent)
+	}
+
+	if err := db.SetNotificationPayload(jobID, `{"Title":"Arrival (2016)"}`); err != nil {
+		t.Fatalf("Failed to set payload: %v", err)
+	}
+	if err := db.ForgetDeliveryMessage(sent[0].ID); err != nil {
+		t.Fatalf("Failed to forget message: %v", err)
+	}
+	if sent, _ := db.GetSentDeliveries(jobID); len(sent) != 1 {
+		t.Errorf("Expected the forgotten message to be left out, got %+v", sent)
+	}
+
+	stored, _ := db.GetNotificationJobs([]uint{jobID})
+	if stored[0].Payload != `{"Title":"Arrival (2016)"}` {
+		t.Errorf("Expected the updated payload, got %q", stored[0].Payload)
+	}
+}
+
+// Test 8: Cancelling a job's deliveries only touches the ones that weren't sent yet
+func TestCancelDeliveries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.EnqueueNotification("movie-1", "", `{"Title":"Arrival"}`, now)
+	jobs, _ := db.GetDueJobs(now)
+	jobID := jobs[0].ID
+	db.ExpandNotificationJob(jobID, []int64{100, 200, 300}, nil)
+	deliveries, _ := db.GetPendingDeliveries(jobID)
+	db.MarkDeliverySent(deliveries[0].ID, 11, false)
+	db.DeferDelivery(deliveries[1].ID, now.Add(time.Hour))
+
+	cancelled, err := db.CancelDeliveries(jobID)
+	if err != nil {
+		t.Fatalf("Failed to cancel deliveries: %v", err)
+	}
+	if cancelled != 2 {
+		t.Errorf("Expected 2 cancelled deliveries, got %d", cancelled)
+	}
+
+	if pending, _ := db.GetPendingDeliveries(jobID); len(pending) != 0 {
+		t.Errorf("Expected no pending deliveries, got %+v", pending)
+	}
+	if deferred, _ := db.GetDueDeferredDeliveries(now.Add(2 * time.Hour)); len(deferred) != 0 {
+		t.Errorf("Expected no deferred deliveries, got %+v", deferred)
+	}
+	if sent, _ := db.GetSentDeliveries(jobID); len(sent) != 1 {
+		t.Errorf("Expected the sent delivery to stay, got %+v", sent)
+	}
+}
+
+// Test 9: Recipients whose access couldn't be checked get flagged deliveries that can be cancelled one by one
+func TestExpandNotificationJob_UncheckedAccess(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.EnqueueNotification("movie-1", "", `{}`, time.Now())
+	jobs, _ := db.GetDueJobs(time.Now())
+	jobID := jobs[0].ID
+
+	if err := db.ExpandNotificationJob(jobID, []int64{100}, []int64{200, 300}); err != nil {
+		t.Fatalf("Failed to expand job: %v", err)
+	}
+
+	deliveries, _ := db.GetPendingDeliveries(jobID)
+	if len(deliveries) != 3 {
+		t.Fatalf("Expected 3 pending deliveries, got %d", len(deliveries))
+	}
+	for _, delivery := range deliveries {
+		if delivery.AccessUnchecked != (delivery.ChatID != 100) {
+			t.Errorf("Delivery to %d: unexpected access flag %v", delivery.ChatID, delivery.AccessUnchecked)
+		}
+	}
+
+	if err := db.CancelDelivery(deliveries[1].ID); err != nil {
+		t.Fatalf("Failed to cancel delivery: %v", err)
+	}
+	deliveries, _ = db.GetPendingDeliveries(jobID)
+	if len(deliveries) != 2 || deliveries[1].ChatID != 300 {
+		t.Errorf("Expected only the cancelled delivery to be gone, got %+v", deliveries)
+	}
+}
+
+// Test 10: An episode merged into a season notification leads revisions to the job of the batch
+func TestItemNotificationJobsFollowMerges(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.EnqueueNotification("episode-1", "dark|1", `{"EpisodeNumber":1}`, now)
+	db.EnqueueNotification("episode-2", "dark|1", `{"EpisodeNumber":2}`, now)
+	batch, _ := db.GetBatchJobs("dark|1")
+	if err := db.MergeNotificationJobs(batch[0].ID, `{"EpisodeCount":2}`, []uint{batch[1].ID}); err != nil {
+		t.Fatalf("Failed to merge jobs: %v", err)
+	}
+
+	jobs, err := db.GetItemNotificationJobs("episode-2", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to get item notification jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != batch[0].ID || jobs[0].Payload != `{"EpisodeCount":2}` {
+		t.Errorf("Expected the job of the batch, got %+v", jobs)
+	}
+}
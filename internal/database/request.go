@@ -0,0 +1,122 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// AddTitleRequest stores a new pending title request
+func (db *DB) AddTitleRequest(request *models.TitleRequest) error {
+	request.Status = models.RequestStatusPending
+	if err := db.Create(request).Error; err != nil {
+		return fmt.Errorf("failed to add title request: %w", err)
+	}
+	return nil
+}
+
+// GetTitleRequest returns a title request by ID, or gorm.ErrRecordNotFound
+func (db *DB) GetTitleRequest(id uint) (*models.TitleRequest, error) {
+	var request models.TitleRequest
+	result := db.First(&request, id)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get title request: %w", result.Error)
+	}
+
+	return &request, nil
+}
+
+// DecideTitleRequest approves or declines a pending title request and returns it
+// Returns gorm.ErrRecordNotFound if the request doesn't exist or was already decided
+func (db *DB) DecideTitleRequest(id uint, status string, adminChatID int64) (*models.TitleRequest, error) {
+	if status != models.RequestStatusApproved && status != models.RequestStatusDeclined {
+		return nil, fmt.Errorf("invalid title request decision: %q", status)
+	}
+
+	result := db.Model(&models.TitleRequest{}).
+		Where("id = ? AND status = ?", id, models.RequestStatusPending).
+		Updates(map[string]interface{}{"status": status, "decided_by": adminChatID})
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to decide title request: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return db.GetTitleRequest(id)
+}
+
+// SetTitleRequestExternalID records the ID a request was forwarded under
+func (db *DB) SetTitleRequestExternalID(id uint, externalID string) error {
+	result := db.Model(&models.TitleRequest{}).
+		Where("id = ?", id).
+		Update("external_id", externalID)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set title request external ID: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetOpenTitleRequests returns the pending and approved requests of all users who aren't banned
+func (db *DB) GetOpenTitleRequests() ([]models.TitleRequest, error) {
+	var requests []models.TitleRequest
+	result := db.Where("status IN ?", []string{models.RequestStatusPending, models.RequestStatusApproved}).
+		Where("NOT EXISTS (SELECT 1 FROM subscribers WHERE subscribers.chat_id = title_requests.chat_id AND subscribers.is_banned = ? AND subscribers.deleted_at IS NULL)", true).
+		Order("id").
+		Find(&requests)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get open title requests: %w", result.Error)
+	}
+
+	return requests, nil
+}
+
+// GetOpenTitleRequestsByUser returns the pending and approved requests of a user, oldest first
+func (db *DB) GetOpenTitleRequestsByUser(chatID int64) ([]models.TitleRequest, error) {
+	var requests []models.TitleRequest
+	result := db.Where("chat_id = ? AND status IN ?", chatID, []string{models.RequestStatusPending, models.RequestStatusApproved}).
+		Order("id").
+		Find(&requests)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get title requests: %w", result.Error)
+	}
+
+	return requests, nil
+}
+
+// MarkTitleRequestAvailable closes an open request once the requester was told about the matching item
+// Returns gorm.ErrRecordNotFound if the request isn't open anymore
+func (db *DB) MarkTitleRequestAvailable(id uint, itemID string) error {
+	now := time.Now()
+	result := db.Model(&models.TitleRequest{}).
+		Where("id = ? AND status IN ?", id, []string{models.RequestStatusPending, models.RequestStatusApproved}).
+		Updates(map[string]interface{}{
+			"status":            models.RequestStatusAvailable,
+			"available_item_id": itemID,
+			"available_at":      &now,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark title request available: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
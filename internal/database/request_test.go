@@ -0,0 +1,82 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// Test 1: A request is decided once, then closed when the title arrives
+func TestTitleRequestLifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	request := &models.TitleRequest{ChatID: 100, WantedTitle: models.WantedTitle{Title: "Dune", Year: 2021}}
+	if err := db.AddTitleRequest(request); err != nil {
+		t.Fatalf("Failed to add title request: %v", err)
+	}
+	declined := &models.TitleRequest{ChatID: 100, WantedTitle: models.WantedTitle{Title: "Cats"}}
+	db.AddTitleRequest(declined)
+
+	decided, err := db.DecideTitleRequest(request.ID, models.RequestStatusApproved, 1)
+	if err != nil {
+		t.Fatalf("Failed to approve title request: %v", err)
+	}
+	if decided.Status != models.RequestStatusApproved || decided.DecidedBy != 1 || decided.Title != "Dune" {
+		t.Errorf("Unexpected decided request: %+v", decided)
+	}
+	if _, err := db.DecideTitleRequest(request.ID, models.RequestStatusDeclined, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected a decided request not to be decided again, got %v", err)
+	}
+	db.DecideTitleRequest(declined.ID, models.RequestStatusDeclined, 1)
+
+	if err := db.SetTitleRequestExternalID(request.ID, "42"); err != nil {
+		t.Fatalf("Failed to set external ID: %v", err)
+	}
+
+	open, err := db.GetOpenTitleRequestsByUser(100)
+	if err != nil || len(open) != 1 || open[0].ExternalID != "42" {
+		t.Fatalf("Expected only the approved request to be open, got %+v (%v)", open, err)
+	}
+
+	if err := db.MarkTitleRequestAvailable(request.ID, "item1"); err != nil {
+		t.Fatalf("Failed to mark request available: %v", err)
+	}
+	if err := db.MarkTitleRequestAvailable(request.ID, "item2"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected an available request not to be closed again, got %v", err)
+	}
+
+	stored, err := db.GetTitleRequest(request.ID)
+	if err != nil {
+		t.Fatalf("Failed to get title request: %v", err)
+	}
+	if stored.Status != models.RequestStatusAvailable || stored.AvailableItemID != "item1" || stored.AvailableAt == nil {
+		t.Errorf("Expected the request to be available, got %+v", stored)
+	}
+}
+
+// Test 2: Requests of banned users are not matched
+func TestGetOpenTitleRequests_SkipsBannedUsers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.AddSubscriber(100, "spammer", "Spam"); err != nil {
+		t.Fatalf("Failed to add subscriber: %v", err)
+	}
+	if err := db.BanSubscriber(100); err != nil {
+		t.Fatalf("Failed to ban subscriber: %v", err)
+	}
+	db.AddTitleRequest(&models.TitleRequest{ChatID: 100, WantedTitle: models.WantedTitle{Title: "Dune"}})
+	db.AddTitleRequest(&models.TitleRequest{ChatID: 200, WantedTitle: models.WantedTitle{Title: "Dune"}})
+
+	open, err := db.GetOpenTitleRequests()
+	if err != nil {
+		t.Fatalf("Failed to get open title requests: %v", err)
+	}
+	if len(open) != 1 || open[0].ChatID != 200 {
+		t.Errorf("Expected only the request of user 200, got %+v", open)
+	}
+}
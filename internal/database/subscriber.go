@@ -3,6 +3,8 @@ package database
 import (
 	"fmt"
 	"jellyfin-telegram-bot/pkg/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -25,8 +27,8 @@ func (db *DB) AddSubscriber(chatID int64, username, firstName string) error {
 
 	// If subscriber was found (not created), ensure they're active
 	if result.RowsAffected == 0 {
-		// Subscriber already exists, reactivate if needed
-		if err := db.Model(&subscriber).Update("is_active", true).Error; err != nil {
+		// Subscriber already exists, reactivate if needed; subscribing also settles a pending request
+		if err := db.Model(&subscriber).Updates(map[string]interface{}{"is_active": true, "is_pending": false}).Error; err != nil {
 			return fmt.Errorf("failed to reactivate subscriber: %w", err)
 		}
 	}
@@ -68,6 +70,20 @@ func (db *DB) GetAllActiveSubscribers() ([]int64, error) {
 	return chatIDs, nil
 }
 
+// CountActiveSubscribers returns the number of active subscribers
+func (db *DB) CountActiveSubscribers() (int64, error) {
+	var count int64
+	result := db.Model(&models.Subscriber{}).
+		Where("is_active = ?", true).
+		Count(&count)
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count active subscribers: %w", result.Error)
+	}
+
+	return count, nil
+}
+
 // IsSubscribed checks if a user is subscribed and active
 func (db *DB) IsSubscribed(chatID int64) (bool, error) {
 	var count int64
@@ -114,3 +130,256 @@ func (db *DB) GetLanguage(chatID int64) (string, error) {
 
 	return subscriber.LanguageCode, nil
 }
+
+// GetDisabledItemTypes returns the item types a subscriber opted out of
+func (db *DB) GetDisabledItemTypes(chatID int64) ([]string, error) {
+	var subscriber models.Subscriber
+	result := db.Where("chat_id = ?", chatID).First(&subscriber)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Unknown subscribers receive every type
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get disabled item types: %w", result.Error)
+	}
+
+	return splitItemTypes(subscriber.DisabledTypes), nil
+}
+
+// IsItemTypeEnabled checks if a subscriber wants notifications for an item type
+func (db *DB) IsItemTypeEnabled(chatID int64, itemType string) (bool, error) {
+	disabled, err := db.GetDisabledItemTypes(chatID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range disabled {
+		if t == itemType {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// SetItemTypeEnabled opts a subscriber in or out of notifications for an item type
+func (db *DB) SetItemTypeEnabled(chatID int64, itemType string, enabled bool) error {
+	disabled, err := db.GetDisabledItemTypes(chatID)
+	if err != nil {
+		return err
+	}
+
+	updated := make([]string, 0, len(disabled)+1)
+	for _, t := range disabled {
+		if t != itemType {
+			updated = append(updated, t)
+		}
+	}
+	if !enabled {
+		updated = append(updated, itemType)
+	}
+
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ?", chatID).
+		Update("disabled_types", strings.Join(updated, ","))
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set item type preference: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// splitItemTypes parses the comma-separated item type list stored on a subscriber
+func splitItemTypes(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// GetEpisodeMode returns whether a subscriber gets episodes of all series or only of followed ones
+func (db *DB) GetEpisodeMode(chatID int64) (string, error) {
+	var subscriber models.Subscriber
+	result := db.Where("chat_id = ?", chatID).First(&subscriber)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Unknown subscribers get every series
+			return models.EpisodeModeAll, nil
+		}
+		return "", fmt.Errorf("failed to get episode mode: %w", result.Error)
+	}
+
+	if subscriber.EpisodeMode == "" {
+		return models.EpisodeModeAll, nil
+	}
+	return subscriber.EpisodeMode, nil
+}
+
+// SetEpisodeMode sets whether a subscriber gets episodes of all series or only of followed ones
+func (db *DB) SetEpisodeMode(chatID int64, mode string) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ?", chatID).
+		Update("episode_mode", mode)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set episode mode: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetContentPreferences retrieves the content filters of a subscriber
+func (db *DB) GetContentPreferences(chatID int64) (*models.ContentPreferences, error) {
+	var subscriber models.Subscriber
+	result := db.Where("chat_id = ?", chatID).First(&subscriber)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Unknown subscribers have no filters
+			return &models.ContentPreferences{}, nil
+		}
+		return nil, fmt.Errorf("failed to get content preferences: %w", result.Error)
+	}
+
+	return &subscriber.Preferences, nil
+}
+
+// SetContentPreferences replaces the content filters of a subscriber
+func (db *DB) SetContentPreferences(chatID int64, prefs *models.ContentPreferences) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ?", chatID).
+		Updates(map[string]interface{}{
+			"min_rating":          prefs.MinRating,
+			"allowed_genres":      prefs.AllowedGenres,
+			"blocked_genres":      prefs.BlockedGenres,
+			"min_year":            prefs.MinYear,
+			"max_year":            prefs.MaxYear,
+			"max_parental_rating": prefs.MaxParentalRating,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set content preferences: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetDeliverySchedule retrieves the time zone and quiet hours of a subscriber
+func (db *DB) GetDeliverySchedule(chatID int64) (*models.DeliverySchedule, error) {
+	var subscriber models.Subscriber
+	result := db.Where("chat_id = ?", chatID).First(&subscriber)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Unknown subscribers have no quiet hours
+			return &models.DeliverySchedule{}, nil
+		}
+		return nil, fmt.Errorf("failed to get delivery schedule: %w", result.Error)
+	}
+
+	return &subscriber.Schedule, nil
+}
+
+// SetDeliverySchedule replaces the time zone, quiet hours and digest settings of a subscriber
+func (db *DB) SetDeliverySchedule(chatID int64, schedule *models.DeliverySchedule) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ?", chatID).
+		Updates(map[string]interface{}{
+			"timezone":       schedule.Timezone,
+			"quiet_start":    schedule.QuietStart,
+			"quiet_end":      schedule.QuietEnd,
+			"delivery_mode":  schedule.DeliveryMode,
+			"digest_time":    schedule.DigestTime,
+			"digest_day":     schedule.DigestDay,
+			"last_digest_at": schedule.LastDigestAt,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set delivery schedule: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetDigestSubscribers returns the active subscribers receiving daily or weekly digests
+func (db *DB) GetDigestSubscribers() ([]models.Subscriber, error) {
+	var subscribers []models.Subscriber
+	result := db.Where("is_active = ? AND delivery_mode IN ?", true,
+		[]string{models.DeliveryModeDaily, models.DeliveryModeWeekly}).
+		Find(&subscribers)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get digest subscribers: %w", result.Error)
+	}
+
+	return subscribers, nil
+}
+
+// MarkDigestSent records when a subscriber's last digest was sent
+func (db *DB) MarkDigestSent(chatID int64, sentAt time.Time) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ?", chatID).
+		Update("last_digest_at", sentAt)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark digest as sent: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetJellyfinLink retrieves the Jellyfin account a subscriber linked
+func (db *DB) GetJellyfinLink(chatID int64) (*models.JellyfinLink, error) {
+	var subscriber models.Subscriber
+	result := db.Where("chat_id = ?", chatID).First(&subscriber)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			// Unknown subscribers have no linked account
+			return &models.JellyfinLink{}, nil
+		}
+		return nil, fmt.Errorf("failed to get jellyfin link: %w", result.Error)
+	}
+
+	return &subscriber.Jellyfin, nil
+}
+
+// SetJellyfinLink replaces the Jellyfin account of a subscriber
+// An empty link unlinks the account
+func (db *DB) SetJellyfinLink(chatID int64, link *models.JellyfinLink) error {
+	result := db.Model(&models.Subscriber{}).
+		Where("chat_id = ?", chatID).
+		Updates(map[string]interface{}{
+			"jellyfin_user_id":   link.JellyfinUserID,
+			"jellyfin_user_name": link.JellyfinUserName,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to set jellyfin link: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
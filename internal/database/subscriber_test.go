@@ -3,6 +3,9 @@ package database
 import (
 	"os"
 	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
 
 	"gorm.io/gorm"
 )
@@ -183,3 +186,230 @@ func TestReactivateSubscriber(t *testing.T) {
 		t.Error("Expected subscriber to be reactivated")
 	}
 }
+
+// Test 7: Item type preferences can be toggled per subscriber
+func TestItemTypePreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chatID := int64(777888999)
+	db.AddSubscriber(chatID, "testuser", "Test")
+
+	// Every type is enabled by default
+	enabled, err := db.IsItemTypeEnabled(chatID, "Book")
+	if err != nil {
+		t.Fatalf("Failed to check item type: %v", err)
+	}
+	if !enabled {
+		t.Error("Expected item types to be enabled by default")
+	}
+
+	if err := db.SetItemTypeEnabled(chatID, "Book", false); err != nil {
+		t.Fatalf("Failed to disable item type: %v", err)
+	}
+	db.SetItemTypeEnabled(chatID, "Audio", false)
+
+	enabled, _ = db.IsItemTypeEnabled(chatID, "Book")
+	if enabled {
+		t.Error("Expected Book to be disabled")
+	}
+
+	db.SetItemTypeEnabled(chatID, "Book", true)
+
+	disabled, err := db.GetDisabledItemTypes(chatID)
+	if err != nil {
+		t.Fatalf("Failed to get disabled item types: %v", err)
+	}
+	if len(disabled) != 1 || disabled[0] != "Audio" {
+		t.Errorf("Expected only Audio to be disabled, got %v", disabled)
+	}
+
+	// Unknown subscribers can't change preferences
+	if err := db.SetItemTypeEnabled(123, "Book", false); err == nil {
+		t.Error("Expected error for unknown subscriber")
+	}
+}
+
+// Test 8: Content preferences are stored per subscriber and can be cleared
+func TestContentPreferences(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chatID := int64(121212)
+	db.AddSubscriber(chatID, "testuser", "Test")
+
+	prefs, err := db.GetContentPreferences(chatID)
+	if err != nil {
+		t.Fatalf("Failed to get preferences: %v", err)
+	}
+	if *prefs != (models.ContentPreferences{}) {
+		t.Errorf("Expected no filters by default, got %+v", prefs)
+	}
+
+	want := models.ContentPreferences{
+		MinRating:         7,
+		AllowedGenres:     "Drama,Comedy",
+		BlockedGenres:     "Horror",
+		MinYear:           2000,
+		MaxParentalRating: "PG-13",
+	}
+	if err := db.SetContentPreferences(chatID, &want); err != nil {
+		t.Fatalf("Failed to set preferences: %v", err)
+	}
+
+	prefs, _ = db.GetContentPreferences(chatID)
+	if *prefs != want {
+		t.Errorf("Expected %+v, got %+v", want, *prefs)
+	}
+
+	// Zero values must be written too, so filters can be reset
+	if err := db.SetContentPreferences(chatID, &models.ContentPreferences{}); err != nil {
+		t.Fatalf("Failed to reset preferences: %v", err)
+	}
+	prefs, _ = db.GetContentPreferences(chatID)
+	if *prefs != (models.ContentPreferences{}) {
+		t.Errorf("Expected filters to be reset, got %+v", prefs)
+	}
+}
+
+// Test 9: Time zone and quiet hours are stored per subscriber
+func TestDeliverySchedule(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chatID := int64(131313)
+	db.AddSubscriber(chatID, "testuser", "Test")
+
+	schedule, err := db.GetDeliverySchedule(chatID)
+	if err != nil {
+		t.Fatalf("Failed to get delivery schedule: %v", err)
+	}
+	if *schedule != (models.DeliverySchedule{}) {
+		t.Errorf("Expected no quiet hours by default, got %+v", schedule)
+	}
+
+	want := models.DeliverySchedule{Timezone: "Asia/Tehran", QuietStart: "23:00", QuietEnd: "08:00"}
+	if err := db.SetDeliverySchedule(chatID, &want); err != nil {
+		t.Fatalf("Failed to set delivery schedule: %v", err)
+	}
+
+	schedule, _ = db.GetDeliverySchedule(chatID)
+	if *schedule != want {
+		t.Errorf("Expected %+v, got %+v", want, *schedule)
+	}
+
+	if err := db.SetDeliverySchedule(999999, &want); err == nil {
+		t.Error("Expected an error for an unknown subscriber")
+	}
+}
+
+// Test 10: Digest subscribers are listed and their last digest time is recorded
+func TestDigestSubscribers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddSubscriber(1, "instant", "Instant")
+	db.AddSubscriber(2, "daily", "Daily")
+	db.AddSubscriber(3, "weekly", "Weekly")
+	db.SetDeliverySchedule(2, &models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily, DigestTime: "20:00"})
+	db.SetDeliverySchedule(3, &models.DeliverySchedule{DeliveryMode: models.DeliveryModeWeekly, DigestDay: time.Friday})
+	db.RemoveSubscriber(3)
+
+	subscribers, err := db.GetDigestSubscribers()
+	if err != nil {
+		t.Fatalf("Failed to get digest subscribers: %v", err)
+	}
+	if len(subscribers) != 1 || subscribers[0].ChatID != 2 {
+		t.Fatalf("Expected only the active daily subscriber, got %+v", subscribers)
+	}
+
+	sentAt := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	if err := db.MarkDigestSent(2, sentAt); err != nil {
+		t.Fatalf("Failed to mark digest sent: %v", err)
+	}
+	schedule, _ := db.GetDeliverySchedule(2)
+	if !schedule.LastDigestAt.Equal(sentAt) || schedule.DigestTime != "20:00" {
+		t.Errorf("Unexpected schedule after digest: %+v", schedule)
+	}
+}
+
+// Test 11: Only active subscribers are counted
+func TestCountActiveSubscribers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.AddSubscriber(1, "one", "One")
+	db.AddSubscriber(2, "two", "Two")
+	db.AddSubscriber(3, "three", "Three")
+	db.RemoveSubscriber(2)
+
+	count, err := db.CountActiveSubscribers()
+	if err != nil {
+		t.Fatalf("Failed to count subscribers: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 active subscribers, got %d", count)
+	}
+}
+
+// Test 12: A linked Jellyfin account is stored per subscriber and can be unlinked
+func TestJellyfinLink(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chatID := int64(141414)
+	db.AddSubscriber(chatID, "testuser", "Test")
+
+	link, err := db.GetJellyfinLink(chatID)
+	if err != nil {
+		t.Fatalf("Failed to get link: %v", err)
+	}
+	if link.IsLinked() {
+		t.Errorf("Expected no linked account by default, got %+v", link)
+	}
+
+	want := models.JellyfinLink{JellyfinUserID: "user-1", JellyfinUserName: "alice"}
+	if err := db.SetJellyfinLink(chatID, &want); err != nil {
+		t.Fatalf("Failed to set link: %v", err)
+	}
+	link, _ = db.GetJellyfinLink(chatID)
+	if *link != want {
+		t.Errorf("Expected %+v, got %+v", want, *link)
+	}
+
+	if err := db.SetJellyfinLink(chatID, &models.JellyfinLink{}); err != nil {
+		t.Fatalf("Failed to unlink: %v", err)
+	}
+	link, _ = db.GetJellyfinLink(chatID)
+	if link.IsLinked() {
+		t.Errorf("Expected the account to be unlinked, got %+v", link)
+	}
+
+	if err := db.SetJellyfinLink(999, &want); err != gorm.ErrRecordNotFound {
+		t.Errorf("Expected ErrRecordNotFound for an unknown subscriber, got %v", err)
+	}
+}
+
+// Test 13: Subscribers get all series until they switch to followed series only
+func TestEpisodeMode(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chatID := int64(151515)
+	db.AddSubscriber(chatID, "testuser", "Test")
+
+	if mode, err := db.GetEpisodeMode(chatID); err != nil || mode != models.EpisodeModeAll {
+		t.Fatalf("Expected mode all by default, got %q (%v)", mode, err)
+	}
+
+	if err := db.SetEpisodeMode(chatID, models.EpisodeModeFollowed); err != nil {
+		t.Fatalf("Failed to set episode mode: %v", err)
+	}
+	if mode, _ := db.GetEpisodeMode(chatID); mode != models.EpisodeModeFollowed {
+		t.Errorf("Expected mode followed, got %q", mode)
+	}
+
+	if err := db.SetEpisodeMode(999, models.EpisodeModeFollowed); err != gorm.ErrRecordNotFound {
+		t.Errorf("Expected ErrRecordNotFound for an unknown subscriber, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// AddWish stores a new wishlist entry
+func (db *DB) AddWish(wish *models.Wish) error {
+	if err := db.Create(wish).Error; err != nil {
+		return fmt.Errorf("failed to add wish: %w", err)
+	}
+	return nil
+}
+
+// GetWishesByUser returns the open wishes of a user, oldest first
+func (db *DB) GetWishesByUser(chatID int64) ([]models.Wish, error) {
+	var wishes []models.Wish
+	result := db.Where("chat_id = ? AND fulfilled_at IS NULL", chatID).Order("id").Find(&wishes)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get wishes: %w", result.Error)
+	}
+
+	return wishes, nil
+}
+
+// GetOpenWishes returns the open wishes of all users who aren't banned
+func (db *DB) GetOpenWishes() ([]models.Wish, error) {
+	var wishes []models.Wish
+	result := db.Where("fulfilled_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM subscribers WHERE subscribers.chat_id = wishes.chat_id AND subscribers.is_banned = ? AND subscribers.deleted_at IS NULL)", true).
+		Order("id").
+		Find(&wishes)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get open wishes: %w", result.Error)
+	}
+
+	return wishes, nil
+}
+
+// FulfillWish closes a wish once the user was told about the matching item
+func (db *DB) FulfillWish(id uint, itemID string) error {
+	now := time.Now()
+	result := db.Model(&models.Wish{}).
+		Where("id = ? AND fulfilled_at IS NULL", id).
+		Updates(map[string]interface{}{"fulfilled_at": &now, "fulfilled_item_id": itemID})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to fulfill wish: %w", result.Error)
+	}
+
+	return nil
+}
+
+// DeleteWish cancels one of a user's wishes
+// Returns gorm.ErrRecordNotFound if the user has no such wish
+func (db *DB) DeleteWish(chatID int64, id uint) error {
+	result := db.Unscoped().Where("id = ? AND chat_id = ?", id, chatID).Delete(&models.Wish{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete wish: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// Test 1: Fulfilled and cancelled wishes are no longer open
+func TestWishLifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, wish := range []models.Wish{
+		{ChatID: 100, WantedTitle: models.WantedTitle{Title: "Dune", Year: 2021}},
+		{ChatID: 100, WantedTitle: models.WantedTitle{ImdbID: "tt0133093"}},
+		{ChatID: 200, WantedTitle: models.WantedTitle{Title: "Severance"}},
+	} {
+		if err := db.AddWish(&wish); err != nil {
+			t.Fatalf("Failed to add wish: %v", err)
+		}
+	}
+
+	wishes, err := db.GetWishesByUser(100)
+	if err != nil || len(wishes) != 2 || wishes[0].Title != "Dune" {
+		t.Fatalf("Expected both wishes of user 100, got %+v (%v)", wishes, err)
+	}
+
+	if err := db.FulfillWish(wishes[0].ID, "item1"); err != nil {
+		t.Fatalf("Failed to fulfill wish: %v", err)
+	}
+	if err := db.DeleteWish(200, wishes[1].ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected another user's wish not to be cancelled, got %v", err)
+	}
+	if err := db.DeleteWish(100, wishes[1].ID); err != nil {
+		t.Fatalf("Failed to cancel wish: %v", err)
+	}
+
+	open, err := db.GetOpenWishes()
+	if err != nil {
+		t.Fatalf("Failed to get open wishes: %v", err)
+	}
+	if len(open) != 1 || open[0].ChatID != 200 {
+		t.Errorf("Expected only the wish of user 200 to be open, got %+v", open)
+	}
+}
+
+// Test 2: Wishes of banned users are not matched
+func TestGetOpenWishes_SkipsBannedUsers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.AddSubscriber(100, "spammer", "Spam"); err != nil {
+		t.Fatalf("Failed to add subscriber: %v", err)
+	}
+	if err := db.BanSubscriber(100); err != nil {
+		t.Fatalf("Failed to ban subscriber: %v", err)
+	}
+	db.AddWish(&models.Wish{ChatID: 100, WantedTitle: models.WantedTitle{Title: "Dune"}})
+	// Users can wish without subscribing in open mode
+	db.AddWish(&models.Wish{ChatID: 200, WantedTitle: models.WantedTitle{Title: "Dune"}})
+
+	open, err := db.GetOpenWishes()
+	if err != nil {
+		t.Fatalf("Failed to get open wishes: %v", err)
+	}
+	if len(open) != 1 || open[0].ChatID != 200 {
+		t.Errorf("Expected only the wish of user 200, got %+v", open)
+	}
+}
@@ -1,38 +1,195 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// healthCheckTimeout bounds each dependency check so a hanging dependency can't stall a probe
+const healthCheckTimeout = 5 * time.Second
+
+// Health and component statuses
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+	ComponentUp     = "up"
+	ComponentDown   = "down"
 )
 
+// CheckFunc verifies that a dependency is reachable and working
+type CheckFunc func(ctx context.Context) error
+
+// BacklogCounter reports the work waiting in the notification outbox
+type BacklogCounter interface {
+	GetOutboxBacklog() (*models.OutboxBacklog, error)
+}
+
+// ComponentStatus represents the result of a single dependency check
+type ComponentStatus struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
 // HealthStatus represents the health status of the bot
 type HealthStatus struct {
-	Status    string    `json:"status"`
-	Version   string    `json:"version"`
-	Timestamp time.Time `json:"timestamp"`
-	Uptime    string    `json:"uptime,omitempty"`
+	Status        string                     `json:"status"`
+	Version       string                     `json:"version"`
+	Timestamp     time.Time                  `json:"timestamp"`
+	Uptime        string                     `json:"uptime,omitempty"`
+	Components    map[string]ComponentStatus `json:"components,omitempty"`
+	LastWebhookAt *time.Time                 `json:"last_webhook_at,omitempty"`
+	OutboxBacklog *models.OutboxBacklog      `json:"outbox_backlog,omitempty"`
+}
+
+// namedCheck is a dependency check registered on the health handler
+type namedCheck struct {
+	name string
+	// liveness checks also run for /health; the others only for /ready
+	liveness bool
+	check    CheckFunc
+}
+
+// HealthHandler serves the /health liveness and /ready readiness endpoints
+type HealthHandler struct {
+	version   string
+	startTime time.Time
+	checks    []namedCheck
+	webhook   *WebhookHandler
+	backlog   BacklogCounter
+}
+
+// NewHealthHandler creates a health handler reporting the given version
+func NewHealthHandler(version string) *HealthHandler {
+	return &HealthHandler{
+		version:   version,
+		startTime: time.Now(),
+	}
+}
+
+// AddLivenessCheck registers a check for a local dependency the bot can't work without
+// It runs for both /health and /ready
+func (h *HealthHandler) AddLivenessCheck(name string, check CheckFunc) {
+	h.checks = append(h.checks, namedCheck{name: name, liveness: true, check: check})
+}
+
+// AddReadinessCheck registers a check for an external dependency
+// It only runs for /ready, since restarting the bot doesn't fix a remote outage
+func (h *HealthHandler) AddReadinessCheck(name string, check CheckFunc) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// SetWebhookHandler sets the webhook handler whose last received webhook is reported
+func (h *HealthHandler) SetWebhookHandler(webhook *WebhookHandler) {
+	h.webhook = webhook
 }
 
-var startTime = time.Now()
+// SetBacklogCounter sets the source of the reported outbox backlog
+func (h *HealthHandler) SetBacklogCounter(backlog BacklogCounter) {
+	h.backlog = backlog
+}
+
+// HandleHealth reports liveness: the process is serving and its local dependencies work
+func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, true)
+}
 
-// HealthCheckHandler returns the health status of the bot
-func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
+// HandleReady reports readiness: every dependency, including Jellyfin and Telegram, is reachable
+func (h *HealthHandler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, false)
+}
+
+func (h *HealthHandler) serve(w http.ResponseWriter, r *http.Request, livenessOnly bool) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	uptime := time.Since(startTime)
+	status := h.check(r.Context(), livenessOnly)
 
-	status := HealthStatus{
-		Status:    "healthy",
-		Version:   "0.1.0",
-		Timestamp: time.Now(),
-		Uptime:    uptime.String(),
+	code := http.StatusOK
+	if status.Status != StatusHealthy {
+		code = http.StatusServiceUnavailable
+		slog.Warn("Health check failed",
+			"endpoint", r.URL.Path,
+			"components", status.Components)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(status)
 }
+
+// check runs the registered checks concurrently and collects the report
+func (h *HealthHandler) check(ctx context.Context, livenessOnly bool) *HealthStatus {
+	status := &HealthStatus{
+		Status:     StatusHealthy,
+		Version:    h.version,
+		Timestamp:  time.Now(),
+		Uptime:     time.Since(h.startTime).Round(time.Second).String(),
+		Components: make(map[string]ComponentStatus),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checks {
+		if livenessOnly && !c.liveness {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			result := runCheck(ctx, c.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			status.Components[c.name] = result
+			if result.Status != ComponentUp {
+				status.Status = StatusUnhealthy
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if h.webhook != nil {
+		if last := h.webhook.LastReceivedAt(); !last.IsZero() {
+			status.LastWebhookAt = &last
+		}
+	}
+
+	if h.backlog != nil {
+		backlog, err := h.backlog.GetOutboxBacklog()
+		if err != nil {
+			slog.Warn("Failed to get outbox backlog for health check", "error", err)
+		} else {
+			status.OutboxBacklog = backlog
+		}
+	}
+
+	return status
+}
+
+// runCheck runs a single check with a timeout and measures its latency
+func runCheck(ctx context.Context, check CheckFunc) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := ComponentStatus{
+		Status:    ComponentUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = ComponentDown
+		result.Error = err.Error()
+	}
+	return result
+}
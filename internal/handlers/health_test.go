@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// mockBacklog implements BacklogCounter for testing
+type mockBacklog struct {
+	backlog models.OutboxBacklog
+}
+
+func (m *mockBacklog) GetOutboxBacklog() (*models.OutboxBacklog, error) {
+	return &m.backlog, nil
+}
+
+func getHealth(t *testing.T, handler http.HandlerFunc, path string) (int, HealthStatus) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var status HealthStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode %s response: %v", path, err)
+	}
+	return rec.Code, status
+}
+
+// TestHealthHandler_ReadyReportsFailingDependency tests that /ready returns 503 with per-component details
+func TestHealthHandler_ReadyReportsFailingDependency(t *testing.T) {
+	health := NewHealthHandler("1.2.3")
+	health.AddLivenessCheck("database", func(ctx context.Context) error { return nil })
+	health.AddReadinessCheck("jellyfin", func(ctx context.Context) error { return errors.New("connection refused") })
+	health.AddReadinessCheck("telegram", func(ctx context.Context) error { return nil })
+
+	code, status := getHealth(t, health.HandleReady, "/ready")
+
+	if code != http.StatusServiceUnavailable || status.Status != StatusUnhealthy {
+		t.Errorf("Expected 503 unhealthy, got %d %s", code, status.Status)
+	}
+	if status.Version != "1.2.3" {
+		t.Errorf("Expected version 1.2.3, got %s", status.Version)
+	}
+	if got := status.Components["jellyfin"]; got.Status != ComponentDown || got.Error != "connection refused" {
+		t.Errorf("Unexpected jellyfin status: %+v", got)
+	}
+	for _, name := range []string{"database", "telegram"} {
+		if got := status.Components[name]; got.Status != ComponentUp {
+			t.Errorf("Expected %s to be up, got %+v", name, got)
+		}
+	}
+}
+
+// TestHealthHandler_HealthSkipsExternalDependencies tests that /health only runs liveness checks
+func TestHealthHandler_HealthSkipsExternalDependencies(t *testing.T) {
+	health := NewHealthHandler("1.2.3")
+	health.AddLivenessCheck("database", func(ctx context.Context) error { return nil })
+	health.AddReadinessCheck("jellyfin", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	code, status := getHealth(t, health.HandleHealth, "/health")
+
+	if code != http.StatusOK || status.Status != StatusHealthy {
+		t.Errorf("Expected 200 healthy, got %d %s", code, status.Status)
+	}
+	if _, ok := status.Components["jellyfin"]; ok {
+		t.Error("Jellyfin should only be checked by /ready")
+	}
+
+	health.AddLivenessCheck("disk", func(ctx context.Context) error { return errors.New("read-only") })
+	if code, _ := getHealth(t, health.HandleHealth, "/health"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when a liveness check fails, got %d", code)
+	}
+}
+
+// TestHealthHandler_ReportsWebhookAndBacklog tests that the last webhook time and outbox backlog are reported
+func TestHealthHandler_ReportsWebhookAndBacklog(t *testing.T) {
+	webhook := NewWebhookHandler(&MockDB{contentNotified: make(map[string]bool)}, "")
+	health := NewHealthHandler("1.2.3")
+	health.SetWebhookHandler(webhook)
+	health.SetBacklogCounter(&mockBacklog{backlog: models.OutboxBacklog{PendingJobs: 1, PendingDeliveries: 4}})
+
+	_, status := getHealth(t, health.HandleHealth, "/health")
+	if status.LastWebhookAt != nil {
+		t.Error("Expected no last webhook time before any webhook was received")
+	}
+	if status.OutboxBacklog == nil || status.OutboxBacklog.PendingDeliveries != 4 {
+		t.Errorf("Unexpected outbox backlog: %+v", status.OutboxBacklog)
+	}
+
+	body := []byte(`{"NotificationType":"ItemAdded","ItemType":"Movie","ItemId":"m1","Name":"Arrival"}`)
+	webhook.HandleWebhook(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body)))
+
+	_, status = getHealth(t, health.HandleHealth, "/health")
+	if status.LastWebhookAt == nil {
+		t.Error("Expected the last webhook time to be reported")
+	}
+}
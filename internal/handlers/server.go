@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"jellyfin-telegram-bot/internal/metrics"
+)
+
+// HTTP server timeouts protecting against slow or stalled clients
+const (
+	serverReadHeaderTimeout = 10 * time.Second
+	serverReadTimeout       = 30 * time.Second
+	serverWriteTimeout      = 30 * time.Second
+	serverIdleTimeout       = 120 * time.Second
+)
+
+// Route is an additional endpoint served next to the Jellyfin webhook
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// NewWebhookServer creates the HTTP server for the webhook, health, readiness and metrics endpoints
+// Additional routes (e.g. Telegram updates in webhook mode) share the same server
+func NewWebhookServer(port string, handler *WebhookHandler, health *HealthHandler, routes ...Route) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", handler.HandleWebhook)
+	mux.HandleFunc("/health", health.HandleHealth)
+	mux.HandleFunc("/ready", health.HandleReady)
+	mux.Handle("/metrics", metrics.Handler())
+	for _, route := range routes {
+		mux.Handle(route.Pattern, route.Handler)
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", port),
+		Handler:           mux,
+		ReadHeaderTimeout: serverReadHeaderTimeout,
+		ReadTimeout:       serverReadTimeout,
+		WriteTimeout:      serverWriteTimeout,
+		IdleTimeout:       serverIdleTimeout,
+	}
+}
+
+// StartWebhookServer serves the webhook endpoint until the context is cancelled
+// In-flight requests get up to shutdownTimeout to complete before the server stops
+func StartWebhookServer(ctx context.Context, port string, handler *WebhookHandler, health *HealthHandler, shutdownTimeout time.Duration, routes ...Route) error {
+	server := NewWebhookServer(port, handler, health, routes...)
+	return serve(ctx, server, shutdownTimeout)
+}
+
+// serve runs the server until it fails or the context is cancelled, then shuts it down gracefully
+func serve(ctx context.Context, server *http.Server, shutdownTimeout time.Duration) error {
+	slog.Info("Starting webhook server", "address", server.Addr)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("webhook server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down webhook server", "timeout", shutdownTimeout)
+
+	// The run context is already cancelled, so the shutdown gets its own deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("webhook server shutdown failed: %w", err)
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("webhook server failed: %w", err)
+	}
+
+	slog.Info("Webhook server stopped")
+	return nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestNewWebhookServer_Routes tests that the built-in and additional routes are served with timeouts set
+func TestNewWebhookServer_Routes(t *testing.T) {
+	handler := NewWebhookHandler(&MockDB{contentNotified: make(map[string]bool)}, "")
+	extra := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	server := NewWebhookServer("8080", handler, NewHealthHandler("test"), Route{Pattern: "/telegram", Handler: extra})
+
+	if server.Addr != ":8080" {
+		t.Errorf("Expected address :8080, got %s", server.Addr)
+	}
+	if server.ReadHeaderTimeout == 0 || server.ReadTimeout == 0 || server.WriteTimeout == 0 || server.IdleTimeout == 0 {
+		t.Error("Expected all server timeouts to be set")
+	}
+
+	for path, want := range map[string]int{"/health": http.StatusOK, "/ready": http.StatusOK, "/metrics": http.StatusOK, "/telegram": http.StatusTeapot} {
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s: expected status %d, got %d", path, want, rec.Code)
+		}
+	}
+}
+
+// TestServe_ShutdownOnCancel tests that the server stops cleanly when the context is cancelled
+func TestServe_ShutdownOnCancel(t *testing.T) {
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: http.NewServeMux()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, server, time.Second)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not stop after the context was cancelled")
+	}
+}
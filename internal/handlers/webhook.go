@@ -3,58 +3,146 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"jellyfin-telegram-bot/internal/metrics"
 	"jellyfin-telegram-bot/pkg/models"
 )
 
 // ContentTracker defines the interface for content tracking operations
 type ContentTracker interface {
 	IsContentNotified(jellyfinID string) (bool, error)
-	MarkContentNotified(jellyfinID, title, contentType string) error
+	RecordContent(content *models.ContentCache) error
 }
 
 // NotificationContent represents content to be broadcasted
 type NotificationContent struct {
 	ItemID        string
-	Type          string // "Movie" or "Episode"
+	Type          string // One of models.SupportedItemTypes
 	Title         string
 	Overview      string
 	Year          int
 	Rating        float64
+	SeriesID      string
 	SeriesName    string
 	SeasonNumber  int
 	EpisodeNumber int
+	Album         string
+	Artist        string
+
+	// Filled in from the Jellyfin API when a metadata fetcher is configured
+	OfficialRating string
+	Genres         []string
+	Media          *models.MediaInfo
+	TrailerURL     string
+	ProviderIDs    map[string]string
 }
 
-// NotificationBroadcaster defines the interface for broadcasting notifications
-type NotificationBroadcaster interface {
-	BroadcastNotification(ctx context.Context, content *NotificationContent) error
+// NotificationQueue defines the interface for accepting notifications for durable delivery
+type NotificationQueue interface {
+	EnqueueNotification(ctx context.Context, content *NotificationContent) error
+}
+
+// NotificationReviser defines the interface for editing or retracting the notifications already sent for an item
+type NotificationReviser interface {
+	ReviseNotification(ctx context.Context, content *NotificationContent) error
+	RetractNotification(ctx context.Context, itemID string) error
+}
+
+// AdminEvent represents a Jellyfin server event for admins
+type AdminEvent struct {
+	Type     string // One of models.AdminEventTypes
+	UserName string
+	Time     time.Time
+
+	// Playback events
+	ItemID             string
+	ItemType           string
+	ItemName           string
+	Year               int
+	SeriesName         string
+	SeasonNumber       int
+	EpisodeNumber      int
+	PlayMethod         string // Transcode, DirectStream or DirectPlay
+	PlayedToCompletion bool
+
+	// Playback and authentication events
+	ClientName    string
+	DeviceName    string
+	RemoteAddress string
+
+	// Plugin events
+	PluginName    string
+	PluginVersion string
+}
+
+// AdminEventNotifier defines the interface for passing server events on to the admins who subscribed to them
+type AdminEventNotifier interface {
+	NotifyAdminEvent(ctx context.Context, event *AdminEvent) error
+}
+
+// MetadataFetcher defines the interface for looking up item metadata missing from webhooks
+type MetadataFetcher interface {
+	GetItem(ctx context.Context, itemID string) (*models.ContentItem, error)
 }
 
 // WebhookHandler handles incoming Jellyfin webhook requests
 type WebhookHandler struct {
-	db          ContentTracker
-	secret      string
-	broadcaster NotificationBroadcaster
+	db       ContentTracker
+	secret   string
+	queue    NotificationQueue
+	metadata MetadataFetcher
+	reviser  NotificationReviser
+	events   AdminEventNotifier
+
+	lastReceived atomic.Int64 // Unix nanoseconds of the last accepted webhook
 }
 
 // NewWebhookHandler creates a new webhook handler
 func NewWebhookHandler(db ContentTracker, secret string) *WebhookHandler {
 	return &WebhookHandler{
-		db:          db,
-		secret:      secret,
-		broadcaster: nil,
+		db:     db,
+		secret: secret,
+		queue:  nil,
 	}
 }
 
-// SetBroadcaster sets the notification broadcaster
-func (h *WebhookHandler) SetBroadcaster(broadcaster NotificationBroadcaster) {
-	h.broadcaster = broadcaster
+// SetQueue sets the notification queue that accepts content for delivery
+func (h *WebhookHandler) SetQueue(queue NotificationQueue) {
+	h.queue = queue
+}
+
+// SetMetadataFetcher sets the source used to enrich notifications with rating, genre, media and link metadata
+func (h *WebhookHandler) SetMetadataFetcher(fetcher MetadataFetcher) {
+	h.metadata = fetcher
+}
+
+// SetReviser sets the reviser that follows ItemUpdated and ItemDeleted webhooks of notified items
+// Without one those webhooks are ignored
+func (h *WebhookHandler) SetReviser(reviser NotificationReviser) {
+	h.reviser = reviser
+}
+
+// SetAdminEventNotifier sets the notifier for playback, user, authentication and plugin events
+// Without one those webhooks are ignored
+func (h *WebhookHandler) SetAdminEventNotifier(notifier AdminEventNotifier) {
+	h.events = notifier
+}
+
+// LastReceivedAt returns when the last authenticated webhook with a valid payload arrived
+// The zero time means no webhook has been received since startup
+func (h *WebhookHandler) LastReceivedAt() time.Time {
+	nanos := h.lastReceived.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
 }
 
 // fixMalformedJSON fixes common JSON issues from Jellyfin webhooks
@@ -126,18 +214,33 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	payload.DecodeHTMLEntities()
 
 	// Log received webhook
+	metrics.WebhooksReceived.Inc()
+	h.lastReceived.Store(time.Now().UnixNano())
 	slog.Info("Received webhook",
 		"notification_type", payload.NotificationType,
 		"item_type", payload.ItemType,
 		"item_id", payload.ItemID,
 		"item_name", payload.ItemName)
 
-	// Validate webhook - must be ItemAdded and Movie or Episode
+	// Metadata changes and removals revise the notifications already sent for the item
+	if payload.IsItemUpdated() || payload.IsItemDeleted() {
+		h.handleRevision(w, r, &payload)
+		return
+	}
+
+	// Playback, user, authentication and plugin events are for admins only
+	if payload.IsAdminEvent() {
+		h.handleAdminEvent(w, r, &payload)
+		return
+	}
+
+	// Validate webhook - must be ItemAdded and a supported item type
 	if !payload.IsValid() {
 		slog.Debug("Webhook ignored - invalid content",
 			"notification_type", payload.NotificationType,
 			"item_type", payload.ItemType,
 			"item_id", payload.ItemID)
+		metrics.WebhooksIgnored.Inc()
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -156,6 +259,7 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		slog.Info("Content already notified, skipping",
 			"item_id", payload.ItemID,
 			"item_name", payload.ItemName)
+		metrics.WebhooksDuplicate.Inc()
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -177,13 +281,41 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 			"episode", metadata.EpisodeNumber)
 	}
 
-	// Mark content as notified to prevent duplicates
-	contentType := "Movie"
-	if payload.IsEpisode() {
-		contentType = "Episode"
+	contentType := metadata.Type
+	content := h.notificationContent(r.Context(), &payload, metadata)
+
+	// Hand the notification to the outbox before marking it as notified,
+	// so a crash between the two steps can never lose a notification
+	if h.queue != nil {
+		if err := h.queue.EnqueueNotification(r.Context(), content); err != nil {
+			slog.Error("Failed to enqueue notification",
+				"error", err,
+				"item_id", payload.ItemID)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		slog.Info("Notification accepted by outbox",
+			"item_id", payload.ItemID)
+	} else {
+		slog.Warn("No notification queue configured, notification not sent")
 	}
 
-	if err := h.db.MarkContentNotified(payload.ItemID, payload.ItemName, contentType); err != nil {
+	// Mark content as notified to prevent duplicates
+	record := &models.ContentCache{
+		JellyfinID:     payload.ItemID,
+		Title:          payload.ItemName,
+		Type:           contentType,
+		SeriesID:       content.SeriesID,
+		SeriesName:     content.SeriesName,
+		SeasonNumber:   content.SeasonNumber,
+		EpisodeNumber:  content.EpisodeNumber,
+		Year:           content.Year,
+		Rating:         content.Rating,
+		OfficialRating: content.OfficialRating,
+		Genres:         strings.Join(content.Genres, ","),
+	}
+	if err := h.db.RecordContent(record); err != nil {
 		slog.Error("Failed to mark content as notified",
 			"error", err,
 			"item_id", payload.ItemID)
@@ -195,40 +327,192 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		"item_id", payload.ItemID,
 		"item_name", payload.ItemName)
 
-	// Broadcast notification to subscribers
-	if h.broadcaster != nil {
-		// Extract all metadata for notification
-		content := &NotificationContent{
-			ItemID:        payload.ItemID,
-			Type:          contentType,
-			Title:         metadata.Title,    // Use metadata.Title which has "Unknown" fallback
-			Overview:      metadata.Overview, // Use metadata.Overview for consistency
-			Year:          payload.Year,
-			Rating:        0,                   // Webhook doesn't include rating - could fetch from API if needed
-			SeriesName:    metadata.SeriesName, // Use metadata.SeriesName for "Unknown Series" fallback
-			SeasonNumber:  payload.SeasonNumber,
-			EpisodeNumber: payload.EpisodeNumber,
-		}
+	w.WriteHeader(http.StatusOK)
+}
 
-		// Broadcast asynchronously to avoid blocking webhook response
-		go func() {
-			ctx := context.Background()
-			if err := h.broadcaster.BroadcastNotification(ctx, content); err != nil {
-				slog.Error("Failed to broadcast notification",
-					"item_id", payload.ItemID,
-					"error", err)
-			}
-		}()
-
-		slog.Info("Notification broadcast initiated",
+// notificationContent builds the notification of a webhook payload, enriched from the Jellyfin API
+func (h *WebhookHandler) notificationContent(ctx context.Context, payload *models.JellyfinWebhook, metadata *ContentMetadata) *NotificationContent {
+	content := &NotificationContent{
+		ItemID:        payload.ItemID,
+		Type:          metadata.Type,
+		Title:         metadata.Title,    // Use metadata.Title which has "Unknown" fallback
+		Overview:      metadata.Overview, // Use metadata.Overview for consistency
+		Year:          payload.Year,
+		Rating:        0, // Webhook doesn't include rating - filled in by enrichContent
+		SeriesID:      metadata.SeriesID,
+		SeriesName:    metadata.SeriesName, // Use metadata.SeriesName for "Unknown Series" fallback
+		SeasonNumber:  payload.SeasonNumber,
+		EpisodeNumber: payload.EpisodeNumber,
+		Album:         metadata.Album,
+		Artist:        metadata.Artist,
+	}
+
+	// Subscriber filters and digests need metadata the webhook doesn't carry
+	h.enrichContent(ctx, content)
+	return content
+}
+
+// handleRevision passes ItemUpdated and ItemDeleted webhooks of notified items to the reviser
+func (h *WebhookHandler) handleRevision(w http.ResponseWriter, r *http.Request, payload *models.JellyfinWebhook) {
+	if h.reviser == nil || !models.IsSupportedItemType(payload.ItemType) || payload.ItemID == "" {
+		slog.Debug("Webhook ignored - no notifications to revise",
+			"notification_type", payload.NotificationType,
+			"item_type", payload.ItemType,
 			"item_id", payload.ItemID)
+		metrics.WebhooksIgnored.Inc()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only items that were announced have notifications to revise
+	notified, err := h.db.IsContentNotified(payload.ItemID)
+	if err != nil {
+		slog.Error("Failed to check content notification status",
+			"error", err,
+			"item_id", payload.ItemID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !notified {
+		metrics.WebhooksIgnored.Inc()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if payload.IsItemDeleted() {
+		err = h.reviser.RetractNotification(r.Context(), payload.ItemID)
 	} else {
-		slog.Warn("No broadcaster configured, notification not sent")
+		err = h.reviser.ReviseNotification(r.Context(), h.notificationContent(r.Context(), payload, h.extractMetadata(payload)))
+	}
+	if err != nil {
+		slog.Error("Failed to revise notifications",
+			"error", err,
+			"notification_type", payload.NotificationType,
+			"item_id", payload.ItemID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("Notification revision accepted",
+		"notification_type", payload.NotificationType,
+		"item_id", payload.ItemID)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleAdminEvent passes a server event on to the admins
+func (h *WebhookHandler) handleAdminEvent(w http.ResponseWriter, r *http.Request, payload *models.JellyfinWebhook) {
+	if h.events == nil {
+		metrics.WebhooksIgnored.Inc()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event := &AdminEvent{
+		Type:               payload.NotificationType,
+		UserName:           payload.NotificationUsername,
+		Time:               time.Now(),
+		ItemID:             payload.ItemID,
+		ItemType:           payload.ItemType,
+		ItemName:           payload.ItemName,
+		Year:               payload.Year,
+		SeriesName:         payload.SeriesName,
+		SeasonNumber:       payload.SeasonNumber,
+		EpisodeNumber:      payload.EpisodeNumber,
+		PlayMethod:         payload.PlayMethod,
+		PlayedToCompletion: payload.PlayedToCompletion,
+		ClientName:         payload.ClientName,
+		DeviceName:         payload.DeviceName,
+		RemoteAddress:      payload.RemoteEndPoint,
+		PluginName:         payload.PluginName,
+		PluginVersion:      payload.PluginVersion,
+	}
+	// Authentication failures carry the attempted user name and the client under other keys
+	if event.UserName == "" {
+		event.UserName = payload.UserName
+	}
+	if event.ClientName == "" {
+		event.ClientName = payload.App
+	}
+
+	if err := h.events.NotifyAdminEvent(r.Context(), event); err != nil {
+		slog.Error("Failed to notify admins of server event",
+			"error", err,
+			"notification_type", payload.NotificationType)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+// enrichContent fills in rating, parental rating and genres from the Jellyfin API
+// Failures are logged and the notification goes out with the webhook data only
+func (h *WebhookHandler) enrichContent(ctx context.Context, content *NotificationContent) {
+	if h.metadata == nil {
+		return
+	}
+
+	item, err := h.metadata.GetItem(ctx, content.ItemID)
+	if err != nil {
+		slog.Warn("Failed to fetch item metadata, sending notification without it",
+			"item_id", content.ItemID,
+			"error", err)
+		return
+	}
+
+	content.Rating = item.CommunityRating
+	content.OfficialRating = item.OfficialRating
+	content.Genres = item.Genres
+	content.Media = item.MediaInfo()
+	content.TrailerURL = item.TrailerURL()
+	content.ProviderIDs = item.ProviderIDs
+	if content.Year == 0 {
+		content.Year = item.ProductionYear
+	}
+	// Webhook templates from before SeriesId was added don't send it
+	if content.SeriesID == "" {
+		content.SeriesID = item.SeriesID
+	}
+}
+
+// NotificationContentFromItem builds notification content from item metadata fetched from the Jellyfin API
+// It is used for notifications that don't originate from a webhook, such as admin resends
+func NotificationContentFromItem(item *models.ContentItem) *NotificationContent {
+	content := &NotificationContent{
+		ItemID:         item.ItemID,
+		Type:           item.Type,
+		Title:          item.Name,
+		Overview:       item.Overview,
+		Year:           item.ProductionYear,
+		Rating:         item.CommunityRating,
+		SeriesID:       item.SeriesID,
+		SeriesName:     item.SeriesName,
+		SeasonNumber:   item.SeasonNumber,
+		EpisodeNumber:  item.EpisodeNumber,
+		Album:          item.Album,
+		Artist:         item.AlbumArtist,
+		OfficialRating: item.OfficialRating,
+		Genres:         item.Genres,
+		Media:          item.MediaInfo(),
+		TrailerURL:     item.TrailerURL(),
+		ProviderIDs:    item.ProviderIDs,
+	}
+
+	// Same fallbacks as for webhook payloads
+	if content.Title == "" {
+		content.Title = "Unknown"
+	}
+	if content.Overview == "" {
+		content.Overview = "No description available"
+	}
+	if (item.Type == models.ItemTypeEpisode || item.Type == models.ItemTypeSeason) && content.SeriesName == "" {
+		content.SeriesName = "Unknown Series"
+	}
+
+	return content
+}
+
 // ContentMetadata represents extracted metadata for notifications
 type ContentMetadata struct {
 	Type          string
@@ -236,27 +520,37 @@ type ContentMetadata struct {
 	Overview      string
 	Year          int
 	ItemID        string
+	SeriesID      string
 	SeriesName    string
 	SeasonNumber  int
 	EpisodeNumber int
+	Album         string
+	Artist        string
 }
 
 // extractMetadata extracts relevant metadata from webhook payload
 func (h *WebhookHandler) extractMetadata(payload *models.JellyfinWebhook) *ContentMetadata {
 	metadata := &ContentMetadata{
+		Type:     payload.ItemType,
 		Title:    payload.ItemName,
 		Overview: payload.Overview,
 		Year:     payload.Year,
 		ItemID:   payload.ItemID,
 	}
 
-	if payload.IsMovie() {
-		metadata.Type = "Movie"
-	} else if payload.IsEpisode() {
-		metadata.Type = "Episode"
+	switch payload.ItemType {
+	case models.ItemTypeEpisode:
+		metadata.SeriesID = payload.SeriesID
 		metadata.SeriesName = payload.SeriesName
 		metadata.SeasonNumber = payload.SeasonNumber
 		metadata.EpisodeNumber = payload.EpisodeNumber
+	case models.ItemTypeSeason:
+		metadata.SeriesID = payload.SeriesID
+		metadata.SeriesName = payload.SeriesName
+		metadata.SeasonNumber = payload.SeasonNumber
+	case models.ItemTypeMusicAlbum, models.ItemTypeAudio, models.ItemTypeAudioBook:
+		metadata.Album = payload.Album
+		metadata.Artist = payload.Artist
 	}
 
 	// Handle missing fields gracefully
@@ -266,24 +560,9 @@ func (h *WebhookHandler) extractMetadata(payload *models.JellyfinWebhook) *Conte
 	if metadata.Overview == "" {
 		metadata.Overview = "No description available"
 	}
-	if payload.IsEpisode() && metadata.SeriesName == "" {
+	if (payload.IsEpisode() || payload.IsSeason()) && metadata.SeriesName == "" {
 		metadata.SeriesName = "Unknown Series"
 	}
 
 	return metadata
 }
-
-// StartWebhookServer starts the HTTP server for webhook endpoint
-func StartWebhookServer(port string, handler *WebhookHandler) error {
-	http.HandleFunc("/webhook", handler.HandleWebhook)
-	http.HandleFunc("/health", HealthCheckHandler)
-
-	addr := fmt.Sprintf(":%s", port)
-	slog.Info("Starting webhook server", "address", addr)
-
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		return fmt.Errorf("webhook server failed: %w", err)
-	}
-
-	return nil
-}
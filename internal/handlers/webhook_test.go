@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"jellyfin-telegram-bot/internal/metrics"
 	"jellyfin-telegram-bot/pkg/models"
 )
 
@@ -97,10 +100,10 @@ func TestWebhookHandler_FilterInvalidContentType(t *testing.T) {
 		name     string
 		itemType string
 	}{
-		{"Series", "Series"},
-		{"Season", "Season"},
-		{"Audio", "Audio"},
-		{"Book", "Book"},
+		{"Folder", "Folder"},
+		{"Photo", "Photo"},
+		{"Playlist", "Playlist"},
+		{"TvChannel", "TvChannel"},
 	}
 
 	for _, tc := range testCases {
@@ -142,6 +145,7 @@ func TestWebhookHandler_DuplicateContent(t *testing.T) {
 	db.contentNotified["movie789"] = true
 
 	handler := NewWebhookHandler(db, "")
+	duplicatesBefore := metrics.WebhooksDuplicate.Value()
 
 	payload := models.JellyfinWebhook{
 		NotificationType: "ItemAdded",
@@ -166,6 +170,10 @@ func TestWebhookHandler_DuplicateContent(t *testing.T) {
 	if db.markCount > 0 {
 		t.Error("Duplicate content should not be marked again")
 	}
+
+	if got := metrics.WebhooksDuplicate.Value() - duplicatesBefore; got != 1 {
+		t.Errorf("Expected the duplicate counter to increase by 1, got %v", got)
+	}
 }
 
 // TestWebhookHandler_InvalidJSON tests handling of malformed JSON
@@ -265,6 +273,344 @@ func TestWebhookHandler_WithSecret(t *testing.T) {
 	}
 }
 
+// TestWebhookHandler_EnqueuesBeforeMarking tests that content is handed to the queue
+func TestWebhookHandler_EnqueuesBeforeMarking(t *testing.T) {
+	db := &MockDB{
+		contentNotified: make(map[string]bool),
+	}
+	queue := &MockQueue{}
+
+	handler := NewWebhookHandler(db, "")
+	handler.SetQueue(queue)
+
+	payload := models.JellyfinWebhook{
+		NotificationType: "ItemAdded",
+		ItemType:         "Episode",
+		ItemID:           "episode777",
+		ItemName:         "Pilot",
+		SeriesName:       "Severance",
+		SeriesID:         "series-severance",
+		SeasonNumber:     1,
+		EpisodeNumber:    1,
+	}
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleWebhook(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	if len(queue.enqueued) != 1 {
+		t.Fatalf("Expected 1 enqueued notification, got %d", len(queue.enqueued))
+	}
+	if queue.enqueued[0].SeriesName != "Severance" || queue.enqueued[0].SeriesID != "series-severance" || queue.enqueued[0].Type != "Episode" {
+		t.Errorf("Unexpected enqueued content: %+v", queue.enqueued[0])
+	}
+
+	if !db.contentNotified["episode777"] {
+		t.Error("Expected content to be marked as notified")
+	}
+}
+
+// TestWebhookHandler_EnqueueFailure tests that content isn't marked when the queue rejects it
+func TestWebhookHandler_EnqueueFailure(t *testing.T) {
+	db := &MockDB{
+		contentNotified: make(map[string]bool),
+	}
+	queue := &MockQueue{err: errors.New("database is locked")}
+
+	handler := NewWebhookHandler(db, "")
+	handler.SetQueue(queue)
+
+	payload := models.JellyfinWebhook{
+		NotificationType: "ItemAdded",
+		ItemType:         "Movie",
+		ItemID:           "movie888",
+		ItemName:         "Dune",
+	}
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleWebhook(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+
+	// Content must stay unmarked so a retried webhook can still be delivered
+	if db.contentNotified["movie888"] {
+		t.Error("Content should not be marked as notified when enqueueing fails")
+	}
+}
+
+// TestWebhookHandler_SupportedItemTypes tests that every supported item type is accepted
+func TestWebhookHandler_SupportedItemTypes(t *testing.T) {
+	for _, itemType := range models.SupportedItemTypes {
+		t.Run(itemType, func(t *testing.T) {
+			db := &MockDB{
+				contentNotified: make(map[string]bool),
+			}
+			queue := &MockQueue{}
+
+			handler := NewWebhookHandler(db, "")
+			handler.SetQueue(queue)
+
+			payload := models.JellyfinWebhook{
+				NotificationType: "ItemAdded",
+				ItemType:         itemType,
+				ItemID:           "item-" + itemType,
+				ItemName:         "Test Item",
+				Album:            "Test Album",
+				Artist:           "Test Artist",
+			}
+
+			body, _ := json.Marshal(payload)
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.HandleWebhook(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", w.Code)
+			}
+			if len(queue.enqueued) != 1 || queue.enqueued[0].Type != itemType {
+				t.Fatalf("Expected 1 enqueued %s notification, got %+v", itemType, queue.enqueued)
+			}
+
+			// Album and artist are only carried for music and audiobooks
+			hasArtist := queue.enqueued[0].Artist == "Test Artist"
+			wantArtist := itemType == models.ItemTypeMusicAlbum || itemType == models.ItemTypeAudio || itemType == models.ItemTypeAudioBook
+			if hasArtist != wantArtist {
+				t.Errorf("Expected artist present=%v for %s, got %q", wantArtist, itemType, queue.enqueued[0].Artist)
+			}
+		})
+	}
+}
+
+// TestWebhookHandler_EnrichesContent tests that API metadata is added to the notification
+func TestWebhookHandler_EnrichesContent(t *testing.T) {
+	db := &MockDB{
+		contentNotified: make(map[string]bool),
+	}
+	queue := &MockQueue{}
+
+	handler := NewWebhookHandler(db, "")
+	handler.SetQueue(queue)
+	handler.SetMetadataFetcher(&MockMetadataFetcher{
+		items: map[string]*models.ContentItem{
+			"movie999": {CommunityRating: 7.9, OfficialRating: "PG-13", Genres: []string{"Action"}, ProductionYear: 2021},
+		},
+	})
+
+	payload := models.JellyfinWebhook{
+		NotificationType: "ItemAdded",
+		ItemType:         "Movie",
+		ItemID:           "movie999",
+		ItemName:         "Dune",
+	}
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleWebhook(w, req)
+
+	if len(queue.enqueued) != 1 {
+		t.Fatalf("Expected 1 enqueued notification, got %d", len(queue.enqueued))
+	}
+
+	content := queue.enqueued[0]
+	if content.Rating != 7.9 || content.OfficialRating != "PG-13" || len(content.Genres) != 1 || content.Year != 2021 {
+		t.Errorf("Expected content to be enriched, got %+v", content)
+	}
+}
+
+// TestWebhookHandler_EnrichmentFailure tests that a failed lookup doesn't block the notification
+func TestWebhookHandler_EnrichmentFailure(t *testing.T) {
+	db := &MockDB{
+		contentNotified: make(map[string]bool),
+	}
+	queue := &MockQueue{}
+
+	handler := NewWebhookHandler(db, "")
+	handler.SetQueue(queue)
+	handler.SetMetadataFetcher(&MockMetadataFetcher{})
+
+	payload := models.JellyfinWebhook{
+		NotificationType: "ItemAdded",
+		ItemType:         "Movie",
+		ItemID:           "movie1000",
+		ItemName:         "Dune",
+	}
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleWebhook(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if len(queue.enqueued) != 1 {
+		t.Errorf("Expected notification to be enqueued without metadata, got %d", len(queue.enqueued))
+	}
+}
+
+// TestWebhookHandler_RevisesNotifiedItems tests that updates and removals of notified items reach the reviser
+func TestWebhookHandler_RevisesNotifiedItems(t *testing.T) {
+	db := &MockDB{
+		contentNotified: map[string]bool{"movie123": true},
+	}
+	reviser := &MockReviser{}
+
+	handler := NewWebhookHandler(db, "")
+	handler.SetReviser(reviser)
+
+	send := func(payload models.JellyfinWebhook) int {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		return w.Code
+	}
+
+	code := send(models.JellyfinWebhook{NotificationType: "ItemUpdated", ItemType: "Movie", ItemID: "movie123", ItemName: "Interstellar", Year: 2014})
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if len(reviser.revised) != 1 || reviser.revised[0].Title != "Interstellar" || reviser.revised[0].Year != 2014 {
+		t.Errorf("Expected the updated content to be revised, got %+v", reviser.revised)
+	}
+
+	// Items that were never announced have nothing to revise
+	send(models.JellyfinWebhook{NotificationType: "ItemUpdated", ItemType: "Movie", ItemID: "movie999", ItemName: "Dune"})
+	if len(reviser.revised) != 1 {
+		t.Errorf("Expected unannounced items to be ignored, got %+v", reviser.revised)
+	}
+
+	code = send(models.JellyfinWebhook{NotificationType: "ItemDeleted", ItemType: "Movie", ItemID: "movie123"})
+	if code != http.StatusOK || len(reviser.retracted) != 1 || reviser.retracted[0] != "movie123" {
+		t.Errorf("Expected the removed item to be retracted, got status %d and %v", code, reviser.retracted)
+	}
+
+	reviser.err = errors.New("database is locked")
+	if code := send(models.JellyfinWebhook{NotificationType: "ItemDeleted", ItemType: "Movie", ItemID: "movie123"}); code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the revision can't be stored, got %d", code)
+	}
+
+	if db.markCount != 0 {
+		t.Errorf("Expected revisions not to record content, got %d", db.markCount)
+	}
+}
+
+// TestWebhookHandler_AdminEvents tests that server events reach the admin event notifier
+func TestWebhookHandler_AdminEvents(t *testing.T) {
+	db := &MockDB{
+		contentNotified: make(map[string]bool),
+	}
+	notifier := &MockAdminEventNotifier{}
+
+	handler := NewWebhookHandler(db, "")
+	handler.SetAdminEventNotifier(notifier)
+
+	body := []byte(`{"NotificationType":"PlaybackStart","ItemType":"Episode","ItemName":"Pilot","SeriesName":"Severance",` +
+		`"SeasonNumber":01,"EpisodeNumber":01,"NotificationUsername":"alice","DeviceName":"Living Room TV",` +
+		`"ClientName":"Jellyfin Android TV","PlayMethod":"Transcode"}`)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("Expected 1 admin event, got %d", len(notifier.events))
+	}
+	event := notifier.events[0]
+	if event.UserName != "alice" || event.SeriesName != "Severance" || event.EpisodeNumber != 1 ||
+		event.DeviceName != "Living Room TV" || event.PlayMethod != "Transcode" {
+		t.Errorf("Unexpected admin event: %+v", event)
+	}
+
+	// Authentication failures name the user and client differently
+	body = []byte(`{"NotificationType":"AuthenticationFailure","Username":"admin","App":"Jellyfin Web","RemoteEndPoint":"203.0.113.7"}`)
+	req = httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	handler.HandleWebhook(httptest.NewRecorder(), req)
+	if len(notifier.events) != 2 || notifier.events[1].UserName != "admin" || notifier.events[1].ClientName != "Jellyfin Web" {
+		t.Errorf("Unexpected authentication failure event: %+v", notifier.events[len(notifier.events)-1])
+	}
+
+	if db.markCount != 0 {
+		t.Errorf("Expected server events not to record content, got %d", db.markCount)
+	}
+}
+
+// MockAdminEventNotifier is a mock admin event notifier for testing
+type MockAdminEventNotifier struct {
+	events []*AdminEvent
+}
+
+func (m *MockAdminEventNotifier) NotifyAdminEvent(ctx context.Context, event *AdminEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// MockReviser is a mock notification reviser for testing
+type MockReviser struct {
+	revised   []*NotificationContent
+	retracted []string
+	err       error
+}
+
+func (m *MockReviser) ReviseNotification(ctx context.Context, content *NotificationContent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.revised = append(m.revised, content)
+	return nil
+}
+
+func (m *MockReviser) RetractNotification(ctx context.Context, itemID string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.retracted = append(m.retracted, itemID)
+	return nil
+}
+
+// MockMetadataFetcher is a mock metadata fetcher for testing
+type MockMetadataFetcher struct {
+	items map[string]*models.ContentItem
+}
+
+func (m *MockMetadataFetcher) GetItem(ctx context.Context, itemID string) (*models.ContentItem, error) {
+	if item, ok := m.items[itemID]; ok {
+		return item, nil
+	}
+	return nil, errors.New("resource not found")
+}
+
+// MockQueue is a mock notification queue for testing
+type MockQueue struct {
+	enqueued []*NotificationContent
+	err      error
+}
+
+func (m *MockQueue) EnqueueNotification(ctx context.Context, content *NotificationContent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.enqueued = append(m.enqueued, content)
+	return nil
+}
+
 // MockDB is a mock database for testing
 type MockDB struct {
 	contentNotified map[string]bool
@@ -275,8 +621,8 @@ func (m *MockDB) IsContentNotified(jellyfinID string) (bool, error) {
 	return m.contentNotified[jellyfinID], nil
 }
 
-func (m *MockDB) MarkContentNotified(jellyfinID, title, contentType string) error {
-	m.contentNotified[jellyfinID] = true
+func (m *MockDB) RecordContent(content *models.ContentCache) error {
+	m.contentNotified[content.JellyfinID] = true
 	m.markCount++
 	return nil
 }
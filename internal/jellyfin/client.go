@@ -1,15 +1,19 @@
 package jellyfin
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"jellyfin-telegram-bot/internal/metrics"
 	"jellyfin-telegram-bot/pkg/models"
 )
 
@@ -40,24 +44,63 @@ func NewClientWithHTTPClient(serverURL, apiKey string, httpClient *http.Client)
 	}
 }
 
-// doRequest performs an HTTP request with authentication headers
-func (c *Client) doRequest(ctx context.Context, method, path string, params url.Values) (*http.Response, error) {
+// ErrNotFound is returned when Jellyfin doesn't know the requested resource
+var ErrNotFound = errors.New("resource not found")
+
+// ErrUnauthorized is returned when Jellyfin rejects the credentials of a request
+var ErrUnauthorized = errors.New("authentication failed")
+
+// doRequest performs an HTTP request authenticated with the API key
+// The endpoint names the API call in metrics, since paths may contain item IDs
+func (c *Client) doRequest(ctx context.Context, endpoint, method, path string, params url.Values) (*http.Response, error) {
+	resp, err := c.do(ctx, endpoint, method, path, params, nil, map[string]string{"X-Emby-Token": c.apiKey})
+	if errors.Is(err, ErrUnauthorized) {
+		return nil, fmt.Errorf("%w: invalid API key", ErrUnauthorized)
+	}
+	return resp, err
+}
+
+// do performs an HTTP request with the given authentication headers
+// A non-nil body is sent as JSON
+func (c *Client) do(ctx context.Context, endpoint, method, path string, params url.Values, body any, headers map[string]string) (resp *http.Response, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.JellyfinRequestDuration.WithLabelValues(endpoint).ObserveDuration(start)
+		if err != nil {
+			metrics.JellyfinRequestErrors.WithLabelValues(endpoint).Inc()
+		}
+	}()
+
 	// Build URL with query parameters
 	u := c.serverURL + path
 	if params != nil {
 		u += "?" + params.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add authentication headers
-	req.Header.Set("X-Emby-Token", c.apiKey)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err = c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -65,12 +108,12 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params url.
 	// Handle HTTP errors
 	if resp.StatusCode == http.StatusUnauthorized {
 		resp.Body.Close()
-		return nil, fmt.Errorf("authentication failed: invalid API key")
+		return nil, ErrUnauthorized
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
 		resp.Body.Close()
-		return nil, fmt.Errorf("resource not found")
+		return nil, ErrNotFound
 	}
 
 	if resp.StatusCode >= 400 {
@@ -85,7 +128,11 @@ func (c *Client) doRequest(ctx context.Context, method, path string, params url.
 func (c *Client) GetPosterImage(ctx context.Context, itemID string) ([]byte, error) {
 	path := fmt.Sprintf("/Items/%s/Images/Primary", itemID)
 
-	resp, err := c.doRequest(ctx, "GET", path, nil)
+	// Telegram only accepts JPEG photos in inline query results
+	params := url.Values{}
+	params.Set("format", "Jpg")
+
+	resp, err := c.doRequest(ctx, "poster", "GET", path, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch poster image: %w", err)
 	}
@@ -99,19 +146,78 @@ func (c *Client) GetPosterImage(ctx context.Context, itemID string) ([]byte, err
 	return imageBytes, nil
 }
 
-// GetRecentItems fetches recently added movies and episodes
+// includeItemTypes is the IncludeItemTypes filter covering every supported item type
+var includeItemTypes = strings.Join(models.SupportedItemTypes, ",")
+
+// GetRecentItems fetches recently added items of the supported types
 func (c *Client) GetRecentItems(ctx context.Context, limit int) ([]models.ContentItem, error) {
+	return c.GetRecentItemsForUser(ctx, "", limit)
+}
+
+// GetRecentItemsForUser fetches recently added items the given user can access
+// An empty userID fetches items of all libraries
+func (c *Client) GetRecentItemsForUser(ctx context.Context, userID string, limit int) ([]models.ContentItem, error) {
+	result, err := c.QueryItems(ctx, models.ItemQuery{UserID: userID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// SearchContent searches for items of the supported types matching the query
+func (c *Client) SearchContent(ctx context.Context, query string, limit int) ([]models.ContentItem, error) {
+	return c.SearchContentForUser(ctx, "", query, limit)
+}
+
+// SearchContentForUser searches the items the given user can access
+// An empty userID searches all libraries
+func (c *Client) SearchContentForUser(ctx context.Context, userID, query string, limit int) ([]models.ContentItem, error) {
+	result, err := c.QueryItems(ctx, models.ItemQuery{UserID: userID, SearchTerm: query, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// QueryItems fetches one page of items, along with the total number of matching items
+// Without a search term items are listed newest first
+func (c *Client) QueryItems(ctx context.Context, query models.ItemQuery) (*models.JellyfinItemsResponse, error) {
+	itemTypes := includeItemTypes
+	if len(query.ItemTypes) > 0 {
+		itemTypes = strings.Join(query.ItemTypes, ",")
+	}
+
 	params := url.Values{}
-	params.Set("Filters", "IsNotFolder")
 	params.Set("Recursive", "true")
-	params.Set("SortBy", "DateCreated")
-	params.Set("SortOrder", "Descending")
-	params.Set("IncludeItemTypes", "Movie,Episode")
-	params.Set("Limit", strconv.Itoa(limit))
-	params.Set("Fields", "Overview,CommunityRating,OfficialRating,ProductionYear")
+	params.Set("IncludeItemTypes", itemTypes)
+	params.Set("Fields", "Overview,CommunityRating,OfficialRating,ProductionYear,RemoteTrailers,ProviderIds")
+	if query.Limit > 0 {
+		params.Set("Limit", strconv.Itoa(query.Limit))
+	}
+	if query.StartIndex > 0 {
+		params.Set("StartIndex", strconv.Itoa(query.StartIndex))
+	}
+	if query.UserID != "" {
+		params.Set("UserId", query.UserID)
+	}
+	if len(query.ItemIDs) > 0 {
+		params.Set("Ids", strings.Join(query.ItemIDs, ","))
+	}
+
+	endpoint := "recent_items"
+	if query.SearchTerm != "" {
+		endpoint = "search"
+		params.Set("SearchTerm", query.SearchTerm)
+	} else {
+		params.Set("SortBy", "DateCreated")
+		params.Set("SortOrder", "Descending")
+	}
 
-	resp, err := c.doRequest(ctx, "GET", "/Items", params)
+	resp, err := c.doRequest(ctx, endpoint, "GET", "/Items", params)
 	if err != nil {
+		if query.SearchTerm != "" {
+			return nil, fmt.Errorf("failed to search content: %w", err)
+		}
 		return nil, fmt.Errorf("failed to fetch recent items: %w", err)
 	}
 	defer resp.Body.Close()
@@ -121,21 +227,19 @@ func (c *Client) GetRecentItems(ctx context.Context, limit int) ([]models.Conten
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Items, nil
+	return &result, nil
 }
 
-// SearchContent searches for movies and episodes matching the query
-func (c *Client) SearchContent(ctx context.Context, query string, limit int) ([]models.ContentItem, error) {
+// GetItem fetches the metadata of a single item, including its genres, parental rating, media streams
+// and external links
+func (c *Client) GetItem(ctx context.Context, itemID string) (*models.ContentItem, error) {
 	params := url.Values{}
-	params.Set("SearchTerm", query)
-	params.Set("Recursive", "true")
-	params.Set("IncludeItemTypes", "Movie,Episode")
-	params.Set("Limit", strconv.Itoa(limit))
-	params.Set("Fields", "Overview,CommunityRating,OfficialRating,ProductionYear")
+	params.Set("Ids", itemID)
+	params.Set("Fields", "Overview,CommunityRating,OfficialRating,ProductionYear,Genres,MediaSources,RemoteTrailers,ProviderIds")
 
-	resp, err := c.doRequest(ctx, "GET", "/Items", params)
+	resp, err := c.doRequest(ctx, "item", "GET", "/Items", params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search content: %w", err)
+		return nil, fmt.Errorf("failed to fetch item: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -144,5 +248,55 @@ func (c *Client) SearchContent(ctx context.Context, query string, limit int) ([]
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.Items, nil
+	if len(result.Items) == 0 {
+		return nil, fmt.Errorf("item not found: %s", itemID)
+	}
+
+	return &result.Items[0], nil
+}
+
+// GetPublicSystemInfo fetches the server's public system information
+// It is cheap and doesn't depend on library contents, so it doubles as a reachability check
+func (c *Client) GetPublicSystemInfo(ctx context.Context) (*models.JellyfinSystemInfo, error) {
+	resp, err := c.doRequest(ctx, "system_info", "GET", "/System/Info/Public", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch system info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var info models.JellyfinSystemInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &info, nil
+}
+
+// FindSeriesID looks up the ID of a series by name
+// Only an exact (case-insensitive) name match is accepted: a search for "Lost" also finds "Lost in Space"
+func (c *Client) FindSeriesID(ctx context.Context, seriesName string) (string, error) {
+	params := url.Values{}
+	params.Set("SearchTerm", seriesName)
+	params.Set("Recursive", "true")
+	params.Set("IncludeItemTypes", models.ItemTypeSeries)
+	params.Set("Limit", "10")
+
+	resp, err := c.doRequest(ctx, "series_lookup", "GET", "/Items", params)
+	if err != nil {
+		return "", fmt.Errorf("failed to search series: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result models.JellyfinItemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, item := range result.Items {
+		if strings.EqualFold(item.Name, seriesName) {
+			return item.ItemID, nil
+		}
+	}
+
+	return "", fmt.Errorf("series not found: %s", seriesName)
 }
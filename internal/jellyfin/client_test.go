@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
 )
 
 // TestClientAuthentication tests that the client includes authentication headers
@@ -110,8 +112,8 @@ func TestGetRecentItemsSuccess(t *testing.T) {
 		if r.URL.Query().Get("SortBy") != "DateCreated" {
 			t.Errorf("Expected SortBy=DateCreated")
 		}
-		if r.URL.Query().Get("IncludeItemTypes") != "Movie,Episode" {
-			t.Errorf("Expected IncludeItemTypes=Movie,Episode")
+		if r.URL.Query().Get("IncludeItemTypes") != "Movie,Episode,Series,Season,MusicAlbum,Audio,AudioBook,Book" {
+			t.Errorf("Expected IncludeItemTypes to cover every supported type, got %s", r.URL.Query().Get("IncludeItemTypes"))
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -171,8 +173,8 @@ func TestSearchContentSuccess(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		receivedSearchTerm = r.URL.Query().Get("SearchTerm")
-		if r.URL.Query().Get("IncludeItemTypes") != "Movie,Episode" {
-			t.Errorf("Expected IncludeItemTypes=Movie,Episode")
+		if r.URL.Query().Get("IncludeItemTypes") != "Movie,Episode,Series,Season,MusicAlbum,Audio,AudioBook,Book" {
+			t.Errorf("Expected IncludeItemTypes to cover every supported type, got %s", r.URL.Query().Get("IncludeItemTypes"))
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -200,6 +202,115 @@ func TestSearchContentSuccess(t *testing.T) {
 	}
 }
 
+// TestQueryItemsPaging tests that a page query sends its offset and type filter and returns the total count
+func TestQueryItemsPaging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("StartIndex") != "10" || query.Get("Limit") != "5" {
+			t.Errorf("Expected StartIndex=10 and Limit=5, got %s and %s", query.Get("StartIndex"), query.Get("Limit"))
+		}
+		if query.Get("IncludeItemTypes") != "Movie" {
+			t.Errorf("Expected IncludeItemTypes=Movie, got %s", query.Get("IncludeItemTypes"))
+		}
+		if query.Get("SearchTerm") != "alien" || query.Get("SortBy") != "" {
+			t.Errorf("Expected a search without date sorting, got %s", r.URL.RawQuery)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"Items": [{"Id": "movie11", "Name": "Aliens", "Type": "Movie"}], "TotalRecordCount": 11}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	result, err := client.QueryItems(context.Background(), models.ItemQuery{
+		SearchTerm: "alien",
+		ItemTypes:  []string{models.ItemTypeMovie},
+		StartIndex: 10,
+		Limit:      5,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if result.TotalRecordCount != 11 || len(result.Items) != 1 || result.Items[0].Name != "Aliens" {
+		t.Errorf("Unexpected page: %+v", result)
+	}
+}
+
+// TestGetItemSuccess tests fetching a single item with genres and parental rating
+func TestGetItemSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("Ids") != "movie1" {
+			t.Errorf("Expected Ids=movie1, got %s", r.URL.Query().Get("Ids"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"Items":[{"Id":"movie1","Name":"Alien","Type":"Movie","OfficialRating":"R","CommunityRating":8.5,"Genres":["Horror","Science Fiction"]}],"TotalRecordCount":1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	item, err := client.GetItem(context.Background(), "movie1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if item.OfficialRating != "R" || len(item.Genres) != 2 || item.Genres[1] != "Science Fiction" {
+		t.Errorf("Unexpected item metadata: %+v", item)
+	}
+}
+
+// TestGetItemMediaInfo tests that the media streams of an item are summarized for notifications
+func TestGetItemMediaInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("Fields"), "MediaSources") {
+			t.Errorf("Expected MediaSources to be requested, got %s", r.URL.Query().Get("Fields"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"Items":[{"Id":"movie1","Name":"Dune","Type":"Movie","MediaSources":[{"Id":"src1","MediaStreams":[
+			{"Type":"Video","Codec":"hevc","Width":3840,"Height":1600,"VideoRangeType":"HDR10"},
+			{"Type":"Audio","Codec":"aac","Language":"per"},
+			{"Type":"Audio","Codec":"dts","Profile":"DTS-HD MA","Language":"eng","IsDefault":true},
+			{"Type":"Subtitle","Codec":"srt","Language":"eng"},
+			{"Type":"Subtitle","Codec":"srt","Language":"fas"},
+			{"Type":"Subtitle","Codec":"srt","Language":"per"},
+			{"Type":"Subtitle","Codec":"srt","Language":"und"}]}]}],"TotalRecordCount":1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	item, err := client.GetItem(context.Background(), "movie1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	media := item.MediaInfo()
+	if media == nil {
+		t.Fatal("Expected media info for a movie with streams")
+	}
+	if media.Resolution != "2160p" || media.HDR != "HDR10" || media.AudioCodec != "DTS-HD" {
+		t.Errorf("Unexpected video and audio details: %+v", media)
+	}
+	if strings.Join(media.AudioLanguages, ",") != "FA,EN" || strings.Join(media.SubtitleLanguages, ",") != "EN,FA" {
+		t.Errorf("Unexpected languages: audio %v, subtitles %v", media.AudioLanguages, media.SubtitleLanguages)
+	}
+}
+
+// TestGetItemNotFound tests fetching an item that doesn't exist
+func TestGetItemNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"Items":[],"TotalRecordCount":0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	if _, err := client.GetItem(context.Background(), "missing"); err == nil {
+		t.Error("Expected error for missing item")
+	}
+}
+
 // TestSearchContentPersian tests search with Persian characters
 func TestSearchContentPersian(t *testing.T) {
 	persianQuery := "فیلم"
@@ -265,3 +376,56 @@ func TestContextTimeout(t *testing.T) {
 		t.Fatal("Expected timeout error, got nil")
 	}
 }
+
+// TestGetPublicSystemInfo tests fetching the server's public system information
+func TestGetPublicSystemInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/System/Info/Public" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ServerName":"Home","Version":"10.9.7","Id":"abc"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	info, err := client.GetPublicSystemInfo(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if info.ServerName != "Home" || info.Version != "10.9.7" {
+		t.Errorf("Unexpected system info: %+v", info)
+	}
+}
+
+// TestFindSeriesID tests that series are only found by their exact name
+func TestFindSeriesID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("IncludeItemTypes") != "Series" {
+			t.Errorf("Expected a series search, got %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusOK)
+		switch strings.ToLower(r.URL.Query().Get("SearchTerm")) {
+		case "the office":
+			w.Write([]byte(`{"Items":[{"Id":"uk","Name":"The Office (UK)"},{"Id":"us","Name":"The Office"}]}`))
+		case "lost":
+			w.Write([]byte(`{"Items":[{"Id":"space","Name":"Lost in Space"}]}`))
+		default:
+			w.Write([]byte(`{"Items":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+
+	if id, err := client.FindSeriesID(context.Background(), "the office"); err != nil || id != "us" {
+		t.Errorf("Expected exact match 'us', got %q (%v)", id, err)
+	}
+	if id, err := client.FindSeriesID(context.Background(), "Lost"); err == nil {
+		t.Errorf("Expected a single result with another name to be rejected, got %q", id)
+	}
+	if _, err := client.FindSeriesID(context.Background(), "Missing"); err == nil {
+		t.Error("Expected an error for an unknown series")
+	}
+}
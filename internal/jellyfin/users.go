@@ -0,0 +1,128 @@
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// clientName identifies the bot in the Jellyfin session list of a user
+const clientName = "Jellyfin Telegram Bot"
+
+// clientVersion is reported to Jellyfin when authenticating users
+const clientVersion = "0.1.0"
+
+// authorizationHeaders returns the headers identifying the bot as a client device
+// A non-empty token authenticates the request as the user the token belongs to
+func authorizationHeaders(deviceID, token string) map[string]string {
+	value := fmt.Sprintf(`MediaBrowser Client="%s", Device="Telegram", DeviceId="%s", Version="%s"`,
+		clientName, deviceID, clientVersion)
+	if token != "" {
+		value += fmt.Sprintf(`, Token="%s"`, token)
+	}
+	return map[string]string{"X-Emby-Authorization": value}
+}
+
+// AuthenticateByName signs in a user with their Jellyfin username and password
+func (c *Client) AuthenticateByName(ctx context.Context, deviceID, username, password string) (*models.JellyfinAuthResult, error) {
+	body := map[string]string{"Username": username, "Pw": password}
+
+	resp, err := c.do(ctx, "authenticate", "POST", "/Users/AuthenticateByName", nil, body, authorizationHeaders(deviceID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result models.JellyfinAuthResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// InitiateQuickConnect starts a Quick Connect request
+// The returned code is approved by the user in a signed-in Jellyfin app
+func (c *Client) InitiateQuickConnect(ctx context.Context, deviceID string) (*models.QuickConnectResult, error) {
+	resp, err := c.do(ctx, "quick_connect", "POST", "/QuickConnect/Initiate", nil, nil, authorizationHeaders(deviceID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate quick connect: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result models.QuickConnectResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetQuickConnectState fetches the state of a Quick Connect request
+func (c *Client) GetQuickConnectState(ctx context.Context, deviceID, secret string) (*models.QuickConnectResult, error) {
+	params := url.Values{}
+	params.Set("Secret", secret)
+
+	resp, err := c.do(ctx, "quick_connect", "GET", "/QuickConnect/Connect", params, nil, authorizationHeaders(deviceID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quick connect state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result models.QuickConnectResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// AuthenticateWithQuickConnect signs in the user who approved a Quick Connect request
+func (c *Client) AuthenticateWithQuickConnect(ctx context.Context, deviceID, secret string) (*models.JellyfinAuthResult, error) {
+	body := map[string]string{"Secret": secret}
+
+	resp, err := c.do(ctx, "authenticate", "POST", "/Users/AuthenticateWithQuickConnect", nil, body, authorizationHeaders(deviceID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with quick connect: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result models.JellyfinAuthResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Logout ends the session of a user access token
+func (c *Client) Logout(ctx context.Context, deviceID, token string) error {
+	resp, err := c.do(ctx, "logout", "POST", "/Sessions/Logout", nil, nil, authorizationHeaders(deviceID, token))
+	if err != nil {
+		return fmt.Errorf("failed to log out: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// IsItemVisible reports whether a user has access to an item
+// Jellyfin answers 404 for items outside the libraries a user may see
+func (c *Client) IsItemVisible(ctx context.Context, userID, itemID string) (bool, error) {
+	path := fmt.Sprintf("/Users/%s/Items/%s", url.PathEscape(userID), url.PathEscape(itemID))
+
+	resp, err := c.doRequest(ctx, "user_item", "GET", path, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check item access: %w", err)
+	}
+	resp.Body.Close()
+
+	return true, nil
+}
@@ -0,0 +1,123 @@
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestAuthenticateByName tests that users sign in with their own credentials instead of the API key
+func TestAuthenticateByName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/Users/AuthenticateByName" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-Emby-Token") != "" {
+			t.Error("Expected the API key not to be sent")
+		}
+		if !strings.Contains(r.Header.Get("X-Emby-Authorization"), `DeviceId="telegram-42"`) {
+			t.Errorf("Expected the device ID in the authorization header, got %q", r.Header.Get("X-Emby-Authorization"))
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["Username"] != "alice" || body["Pw"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"User":{"Id":"user-1","Name":"alice"},"AccessToken":"token-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "api-key")
+
+	result, err := client.AuthenticateByName(context.Background(), "telegram-42", "alice", "secret")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.User.ID != "user-1" || result.AccessToken != "token-1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	_, err = client.AuthenticateByName(context.Background(), "telegram-42", "alice", "wrong")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized for a wrong password, got: %v", err)
+	}
+}
+
+// TestQuickConnect tests the Quick Connect flow from initiation to authentication
+func TestQuickConnect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/QuickConnect/Initiate":
+			w.Write([]byte(`{"Secret":"s3cret","Code":"123456","Authenticated":false}`))
+		case "/QuickConnect/Connect":
+			if r.URL.Query().Get("Secret") != "s3cret" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"Secret":"s3cret","Code":"123456","Authenticated":true}`))
+		case "/Users/AuthenticateWithQuickConnect":
+			w.Write([]byte(`{"User":{"Id":"user-1","Name":"alice"},"AccessToken":"token-1"}`))
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "api-key")
+	ctx := context.Background()
+
+	request, err := client.InitiateQuickConnect(ctx, "telegram-42")
+	if err != nil {
+		t.Fatalf("Failed to initiate quick connect: %v", err)
+	}
+	if request.Code != "123456" {
+		t.Errorf("Expected code 123456, got %q", request.Code)
+	}
+
+	state, err := client.GetQuickConnectState(ctx, "telegram-42", request.Secret)
+	if err != nil || !state.Authenticated {
+		t.Fatalf("Expected an authenticated request, got %+v, %v", state, err)
+	}
+
+	result, err := client.AuthenticateWithQuickConnect(ctx, "telegram-42", request.Secret)
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	if result.User.Name != "alice" {
+		t.Errorf("Expected user alice, got %+v", result.User)
+	}
+}
+
+// TestIsItemVisible tests that items Jellyfin hides from a user are reported as not visible
+func TestIsItemVisible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Users/user-1/Items/visible":
+			w.Write([]byte(`{"Id":"visible"}`))
+		case "/Users/user-1/Items/hidden":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "api-key")
+	ctx := context.Background()
+
+	if visible, err := client.IsItemVisible(ctx, "user-1", "visible"); err != nil || !visible {
+		t.Errorf("Expected the item to be visible, got %v, %v", visible, err)
+	}
+	if visible, err := client.IsItemVisible(ctx, "user-1", "hidden"); err != nil || visible {
+		t.Errorf("Expected the item to be hidden, got %v, %v", visible, err)
+	}
+	if _, err := client.IsItemVisible(ctx, "user-1", "broken"); err == nil {
+		t.Error("Expected an error for a server error")
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import "net/http"
+
+// Default is the registry holding the bot's metrics
+var Default = NewRegistry()
+
+// Webhook metrics
+var (
+	WebhooksReceived  = Default.NewCounter("jellyfin_bot_webhooks_received_total", "Jellyfin webhooks received with a valid payload")
+	WebhooksIgnored   = Default.NewCounter("jellyfin_bot_webhooks_ignored_total", "Jellyfin webhooks ignored because of their event or content type")
+	WebhooksDuplicate = Default.NewCounter("jellyfin_bot_webhooks_duplicate_total", "Jellyfin webhooks for content that was already notified")
+)
+
+// Delivery metrics
+var (
+	BroadcastsStarted = Default.NewCounter("jellyfin_bot_broadcasts_started_total", "Notification broadcasts started")
+	NotificationSends = Default.NewCounterVec("jellyfin_bot_notification_sends_total", "Per-recipient notification sends by result (success, failure, blocked)", "result")
+	PosterFetchErrors = Default.NewCounter("jellyfin_bot_poster_fetch_failures_total", "Poster images that could not be fetched from Jellyfin")
+)
+
+// Jellyfin API metrics
+var (
+	JellyfinRequestDuration = Default.NewHistogramVec("jellyfin_bot_jellyfin_request_duration_seconds", "Latency of Jellyfin API requests by endpoint", DefaultBuckets, "endpoint")
+	JellyfinRequestErrors   = Default.NewCounterVec("jellyfin_bot_jellyfin_request_errors_total", "Failed Jellyfin API requests by endpoint", "endpoint")
+)
+
+// Telegram metrics
+var (
+	CallbackQueries   = Default.NewCounterVec("jellyfin_bot_callback_queries_total", "Inline keyboard callback queries by data prefix", "prefix")
+	ActiveSubscribers = Default.NewGaugeFunc("jellyfin_bot_active_subscribers", "Number of active subscribers")
+)
+
+// Send results used as the result label of NotificationSends
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultBlocked = "blocked"
+)
+
+// Handler serves the default registry in the Prometheus text format
+func Handler() http.Handler {
+	return Default.Handler()
+}
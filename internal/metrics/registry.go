@@ -0,0 +1,329 @@
+// SPDX-License-Identifier: MIT
+
+// Package metrics implements a small Prometheus-compatible metrics registry
+// exposed in the text exposition format without external dependencies
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are histogram buckets suited to HTTP request latencies in seconds
+var DefaultBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// collector writes one metric family in the text exposition format
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry holds metric families in registration order
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes all metrics in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler serves the registry's metrics over HTTP
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// vec keeps one series per combination of label values
+type vec[T any] struct {
+	name       string
+	help       string
+	typ        string
+	labelNames []string
+	newSeries  func() *T
+
+	mu     sync.Mutex
+	series map[string]*T
+	labels map[string][]string
+}
+
+func newVec[T any](name, help, typ string, labelNames []string, newSeries func() *T) *vec[T] {
+	return &vec[T]{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: labelNames,
+		newSeries:  newSeries,
+		series:     make(map[string]*T),
+		labels:     make(map[string][]string),
+	}
+}
+
+// with returns the series for the label values, creating it on first use
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", v.name, len(v.labelNames), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = v.newSeries()
+		v.series[key] = s
+		v.labels[key] = append([]string(nil), values...)
+	}
+	return s
+}
+
+// each calls fn for every series sorted by label values, so output is stable
+func (v *vec[T]) each(fn func(labels string, series *T)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	v.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v.mu.Lock()
+		series, values := v.series[key], v.labels[key]
+		v.mu.Unlock()
+		fn(formatLabels(v.labelNames, values), series)
+	}
+}
+
+func (v *vec[T]) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.typ)
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increments the counter by delta, which must not be negative
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.value += delta
+	c.mu.Unlock()
+}
+
+// Value returns the current count
+func (c *Counter) Value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// CounterVec is a family of counters partitioned by labels
+type CounterVec struct {
+	*vec[Counter]
+}
+
+// NewCounterVec registers a counter family with the given label names
+// Without label names it holds a single series
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, "counter", labelNames, func() *Counter { return &Counter{} })}
+	r.register(c)
+	return c
+}
+
+// NewCounter registers a counter without labels
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := r.NewCounterVec(name, help)
+	return c.WithLabelValues()
+}
+
+// WithLabelValues returns the counter for the given label values
+func (c *CounterVec) WithLabelValues(values ...string) *Counter {
+	return c.with(values)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.writeHeader(w)
+	c.each(func(labels string, counter *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels, formatValue(counter.Value()))
+	})
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// Observe records a single observation
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// ObserveDuration records the time elapsed since start in seconds
+func (h *Histogram) ObserveDuration(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// HistogramVec is a family of histograms partitioned by labels
+type HistogramVec struct {
+	*vec[Histogram]
+}
+
+// NewHistogramVec registers a histogram family with the given buckets and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	h := &HistogramVec{newVec(name, help, "histogram", labelNames, func() *Histogram {
+		return &Histogram{buckets: sorted, counts: make([]uint64, len(sorted))}
+	})}
+	r.register(h)
+	return h
+}
+
+// WithLabelValues returns the histogram for the given label values
+func (h *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return h.with(values)
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.writeHeader(w)
+	h.each(func(labels string, histogram *Histogram) {
+		histogram.mu.Lock()
+		defer histogram.mu.Unlock()
+
+		for i, bound := range histogram.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(labels, "le", formatValue(bound)), histogram.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(labels, "le", "+Inf"), histogram.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatValue(histogram.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, histogram.count)
+	})
+}
+
+// GaugeFunc is a gauge whose value is read from a function at scrape time
+type GaugeFunc struct {
+	name string
+	help string
+
+	mu sync.Mutex
+	fn func() float64
+}
+
+// NewGaugeFunc registers a gauge; it isn't exported until a value function is set
+func (r *Registry) NewGaugeFunc(name, help string) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help}
+	r.register(g)
+	return g
+}
+
+// Set sets the function providing the gauge value
+func (g *GaugeFunc) Set(fn func() float64) {
+	g.mu.Lock()
+	g.fn = fn
+	g.mu.Unlock()
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.mu.Lock()
+	fn := g.fn
+	g.mu.Unlock()
+	if fn == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, escapeHelp(g.help), g.name)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(fn()))
+}
+
+// formatLabels renders label pairs like {endpoint="item"}, or nothing without labels
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelValueEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel appends one more label pair to rendered labels
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + labelValueEscaper.Replace(value) + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+// labelValueEscaper escapes the characters the exposition format reserves in label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes the characters the exposition format reserves in help texts
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test 1: Counters are exposed per label combination in a stable order
+func TestCounterVec_Exposition(t *testing.T) {
+	registry := NewRegistry()
+	sends := registry.NewCounterVec("sends_total", "Sends by result", "result")
+	sends.WithLabelValues("success").Add(2)
+	sends.WithLabelValues("blocked").Inc()
+	sends.WithLabelValues("success").Inc()
+
+	var out strings.Builder
+	registry.Write(&out)
+
+	want := "# HELP sends_total Sends by result\n" +
+		"# TYPE sends_total counter\n" +
+		"sends_total{result=\"blocked\"} 1\n" +
+		"sends_total{result=\"success\"} 3\n"
+	if out.String() != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+// Test 2: Histograms expose cumulative buckets, sum and count
+func TestHistogramVec_Exposition(t *testing.T) {
+	registry := NewRegistry()
+	latency := registry.NewHistogramVec("latency_seconds", "Latency", []float64{1, 0.1}, "endpoint")
+	latency.WithLabelValues("item").Observe(0.05)
+	latency.WithLabelValues("item").Observe(0.5)
+	latency.WithLabelValues("item").Observe(5)
+
+	var out strings.Builder
+	registry.Write(&out)
+
+	for _, want := range []string{
+		"# TYPE latency_seconds histogram\n",
+		"latency_seconds_bucket{endpoint=\"item\",le=\"0.1\"} 1\n",
+		"latency_seconds_bucket{endpoint=\"item\",le=\"1\"} 2\n",
+		"latency_seconds_bucket{endpoint=\"item\",le=\"+Inf\"} 3\n",
+		"latency_seconds_sum{endpoint=\"item\"} 5.55\n",
+		"latency_seconds_count{endpoint=\"item\"} 3\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+// Test 3: Label values are escaped and gauges are read at scrape time over HTTP
+func TestHandler_EscapingAndGauge(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("callbacks_total", "Callbacks", "prefix").WithLabelValues("a\"b\\c\nd").Inc()
+	gauge := registry.NewGaugeFunc("subscribers", "Subscribers")
+
+	subscribers := 3.0
+	gauge.Set(func() float64 { return subscribers })
+	subscribers = 7
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `callbacks_total{prefix="a\"b\\c\nd"} 1`) {
+		t.Errorf("Expected escaped label value, got:\n%s", body)
+	}
+	if !strings.Contains(body, "subscribers 7\n") {
+		t.Errorf("Expected gauge value read at scrape time, got:\n%s", body)
+	}
+}
@@ -0,0 +1,221 @@
+// Package seerr forwards approved title requests to Overseerr or Jellyseerr
+package seerr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Media types of the Overseerr API
+const (
+	mediaTypeMovie = "movie"
+	mediaTypeTV    = "tv"
+)
+
+// ErrNoMatch is returned when Overseerr finds no movie or series for a request
+var ErrNoMatch = errors.New("no matching title found")
+
+// Client represents an Overseerr or Jellyseerr API client; both share the same API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Overseerr API client
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// searchResult is a single result of the search endpoint
+type searchResult struct {
+	ID           int    `json:"id"` // TMDB ID
+	MediaType    string `json:"mediaType"`
+	Title        string `json:"title"` // Movies
+	Name         string `json:"name"`  // Series
+	ReleaseDate  string `json:"releaseDate"`
+	FirstAirDate string `json:"firstAirDate"`
+}
+
+// year returns the release year of a result, or 0 if unknown
+func (r *searchResult) year() int {
+	date := r.ReleaseDate
+	if r.MediaType == mediaTypeTV {
+		date = r.FirstAirDate
+	}
+	if len(date) < 4 {
+		return 0
+	}
+	year, _ := strconv.Atoi(date[:4])
+	return year
+}
+
+// requestBody is the body of a new media request
+type requestBody struct {
+	MediaType string `json:"mediaType"`
+	MediaID   int    `json:"mediaId"`
+	Seasons   string `json:"seasons,omitempty"`
+}
+
+// ForwardRequest creates a request for the title in Overseerr and returns its ID there
+func (c *Client) ForwardRequest(ctx context.Context, request *models.TitleRequest) (string, error) {
+	media, err := c.findMedia(ctx, &request.WantedTitle)
+	if err != nil {
+		return "", err
+	}
+
+	body := requestBody{MediaType: media.MediaType, MediaID: media.ID}
+	if media.MediaType == mediaTypeTV {
+		body.Seasons = "all"
+	}
+
+	var created struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/request", nil, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return strconv.Itoa(created.ID), nil
+}
+
+// findMedia looks up the movie or series a user asked for
+func (c *Client) findMedia(ctx context.Context, wanted *models.WantedTitle) (*searchResult, error) {
+	// Overseerr has no IMDb lookup, so a request needs a title or a TMDB ID
+	if wanted.Title == "" {
+		if wanted.TmdbID == "" {
+			return nil, ErrNoMatch
+		}
+		return c.findByTmdbID(ctx, wanted.TmdbID)
+	}
+
+	var response struct {
+		Results []searchResult `json:"results"`
+	}
+	// Overseerr rejects "+" for spaces in the query
+	query := "query=" + strings.ReplaceAll(url.QueryEscape(wanted.Title), "+", "%20")
+	if err := c.do(ctx, http.MethodGet, "/api/v1/search", &query, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to search titles: %w", err)
+	}
+
+	return pickResult(response.Results, wanted)
+}
+
+// findByTmdbID looks up a TMDB ID as a movie first, then as a series
+func (c *Client) findByTmdbID(ctx context.Context, tmdbID string) (*searchResult, error) {
+	id, err := strconv.Atoi(tmdbID)
+	if err != nil || id <= 0 {
+		return nil, ErrNoMatch
+	}
+
+	for _, mediaType := range []string{mediaTypeMovie, mediaTypeTV} {
+		var result searchResult
+		err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/%s/%d", mediaType, id), nil, nil, &result)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up TMDB ID: %w", err)
+		}
+		result.ID = id
+		result.MediaType = mediaType
+		return &result, nil
+	}
+	return nil, ErrNoMatch
+}
+
+// pickResult chooses the movie or series of a request from search results
+// A matching TMDB ID wins, then a matching year, then the first result
+func pickResult(results []searchResult, wanted *models.WantedTitle) (*searchResult, error) {
+	var candidates []*searchResult
+	for i := range results {
+		if results[i].MediaType == mediaTypeMovie || results[i].MediaType == mediaTypeTV {
+			candidates = append(candidates, &results[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoMatch
+	}
+
+	if wanted.TmdbID != "" {
+		for _, candidate := range candidates {
+			if strconv.Itoa(candidate.ID) == wanted.TmdbID {
+				return candidate, nil
+			}
+		}
+	}
+	if wanted.Year > 0 {
+		for _, candidate := range candidates {
+			if candidate.year() == wanted.Year {
+				return candidate, nil
+			}
+		}
+	}
+	return candidates[0], nil
+}
+
+// errNotFound is returned for 404 responses
+var errNotFound = errors.New("not found")
+
+// do performs a request authenticated with the API key and decodes the JSON response into out
+// A non-nil body is sent as JSON
+func (c *Client) do(ctx context.Context, method, path string, query *string, body, out any) error {
+	u := c.baseURL + path
+	if query != nil {
+		u += "?" + *query
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("HTTP error: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
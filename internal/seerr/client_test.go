@@ -0,0 +1,121 @@
+package seerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// TestForwardRequest_SearchesAndRequests tests that a series is found by year and requested with all seasons
+func TestForwardRequest_SearchesAndRequests(t *testing.T) {
+	var query, apiKey string
+	var created requestBody
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-Api-Key")
+		switch r.URL.Path {
+		case "/api/v1/search":
+			query = r.URL.RawQuery
+			w.Write([]byte(`{"results":[
+				{"id":1,"mediaType":"person","name":"Ben Stiller"},
+				{"id":2,"mediaType":"movie","title":"Severance","releaseDate":"2006-08-25"},
+				{"id":95396,"mediaType":"tv","name":"Severance","firstAirDate":"2022-02-17"}
+			]}`))
+		case "/api/v1/request":
+			if r.Method != http.MethodPost {
+				t.Errorf("Expected POST, got %s", r.Method)
+			}
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":7}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "secret")
+	id, err := client.ForwardRequest(context.Background(), &models.TitleRequest{
+		WantedTitle: models.WantedTitle{Title: "Severance Show", Year: 2022},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if id != "7" {
+		t.Errorf("Expected request ID 7, got %q", id)
+	}
+	if apiKey != "secret" {
+		t.Errorf("Expected the API key header, got %q", apiKey)
+	}
+	if query != "query=Severance%20Show" {
+		t.Errorf("Expected spaces encoded as %%20, got %q", query)
+	}
+	if created.MediaType != "tv" || created.MediaID != 95396 || created.Seasons != "all" {
+		t.Errorf("Unexpected request body: %+v", created)
+	}
+}
+
+// TestForwardRequest_TmdbID tests that a bare TMDB ID is looked up as a movie, then as a series
+func TestForwardRequest_TmdbID(t *testing.T) {
+	var created requestBody
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/tv/95396":
+			w.Write([]byte(`{"id":95396,"name":"Severance"}`))
+		case "/api/v1/request":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.Write([]byte(`{"id":8}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	if _, err := client.ForwardRequest(context.Background(), &models.TitleRequest{
+		WantedTitle: models.WantedTitle{TmdbID: "95396"},
+	}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if created.MediaType != "tv" || created.MediaID != 95396 {
+		t.Errorf("Unexpected request body: %+v", created)
+	}
+}
+
+// TestForwardRequest_NoMatch tests that requests without movie or series results fail
+func TestForwardRequest_NoMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[{"id":1,"mediaType":"person","name":"Dune"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	_, err := client.ForwardRequest(context.Background(), &models.TitleRequest{
+		WantedTitle: models.WantedTitle{Title: "Dune"},
+	})
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch, got: %v", err)
+	}
+}
+
+// TestForwardRequest_HTTPError tests that rejected requests return an error
+func TestForwardRequest_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "wrong")
+	if _, err := client.ForwardRequest(context.Background(), &models.TitleRequest{
+		WantedTitle: models.WantedTitle{Title: "Dune"},
+	}); err == nil {
+		t.Error("Expected an error for a forbidden request")
+	}
+}
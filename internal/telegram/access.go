@@ -0,0 +1,441 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
+)
+
+// inviteCodeBytes is the random part of an invite code, encoded to 12 URL-safe characters
+const inviteCodeBytes = 9
+
+// maxInviteUses bounds the number of uses of a single invite code
+const maxInviteUses = 1000
+
+// AccessStore defines the interface for subscription requests and invite codes
+type AccessStore interface {
+	GetSubscriber(chatID int64) (*models.Subscriber, error)
+	AddPendingSubscriber(chatID int64, username, firstName string) error
+	ApproveSubscriber(chatID int64) error
+	RejectSubscriber(chatID int64) error
+	CreateInvite(code string, maxUses int, createdBy int64) error
+	RedeemInvite(code string) error
+	GetOpenInvites() ([]models.Invite, error)
+	RevokeInvite(code string) error
+}
+
+// SetAccessStore enables the approval and invite access modes
+func (b *Bot) SetAccessStore(store AccessStore) {
+	b.access = store
+}
+
+// accessMode returns the configured access mode; without an access store the bot is open
+func (b *Bot) accessMode() string {
+	if b.access == nil || b.config == nil || b.config.Admin.AccessMode == "" {
+		return config.AccessModeOpen
+	}
+	return b.config.Admin.AccessMode
+}
+
+// admitSubscriber decides whether the sender of /start may subscribe right away
+// Otherwise it answers the user itself, creating a pending request in approval mode
+func (b *Bot) admitSubscriber(ctx context.Context, update *botModels.Update) bool {
+	mode := b.accessMode()
+	if mode == config.AccessModeOpen {
+		return true
+	}
+
+	chatID := update.Message.Chat.ID
+	if b.isAdmin(chatID) {
+		return true
+	}
+
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	subscriber, err := b.access.GetSubscriber(chatID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.Error("Failed to look up subscriber",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "error.generic"))
+		return false
+	}
+
+	// Users admitted before (who stopped or blocked the bot since) don't need to be admitted again
+	if subscriber != nil && !subscriber.IsPending {
+		return true
+	}
+
+	if args := commandArgs(update); len(args) > 0 {
+		code := args[0]
+		if err := b.access.RedeemInvite(code); err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				slog.Error("Failed to redeem invite",
+					"chat_id", chatID,
+					"error", err)
+			}
+			slog.Info("Rejected invalid invite code", "chat_id", chatID)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "access.invite_invalid"))
+			return false
+		}
+
+		slog.Info("Invite code redeemed",
+			"chat_id", chatID,
+			"code", code)
+		return true
+	}
+
+	if mode == config.AccessModeInvite {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "access.invite_required"))
+		return false
+	}
+
+	if subscriber != nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "access.pending"))
+		return false
+	}
+
+	from := update.Message.From
+	if err := b.access.AddPendingSubscriber(chatID, from.Username, from.FirstName); err != nil {
+		slog.Error("Failed to add pending subscriber",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "error.generic"))
+		return false
+	}
+	b.saveDetectedLanguage(chatID, from.LanguageCode)
+
+	slog.Info("Subscription request created",
+		"chat_id", chatID,
+		"username", from.Username)
+
+	b.sendReply(ctx, chatID, i18n.T(localizer, "access.requested"))
+	b.notifyAdminsOfRequest(ctx, &models.Subscriber{ChatID: chatID, Username: from.Username, FirstName: from.FirstName})
+	return false
+}
+
+// restrictAccess is a middleware limiting the bot to subscribers and admins outside open mode
+// Everybody can still send /start to subscribe or ask for access
+func (b *Bot) restrictAccess(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+		if b.accessMode() == config.AccessModeOpen || b.hasAccess(update) {
+			next(ctx, botInstance, update)
+			return
+		}
+
+		userID := updateUserID(update)
+		slog.Debug("Ignoring update from user without access", "user_id", userID)
+
+		switch {
+		case update.Message != nil:
+			localizer := b.getLocalizerForUser(ctx, userID, update.Message.From.LanguageCode)
+			b.sendReply(ctx, update.Message.Chat.ID, i18n.T(localizer, "access.required"))
+		case update.CallbackQuery != nil:
+			if update.CallbackQuery.Message.Message != nil {
+				b.answerCallback(ctx, botInstance, update.CallbackQuery, "access.required", true)
+			}
+		case update.InlineQuery != nil:
+			// An empty answer stops the loading indicator in the user's chat
+			botInstance.AnswerInlineQuery(ctx, &bot.AnswerInlineQueryParams{
+				InlineQueryID: update.InlineQuery.ID,
+				Results:       []botModels.InlineQueryResult{},
+				IsPersonal:    true,
+			})
+		}
+	}
+}
+
+// hasAccess reports whether an update may be handled outside open mode
+func (b *Bot) hasAccess(update *botModels.Update) bool {
+	userID := updateUserID(update)
+	if userID == 0 {
+		return true
+	}
+
+	if update.Message != nil && strings.HasPrefix(update.Message.Text, "/start") {
+		return true
+	}
+
+	if b.isAdmin(userID) {
+		return true
+	}
+
+	subscribed, err := b.db.IsSubscribed(userID)
+	if err != nil {
+		slog.Warn("Failed to check subscription status", "user_id", userID, "error", err)
+		return false
+	}
+	return subscribed
+}
+
+// notifyAdminsOfRequest sends every admin a subscription request with approve and reject buttons
+func (b *Bot) notifyAdminsOfRequest(ctx context.Context, subscriber *models.Subscriber) {
+	if b.admin == nil {
+		return
+	}
+
+	admins, err := b.admin.GetAdmins()
+	if err != nil {
+		slog.Error("Failed to load admins for subscription request", "error", err)
+		return
+	}
+	if len(admins) == 0 {
+		slog.Warn("Subscription request can't be approved: no admins configured",
+			"chat_id", subscriber.ChatID)
+		return
+	}
+
+	for _, admin := range admins {
+		localizer := b.getLocalizerForUser(ctx, admin.ChatID, "")
+		text := i18n.TWithData(localizer, "access.request", map[string]interface{}{
+			"Name":   subscriberName(subscriber),
+			"ChatID": subscriber.ChatID,
+		})
+		keyboard := &botModels.InlineKeyboardMarkup{
+			InlineKeyboard: [][]botModels.InlineKeyboardButton{
+				{
+					{
+						Text:         i18n.T(localizer, "button.approve"),
+						CallbackData: fmt.Sprintf("access:approve:%d", subscriber.ChatID),
+					},
+					{
+						Text:         i18n.T(localizer, "button.reject"),
+						CallbackData: fmt.Sprintf("access:reject:%d", subscriber.ChatID),
+					},
+				},
+			},
+		}
+
+		if err := b.SendMessageWithKeyboard(ctx, admin.ChatID, text, keyboard); err != nil {
+			slog.Warn("Failed to send subscription request to admin",
+				"admin", admin.ChatID,
+				"error", err)
+		}
+	}
+}
+
+// handleAccessCallback handles the approve and reject buttons of subscription requests
+// Callback data format: "access:approve:{chatID}" or "access:reject:{chatID}"
+func (b *Bot) handleAccessCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	adminChatID := callbackQuery.Message.Message.Chat.ID
+	if !b.isAdmin(adminChatID) || b.access == nil {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	decision, arg, _ := strings.Cut(strings.TrimPrefix(callbackQuery.Data, "access:"), ":")
+	chatID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || (decision != "approve" && decision != "reject") {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	if decision == "approve" {
+		err = b.access.ApproveSubscriber(chatID)
+	} else {
+		err = b.access.RejectSubscriber(chatID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Another admin already decided
+		b.answerCallback(ctx, botInstance, callbackQuery, "access.already_handled", true)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to decide subscription request",
+			"chat_id", chatID,
+			"decision", decision,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "admin.error", false)
+		return
+	}
+
+	slog.Info("Subscription request decided",
+		"admin", adminChatID,
+		"chat_id", chatID,
+		"decision", decision)
+
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+	})
+
+	resultKey := "access.approved_admin"
+	if decision == "reject" {
+		resultKey = "access.rejected_admin"
+	}
+	adminLocalizer := b.getLocalizerForUser(ctx, adminChatID, callbackQuery.From.LanguageCode)
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    adminChatID,
+		MessageID: callbackQuery.Message.Message.ID,
+		Text:      callbackQuery.Message.Message.Text + "\n\n" + i18n.T(adminLocalizer, resultKey),
+	})
+	if err != nil {
+		slog.Warn("Failed to update subscription request", "admin", adminChatID, "error", err)
+	}
+
+	userLocalizer := b.getLocalizerForUser(ctx, chatID, "")
+	if decision == "approve" {
+		b.sendReply(ctx, chatID, i18n.T(userLocalizer, "access.approved"))
+		b.sendWelcome(ctx, chatID, userLocalizer)
+	} else {
+		b.sendReply(ctx, chatID, i18n.T(userLocalizer, "access.rejected"))
+	}
+}
+
+// handleInvite handles the /invite admin command
+// Usage: "/invite [uses]" creates a code, "/invite list" lists open codes, "/invite revoke <code>" deletes one
+func (b *Bot) handleInvite(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	args := commandArgs(update)
+
+	slog.Info("Processing /invite command",
+		"chat_id", chatID,
+		"arguments", args)
+
+	if b.access == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		invites, err := b.access.GetOpenInvites()
+		if err != nil {
+			slog.Error("Failed to list invites", "error", err)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+			return
+		}
+		b.sendReply(ctx, chatID, b.formatInvites(ctx, invites, localizer))
+	case len(args) == 2 && args[0] == "revoke":
+		err := b.access.RevokeInvite(args[1])
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.invite_not_found", map[string]interface{}{
+				"Code": args[1],
+			}))
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to revoke invite", "error", err)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+			return
+		}
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.invite_revoked", map[string]interface{}{
+			"Code": args[1],
+		}))
+	case len(args) <= 1:
+		uses := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > maxInviteUses {
+				b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.invite_usage", map[string]interface{}{
+					"Max": maxInviteUses,
+				}))
+				return
+			}
+			uses = n
+		}
+		b.createInvite(ctx, chatID, uses, localizer)
+	default:
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.invite_usage", map[string]interface{}{
+			"Max": maxInviteUses,
+		}))
+	}
+}
+
+// createInvite creates an invite code and sends its deep link to the admin
+func (b *Bot) createInvite(ctx context.Context, chatID int64, uses int, localizer *goi18n.Localizer) {
+	code, err := generateInviteCode()
+	if err != nil {
+		slog.Error("Failed to generate invite code", "error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	if err := b.access.CreateInvite(code, uses, chatID); err != nil {
+		slog.Error("Failed to create invite", "error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	slog.Info("Invite created",
+		"admin", chatID,
+		"code", code,
+		"uses", uses)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.invite_created", map[string]interface{}{
+		"Link": inviteLink(b.botUsername(ctx), code),
+		"Code": code,
+		"Uses": uses,
+	}))
+}
+
+// formatInvites formats the list of open invite codes
+func (b *Bot) formatInvites(ctx context.Context, invites []models.Invite, localizer *goi18n.Localizer) string {
+	if len(invites) == 0 {
+		return i18n.T(localizer, "admin.invite_list_empty")
+	}
+
+	username := b.botUsername(ctx)
+
+	var message strings.Builder
+	message.WriteString(i18n.T(localizer, "admin.invite_list_title"))
+	message.WriteString("\n")
+	for _, invite := range invites {
+		message.WriteString(fmt.Sprintf("\n• %s — %d/%d", inviteLink(username, invite.Code), invite.Remaining(), invite.MaxUses))
+	}
+	return message.String()
+}
+
+// botUsername returns the bot's username, or "" if Telegram can't be reached
+func (b *Bot) botUsername(ctx context.Context) string {
+	me, err := b.bot.GetMe(ctx)
+	if err != nil {
+		slog.Warn("Failed to get bot username", "error", err)
+		return ""
+	}
+	return me.Username
+}
+
+// inviteLink returns the t.me deep link that starts the bot with an invite code
+// Falls back to the bare "/start <code>" command if the bot's username is unknown
+func inviteLink(username, code string) string {
+	if username == "" {
+		return "/start " + code
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", username, code)
+}
+
+// generateInviteCode creates a random code usable as a /start deep link parameter
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
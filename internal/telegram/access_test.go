@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// mockAccessStore implements AccessStore in memory for testing
+type mockAccessStore struct {
+	subscribers map[int64]*models.Subscriber
+	invites     map[string]*models.Invite
+}
+
+func newMockAccessStore() *mockAccessStore {
+	return &mockAccessStore{
+		subscribers: make(map[int64]*models.Subscriber),
+		invites:     make(map[string]*models.Invite),
+	}
+}
+
+func (m *mockAccessStore) GetSubscriber(chatID int64) (*models.Subscriber, error) {
+	subscriber, ok := m.subscribers[chatID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return subscriber, nil
+}
+
+func (m *mockAccessStore) AddPendingSubscriber(chatID int64, username, firstName string) error {
+	m.subscribers[chatID] = &models.Subscriber{ChatID: chatID, Username: username, FirstName: firstName, IsPending: true}
+	return nil
+}
+
+func (m *mockAccessStore) ApproveSubscriber(chatID int64) error {
+	subscriber, ok := m.subscribers[chatID]
+	if !ok || !subscriber.IsPending {
+		return gorm.ErrRecordNotFound
+	}
+	subscriber.IsPending = false
+	subscriber.IsActive = true
+	return nil
+}
+
+func (m *mockAccessStore) RejectSubscriber(chatID int64) error {
+	subscriber, ok := m.subscribers[chatID]
+	if !ok || !subscriber.IsPending {
+		return gorm.ErrRecordNotFound
+	}
+	delete(m.subscribers, chatID)
+	return nil
+}
+
+func (m *mockAccessStore) CreateInvite(code string, maxUses int, createdBy int64) error {
+	m.invites[code] = &models.Invite{Code: code, MaxUses: maxUses, CreatedBy: createdBy}
+	return nil
+}
+
+func (m *mockAccessStore) RedeemInvite(code string) error {
+	invite, ok := m.invites[code]
+	if !ok || invite.Remaining() == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	invite.Uses++
+	return nil
+}
+
+func (m *mockAccessStore) GetOpenInvites() ([]models.Invite, error) {
+	var invites []models.Invite
+	for _, invite := range m.invites {
+		if invite.Remaining() > 0 {
+			invites = append(invites, *invite)
+		}
+	}
+	return invites, nil
+}
+
+func (m *mockAccessStore) RevokeInvite(code string) error {
+	if _, ok := m.invites[code]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(m.invites, code)
+	return nil
+}
+
+func newAccessTestBot(t *testing.T, api *recordingTelegramAPI, db *mockSubscriberDB, mode string) (*Bot, *mockAccessStore) {
+	b := newCallbackTestBot(t, api, db)
+	b.config = &config.Config{Admin: config.AdminConfig{AccessMode: mode}}
+	b.SetAdminStore(newMockAdminStore(100))
+	access := newMockAccessStore()
+	b.SetAccessStore(access)
+	return b, access
+}
+
+// Test 1: In approval mode /start creates a request that an admin approves
+func TestAccess_ApprovalMode(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	b, access := newAccessTestBot(t, api, db, config.AccessModeApproval)
+	ctx := context.Background()
+
+	b.handleStart(ctx, b.bot, commandUpdate(200, "/start"))
+
+	if len(db.subscribers) != 0 {
+		t.Fatalf("Expected no subscriber before approval, got %v", db.subscribers)
+	}
+	if subscriber, ok := access.subscribers[200]; !ok || !subscriber.IsPending {
+		t.Fatal("Expected a pending request")
+	}
+	requests := 0
+	for _, message := range api.sent("sendMessage") {
+		if message.chatID == 100 && strings.Contains(message.text, "200") {
+			requests++
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the admin to get 1 request, got %d", requests)
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "access:approve:200"))
+
+	if access.subscribers[200].IsPending {
+		t.Error("Expected the request to be approved")
+	}
+	welcomed := slices.ContainsFunc(api.sent("sendMessage"), func(message sentMessage) bool {
+		return message.chatID == 200 && strings.Contains(message.text, "approved")
+	})
+	if !welcomed {
+		t.Error("Expected the user to be told about the approval")
+	}
+}
+
+// Test 2: In invite mode only a valid invite code subscribes a new user
+func TestAccess_InviteMode(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	b, access := newAccessTestBot(t, api, db, config.AccessModeInvite)
+	access.CreateInvite("abc123", 1, 100)
+	ctx := context.Background()
+
+	b.handleStart(ctx, b.bot, commandUpdate(200, "/start"))
+	b.handleStart(ctx, b.bot, commandUpdate(200, "/start wrong"))
+	if len(db.subscribers) != 0 {
+		t.Fatalf("Expected no subscriber without a valid invite, got %v", db.subscribers)
+	}
+
+	b.handleStart(ctx, b.bot, commandUpdate(200, "/start abc123"))
+	if !slices.Contains(db.subscribers, int64(200)) {
+		t.Fatal("Expected the invite to subscribe the user")
+	}
+
+	// The invite was single-use
+	b.handleStart(ctx, b.bot, commandUpdate(300, "/start abc123"))
+	if slices.Contains(db.subscribers, int64(300)) {
+		t.Error("Expected a used invite to be rejected")
+	}
+}
+
+// Test 3: Outside open mode only subscribers and admins reach the handlers, except for /start
+func TestRestrictAccess(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{200}
+	b, _ := newAccessTestBot(t, &recordingTelegramAPI{}, db, config.AccessModeInvite)
+
+	var handled []string
+	next := b.restrictAccess(func(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+		handled = append(handled, update.Message.Text)
+	})
+
+	next(context.Background(), b.bot, commandUpdate(300, "/recent"))
+	next(context.Background(), b.bot, commandUpdate(300, "/start abc"))
+	next(context.Background(), b.bot, commandUpdate(200, "/search dune"))
+	next(context.Background(), b.bot, commandUpdate(100, "/stats"))
+
+	want := []string{"/start abc", "/search dune", "/stats"}
+	if !slices.Equal(handled, want) {
+		t.Errorf("Expected %v to be handled, got %v", want, handled)
+	}
+}
@@ -44,6 +44,56 @@ func (a *JellyfinClientAdapter) GetPosterImage(ctx context.Context, itemID strin
 	return a.client.GetPosterImage(ctx, itemID)
 }
 
+// QueryItems adapts QueryItems from jellyfin.Client
+func (a *JellyfinClientAdapter) QueryItems(ctx context.Context, query models.ItemQuery) ([]ContentItem, int, error) {
+	result, err := a.client.QueryItems(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return convertToTelegramContentItems(result.Items), result.TotalRecordCount, nil
+}
+
+// SearchContentForUser adapts SearchContentForUser from jellyfin.Client
+func (a *JellyfinClientAdapter) SearchContentForUser(ctx context.Context, userID, query string, limit int) ([]ContentItem, error) {
+	items, err := a.client.SearchContentForUser(ctx, userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToTelegramContentItems(items), nil
+}
+
+// AuthenticateByName adapts AuthenticateByName from jellyfin.Client
+func (a *JellyfinClientAdapter) AuthenticateByName(ctx context.Context, deviceID, username, password string) (*models.JellyfinAuthResult, error) {
+	return a.client.AuthenticateByName(ctx, deviceID, username, password)
+}
+
+// InitiateQuickConnect adapts InitiateQuickConnect from jellyfin.Client
+func (a *JellyfinClientAdapter) InitiateQuickConnect(ctx context.Context, deviceID string) (*models.QuickConnectResult, error) {
+	return a.client.InitiateQuickConnect(ctx, deviceID)
+}
+
+// GetQuickConnectState adapts GetQuickConnectState from jellyfin.Client
+func (a *JellyfinClientAdapter) GetQuickConnectState(ctx context.Context, deviceID, secret string) (*models.QuickConnectResult, error) {
+	return a.client.GetQuickConnectState(ctx, deviceID, secret)
+}
+
+// AuthenticateWithQuickConnect adapts AuthenticateWithQuickConnect from jellyfin.Client
+func (a *JellyfinClientAdapter) AuthenticateWithQuickConnect(ctx context.Context, deviceID, secret string) (*models.JellyfinAuthResult, error) {
+	return a.client.AuthenticateWithQuickConnect(ctx, deviceID, secret)
+}
+
+// Logout adapts Logout from jellyfin.Client
+func (a *JellyfinClientAdapter) Logout(ctx context.Context, deviceID, token string) error {
+	return a.client.Logout(ctx, deviceID, token)
+}
+
+// IsItemVisible adapts IsItemVisible from jellyfin.Client
+func (a *JellyfinClientAdapter) IsItemVisible(ctx context.Context, userID, itemID string) (bool, error) {
+	return a.client.IsItemVisible(ctx, userID, itemID)
+}
+
 // convertToTelegramContentItems converts jellyfin models to telegram ContentItems
 func convertToTelegramContentItems(items []models.ContentItem) []ContentItem {
 	result := make([]ContentItem, len(items))
@@ -56,9 +106,14 @@ func convertToTelegramContentItems(items []models.ContentItem) []ContentItem {
 			CommunityRating: item.CommunityRating,
 			OfficialRating:  item.OfficialRating,
 			ProductionYear:  item.ProductionYear,
+			SeriesID:        item.SeriesID,
 			SeriesName:      item.SeriesName,
 			SeasonNumber:    item.SeasonNumber,
 			EpisodeNumber:   item.EpisodeNumber,
+			Album:           item.Album,
+			Artist:          item.AlbumArtist,
+			TrailerURL:      item.TrailerURL(),
+			ProviderIDs:     item.ProviderIDs,
 		}
 	}
 	return result
@@ -0,0 +1,721 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
+)
+
+// subscribersPageSize is the number of subscribers listed per /subscribers page
+const subscribersPageSize = 20
+
+// statsWindow is the period /stats counts sent notifications for
+const statsWindow = 24 * time.Hour
+
+// AdminStore defines the interface for admin, ban and statistics operations
+type AdminStore interface {
+	IsAdmin(chatID int64) (bool, error)
+	GetAdmins() ([]models.Admin, error)
+	AddAdmin(chatID, addedBy int64) error
+	RemoveAdmin(chatID int64) error
+	BanSubscriber(chatID int64) error
+	UnbanSubscriber(chatID int64) error
+	IsBanned(chatID int64) (bool, error)
+	ListSubscribers(offset, limit int) ([]models.Subscriber, int64, error)
+	GetBotStats(since time.Time) (*models.BotStats, error)
+	SetAdminEventEnabled(chatID int64, eventType string, enabled bool) error
+}
+
+// SetAdminStore enables the admin commands and makes the bot ignore banned users
+func (b *Bot) SetAdminStore(store AdminStore) {
+	b.admin = store
+}
+
+// SetNotificationQueue sets the queue /resend hands notifications to
+func (b *Bot) SetNotificationQueue(queue handlers.NotificationQueue) {
+	b.queue = queue
+}
+
+// SetItemFetcher sets the source of item metadata for /resend and /testnotify
+func (b *Bot) SetItemFetcher(items handlers.MetadataFetcher) {
+	b.items = items
+}
+
+// isAdmin checks whether a chat belongs to an admin
+func (b *Bot) isAdmin(chatID int64) bool {
+	if b.admin == nil {
+		return false
+	}
+
+	isAdmin, err := b.admin.IsAdmin(chatID)
+	if err != nil {
+		slog.Error("Failed to check admin status",
+			"chat_id", chatID,
+			"error", err)
+		return false
+	}
+	return isAdmin
+}
+
+// requireAdmin returns the localizer for an admin command, or false if the sender isn't an admin
+// Non-admins get the unknown command reply so the admin commands stay hidden
+func (b *Bot) requireAdmin(ctx context.Context, update *botModels.Update) (*goi18n.Localizer, bool) {
+	if update.Message == nil {
+		return nil, false
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	if !b.isAdmin(chatID) {
+		slog.Warn("Admin command from non-admin",
+			"chat_id", chatID,
+			"command", strings.Fields(update.Message.Text)[0])
+		b.sendReply(ctx, chatID, i18n.T(localizer, "help.invalid_command"))
+		return nil, false
+	}
+
+	return localizer, true
+}
+
+// ignoreBannedUsers is a middleware dropping updates from banned users
+func (b *Bot) ignoreBannedUsers(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+		if b.admin != nil {
+			if userID := updateUserID(update); userID != 0 {
+				banned, err := b.admin.IsBanned(userID)
+				if err != nil {
+					slog.Warn("Failed to check ban status", "user_id", userID, "error", err)
+				} else if banned {
+					slog.Debug("Ignoring update from banned user", "user_id", userID)
+					return
+				}
+			}
+		}
+		next(ctx, botInstance, update)
+	}
+}
+
+// updateUserID returns the ID of the user who sent a message, pressed a button or typed an inline query
+func updateUserID(update *botModels.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.InlineQuery != nil && update.InlineQuery.From != nil:
+		return update.InlineQuery.From.ID
+	}
+	return 0
+}
+
+// commandArgs returns the arguments following the command of a message
+func commandArgs(update *botModels.Update) []string {
+	fields := strings.Fields(update.Message.Text)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[1:]
+}
+
+// matchCommand returns the arguments of a message whose first word is exactly the command,
+// optionally addressed to this bot ("/wish@jellyfin_bot"), and false for any other command
+// Commands registered by prefix need it so "/wishlist" isn't taken for "/wish list"
+func (b *Bot) matchCommand(ctx context.Context, update *botModels.Update, command string) ([]string, bool) {
+	fields := strings.Fields(update.Message.Text)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	name, botName, addressed := strings.Cut(fields[0], "@")
+	if name != command {
+		return nil, false
+	}
+	if addressed && !strings.EqualFold(botName, b.botUsername(ctx)) {
+		return nil, false
+	}
+	return fields[1:], true
+}
+
+// parseChatIDArg parses the single chat ID argument of /ban, /unban and /admins
+func parseChatIDArg(args []string) (int64, bool) {
+	if len(args) != 1 {
+		return 0, false
+	}
+	chatID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || chatID == 0 {
+		return 0, false
+	}
+	return chatID, true
+}
+
+// handleStats handles the /stats admin command
+func (b *Bot) handleStats(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	slog.Info("Processing /stats command", "chat_id", chatID)
+
+	stats, err := b.admin.GetBotStats(time.Now().Add(-statsWindow))
+	if err != nil {
+		slog.Error("Failed to get bot stats",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	b.sendReply(ctx, chatID, formatStats(stats, localizer))
+}
+
+// formatStats formats the /stats report
+func formatStats(stats *models.BotStats, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+
+	message.WriteString(i18n.TWithData(localizer, "admin.stats", map[string]interface{}{
+		"Total":             stats.TotalSubscribers,
+		"Active":            stats.ActiveSubscribers,
+		"Inactive":          stats.InactiveSubscribers,
+		"Banned":            stats.BannedSubscribers,
+		"Pending":           stats.PendingSubscribers,
+		"MutedSeries":       stats.MutedSeries,
+		"MutingSubscribers": stats.MutingSubscribers,
+		"Sent":              stats.NotificationsSent,
+	}))
+
+	languages := make([]string, 0, len(stats.Languages))
+	for code := range stats.Languages {
+		languages = append(languages, code)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if stats.Languages[languages[i]] != stats.Languages[languages[j]] {
+			return stats.Languages[languages[i]] > stats.Languages[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	for _, code := range languages {
+		message.WriteString(fmt.Sprintf("\n• %s: %d", code, stats.Languages[code]))
+	}
+
+	return message.String()
+}
+
+// handleSubscribers handles the /subscribers admin command
+func (b *Bot) handleSubscribers(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	slog.Info("Processing /subscribers command", "chat_id", chatID)
+
+	text, keyboard, err := b.subscribersPage(1, localizer)
+	if err != nil {
+		slog.Error("Failed to list subscribers",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	if err := b.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+		slog.Error("Failed to send subscriber list",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handleSubscribersCallback handles the page buttons of /subscribers
+// Callback data format: "subs:{page}"
+func (b *Bot) handleSubscribersCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	if !b.isAdmin(chatID) {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	page, err := strconv.Atoi(strings.TrimPrefix(callbackQuery.Data, "subs:"))
+	if err != nil || page < 1 {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	text, keyboard, err := b.subscribersPage(page, localizer)
+	if err != nil {
+		slog.Error("Failed to list subscribers",
+			"chat_id", chatID,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "admin.error", false)
+		return
+	}
+
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+	})
+
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   callbackQuery.Message.Message.ID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to show subscriber page",
+			"chat_id", chatID,
+			"page", page,
+			"error", err)
+	}
+}
+
+// subscribersPage formats one page of the subscriber list with its navigation buttons
+func (b *Bot) subscribersPage(page int, localizer *goi18n.Localizer) (string, *botModels.InlineKeyboardMarkup, error) {
+	subscribers, total, err := b.admin.ListSubscribers((page-1)*subscribersPageSize, subscribersPageSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if total == 0 {
+		return i18n.T(localizer, "admin.subscribers_empty"), nil, nil
+	}
+
+	pages := int((total + subscribersPageSize - 1) / subscribersPageSize)
+	if page > pages {
+		// The list shrank since the page buttons were sent
+		return b.subscribersPage(pages, localizer)
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.TWithData(localizer, "admin.subscribers_title", map[string]interface{}{
+		"Total": total,
+		"Page":  page,
+		"Pages": pages,
+	}))
+	message.WriteString("\n")
+
+	for i, subscriber := range subscribers {
+		message.WriteString(fmt.Sprintf("\n%d. %s — %d · %s · %s",
+			(page-1)*subscribersPageSize+i+1,
+			subscriberName(&subscriber),
+			subscriber.ChatID,
+			subscriber.LanguageCode,
+			subscriberStatus(&subscriber, localizer)))
+	}
+
+	var row []botModels.InlineKeyboardButton
+	if page > 1 {
+		row = append(row, botModels.InlineKeyboardButton{
+			Text:         i18n.T(localizer, "button.previous_page"),
+			CallbackData: fmt.Sprintf("subs:%d", page-1),
+		})
+	}
+	if page < pages {
+		row = append(row, botModels.InlineKeyboardButton{
+			Text:         i18n.T(localizer, "button.next_page"),
+			CallbackData: fmt.Sprintf("subs:%d", page+1),
+		})
+	}
+
+	var keyboard *botModels.InlineKeyboardMarkup
+	if len(row) > 0 {
+		keyboard = &botModels.InlineKeyboardMarkup{InlineKeyboard: [][]botModels.InlineKeyboardButton{row}}
+	}
+
+	return message.String(), keyboard, nil
+}
+
+// subscriberName returns the display name of a subscriber in the subscriber list
+func subscriberName(subscriber *models.Subscriber) string {
+	name := subscriber.FirstName
+	if subscriber.Username != "" {
+		name = strings.TrimSpace(name + " @" + subscriber.Username)
+	}
+	if name == "" {
+		name = "—"
+	}
+	return name
+}
+
+// subscriberStatus returns the localized state of a subscriber
+func subscriberStatus(subscriber *models.Subscriber, localizer *goi18n.Localizer) string {
+	switch {
+	case subscriber.IsBanned:
+		return i18n.T(localizer, "admin.status.banned")
+	case subscriber.IsPending:
+		return i18n.T(localizer, "admin.status.pending")
+	case subscriber.IsActive:
+		return i18n.T(localizer, "admin.status.active")
+	default:
+		return i18n.T(localizer, "admin.status.inactive")
+	}
+}
+
+// handleBan handles the /ban admin command
+// Usage: "/ban <chat ID>"
+func (b *Bot) handleBan(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	target, valid := parseChatIDArg(commandArgs(update))
+	if !valid {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.ban_usage"))
+		return
+	}
+
+	slog.Info("Processing /ban command",
+		"chat_id", chatID,
+		"target", target)
+
+	// Admins can't lock each other out
+	if b.isAdmin(target) {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.ban_admin"))
+		return
+	}
+
+	if err := b.admin.BanSubscriber(target); err != nil {
+		slog.Error("Failed to ban user",
+			"chat_id", chatID,
+			"target", target,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	slog.Info("User banned",
+		"admin", chatID,
+		"target", target)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.ban_success", map[string]interface{}{
+		"ChatID": target,
+	}))
+}
+
+// handleUnban handles the /unban admin command
+// Usage: "/unban <chat ID>"
+func (b *Bot) handleUnban(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	target, valid := parseChatIDArg(commandArgs(update))
+	if !valid {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.unban_usage"))
+		return
+	}
+
+	slog.Info("Processing /unban command",
+		"chat_id", chatID,
+		"target", target)
+
+	err := b.admin.UnbanSubscriber(target)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.unban_not_banned", map[string]interface{}{
+			"ChatID": target,
+		}))
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to unban user",
+			"chat_id", chatID,
+			"target", target,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	slog.Info("User unbanned",
+		"admin", chatID,
+		"target", target)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.unban_success", map[string]interface{}{
+		"ChatID": target,
+	}))
+}
+
+// handleResend handles the /resend admin command, notifying all subscribers of an item again
+// Usage: "/resend <Jellyfin item ID>"
+func (b *Bot) handleResend(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	args := commandArgs(update)
+	if len(args) != 1 {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.resend_usage"))
+		return
+	}
+	itemID := args[0]
+
+	slog.Info("Processing /resend command",
+		"chat_id", chatID,
+		"item_id", itemID)
+
+	if b.queue == nil || b.items == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	item, err := b.items.GetItem(ctx, itemID)
+	if err != nil {
+		slog.Warn("Failed to fetch item for resend",
+			"item_id", itemID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.resend_not_found", map[string]interface{}{
+			"ItemID": itemID,
+		}))
+		return
+	}
+
+	content := handlers.NotificationContentFromItem(item)
+	if err := b.queue.EnqueueNotification(ctx, content); err != nil {
+		slog.Error("Failed to enqueue resend",
+			"item_id", itemID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	slog.Info("Notification queued for resend",
+		"admin", chatID,
+		"item_id", itemID,
+		"title", content.Title)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.resend_queued", map[string]interface{}{
+		"Title": content.Title,
+	}))
+}
+
+// handleTestNotify handles the /testnotify admin command
+// It sends the notification of the most recently added item to the admin only
+func (b *Bot) handleTestNotify(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	slog.Info("Processing /testnotify command", "chat_id", chatID)
+
+	if b.items == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	recent, err := b.jellyfinClient.GetRecentItems(ctx, 1)
+	if err != nil {
+		slog.Error("Failed to fetch recent item for test notification",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+	if len(recent) == 0 {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.testnotify_empty"))
+		return
+	}
+
+	item, err := b.items.GetItem(ctx, recent[0].ItemID)
+	if err != nil {
+		slog.Error("Failed to fetch item for test notification",
+			"item_id", recent[0].ItemID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	content := convertNotificationContent(handlers.NotificationContentFromItem(item))
+	if _, err := b.deliverNotification(ctx, chatID, content, b.fetchPoster(ctx, content)); err != nil {
+		slog.Error("Failed to send test notification",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+	}
+}
+
+// handleAdmins handles the /admins admin command
+// Usage: "/admins" lists admins, "/admins add <chat ID>" and "/admins remove <chat ID>" manage them
+func (b *Bot) handleAdmins(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	args := commandArgs(update)
+
+	slog.Info("Processing /admins command",
+		"chat_id", chatID,
+		"arguments", args)
+
+	if len(args) == 0 {
+		admins, err := b.admin.GetAdmins()
+		if err != nil {
+			slog.Error("Failed to get admins", "error", err)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+			return
+		}
+		b.sendReply(ctx, chatID, formatAdmins(admins, localizer))
+		return
+	}
+
+	target, valid := parseChatIDArg(args[1:])
+	if !valid || (args[0] != "add" && args[0] != "remove") {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.admins_usage"))
+		return
+	}
+
+	if args[0] == "add" {
+		if err := b.admin.AddAdmin(target, chatID); err != nil {
+			slog.Error("Failed to add admin", "target", target, "error", err)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+			return
+		}
+		b.setAdminCommands(ctx, target)
+
+		slog.Info("Admin added", "admin", chatID, "target", target)
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.admins_added", map[string]interface{}{
+			"ChatID": target,
+		}))
+		return
+	}
+
+	admins, err := b.admin.GetAdmins()
+	if err != nil {
+		slog.Error("Failed to get admins", "error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+	for _, admin := range admins {
+		if admin.ChatID == target && admin.FromConfig {
+			b.sendReply(ctx, chatID, i18n.T(localizer, "admin.admins_config"))
+			return
+		}
+	}
+
+	err = b.admin.RemoveAdmin(target)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.admins_not_found", map[string]interface{}{
+			"ChatID": target,
+		}))
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to remove admin", "target", target, "error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+	b.removeAdminCommands(ctx, target)
+
+	slog.Info("Admin removed", "admin", chatID, "target", target)
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.admins_removed", map[string]interface{}{
+		"ChatID": target,
+	}))
+}
+
+// formatAdmins formats the /admins list
+func formatAdmins(admins []models.Admin, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+	message.WriteString(i18n.T(localizer, "admin.admins_title"))
+	message.WriteString("\n")
+
+	for _, admin := range admins {
+		message.WriteString(fmt.Sprintf("\n• %d", admin.ChatID))
+		if admin.FromConfig {
+			message.WriteString(" " + i18n.T(localizer, "admin.admins_from_config"))
+		}
+	}
+
+	return message.String()
+}
+
+// adminCommands returns the commands shown in the menu of admins only
+func adminCommands(localizer *goi18n.Localizer) []botModels.BotCommand {
+	return []botModels.BotCommand{
+		{Command: "stats", Description: i18n.T(localizer, "command.stats.description")},
+		{Command: "subscribers", Description: i18n.T(localizer, "command.subscribers.description")},
+		{Command: "ban", Description: i18n.T(localizer, "command.ban.description")},
+		{Command: "unban", Description: i18n.T(localizer, "command.unban.description")},
+		{Command: "resend", Description: i18n.T(localizer, "command.resend.description")},
+		{Command: "testnotify", Description: i18n.T(localizer, "command.testnotify.description")},
+		{Command: "admins", Description: i18n.T(localizer, "command.admins.description")},
+		{Command: "announce", Description: i18n.T(localizer, "command.announce.description")},
+		{Command: "invite", Description: i18n.T(localizer, "command.invite.description")},
+		{Command: "events", Description: i18n.T(localizer, "command.events.description")},
+	}
+}
+
+// registerAdminCommands shows the admin commands in the command menu of every admin
+func (b *Bot) registerAdminCommands(ctx context.Context) {
+	admins, err := b.admin.GetAdmins()
+	if err != nil {
+		slog.Warn("Failed to load admins for command menu", "error", err)
+		return
+	}
+
+	for _, admin := range admins {
+		b.setAdminCommands(ctx, admin.ChatID)
+	}
+}
+
+// setAdminCommands sets the menu of an admin's chat to the user and admin commands
+// The menu uses the admin's language, since chat-scoped menus take precedence over language-scoped ones
+func (b *Bot) setAdminCommands(ctx context.Context, chatID int64) {
+	localizer := b.getLocalizerForUser(ctx, chatID, "")
+	commands := append(userCommands(localizer), adminCommands(localizer)...)
+
+	_, err := b.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+		Commands: commands,
+		Scope:    &botModels.BotCommandScopeChat{ChatID: chatID},
+	})
+	if err != nil {
+		slog.Warn("Failed to set admin commands",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// removeAdminCommands resets the menu of a former admin's chat to the user commands
+func (b *Bot) removeAdminCommands(ctx context.Context, chatID int64) {
+	_, err := b.bot.DeleteMyCommands(ctx, &bot.DeleteMyCommandsParams{
+		Scope: &botModels.BotCommandScopeChat{ChatID: chatID},
+	})
+	if err != nil {
+		slog.Warn("Failed to remove admin commands",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
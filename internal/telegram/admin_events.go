@@ -0,0 +1,440 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// adminEventCheckInterval is how often held back server events are looked at
+	adminEventCheckInterval = 30 * time.Second
+	// adminEventQueueSize is how many server events can wait for the worker before new ones are dropped
+	adminEventQueueSize = 256
+	// adminEventMaxNameLength caps user names in server events, which are whatever a failed login tried
+	adminEventMaxNameLength = 64
+)
+
+// adminEventKeys maps each server event to the suffix of its message ("admin_event.") and label ("events.type.") keys
+var adminEventKeys = map[string]string{
+	models.EventPlaybackStart:         "playback_start",
+	models.EventPlaybackStop:          "playback_stop",
+	models.EventUserCreated:           "user_created",
+	models.EventAuthenticationFailure: "authentication_failure",
+	models.EventPluginInstalled:       "plugin_installed",
+}
+
+// playMethodKeys maps Jellyfin play methods to their display labels
+var playMethodKeys = map[string]string{
+	"Transcode":    "admin_event.play_method.transcode",
+	"DirectStream": "admin_event.play_method.direct_stream",
+	"DirectPlay":   "admin_event.play_method.direct_play",
+}
+
+// adminEventDeliverer sends server events to the admins who subscribed to them (implemented by Bot)
+type adminEventDeliverer interface {
+	deliverAdminEvents(ctx context.Context, events []handlers.AdminEvent)
+}
+
+// AdminEvents passes server events on to the admins who subscribed to them with /events
+// The first event of a user and type is sent right away; more of them within the window are held back
+// and summarized in one message when it ends, so a binge doesn't flood the admins
+// Events are sent by the Run worker, so webhooks don't wait for Telegram
+// It implements the handlers.AdminEventNotifier interface
+type AdminEvents struct {
+	deliverer     adminEventDeliverer
+	window        time.Duration
+	checkInterval time.Duration
+	queue         chan handlers.AdminEvent
+
+	groups map[string]*adminEventGroup // Only used by the worker
+}
+
+// adminEventGroup holds the events of one type and user that arrived since the last message about them
+type adminEventGroup struct {
+	until time.Time // Events before this time are held back
+	held  []handlers.AdminEvent
+}
+
+// NewAdminEvents creates a new admin event notifier delivering through the bot
+// A zero window sends every event as it arrives
+func NewAdminEvents(bot *Bot, window time.Duration) *AdminEvents {
+	return &AdminEvents{
+		deliverer:     bot,
+		window:        window,
+		checkInterval: adminEventCheckInterval,
+		queue:         make(chan handlers.AdminEvent, adminEventQueueSize),
+		groups:        make(map[string]*adminEventGroup),
+	}
+}
+
+// NotifyAdminEvent hands a server event to the worker, which sends it to the subscribed admins
+// or holds it back for the next summary
+func (a *AdminEvents) NotifyAdminEvent(ctx context.Context, event *handlers.AdminEvent) error {
+	select {
+	case a.queue <- *event:
+		return nil
+	default:
+		return fmt.Errorf("admin event queue is full, dropping %s event", event.Type)
+	}
+}
+
+// Run sends queued server events and summarizes held back ones once their window ends,
+// until the context is cancelled
+func (a *AdminEvents) Run(ctx context.Context) {
+	slog.Info("Starting admin event worker", "window", a.window)
+
+	// Without a window nothing is held back, so there are no summaries to check for
+	var tick <-chan time.Time
+	if a.window > 0 {
+		ticker := time.NewTicker(a.checkInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.stop(context.WithoutCancel(ctx))
+			return
+		case event := <-a.queue:
+			// select picks at random when the context is cancelled too, so don't send with a dead context
+			if ctx.Err() != nil {
+				a.handle(context.WithoutCancel(ctx), &event)
+				a.stop(context.WithoutCancel(ctx))
+				return
+			}
+			a.handle(ctx, &event)
+		case <-tick:
+			a.flush(ctx, time.Now())
+		}
+	}
+}
+
+// stop sends what is still queued or held back instead of dropping it
+func (a *AdminEvents) stop(ctx context.Context) {
+	for {
+		select {
+		case event := <-a.queue:
+			a.handle(ctx, &event)
+		default:
+			a.flush(ctx, time.Now().Add(a.window))
+			slog.Info("Admin event worker stopped")
+			return
+		}
+	}
+}
+
+// handle sends a server event to the subscribed admins, or holds it back for the next summary
+func (a *AdminEvents) handle(ctx context.Context, event *handlers.AdminEvent) {
+	if a.window > 0 {
+		key := adminEventGroupKey(event)
+		if group, ok := a.groups[key]; ok && event.Time.Before(group.until) {
+			group.held = append(group.held, *event)
+			return
+		}
+		a.groups[key] = &adminEventGroup{until: event.Time.Add(a.window)}
+	}
+
+	a.deliverer.deliverAdminEvents(ctx, []handlers.AdminEvent{*event})
+}
+
+// flush sends a summary for every group whose window ended before now
+// A group that had events stays open for another window, so a binge gets one summary per window
+func (a *AdminEvents) flush(ctx context.Context, now time.Time) {
+	var summaries [][]handlers.AdminEvent
+
+	for key, group := range a.groups {
+		if now.Before(group.until) {
+			continue
+		}
+		if len(group.held) == 0 {
+			delete(a.groups, key)
+			continue
+		}
+		summaries = append(summaries, group.held)
+		group.held = nil
+		group.until = now.Add(a.window)
+	}
+
+	for _, events := range summaries {
+		a.deliverer.deliverAdminEvents(ctx, events)
+	}
+}
+
+// adminEventGroupKey returns the key events are summarized by
+// Failed logins are grouped by address, so guessing many user names still ends up in one summary
+func adminEventGroupKey(event *handlers.AdminEvent) string {
+	subject := event.UserName
+	switch event.Type {
+	case models.EventAuthenticationFailure:
+		if event.RemoteAddress != "" {
+			subject = event.RemoteAddress
+		}
+	case models.EventPluginInstalled:
+		subject = ""
+	}
+	return event.Type + "|" + subject
+}
+
+// deliverAdminEvents sends server events of one type to every admin who subscribed to it
+// A single event gets the full message, several are summarized one line each
+func (b *Bot) deliverAdminEvents(ctx context.Context, events []handlers.AdminEvent) {
+	if b.admin == nil || len(events) == 0 {
+		return
+	}
+
+	admins, err := b.admin.GetAdmins()
+	if err != nil {
+		slog.Error("Failed to load admins for server event", "error", err)
+		return
+	}
+
+	for i := range admins {
+		if !admins[i].WantsEvent(events[0].Type) {
+			continue
+		}
+
+		localizer := b.getLocalizerForUser(ctx, admins[i].ChatID, "")
+		message := formatAdminEvent(&events[0], localizer)
+		if len(events) > 1 {
+			message = formatAdminEventSummary(events, localizer)
+		}
+
+		if err := b.SendMessage(ctx, admins[i].ChatID, message); err != nil {
+			slog.Warn("Failed to send server event to admin",
+				"admin", admins[i].ChatID,
+				"event", events[0].Type,
+				"error", err)
+		}
+	}
+}
+
+// formatAdminEvent formats a server event with the details of its session
+func formatAdminEvent(event *handlers.AdminEvent, localizer *goi18n.Localizer) string {
+	lines := []string{adminEventHeadline(event, localizer)}
+
+	var device []string
+	for _, part := range []string{event.DeviceName, event.ClientName} {
+		if part != "" {
+			device = append(device, part)
+		}
+	}
+	if len(device) > 0 {
+		lines = append(lines, i18n.TWithData(localizer, "admin_event.device", map[string]interface{}{
+			"Device": truncateText(strings.Join(device, " · "), 2*adminEventMaxNameLength),
+		}))
+	}
+	if key, ok := playMethodKeys[event.PlayMethod]; ok && event.Type == models.EventPlaybackStart {
+		lines = append(lines, i18n.T(localizer, key))
+	}
+	if event.RemoteAddress != "" && event.Type == models.EventAuthenticationFailure {
+		lines = append(lines, i18n.TWithData(localizer, "admin_event.address", map[string]interface{}{
+			"Address": event.RemoteAddress,
+		}))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatAdminEventSummary formats the events held back during a window, one line each
+// Lines are capped like catch-ups, so a burst of failed logins still fits in one message
+func formatAdminEventSummary(events []handlers.AdminEvent, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+	message.WriteString(i18n.TWithData(localizer, "admin_event.summary", map[string]interface{}{
+		"Event": i18n.T(localizer, "events.type."+adminEventKeys[events[0].Type]),
+		"Count": len(events),
+	}))
+	message.WriteString("\n")
+
+	for i := range events {
+		if i == catchUpMaxLines {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "catchup.more", map[string]interface{}{
+				"Count": len(events) - catchUpMaxLines,
+			}))
+			break
+		}
+		message.WriteString("\n• " + adminEventHeadline(&events[i], localizer))
+	}
+
+	return message.String()
+}
+
+// adminEventHeadline formats the one-line description of a server event
+func adminEventHeadline(event *handlers.AdminEvent, localizer *goi18n.Localizer) string {
+	key := "admin_event." + adminEventKeys[event.Type]
+	if event.Type == models.EventPlaybackStop && event.PlayedToCompletion {
+		key = "admin_event.playback_finished"
+	}
+
+	user := truncateText(event.UserName, adminEventMaxNameLength)
+	if user == "" {
+		user = "?"
+	}
+	return i18n.TWithData(localizer, key, map[string]interface{}{
+		"User":    user,
+		"Item":    adminEventItem(event),
+		"Plugin":  event.PluginName,
+		"Version": event.PluginVersion,
+	})
+}
+
+// adminEventItem returns the played item of a server event as shown to admins
+func adminEventItem(event *handlers.AdminEvent) string {
+	switch {
+	case event.ItemType == models.ItemTypeEpisode && event.SeriesName != "":
+		return fmt.Sprintf("%s S%02dE%02d", event.SeriesName, event.SeasonNumber, event.EpisodeNumber)
+	case event.Year > 0:
+		return fmt.Sprintf("%s (%d)", event.ItemName, event.Year)
+	}
+	return event.ItemName
+}
+
+// handleEvents handles the /events admin command
+func (b *Bot) handleEvents(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+
+	slog.Info("Processing /events command", "chat_id", chatID)
+
+	keyboard, err := b.createEventsKeyboard(chatID, localizer)
+	if err != nil {
+		slog.Error("Failed to load server event subscriptions",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	if err := b.SendMessageWithKeyboard(ctx, chatID, i18n.T(localizer, "events.select"), keyboard); err != nil {
+		slog.Error("Failed to send server event selection",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handleEventsCallback toggles a server event from the /events keyboard
+// Callback data format: "events:{EventType}"
+func (b *Bot) handleEventsCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	eventType := strings.TrimPrefix(callbackQuery.Data, "events:")
+	if !b.isAdmin(chatID) || !models.IsAdminEventType(eventType) {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	admin, err := b.findAdmin(chatID)
+	enabled := false
+	if err == nil {
+		enabled = !admin.WantsEvent(eventType)
+		err = b.admin.SetAdminEventEnabled(chatID, eventType, enabled)
+	}
+	if err != nil {
+		slog.Error("Failed to toggle server event",
+			"chat_id", chatID,
+			"event", eventType,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "admin.error", false)
+		return
+	}
+
+	answerKey := "events.disabled"
+	if enabled {
+		answerKey = "events.enabled"
+	}
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+		Text: i18n.TWithData(localizer, answerKey, map[string]interface{}{
+			"Event": i18n.T(localizer, "events.type."+adminEventKeys[eventType]),
+		}),
+	})
+
+	// Refresh the keyboard so it shows the new state
+	keyboard, err := b.createEventsKeyboard(chatID, localizer)
+	if err != nil {
+		slog.Warn("Failed to rebuild server event keyboard",
+			"chat_id", chatID,
+			"error", err)
+		return
+	}
+
+	_, err = botInstance.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:      chatID,
+		MessageID:   callbackQuery.Message.Message.ID,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to edit message markup",
+			"chat_id", chatID,
+			"message_id", callbackQuery.Message.Message.ID,
+			"error", err)
+	}
+
+	slog.Info("Server event subscription updated",
+		"chat_id", chatID,
+		"event", eventType,
+		"enabled", enabled)
+}
+
+// findAdmin returns the admin record of a chat, or an empty one if the chat isn't an admin
+func (b *Bot) findAdmin(chatID int64) (*models.Admin, error) {
+	admins, err := b.admin.GetAdmins()
+	if err != nil {
+		return nil, err
+	}
+	for i := range admins {
+		if admins[i].ChatID == chatID {
+			return &admins[i], nil
+		}
+	}
+	return &models.Admin{ChatID: chatID}, nil
+}
+
+// createEventsKeyboard creates the inline keyboard showing which server events an admin subscribed to
+func (b *Bot) createEventsKeyboard(chatID int64, localizer *goi18n.Localizer) (*botModels.InlineKeyboardMarkup, error) {
+	admin, err := b.findAdmin(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]botModels.InlineKeyboardButton, 0, len(models.AdminEventTypes))
+	for _, eventType := range models.AdminEventTypes {
+		buttonKey := "events.button.disabled"
+		if admin.WantsEvent(eventType) {
+			buttonKey = "events.button.enabled"
+		}
+		rows = append(rows, []botModels.InlineKeyboardButton{{
+			Text: i18n.TWithData(localizer, buttonKey, map[string]interface{}{
+				"Event": i18n.T(localizer, "events.type."+adminEventKeys[eventType]),
+			}),
+			CallbackData: "events:" + eventType,
+		}})
+	}
+
+	return &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
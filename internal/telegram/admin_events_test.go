@@ -0,0 +1,190 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// mockAdminEventDeliverer records the server events passed on to the admins
+type mockAdminEventDeliverer struct {
+	delivered [][]handlers.AdminEvent
+	ctxErrs   []error // Context state of each delivery
+}
+
+func (m *mockAdminEventDeliverer) deliverAdminEvents(ctx context.Context, events []handlers.AdminEvent) {
+	m.delivered = append(m.delivered, events)
+	m.ctxErrs = append(m.ctxErrs, ctx.Err())
+}
+
+// Test 1: Repeated events of one user are held back and summarized when the window ends
+func TestAdminEvents_SummarizesRepeatedEvents(t *testing.T) {
+	deliverer := &mockAdminEventDeliverer{}
+	events := &AdminEvents{deliverer: deliverer, window: 10 * time.Minute, groups: make(map[string]*adminEventGroup)}
+	ctx := context.Background()
+	start := time.Now()
+
+	for i := range 3 {
+		events.handle(ctx, &handlers.AdminEvent{
+			Type:     models.EventPlaybackStart,
+			UserName: "alice",
+			ItemName: "Episode",
+			Time:     start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	// Another user isn't held back by alice's window
+	events.handle(ctx, &handlers.AdminEvent{Type: models.EventPlaybackStart, UserName: "bob", Time: start})
+
+	if len(deliverer.delivered) != 2 {
+		t.Fatalf("Expected the first event of each user right away, got %d messages", len(deliverer.delivered))
+	}
+
+	events.flush(ctx, start.Add(5*time.Minute))
+	if len(deliverer.delivered) != 2 {
+		t.Fatalf("Expected nothing before the window ends, got %d messages", len(deliverer.delivered))
+	}
+
+	events.flush(ctx, start.Add(10*time.Minute))
+	if len(deliverer.delivered) != 3 || len(deliverer.delivered[2]) != 2 {
+		t.Fatalf("Expected one summary of the 2 held back events, got %+v", deliverer.delivered)
+	}
+
+	// With nothing new the groups are closed, so the next event is sent right away again
+	events.flush(ctx, start.Add(30*time.Minute))
+	events.handle(ctx, &handlers.AdminEvent{Type: models.EventPlaybackStart, UserName: "alice", Time: start.Add(31 * time.Minute)})
+	if len(deliverer.delivered) != 4 || len(deliverer.delivered[3]) != 1 {
+		t.Errorf("Expected the event after a quiet window to be sent right away, got %+v", deliverer.delivered)
+	}
+}
+
+// Test 2: Server events reach only the admins who subscribed to them, with session details
+func TestDeliverAdminEvents_SubscribedAdmins(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	store := newMockAdminStore(100, 200)
+	store.events[100] = []string{models.EventPlaybackStart}
+	b.SetAdminStore(store)
+	ctx := context.Background()
+
+	b.deliverAdminEvents(ctx, []handlers.AdminEvent{{
+		Type:          models.EventPlaybackStart,
+		UserName:      "alice",
+		ItemType:      models.ItemTypeEpisode,
+		SeriesName:    "Dark",
+		SeasonNumber:  1,
+		EpisodeNumber: 2,
+		DeviceName:    "Living Room TV",
+		ClientName:    "Jellyfin Android TV",
+		PlayMethod:    "Transcode",
+	}})
+
+	sent := api.sent("sendMessage")
+	if len(sent) != 1 || sent[0].chatID != 100 {
+		t.Fatalf("Expected one message to the subscribed admin, got %+v", sent)
+	}
+	for _, want := range []string{"alice started watching Dark S01E02", "Living Room TV · Jellyfin Android TV", "Transcoding"} {
+		if !strings.Contains(sent[0].text, want) {
+			t.Errorf("Expected %q in message, got %q", want, sent[0].text)
+		}
+	}
+
+	b.deliverAdminEvents(ctx, []handlers.AdminEvent{
+		{Type: models.EventPlaybackStart, UserName: "alice", ItemName: "Arrival", Year: 2016},
+		{Type: models.EventPlaybackStart, UserName: "alice", ItemName: "Dune", Year: 2021},
+	})
+	sent = api.sent("sendMessage")
+	if len(sent) != 2 || !strings.Contains(sent[1].text, "2 more") || !strings.Contains(sent[1].text, "Dune (2021)") {
+		t.Errorf("Expected a summary of both events, got %+v", sent)
+	}
+}
+
+// Test 3: The /events keyboard turns server events on and off for the admin
+func TestEventsCallback_TogglesEvent(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	store := newMockAdminStore(100)
+	b.SetAdminStore(store)
+	ctx := context.Background()
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "events:PlaybackStart"))
+	if !slices.Equal(store.events[100], []string{models.EventPlaybackStart}) {
+		t.Fatalf("Expected PlaybackStart to be turned on, got %v", store.events[100])
+	}
+	if edits := api.sent("editMessageReplyMarkup"); len(edits) != 1 {
+		t.Errorf("Expected the keyboard to be refreshed, got %d edits", len(edits))
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "events:PlaybackStart"))
+	if len(store.events[100]) != 0 {
+		t.Errorf("Expected PlaybackStart to be turned off, got %v", store.events[100])
+	}
+
+	// Non-admins and unknown events change nothing
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(300, "events:PlaybackStart"))
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "events:ItemAdded"))
+	if len(store.events) != 1 || len(store.events[100]) != 0 {
+		t.Errorf("Expected no subscriptions, got %v", store.events)
+	}
+}
+
+// Test 4: Webhooks only queue server events; the worker sends them even after the request is gone
+func TestAdminEvents_QueuesForWorker(t *testing.T) {
+	deliverer := &mockAdminEventDeliverer{}
+	events := NewAdminEvents(nil, 10*time.Minute)
+	events.deliverer = deliverer
+
+	requestCtx, cancelRequest := context.WithCancel(context.Background())
+	cancelRequest()
+	for _, user := range []string{"alice", "alice", "bob"} {
+		event := &handlers.AdminEvent{Type: models.EventAuthenticationFailure, UserName: user, Time: time.Now()}
+		if err := events.NotifyAdminEvent(requestCtx, event); err != nil {
+			t.Fatalf("NotifyAdminEvent failed: %v", err)
+		}
+	}
+	if len(deliverer.delivered) != 0 {
+		t.Fatalf("Expected nothing to be sent on the webhook request, got %+v", deliverer.delivered)
+	}
+
+	// On shutdown the worker sends what is queued and summarizes what is held back
+	runCtx, stop := context.WithCancel(context.Background())
+	stop()
+	events.Run(runCtx)
+
+	if len(deliverer.delivered) != 3 || deliverer.delivered[2][0].UserName != "alice" {
+		t.Fatalf("Expected the first failed login of each user and alice's held back one, got %+v", deliverer.delivered)
+	}
+	for _, err := range deliverer.ctxErrs {
+		if err != nil {
+			t.Errorf("Expected sends with a live context, got %v", err)
+		}
+	}
+}
+
+// Test 5: A burst of failed logins is summarized in a message within Telegram's size limit
+func TestFormatAdminEventSummary_CapsLines(t *testing.T) {
+	b := newCallbackTestBot(t, &fakeTelegramAPI{}, newMockSubscriberDB())
+	localizer := b.getLocalizerForUser(context.Background(), 100, "en")
+
+	burst := make([]handlers.AdminEvent, 500)
+	for i := range burst {
+		burst[i] = handlers.AdminEvent{
+			Type:          models.EventAuthenticationFailure,
+			UserName:      strings.Repeat("x", 500),
+			RemoteAddress: "203.0.113.7",
+		}
+	}
+
+	message := formatAdminEventSummary(burst, localizer)
+	if len([]rune(message)) > 4096 {
+		t.Errorf("Expected the summary to fit in one message, got %d characters", len([]rune(message)))
+	}
+	if !strings.Contains(message, fmt.Sprintf("%d more", 500-catchUpMaxLines)) {
+		t.Errorf("Expected a line counting the events left out, got %q", message)
+	}
+}
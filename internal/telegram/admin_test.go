@@ -0,0 +1,263 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// mockAdminStore implements AdminStore in memory for testing
+type mockAdminStore struct {
+	admins      map[int64]bool
+	banned      map[int64]bool
+	events      map[int64][]string
+	subscribers []models.Subscriber
+	statsCalls  int
+}
+
+func newMockAdminStore(admins ...int64) *mockAdminStore {
+	store := &mockAdminStore{
+		admins: make(map[int64]bool),
+		banned: make(map[int64]bool),
+		events: make(map[int64][]string),
+	}
+	for _, chatID := range admins {
+		store.admins[chatID] = true
+	}
+	return store
+}
+
+func (m *mockAdminStore) IsAdmin(chatID int64) (bool, error) {
+	return m.admins[chatID], nil
+}
+
+func (m *mockAdminStore) GetAdmins() ([]models.Admin, error) {
+	var admins []models.Admin
+	for chatID := range m.admins {
+		admins = append(admins, models.Admin{ChatID: chatID, Events: strings.Join(m.events[chatID], ",")})
+	}
+	return admins, nil
+}
+
+func (m *mockAdminStore) SetAdminEventEnabled(chatID int64, eventType string, enabled bool) error {
+	if !m.admins[chatID] {
+		return gorm.ErrRecordNotFound
+	}
+	events := slices.DeleteFunc(m.events[chatID], func(e string) bool { return e == eventType })
+	if enabled {
+		events = append(events, eventType)
+	}
+	m.events[chatID] = events
+	return nil
+}
+
+func (m *mockAdminStore) AddAdmin(chatID, addedBy int64) error {
+	m.admins[chatID] = true
+	return nil
+}
+
+func (m *mockAdminStore) RemoveAdmin(chatID int64) error {
+	if !m.admins[chatID] {
+		return gorm.ErrRecordNotFound
+	}
+	delete(m.admins, chatID)
+	return nil
+}
+
+func (m *mockAdminStore) BanSubscriber(chatID int64) error {
+	m.banned[chatID] = true
+	return nil
+}
+
+func (m *mockAdminStore) UnbanSubscriber(chatID int64) error {
+	if !m.banned[chatID] {
+		return gorm.ErrRecordNotFound
+	}
+	delete(m.banned, chatID)
+	return nil
+}
+
+func (m *mockAdminStore) IsBanned(chatID int64) (bool, error) {
+	return m.banned[chatID], nil
+}
+
+func (m *mockAdminStore) ListSubscribers(offset, limit int) ([]models.Subscriber, int64, error) {
+	end := min(offset+limit, len(m.subscribers))
+	if offset > end {
+		offset = end
+	}
+	return m.subscribers[offset:end], int64(len(m.subscribers)), nil
+}
+
+func (m *mockAdminStore) GetBotStats(since time.Time) (*models.BotStats, error) {
+	m.statsCalls++
+	return &models.BotStats{}, nil
+}
+
+// mockItemFetcher implements handlers.MetadataFetcher for testing
+type mockItemFetcher struct {
+	items map[string]*models.ContentItem
+}
+
+func (m *mockItemFetcher) GetItem(ctx context.Context, itemID string) (*models.ContentItem, error) {
+	item, ok := m.items[itemID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return item, nil
+}
+
+// mockNotificationQueue implements handlers.NotificationQueue for testing
+type mockNotificationQueue struct {
+	enqueued []*handlers.NotificationContent
+}
+
+func (m *mockNotificationQueue) EnqueueNotification(ctx context.Context, content *handlers.NotificationContent) error {
+	m.enqueued = append(m.enqueued, content)
+	return nil
+}
+
+func commandUpdate(chatID int64, text string) *botModels.Update {
+	return &botModels.Update{
+		Message: &botModels.Message{
+			Text: text,
+			Chat: botModels.Chat{ID: chatID},
+			From: &botModels.User{ID: chatID},
+		},
+	}
+}
+
+// Test 1: Admin commands from non-admins are treated as unknown commands
+func TestAdminCommands_RequireAdmin(t *testing.T) {
+	b := newCallbackTestBot(t, &fakeTelegramAPI{}, newMockSubscriberDB())
+	store := newMockAdminStore(100)
+	b.SetAdminStore(store)
+
+	b.handleStats(context.Background(), b.bot, commandUpdate(200, "/stats"))
+	if store.statsCalls != 0 {
+		t.Error("Expected /stats to be refused for a non-admin")
+	}
+
+	b.handleBan(context.Background(), b.bot, commandUpdate(200, "/ban 300"))
+	if store.banned[300] {
+		t.Error("Expected /ban to be refused for a non-admin")
+	}
+
+	b.handleStats(context.Background(), b.bot, commandUpdate(100, "/stats"))
+	if store.statsCalls != 1 {
+		t.Error("Expected /stats to run for an admin")
+	}
+}
+
+// Test 2: /ban bans users but not admins, /unban lifts bans
+func TestHandleBanAndUnban(t *testing.T) {
+	b := newCallbackTestBot(t, &fakeTelegramAPI{}, newMockSubscriberDB())
+	store := newMockAdminStore(100, 101)
+	b.SetAdminStore(store)
+
+	b.handleBan(context.Background(), b.bot, commandUpdate(100, "/ban 101"))
+	if store.banned[101] {
+		t.Error("Expected admins not to be bannable")
+	}
+
+	b.handleBan(context.Background(), b.bot, commandUpdate(100, "/ban abc"))
+	b.handleBan(context.Background(), b.bot, commandUpdate(100, "/ban 300"))
+	if len(store.banned) != 1 || !store.banned[300] {
+		t.Errorf("Expected only 300 to be banned, got %v", store.banned)
+	}
+
+	b.handleUnban(context.Background(), b.bot, commandUpdate(100, "/unban 300"))
+	if store.banned[300] {
+		t.Error("Expected 300 to be unbanned")
+	}
+}
+
+// Test 3: Updates from banned users don't reach the handlers
+func TestIgnoreBannedUsers(t *testing.T) {
+	b := &Bot{}
+	store := newMockAdminStore()
+	store.banned[300] = true
+	b.SetAdminStore(store)
+
+	var handled []int64
+	next := b.ignoreBannedUsers(func(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+		handled = append(handled, updateUserID(update))
+	})
+
+	next(context.Background(), nil, commandUpdate(300, "/start"))
+	next(context.Background(), nil, callbackUpdate(300, "nav:recent"))
+	next(context.Background(), nil, commandUpdate(200, "/start"))
+
+	if len(handled) != 1 || handled[0] != 200 {
+		t.Errorf("Expected only the update of user 200 to be handled, got %v", handled)
+	}
+}
+
+// Test 4: /resend queues the item's notification for all subscribers
+func TestHandleResend(t *testing.T) {
+	b := newCallbackTestBot(t, &fakeTelegramAPI{}, newMockSubscriberDB())
+	b.SetAdminStore(newMockAdminStore(100))
+	queue := &mockNotificationQueue{}
+	b.SetNotificationQueue(queue)
+	b.SetItemFetcher(&mockItemFetcher{items: map[string]*models.ContentItem{
+		"ep1": {ItemID: "ep1", Type: "Episode", Name: "Pilot", SeriesID: "sev-id", SeriesName: "Severance"},
+	}})
+
+	b.handleResend(context.Background(), b.bot, commandUpdate(100, "/resend missing"))
+	b.handleResend(context.Background(), b.bot, commandUpdate(100, "/resend ep1"))
+
+	if len(queue.enqueued) != 1 {
+		t.Fatalf("Expected 1 queued notification, got %d", len(queue.enqueued))
+	}
+	content := queue.enqueued[0]
+	if content.ItemID != "ep1" || content.Title != "Pilot" || content.SeriesID != "sev-id" {
+		t.Errorf("Unexpected queued content: %+v", content)
+	}
+}
+
+// Test 5: Subscriber pages are numbered and link to their neighbours
+func TestSubscribersPage(t *testing.T) {
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+	localizer := i18n.GetLocalizer(bundle, "en")
+
+	store := newMockAdminStore()
+	for i := 0; i < subscribersPageSize+5; i++ {
+		store.subscribers = append(store.subscribers, models.Subscriber{
+			ChatID: int64(1000 + i), FirstName: "User", LanguageCode: "en", IsActive: true,
+		})
+	}
+	store.subscribers[subscribersPageSize].Username = "banned_one"
+	store.subscribers[subscribersPageSize].IsBanned = true
+	b := &Bot{}
+	b.SetAdminStore(store)
+
+	text, keyboard, err := b.subscribersPage(2, localizer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "page 2/2") || !strings.Contains(text, "21. User @banned_one — 1020 · en · banned") {
+		t.Errorf("Unexpected page:\n%s", text)
+	}
+	if keyboard == nil || len(keyboard.InlineKeyboard[0]) != 1 || keyboard.InlineKeyboard[0][0].CallbackData != "subs:1" {
+		t.Errorf("Expected only a previous page button, got %+v", keyboard)
+	}
+
+	// Pages past the end show the last page
+	if text, _, _ := b.subscribersPage(5, localizer); !strings.Contains(text, "page 2/2") {
+		t.Errorf("Expected the last page, got:\n%s", text)
+	}
+}
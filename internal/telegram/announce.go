@@ -0,0 +1,337 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// announceProgressInterval is how often the progress message of a running announcement is updated
+const announceProgressInterval = 3 * time.Second
+
+// announcement is an admin's draft of a message to all subscribers, with one text per language
+type announcement struct {
+	texts map[string]string
+	// revision changes with every edit, so a preview can only be confirmed while it is current
+	revision int
+}
+
+// textFor returns the text for a subscriber language, falling back to the default language
+func (a *announcement) textFor(languageCode string) string {
+	if text, ok := a.texts[languageCode]; ok {
+		return text
+	}
+	return a.texts[i18n.DefaultLanguage]
+}
+
+// languages returns the languages the announcement has a text for
+func (a *announcement) languages() []string {
+	languages := make([]string, 0, len(a.texts))
+	for lang := range a.texts {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// handleAnnounce handles the /announce admin command
+// Usage: "/announce <language> <text>" sets the text for a language, "/announce preview" shows the
+// announcement with a send button and "/announce cancel" discards the draft
+func (b *Bot) handleAnnounce(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	localizer, ok := b.requireAdmin(ctx, update)
+	if !ok {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	subcommand, text := splitAnnounceArgs(update.Message.Text)
+
+	slog.Info("Processing /announce command",
+		"chat_id", chatID,
+		"subcommand", subcommand)
+
+	switch subcommand {
+	case "":
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.announce_usage", map[string]interface{}{
+			"Languages": strings.Join(i18n.SupportedLanguages, ", "),
+		}))
+	case "preview":
+		b.previewAnnouncement(ctx, chatID, localizer)
+	case "cancel":
+		b.takeAnnouncement(chatID, -1)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.announce_cancelled"))
+	default:
+		lang := strings.ToLower(subcommand)
+		if !i18n.IsSupportedLanguage(lang) {
+			b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.announce_unknown_language", map[string]interface{}{
+				"Language":  subcommand,
+				"Languages": strings.Join(i18n.SupportedLanguages, ", "),
+			}))
+			return
+		}
+		if text == "" {
+			b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.announce_usage", map[string]interface{}{
+				"Languages": strings.Join(i18n.SupportedLanguages, ", "),
+			}))
+			return
+		}
+
+		draft := b.setAnnouncementText(chatID, lang, text)
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.announce_saved", map[string]interface{}{
+			"Language":  lang,
+			"Languages": strings.Join(draft.languages(), ", "),
+		}))
+	}
+}
+
+// splitAnnounceArgs splits an /announce message into its subcommand and the text following it
+// Line breaks of the text are kept
+func splitAnnounceArgs(message string) (string, string) {
+	fields := strings.Fields(message)
+	if len(fields) < 2 {
+		return "", ""
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), fields[0]))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[1]))
+	return fields[1], rest
+}
+
+// setAnnouncementText sets the text of one language in an admin's draft and returns a copy of the draft
+func (b *Bot) setAnnouncementText(chatID int64, lang, text string) announcement {
+	b.announceMu.Lock()
+	defer b.announceMu.Unlock()
+
+	if b.announcements == nil {
+		b.announcements = make(map[int64]*announcement)
+	}
+	draft, ok := b.announcements[chatID]
+	if !ok {
+		draft = &announcement{texts: make(map[string]string)}
+		b.announcements[chatID] = draft
+	}
+	draft.texts[lang] = text
+	draft.revision++
+
+	return *draft
+}
+
+// getAnnouncement returns a copy of an admin's draft
+func (b *Bot) getAnnouncement(chatID int64) (announcement, bool) {
+	b.announceMu.Lock()
+	defer b.announceMu.Unlock()
+
+	draft, ok := b.announcements[chatID]
+	if !ok {
+		return announcement{}, false
+	}
+	return *draft, true
+}
+
+// takeAnnouncement removes an admin's draft and returns it if it still has the given revision
+// A negative revision discards the draft regardless of its revision
+func (b *Bot) takeAnnouncement(chatID int64, revision int) (*announcement, bool) {
+	b.announceMu.Lock()
+	defer b.announceMu.Unlock()
+
+	draft, ok := b.announcements[chatID]
+	if !ok || (revision >= 0 && draft.revision != revision) {
+		return nil, false
+	}
+	delete(b.announcements, chatID)
+	return draft, true
+}
+
+// previewAnnouncement sends every text of the draft to the admin, followed by the send and cancel buttons
+func (b *Bot) previewAnnouncement(ctx context.Context, chatID int64, localizer *goi18n.Localizer) {
+	draft, ok := b.getAnnouncement(chatID)
+	if !ok {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.announce_empty"))
+		return
+	}
+	if _, ok := draft.texts[i18n.DefaultLanguage]; !ok {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.announce_missing_default", map[string]interface{}{
+			"Language": i18n.DefaultLanguage,
+		}))
+		return
+	}
+
+	recipients, err := b.db.GetAllActiveSubscribers()
+	if err != nil {
+		slog.Error("Failed to count announcement recipients",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	for _, lang := range draft.languages() {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "admin.announce_preview", map[string]interface{}{
+			"Language": lang,
+		})+"\n\n"+draft.texts[lang])
+	}
+
+	keyboard := &botModels.InlineKeyboardMarkup{
+		InlineKeyboard: [][]botModels.InlineKeyboardButton{
+			{
+				{
+					Text:         i18n.T(localizer, "button.announce_send"),
+					CallbackData: fmt.Sprintf("announce:send:%d", draft.revision),
+				},
+				{
+					Text:         i18n.T(localizer, "button.cancel"),
+					CallbackData: "announce:cancel",
+				},
+			},
+		},
+	}
+
+	text := i18n.TWithData(localizer, "admin.announce_confirm", map[string]interface{}{
+		"Count":    len(recipients),
+		"Language": i18n.DefaultLanguage,
+	})
+	if err := b.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+		slog.Error("Failed to send announcement confirmation",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handleAnnounceCallback handles the send and cancel buttons of an announcement preview
+// Callback data format: "announce:send:{revision}" or "announce:cancel"
+func (b *Bot) handleAnnounceCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	messageID := callbackQuery.Message.Message.ID
+	if !b.isAdmin(chatID) {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	action, arg, _ := strings.Cut(strings.TrimPrefix(callbackQuery.Data, "announce:"), ":")
+	switch action {
+	case "cancel":
+		b.takeAnnouncement(chatID, -1)
+		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: callbackQuery.ID,
+		})
+		b.editAnnouncementStatus(ctx, chatID, messageID, i18n.T(localizer, "admin.announce_cancelled"))
+	case "send":
+		revision, err := strconv.Atoi(arg)
+		if err != nil {
+			b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+			return
+		}
+
+		// Taking the draft makes a second press of the button a no-op
+		draft, ok := b.takeAnnouncement(chatID, revision)
+		if !ok {
+			b.answerCallback(ctx, botInstance, callbackQuery, "admin.announce_outdated", true)
+			return
+		}
+
+		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: callbackQuery.ID,
+		})
+		b.sendAnnouncement(ctx, chatID, messageID, draft, localizer)
+	default:
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+	}
+}
+
+// sendAnnouncement sends an announcement to all active subscribers in their language
+// Progress and the final summary are edited into the admin's confirmation message
+func (b *Bot) sendAnnouncement(ctx context.Context, adminChatID int64, messageID int, draft *announcement, localizer *goi18n.Localizer) {
+	recipients, err := b.db.GetAllActiveSubscribers()
+	if err != nil {
+		slog.Error("Failed to load announcement recipients", "error", err)
+		b.editAnnouncementStatus(ctx, adminChatID, messageID, i18n.T(localizer, "admin.error"))
+		return
+	}
+
+	slog.Info("Sending announcement",
+		"admin", adminChatID,
+		"languages", draft.languages(),
+		"recipients", len(recipients))
+
+	total := len(recipients)
+	b.editAnnouncementStatus(ctx, adminChatID, messageID, i18n.TWithData(localizer, "admin.announce_progress", map[string]interface{}{
+		"Done":  0,
+		"Total": total,
+	}))
+
+	lastProgress := time.Now()
+	result := b.broadcast(ctx, recipients, func(chatID int64) error {
+		lang, err := b.db.GetLanguage(chatID)
+		if err != nil {
+			lang = i18n.DefaultLanguage
+		}
+		return b.SendMessage(ctx, chatID, draft.textFor(lang))
+	}, func(done int, _ broadcastResult) {
+		if done == total || time.Since(lastProgress) < announceProgressInterval {
+			return
+		}
+		lastProgress = time.Now()
+		b.editAnnouncementStatus(ctx, adminChatID, messageID, i18n.TWithData(localizer, "admin.announce_progress", map[string]interface{}{
+			"Done":  done,
+			"Total": total,
+		}))
+	})
+
+	if ctx.Err() != nil {
+		slog.Warn("Announcement interrupted by shutdown",
+			"admin", adminChatID,
+			"success", result.success,
+			"failures", result.failed,
+			"blocked", result.blocked,
+			"remaining", total-result.success-result.failed-result.blocked)
+		return
+	}
+
+	slog.Info("Announcement completed",
+		"admin", adminChatID,
+		"sent_to", total,
+		"success", result.success,
+		"failures", result.failed,
+		"blocked", result.blocked)
+
+	b.editAnnouncementStatus(ctx, adminChatID, messageID, i18n.TWithData(localizer, "admin.announce_done", map[string]interface{}{
+		"Total":   total,
+		"Success": result.success,
+		"Failed":  result.failed,
+		"Blocked": result.blocked,
+	}))
+}
+
+// editAnnouncementStatus replaces the admin's confirmation message, removing its buttons
+func (b *Bot) editAnnouncementStatus(ctx context.Context, chatID int64, messageID int, text string) {
+	_, err := b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+	})
+	if err != nil {
+		slog.Warn("Failed to update announcement status",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
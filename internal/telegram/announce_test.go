@@ -0,0 +1,150 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sentMessage is a message recorded by recordingTelegramAPI
+type sentMessage struct {
+	method string
+	chatID int64
+	text   string
+}
+
+// recordingTelegramAPI records the messages the bot sends and edits
+type recordingTelegramAPI struct {
+	mu       sync.Mutex
+	messages []sentMessage
+}
+
+func (f *recordingTelegramAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	r.ParseMultipartForm(1 << 20)
+	chatID, _ := strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+	text := r.FormValue("text")
+	if text == "" {
+		text = r.FormValue("caption")
+	}
+
+	f.mu.Lock()
+	f.messages = append(f.messages, sentMessage{method: method, chatID: chatID, text: text})
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if method == "getMe" {
+		w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Jellyfin","username":"jellyfin_bot"}}`))
+		return
+	}
+	if method == "answerCallbackQuery" || method == "deleteMessage" || method == "answerInlineQuery" {
+		w.Write([]byte(`{"ok":true,"result":true}`))
+		return
+	}
+	w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`))
+}
+
+// sent returns the messages sent with the given method
+func (f *recordingTelegramAPI) sent(method string) []sentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var messages []sentMessage
+	for _, message := range f.messages {
+		if message.method == method {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Test 1: The text of an /announce message keeps its line breaks
+func TestSplitAnnounceArgs(t *testing.T) {
+	tests := []struct {
+		message        string
+		wantSubcommand string
+		wantText       string
+	}{
+		{"/announce", "", ""},
+		{"/announce preview", "preview", ""},
+		{"/announce en Server down\ntonight", "en", "Server down\ntonight"},
+		{"/announce fa\nسرور  امشب", "fa", "سرور  امشب"},
+	}
+
+	for _, tt := range tests {
+		subcommand, text := splitAnnounceArgs(tt.message)
+		if subcommand != tt.wantSubcommand || text != tt.wantText {
+			t.Errorf("splitAnnounceArgs(%q) = %q, %q, want %q, %q",
+				tt.message, subcommand, text, tt.wantSubcommand, tt.wantText)
+		}
+	}
+}
+
+// Test 2: A confirmed announcement reaches every subscriber in their language, once
+func TestAnnounce_SendsPerLanguage(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{1, 2, 3}
+	db.languages[2] = "fa"
+	db.languages[3] = "de"
+	b := newCallbackTestBot(t, api, db)
+	b.SetAdminStore(newMockAdminStore(100))
+	ctx := context.Background()
+
+	b.handleAnnounce(ctx, b.bot, commandUpdate(100, "/announce en Maintenance tonight"))
+	b.handleAnnounce(ctx, b.bot, commandUpdate(100, "/announce fa تعمیرات امشب"))
+	b.handleAnnounce(ctx, b.bot, commandUpdate(100, "/announce preview"))
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "announce:send:2"))
+	// A second press must not send the announcement again
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "announce:send:2"))
+
+	want := map[int64]string{1: "Maintenance tonight", 2: "تعمیرات امشب", 3: "Maintenance tonight"}
+	got := make(map[int64]string)
+	for _, message := range api.sent("sendMessage") {
+		if message.chatID != 100 {
+			if _, seen := got[message.chatID]; seen {
+				t.Errorf("Subscriber %d received the announcement twice", message.chatID)
+			}
+			got[message.chatID] = message.text
+		}
+	}
+	for chatID, text := range want {
+		if got[chatID] != text {
+			t.Errorf("Subscriber %d got %q, want %q", chatID, got[chatID], text)
+		}
+	}
+
+	edits := api.sent("editMessageText")
+	if len(edits) == 0 || !strings.Contains(edits[len(edits)-1].text, "Delivered: 3") {
+		t.Errorf("Expected the final summary to report 3 deliveries, got %+v", edits)
+	}
+}
+
+// Test 3: A preview can't be confirmed after the draft was edited
+func TestAnnounce_OutdatedPreview(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{1}
+	b := newCallbackTestBot(t, api, db)
+	b.SetAdminStore(newMockAdminStore(100))
+	ctx := context.Background()
+
+	b.handleAnnounce(ctx, b.bot, commandUpdate(100, "/announce en First"))
+	b.handleAnnounce(ctx, b.bot, commandUpdate(100, "/announce preview"))
+	b.handleAnnounce(ctx, b.bot, commandUpdate(100, "/announce en Second"))
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "announce:send:1"))
+
+	for _, message := range api.sent("sendMessage") {
+		if message.chatID == 1 {
+			t.Fatalf("Expected no announcement to be sent, got %q", message.text)
+		}
+	}
+	if draft, ok := b.getAnnouncement(100); !ok || draft.textFor("en") != "Second" {
+		t.Error("Expected the edited draft to be kept")
+	}
+}
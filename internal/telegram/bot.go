@@ -5,11 +5,16 @@ package telegram
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/internal/handlers"
 	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/internal/metrics"
 	"jellyfin-telegram-bot/pkg/models"
 
 	"github.com/go-telegram/bot"
@@ -24,6 +29,20 @@ type Bot struct {
 	jellyfinClient JellyfinClient
 	config         *config.Config
 	i18nBundle     *goi18n.Bundle
+	webhookURL     string // Public URL Telegram sends updates to; empty uses long polling
+	webhookSecret  string
+	callbackRoutes map[string]callbackRoute // Inline button handlers by callback action
+	admin          AdminStore               // Enables admin commands and bans; nil disables them
+	access         AccessStore              // Enables the approval and invite access modes
+	accounts       JellyfinAccounts         // Enables /link and per-user library permissions
+	wishes         WishStore                // Enables /wish and /wishes
+	requests       RequestStore             // Enables /request
+	forwarder      RequestForwarder         // Forwards approved title requests; nil leaves them to the admins
+	queue          handlers.NotificationQueue
+	items          handlers.MetadataFetcher
+	announceMu     sync.Mutex
+	announcements  map[int64]*announcement // Announcement drafts by admin chat ID
+	posterKey      []byte                  // Signs poster proxy URLs, random per start
 }
 
 // SubscriberDB defines the interface for subscriber operations
@@ -40,6 +59,31 @@ type SubscriberDB interface {
 	RemoveMutedSeries(chatID int64, seriesID string) error
 	GetMutedSeriesByUser(chatID int64) ([]models.MutedSeries, error)
 	IsSeriesMuted(chatID int64, seriesID string) (bool, error)
+	// Follow operations and whether only followed series are notified
+	AddFollowedSeries(chatID int64, seriesID string, seriesName string) error
+	RemoveFollowedSeries(chatID int64, seriesID string) error
+	GetFollowedSeriesByUser(chatID int64) ([]models.MutedSeries, error)
+	IsSeriesFollowed(chatID int64, seriesID string) (bool, error)
+	GetEpisodeMode(chatID int64) (string, error)
+	SetEpisodeMode(chatID int64, mode string) error
+	// Callback tokens (inline button payloads too long for callback data)
+	SaveCallbackToken(token *models.CallbackToken) error
+	GetCallbackToken(token string) (*models.CallbackToken, error)
+	FindCallbackToken(action, payload string, validUntil time.Time) (*models.CallbackToken, error)
+	DeleteExpiredCallbackTokens(now time.Time) (int64, error)
+	// Item type preferences
+	GetDisabledItemTypes(chatID int64) ([]string, error)
+	IsItemTypeEnabled(chatID int64, itemType string) (bool, error)
+	SetItemTypeEnabled(chatID int64, itemType string, enabled bool) error
+	// Content preferences
+	GetContentPreferences(chatID int64) (*models.ContentPreferences, error)
+	SetContentPreferences(chatID int64, prefs *models.ContentPreferences) error
+	// Delivery schedule (time zone and quiet hours)
+	GetDeliverySchedule(chatID int64) (*models.DeliverySchedule, error)
+	SetDeliverySchedule(chatID int64, schedule *models.DeliverySchedule) error
+	// Linked Jellyfin account
+	GetJellyfinLink(chatID int64) (*models.JellyfinLink, error)
+	SetJellyfinLink(chatID int64, link *models.JellyfinLink) error
 }
 
 // JellyfinClient defines the interface for Jellyfin API operations
@@ -47,6 +91,7 @@ type JellyfinClient interface {
 	GetRecentItems(ctx context.Context, limit int) ([]ContentItem, error)
 	SearchContent(ctx context.Context, query string, limit int) ([]ContentItem, error)
 	GetPosterImage(ctx context.Context, itemID string) ([]byte, error)
+	QueryItems(ctx context.Context, query models.ItemQuery) ([]ContentItem, int, error)
 }
 
 // ContentItem represents content from Jellyfin (local interface to avoid circular imports)
@@ -58,9 +103,14 @@ type ContentItem struct {
 	CommunityRating float64
 	OfficialRating  string
 	ProductionYear  int
+	SeriesID        string
 	SeriesName      string
 	SeasonNumber    int
 	EpisodeNumber   int
+	Album           string
+	Artist          string
+	TrailerURL      string
+	ProviderIDs     map[string]string
 }
 
 // NewBot creates a new Telegram bot instance
@@ -81,19 +131,58 @@ func NewBot(token string, db SubscriberDB, jellyfinClient JellyfinClient, cfg *c
 		config:         cfg,
 		i18nBundle:     bundle,
 	}
+	botInstance.registerCallbacks()
+
+	botInstance.posterKey = make([]byte, 32)
+	if _, err := rand.Read(botInstance.posterKey); err != nil {
+		return nil, fmt.Errorf("failed to generate poster key: %w", err)
+	}
 
 	opts := []bot.Option{
 		bot.WithDefaultHandler(botInstance.defaultHandler),
-		bot.WithMessageTextHandler("/start", bot.MatchTypeExact, botInstance.handleStart),
+		bot.WithMiddlewares(botInstance.countCallbackQueries, botInstance.ignoreBannedUsers, botInstance.restrictAccess),
+		bot.WithMessageTextHandler("/start", bot.MatchTypePrefix, botInstance.handleStart),
 		bot.WithMessageTextHandler("/recent", bot.MatchTypeExact, botInstance.handleRecent),
 		bot.WithMessageTextHandler("/search", bot.MatchTypePrefix, botInstance.handleSearch),
 		bot.WithMessageTextHandler("/mutedlist", bot.MatchTypeExact, botInstance.handleMutedList),
+		bot.WithMessageTextHandler("/following", bot.MatchTypeExact, botInstance.handleFollowing),
 		bot.WithMessageTextHandler("/language", bot.MatchTypeExact, botInstance.handleLanguage),
-		bot.WithCallbackQueryDataHandler("nav:", bot.MatchTypePrefix, botInstance.handleNavigationCallback),
-		bot.WithCallbackQueryDataHandler("mute:", bot.MatchTypePrefix, botInstance.handleMuteCallback),
-		bot.WithCallbackQueryDataHandler("undo_mute:", bot.MatchTypePrefix, botInstance.handleUndoMuteCallback),
-		bot.WithCallbackQueryDataHandler("unmute:", bot.MatchTypePrefix, botInstance.handleUnmuteCallback),
-		bot.WithCallbackQueryDataHandler("lang:", bot.MatchTypePrefix, botInstance.handleLanguageCallback),
+		bot.WithMessageTextHandler("/types", bot.MatchTypeExact, botInstance.handleTypes),
+		bot.WithMessageTextHandler("/preferences", bot.MatchTypeExact, botInstance.handlePreferences),
+		bot.WithMessageTextHandler("/quiet", bot.MatchTypePrefix, botInstance.handleQuiet),
+		bot.WithMessageTextHandler("/timezone", bot.MatchTypePrefix, botInstance.handleTimezone),
+		bot.WithMessageTextHandler("/digest", bot.MatchTypePrefix, botInstance.handleDigest),
+		bot.WithMessageTextHandler("/link", bot.MatchTypePrefix, botInstance.handleLink),
+		bot.WithMessageTextHandler("/unlink", bot.MatchTypeExact, botInstance.handleUnlink),
+		// /wishes before /wish, the prefix match would take it otherwise
+		bot.WithMessageTextHandler("/wishes", bot.MatchTypeExact, botInstance.handleWishes),
+		bot.WithMessageTextHandler("/wish", bot.MatchTypePrefix, botInstance.handleWish),
+		bot.WithMessageTextHandler("/request", bot.MatchTypePrefix, botInstance.handleRequest),
+		// Admin commands (see admin.go)
+		bot.WithMessageTextHandler("/stats", bot.MatchTypeExact, botInstance.handleStats),
+		bot.WithMessageTextHandler("/subscribers", bot.MatchTypeExact, botInstance.handleSubscribers),
+		bot.WithMessageTextHandler("/ban", bot.MatchTypePrefix, botInstance.handleBan),
+		bot.WithMessageTextHandler("/unban", bot.MatchTypePrefix, botInstance.handleUnban),
+		bot.WithMessageTextHandler("/resend", bot.MatchTypePrefix, botInstance.handleResend),
+		bot.WithMessageTextHandler("/testnotify", bot.MatchTypeExact, botInstance.handleTestNotify),
+		bot.WithMessageTextHandler("/admins", bot.MatchTypePrefix, botInstance.handleAdmins),
+		bot.WithMessageTextHandler("/announce", bot.MatchTypePrefix, botInstance.handleAnnounce),
+		bot.WithMessageTextHandler("/invite", bot.MatchTypePrefix, botInstance.handleInvite),
+		bot.WithMessageTextHandler("/events", bot.MatchTypeExact, botInstance.handleEvents),
+		// Every callback query goes through the router, see registerCallbacks
+		bot.WithCallbackQueryDataHandler("", bot.MatchTypePrefix, botInstance.dispatchCallback),
+	}
+
+	if cfg != nil && cfg.Telegram.UseWebhook() {
+		secret := cfg.Telegram.WebhookSecret
+		if secret == "" {
+			if secret, err = generateWebhookSecret(); err != nil {
+				return nil, err
+			}
+		}
+		botInstance.webhookURL = cfg.Telegram.WebhookEndpoint()
+		botInstance.webhookSecret = secret
+		opts = append(opts, bot.WithWebhookSecretToken(secret))
 	}
 
 	b, err := bot.New(token, opts...)
@@ -103,6 +192,11 @@ func NewBot(token string, db SubscriberDB, jellyfinClient JellyfinClient, cfg *c
 
 	botInstance.bot = b
 
+	// Inline queries ("@bot dune" in any chat) have no text or data to match on
+	b.RegisterHandlerMatchFunc(func(update *botModels.Update) bool {
+		return update.InlineQuery != nil
+	}, botInstance.handleInlineQuery)
+
 	// Register bot commands for Telegram Menu Button API
 	err = botInstance.registerBotCommands(context.Background())
 	if err != nil {
@@ -122,28 +216,7 @@ func (b *Bot) registerBotCommands(ctx context.Context) error {
 	for _, langCode := range i18n.SupportedLanguages {
 		localizer := i18n.GetLocalizer(b.i18nBundle, langCode)
 
-		commands := []botModels.BotCommand{
-			{
-				Command:     "start",
-				Description: i18n.T(localizer, "command.start.description"),
-			},
-			{
-				Command:     "recent",
-				Description: i18n.T(localizer, "command.recent.description"),
-			},
-			{
-				Command:     "search",
-				Description: i18n.T(localizer, "command.search.description"),
-			},
-			{
-				Command:     "mutedlist",
-				Description: i18n.T(localizer, "command.mutedlist.description"),
-			},
-			{
-				Command:     "language",
-				Description: i18n.T(localizer, "command.language.description"),
-			},
-		}
+		commands := userCommands(localizer)
 
 		_, err := b.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
 			Commands:     commands,
@@ -165,8 +238,96 @@ func (b *Bot) registerBotCommands(ctx context.Context) error {
 	return nil
 }
 
-// Start starts the bot with polling mode
+// userCommands returns the commands shown in every user's command menu
+func userCommands(localizer *goi18n.Localizer) []botModels.BotCommand {
+	return []botModels.BotCommand{
+		{
+			Command:     "start",
+			Description: i18n.T(localizer, "command.start.description"),
+		},
+		{
+			Command:     "recent",
+			Description: i18n.T(localizer, "command.recent.description"),
+		},
+		{
+			Command:     "search",
+			Description: i18n.T(localizer, "command.search.description"),
+		},
+		{
+			Command:     "mutedlist",
+			Description: i18n.T(localizer, "command.mutedlist.description"),
+		},
+		{
+			Command:     "following",
+			Description: i18n.T(localizer, "command.following.description"),
+		},
+		{
+			Command:     "language",
+			Description: i18n.T(localizer, "command.language.description"),
+		},
+		{
+			Command:     "types",
+			Description: i18n.T(localizer, "command.types.description"),
+		},
+		{
+			Command:     "preferences",
+			Description: i18n.T(localizer, "command.preferences.description"),
+		},
+		{
+			Command:     "quiet",
+			Description: i18n.T(localizer, "command.quiet.description"),
+		},
+		{
+			Command:     "timezone",
+			Description: i18n.T(localizer, "command.timezone.description"),
+		},
+		{
+			Command:     "digest",
+			Description: i18n.T(localizer, "command.digest.description"),
+		},
+		{
+			Command:     "link",
+			Description: i18n.T(localizer, "command.link.description"),
+		},
+		{
+			Command:     "unlink",
+			Description: i18n.T(localizer, "command.unlink.description"),
+		},
+		{
+			Command:     "wish",
+			Description: i18n.T(localizer, "command.wish.description"),
+		},
+		{
+			Command:     "wishes",
+			Description: i18n.T(localizer, "command.wishes.description"),
+		},
+		{
+			Command:     "request",
+			Description: i18n.T(localizer, "command.request.description"),
+		},
+	}
+}
+
+// Start starts the bot in webhook mode when a public URL is configured and in polling mode otherwise
 func (b *Bot) Start(ctx context.Context) {
+	if b.admin != nil {
+		b.registerAdminCommands(ctx)
+	}
+
+	if b.IsWebhookMode() {
+		slog.Info("Starting Telegram bot in webhook mode...")
+		err := b.startWebhook(ctx)
+		if err == nil {
+			return
+		}
+		slog.Error("Telegram webhook unavailable, falling back to long polling", "error", err)
+	}
+
+	// Polling fails while a webhook from an earlier run is still registered
+	if _, err := b.bot.DeleteWebhook(ctx, &bot.DeleteWebhookParams{}); err != nil {
+		slog.Warn("Failed to remove Telegram webhook before polling", "error", err)
+	}
+
 	slog.Info("Starting Telegram bot...")
 	b.bot.Start(ctx)
 }
@@ -193,6 +354,16 @@ func (b *Bot) defaultHandler(ctx context.Context, bot *bot.Bot, update *botModel
 	}
 }
 
+// countCallbackQueries is a middleware counting callback queries by their action
+func (b *Bot) countCallbackQueries(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+		if update.CallbackQuery != nil {
+			metrics.CallbackQueries.WithLabelValues(b.callbackAction(update.CallbackQuery.Data)).Inc()
+		}
+		next(ctx, botInstance, update)
+	}
+}
+
 // getLocalizerForUser gets the localizer for a user with fallback chain
 // Fallback chain: saved preference → Telegram language → English default
 func (b *Bot) getLocalizerForUser(ctx context.Context, chatID int64, telegramLangCode string) *goi18n.Localizer {
@@ -252,6 +423,14 @@ func (b *Bot) SendPhotoBytesWithKeyboard(ctx context.Context, chatID int64, imag
 	return err
 }
 
+// Ping verifies the bot token with Telegram's getMe
+func (b *Bot) Ping(ctx context.Context) error {
+	if _, err := b.bot.GetMe(ctx); err != nil {
+		return fmt.Errorf("failed to verify bot token: %w", err)
+	}
+	return nil
+}
+
 // GetBot returns the underlying bot instance (for testing)
 func (b *Bot) GetBot() *bot.Bot {
 	return b.bot
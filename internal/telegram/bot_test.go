@@ -3,20 +3,30 @@ package telegram
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"jellyfin-telegram-bot/internal/i18n"
 	"jellyfin-telegram-bot/pkg/models"
 
 	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
 )
 
 // Mock implementations for testing
 
 type MockSubscriberDB struct {
 	subscribers   map[int64]bool
-	languages     map[int64]string          // chatID -> languageCode
-	mutedSeries   map[int64]map[string]bool // chatID -> seriesID -> isMuted
+	languages     map[int64]string            // chatID -> languageCode
+	mutedSeries   map[int64]map[string]bool   // chatID -> seriesID -> isMuted
+	followed      map[int64]map[string]string // chatID -> seriesID -> seriesName
+	episodeModes  map[int64]string
+	disabledTypes map[int64]map[string]bool // chatID -> itemType -> isDisabled
+	preferences   map[int64]*models.ContentPreferences
+	schedules     map[int64]*models.DeliverySchedule
+	links         map[int64]*models.JellyfinLink
+	tokens        map[string]*models.CallbackToken
 	shouldFailAdd bool
 	shouldFailGet bool
 }
@@ -107,11 +117,172 @@ func (m *MockSubscriberDB) IsSeriesMuted(chatID int64, seriesID string) (bool, e
 	return false, nil
 }
 
+func (m *MockSubscriberDB) AddFollowedSeries(chatID int64, seriesID string, seriesName string) error {
+	if m.followed == nil {
+		m.followed = make(map[int64]map[string]string)
+	}
+	if m.followed[chatID] == nil {
+		m.followed[chatID] = make(map[string]string)
+	}
+	m.followed[chatID][seriesID] = seriesName
+	delete(m.mutedSeries[chatID], seriesID)
+	return nil
+}
+
+func (m *MockSubscriberDB) RemoveFollowedSeries(chatID int64, seriesID string) error {
+	if _, ok := m.followed[chatID][seriesID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(m.followed[chatID], seriesID)
+	return nil
+}
+
+func (m *MockSubscriberDB) GetFollowedSeriesByUser(chatID int64) ([]models.MutedSeries, error) {
+	var result []models.MutedSeries
+	for seriesID, seriesName := range m.followed[chatID] {
+		result = append(result, models.MutedSeries{
+			ChatID:     chatID,
+			SeriesID:   seriesID,
+			SeriesName: seriesName,
+			Kind:       models.SeriesKindFollow,
+		})
+	}
+	return result, nil
+}
+
+func (m *MockSubscriberDB) IsSeriesFollowed(chatID int64, seriesID string) (bool, error) {
+	_, ok := m.followed[chatID][seriesID]
+	return ok, nil
+}
+
+func (m *MockSubscriberDB) GetEpisodeMode(chatID int64) (string, error) {
+	if mode := m.episodeModes[chatID]; mode != "" {
+		return mode, nil
+	}
+	return models.EpisodeModeAll, nil
+}
+
+func (m *MockSubscriberDB) SetEpisodeMode(chatID int64, mode string) error {
+	if m.episodeModes == nil {
+		m.episodeModes = make(map[int64]string)
+	}
+	m.episodeModes[chatID] = mode
+	return nil
+}
+
+func (m *MockSubscriberDB) GetDisabledItemTypes(chatID int64) ([]string, error) {
+	var result []string
+	for itemType, disabled := range m.disabledTypes[chatID] {
+		if disabled {
+			result = append(result, itemType)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockSubscriberDB) IsItemTypeEnabled(chatID int64, itemType string) (bool, error) {
+	return !m.disabledTypes[chatID][itemType], nil
+}
+
+func (m *MockSubscriberDB) SetItemTypeEnabled(chatID int64, itemType string, enabled bool) error {
+	if m.disabledTypes == nil {
+		m.disabledTypes = make(map[int64]map[string]bool)
+	}
+	if m.disabledTypes[chatID] == nil {
+		m.disabledTypes[chatID] = make(map[string]bool)
+	}
+	m.disabledTypes[chatID][itemType] = !enabled
+	return nil
+}
+
+func (m *MockSubscriberDB) GetContentPreferences(chatID int64) (*models.ContentPreferences, error) {
+	if prefs, ok := m.preferences[chatID]; ok {
+		return prefs, nil
+	}
+	return &models.ContentPreferences{}, nil
+}
+
+func (m *MockSubscriberDB) SetContentPreferences(chatID int64, prefs *models.ContentPreferences) error {
+	if m.preferences == nil {
+		m.preferences = make(map[int64]*models.ContentPreferences)
+	}
+	m.preferences[chatID] = prefs
+	return nil
+}
+
+func (m *MockSubscriberDB) GetDeliverySchedule(chatID int64) (*models.DeliverySchedule, error) {
+	if schedule, ok := m.schedules[chatID]; ok {
+		copied := *schedule
+		return &copied, nil
+	}
+	return &models.DeliverySchedule{}, nil
+}
+
+func (m *MockSubscriberDB) SetDeliverySchedule(chatID int64, schedule *models.DeliverySchedule) error {
+	if m.schedules == nil {
+		m.schedules = make(map[int64]*models.DeliverySchedule)
+	}
+	m.schedules[chatID] = schedule
+	return nil
+}
+
+func (m *MockSubscriberDB) GetJellyfinLink(chatID int64) (*models.JellyfinLink, error) {
+	if link, ok := m.links[chatID]; ok {
+		return link, nil
+	}
+	return &models.JellyfinLink{}, nil
+}
+
+func (m *MockSubscriberDB) SetJellyfinLink(chatID int64, link *models.JellyfinLink) error {
+	if m.links == nil {
+		m.links = make(map[int64]*models.JellyfinLink)
+	}
+	m.links[chatID] = link
+	return nil
+}
+
+func (m *MockSubscriberDB) SaveCallbackToken(token *models.CallbackToken) error {
+	if m.tokens == nil {
+		m.tokens = make(map[string]*models.CallbackToken)
+	}
+	m.tokens[token.Token] = token
+	return nil
+}
+
+func (m *MockSubscriberDB) GetCallbackToken(token string) (*models.CallbackToken, error) {
+	record, ok := m.tokens[token]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return record, nil
+}
+
+func (m *MockSubscriberDB) FindCallbackToken(action, payload string, validUntil time.Time) (*models.CallbackToken, error) {
+	for _, record := range m.tokens {
+		if record.Action == action && record.Payload == payload && !record.IsExpired(validUntil) {
+			return record, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockSubscriberDB) DeleteExpiredCallbackTokens(now time.Time) (int64, error) {
+	var deleted int64
+	for token, record := range m.tokens {
+		if record.IsExpired(now) {
+			delete(m.tokens, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 type MockJellyfinClient struct {
 	recentItems   []ContentItem
 	searchResults []ContentItem
 	imageData     []byte
 	shouldFail    bool
+	lastQuery     models.ItemQuery
 }
 
 func NewMockJellyfinClient() *MockJellyfinClient {
@@ -173,6 +344,39 @@ func (m *MockJellyfinClient) GetPosterImage(ctx context.Context, itemID string)
 	return m.imageData, nil
 }
 
+func (m *MockJellyfinClient) QueryItems(ctx context.Context, query models.ItemQuery) ([]ContentItem, int, error) {
+	m.lastQuery = query
+	if m.shouldFail {
+		return nil, 0, errors.New("jellyfin error")
+	}
+
+	source := m.recentItems
+	if query.SearchTerm != "" {
+		source = m.searchResults
+	}
+	if len(query.ItemIDs) > 0 {
+		source = append(slices.Clone(m.recentItems), m.searchResults...)
+	}
+
+	var matches []ContentItem
+	for _, item := range source {
+		if len(query.ItemTypes) > 0 && !slices.Contains(query.ItemTypes, item.Type) {
+			continue
+		}
+		if len(query.ItemIDs) > 0 && !slices.Contains(query.ItemIDs, item.ItemID) {
+			continue
+		}
+		matches = append(matches, item)
+	}
+
+	start := min(query.StartIndex, len(matches))
+	end := len(matches)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, len(matches))
+	}
+	return matches[start:end], len(matches), nil
+}
+
 // Helper function to get English localizer for testing
 func getTestLocalizer() *goi18n.Localizer {
 	bundle, err := i18n.InitBundle()
@@ -382,81 +586,34 @@ func TestFormatNotification_Episode(t *testing.T) {
 	}
 }
 
-// Test 7: BroadcastNotification success
-func TestBroadcastNotification_Success(t *testing.T) {
-	db := NewMockSubscriberDB()
-	_ = NewMockJellyfinClient() // Not used in this test
-
-	// Add some subscribers
-	db.AddSubscriber(12345, "user1", "Test User 1")
-	db.AddSubscriber(67890, "user2", "Test User 2")
-
-	// Note: We can't fully test the bot without a real Telegram token
-	// This test verifies the logic with mocks
-	content := &NotificationContent{
-		ItemID:   "movie1",
-		Type:     "Movie",
-		Title:    "Test Movie",
-		Overview: "A test",
-		Year:     2023,
-		Rating:   8.0,
-	}
-
-	// Verify subscribers exist
-	subscribers, err := db.GetAllActiveSubscribers()
-	if err != nil {
-		t.Fatalf("Failed to get subscribers: %v", err)
-	}
-
-	if len(subscribers) != 2 {
-		t.Errorf("Expected 2 subscribers, got %d", len(subscribers))
-	}
-
-	// Verify notification formatting
+// Test 7: FormatNotification for several episodes of one season
+func TestFormatNotification_EpisodeBatch(t *testing.T) {
 	localizer := getTestLocalizer()
 	if localizer == nil {
 		t.Fatal("Failed to initialize test localizer")
 	}
 
-	message := FormatNotification(content, localizer)
-	if message == "" {
-		t.Error("Expected formatted notification message")
-	}
-}
-
-// Test 8: BroadcastNotification with no subscribers
-func TestBroadcastNotification_NoSubscribers(t *testing.T) {
-	db := NewMockSubscriberDB()
-	_ = NewMockJellyfinClient() // Not used in this test
-
 	content := &NotificationContent{
-		ItemID:   "movie1",
-		Type:     "Movie",
-		Title:    "Test Movie",
-		Overview: "A test",
-		Year:     2023,
-		Rating:   8.0,
+		ItemID:        "episode1",
+		Type:          "Episode",
+		SeriesName:    "Friends",
+		SeasonNumber:  2,
+		EpisodeNumber: 1,
+		EpisodeCount:  10,
+		FirstEpisode:  1,
+		LastEpisode:   10,
 	}
 
-	// Verify no subscribers
-	subscribers, err := db.GetAllActiveSubscribers()
-	if err != nil {
-		t.Fatalf("Failed to get subscribers: %v", err)
-	}
+	message := FormatNotification(content, localizer)
 
-	if len(subscribers) != 0 {
-		t.Errorf("Expected 0 subscribers, got %d", len(subscribers))
+	if !contains(message, "New Episodes") {
+		t.Error("Notification should contain 'new episodes' indicator")
 	}
-
-	// Format notification should still work
-	localizer := getTestLocalizer()
-	if localizer == nil {
-		t.Fatal("Failed to initialize test localizer")
+	if !contains(message, "Friends") {
+		t.Error("Notification should contain series name")
 	}
-
-	message := FormatNotification(content, localizer)
-	if message == "" {
-		t.Error("Expected formatted notification message even with no subscribers")
+	if !contains(message, "Season 2") || !contains(message, "10 new episodes") || !contains(message, "E01–E10") {
+		t.Errorf("Notification should summarize the episode range, got %q", message)
 	}
 }
 
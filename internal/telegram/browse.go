@@ -0,0 +1,363 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// browsePageSize is the number of items listed per page of /recent and /search
+const browsePageSize = 5
+
+// browseSourceRecent is the list source of /recent; search lists use a callback token holding their query
+const browseSourceRecent = "recent"
+
+// browseFilter narrows a list to some item types
+type browseFilter struct {
+	code      string
+	labelID   string
+	itemTypes []string // Empty for all supported types
+}
+
+// browseFilters are the filter buttons of a list, in display order
+var browseFilters = []browseFilter{
+	{code: "all", labelID: "browse.filter_all"},
+	{code: "movies", labelID: "item_type.movie", itemTypes: []string{models.ItemTypeMovie}},
+	{code: "episodes", labelID: "item_type.episode", itemTypes: []string{models.ItemTypeEpisode}},
+}
+
+// browseItemIcons maps item types to the icon shown in front of them in a list
+var browseItemIcons = map[string]string{
+	models.ItemTypeMovie:      "🎬",
+	models.ItemTypeEpisode:    "📺",
+	models.ItemTypeSeries:     "📺",
+	models.ItemTypeSeason:     "📺",
+	models.ItemTypeMusicAlbum: "💿",
+	models.ItemTypeAudio:      "🎵",
+	models.ItemTypeAudioBook:  "🎧",
+	models.ItemTypeBook:       "📚",
+}
+
+// browseSearch is the payload of the callback token of a search list
+type browseSearch struct {
+	Query string `json:"query"`
+}
+
+// browseView identifies one page of a /recent or /search list
+type browseView struct {
+	source string // browseSourceRecent, the callback token of a search, or "" for a new search
+	query  string
+	filter browseFilter
+	page   int
+}
+
+// callbackData returns the callback data showing another page or filter of the list
+// Format: "browse:{source}:{filter}:{page}"
+func (v browseView) callbackData(filter browseFilter, page int) string {
+	return fmt.Sprintf("browse:%s:%s:%d", v.source, filter.code, page)
+}
+
+// findBrowseFilter returns the filter with the given code
+func findBrowseFilter(code string) (browseFilter, bool) {
+	for _, filter := range browseFilters {
+		if filter.code == code {
+			return filter, true
+		}
+	}
+	return browseFilter{}, false
+}
+
+// sendBrowseList sends the first page of recently added items, or of the results of a search
+func (b *Bot) sendBrowseList(ctx context.Context, chatID int64, query string, localizer *goi18n.Localizer) {
+	errorID, emptyID := "recent.error", "recent.no_results"
+	if query != "" {
+		errorID, emptyID = "search.error", "search.no_results"
+	}
+
+	// A new search gets its source once it has results
+	view := browseView{query: query, filter: browseFilters[0], page: 1}
+	if query == "" {
+		view.source = browseSourceRecent
+	}
+	text, keyboard, total, err := b.browsePage(ctx, chatID, &view, localizer)
+	if err != nil {
+		slog.Error("Failed to fetch items",
+			"chat_id", chatID,
+			"query", query,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, errorID))
+		return
+	}
+
+	if total == 0 {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, emptyID, map[string]interface{}{
+			"Query": query,
+		}))
+		return
+	}
+
+	if err := b.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+		slog.Error("Failed to send item list",
+			"chat_id", chatID,
+			"error", err)
+		return
+	}
+
+	slog.Info("Sent item list",
+		"chat_id", chatID,
+		"query", query,
+		"total", total)
+}
+
+// handleBrowseCallback shows another page or filter of a /recent or /search list in place
+// Callback data format: "browse:{source}:{filter}:{page}"
+func (b *Bot) handleBrowseCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	parts := strings.Split(strings.TrimPrefix(callbackQuery.Data, "browse:"), ":")
+	if len(parts) != 3 {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+	filter, ok := findBrowseFilter(parts[1])
+	page, err := strconv.Atoi(parts[2])
+	if !ok || err != nil || page < 1 {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	view := browseView{source: parts[0], filter: filter, page: page}
+	if view.source != browseSourceRecent {
+		search, err := loadCallbackPayload[browseSearch](b, "browse", view.source, nil)
+		if errors.Is(err, errCallbackExpired) {
+			b.answerCallback(ctx, botInstance, callbackQuery, "error.callback_expired", true)
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to resolve search of item list",
+				"chat_id", chatID,
+				"error", err)
+			b.answerCallback(ctx, botInstance, callbackQuery, "error.request_processing", false)
+			return
+		}
+		view.query = search.Query
+	}
+
+	text, keyboard, _, err := b.browsePage(ctx, chatID, &view, localizer)
+	if err != nil {
+		slog.Error("Failed to fetch items",
+			"chat_id", chatID,
+			"query", view.query,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "recent.error", false)
+		return
+	}
+
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+	})
+
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   callbackQuery.Message.Message.ID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to show item list page",
+			"chat_id", chatID,
+			"page", view.page,
+			"error", err)
+	}
+}
+
+// handleItemCallback sends the full card of an item tapped in a list
+// Callback data format: "item:{itemID}"
+func (b *Bot) handleItemCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	itemID := strings.TrimPrefix(callbackQuery.Data, "item:")
+	if itemID == "" {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	// The query runs as the linked Jellyfin user, so items outside their libraries aren't found
+	items, _, err := b.itemPage(ctx, chatID, models.ItemQuery{ItemIDs: []string{itemID}, Limit: 1})
+	if err != nil {
+		slog.Error("Failed to fetch item",
+			"chat_id", chatID,
+			"item_id", itemID,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "recent.error", false)
+		return
+	}
+	if len(items) == 0 {
+		b.answerCallback(ctx, botInstance, callbackQuery, "browse.item_gone", true)
+		return
+	}
+
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+	})
+
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+	b.sendContentItem(ctx, chatID, &items[0], localizer)
+}
+
+// browsePage formats one page of a list with its item, page and filter buttons
+// A new search stores its query under a callback token, so its buttons fit Telegram's size limit
+func (b *Bot) browsePage(ctx context.Context, chatID int64, view *browseView, localizer *goi18n.Localizer) (string, *botModels.InlineKeyboardMarkup, int, error) {
+	query := models.ItemQuery{
+		SearchTerm: view.query,
+		ItemTypes:  view.filter.itemTypes,
+		StartIndex: (view.page - 1) * browsePageSize,
+		Limit:      browsePageSize,
+	}
+	items, total, err := b.itemPage(ctx, chatID, query)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	pages := (total + browsePageSize - 1) / browsePageSize
+	if view.page > pages && pages > 0 {
+		// The list shrank since the page buttons were sent
+		view.page = pages
+		return b.browsePage(ctx, chatID, view, localizer)
+	}
+
+	if view.source == "" {
+		if total == 0 {
+			return "", nil, 0, nil
+		}
+		data, err := b.newCallbackData("browse", browseSearch{Query: view.query})
+		if err != nil {
+			return "", nil, 0, err
+		}
+		view.source = strings.TrimPrefix(data, "browse:")
+	}
+
+	var message strings.Builder
+	if view.query != "" {
+		message.WriteString(i18n.TWithData(localizer, "browse.search_title", map[string]interface{}{
+			"Query": view.query,
+		}))
+	} else {
+		message.WriteString(i18n.T(localizer, "browse.recent_title"))
+	}
+
+	var rows [][]botModels.InlineKeyboardButton
+	if total == 0 {
+		message.WriteString("\n\n")
+		message.WriteString(i18n.T(localizer, "browse.empty"))
+	} else {
+		message.WriteString("\n")
+		message.WriteString(i18n.TWithData(localizer, "browse.page", map[string]interface{}{
+			"Page":  view.page,
+			"Pages": pages,
+			"Total": total,
+		}))
+		message.WriteString("\n")
+
+		var numbers []botModels.InlineKeyboardButton
+		for i := range items {
+			number := (view.page-1)*browsePageSize + i + 1
+			message.WriteString(fmt.Sprintf("\n%d. %s", number, browseItemLine(&items[i])))
+			numbers = append(numbers, botModels.InlineKeyboardButton{
+				Text:         strconv.Itoa(number),
+				CallbackData: "item:" + items[i].ItemID,
+			})
+		}
+		message.WriteString("\n\n")
+		message.WriteString(i18n.T(localizer, "browse.hint"))
+		rows = append(rows, numbers)
+	}
+
+	var pageRow []botModels.InlineKeyboardButton
+	if view.page > 1 {
+		pageRow = append(pageRow, botModels.InlineKeyboardButton{
+			Text:         i18n.T(localizer, "button.previous_page"),
+			CallbackData: view.callbackData(view.filter, view.page-1),
+		})
+	}
+	if view.page < pages {
+		pageRow = append(pageRow, botModels.InlineKeyboardButton{
+			Text:         i18n.T(localizer, "button.next_page"),
+			CallbackData: view.callbackData(view.filter, view.page+1),
+		})
+	}
+	if len(pageRow) > 0 {
+		rows = append(rows, pageRow)
+	}
+
+	// Switching the filter starts over at the first page
+	var filterRow []botModels.InlineKeyboardButton
+	for _, filter := range browseFilters {
+		label := i18n.T(localizer, filter.labelID)
+		if filter.code == view.filter.code {
+			label = i18n.TWithData(localizer, "browse.filter_selected", map[string]interface{}{
+				"Filter": label,
+			})
+		}
+		filterRow = append(filterRow, botModels.InlineKeyboardButton{
+			Text:         label,
+			CallbackData: view.callbackData(filter, 1),
+		})
+	}
+	rows = append(rows, filterRow)
+
+	return message.String(), &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}, total, nil
+}
+
+// browseItemLine formats an item as one line of a list
+func browseItemLine(item *ContentItem) string {
+	var line strings.Builder
+	if icon, ok := browseItemIcons[item.Type]; ok {
+		line.WriteString(icon)
+		line.WriteString(" ")
+	}
+
+	switch {
+	case item.Type == models.ItemTypeEpisode && item.SeriesName != "":
+		line.WriteString(fmt.Sprintf("%s S%02dE%02d", item.SeriesName, item.SeasonNumber, item.EpisodeNumber))
+		if item.Name != "" {
+			line.WriteString(" · ")
+			line.WriteString(item.Name)
+		}
+	case item.Artist != "":
+		line.WriteString(item.Name)
+		line.WriteString(" – ")
+		line.WriteString(item.Artist)
+	default:
+		line.WriteString(item.Name)
+	}
+
+	if item.ProductionYear > 0 && item.Type != models.ItemTypeEpisode {
+		line.WriteString(fmt.Sprintf(" (%d)", item.ProductionYear))
+	}
+	return line.String()
+}
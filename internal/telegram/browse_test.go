@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newBrowseTestBot creates a bot whose library has 4 movies and 3 episodes, newest first
+func newBrowseTestBot(t *testing.T, api *recordingTelegramAPI) (*Bot, *MockJellyfinClient) {
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	jellyfinClient := NewMockJellyfinClient()
+	jellyfinClient.recentItems = nil
+	for i := 1; i <= 7; i++ {
+		item := ContentItem{ItemID: fmt.Sprintf("item%d", i), Name: fmt.Sprintf("Title %d", i), Type: "Movie"}
+		if i%2 == 0 {
+			item.Type, item.SeriesName, item.SeasonNumber, item.EpisodeNumber = "Episode", "Dark", 1, i
+		}
+		jellyfinClient.recentItems = append(jellyfinClient.recentItems, item)
+	}
+	b.jellyfinClient = jellyfinClient
+	return b, jellyfinClient
+}
+
+// Test 1: /recent sends a single list message whose page and filter buttons edit it in place
+func TestRecent_PaginatedList(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b, _ := newBrowseTestBot(t, api)
+	ctx := context.Background()
+
+	b.handleRecent(ctx, b.bot, commandUpdate(200, "/recent"))
+
+	sent := api.sent("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("Expected one list message, got %d", len(sent))
+	}
+	if !strings.Contains(sent[0].text, "1. 🎬 Title 1") || !strings.Contains(sent[0].text, "5. 🎬 Title 5") || strings.Contains(sent[0].text, "Title 6") {
+		t.Errorf("Expected the first 5 items, got %q", sent[0].text)
+	}
+	if len(api.sent("sendPhoto")) != 0 {
+		t.Error("Expected no posters in the list")
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "browse:recent:all:2"))
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "browse:recent:episodes:1"))
+
+	edits := api.sent("editMessageText")
+	if len(edits) != 2 {
+		t.Fatalf("Expected the list to be edited twice, got %d", len(edits))
+	}
+	if !strings.Contains(edits[0].text, "6. 📺 Dark S01E06") || !strings.Contains(edits[0].text, "Page 2 of 2") {
+		t.Errorf("Expected the second page, got %q", edits[0].text)
+	}
+	if !strings.Contains(edits[1].text, "3 items") || strings.Contains(edits[1].text, "🎬") {
+		t.Errorf("Expected only the episodes, got %q", edits[1].text)
+	}
+	if len(api.sent("sendMessage")) != 1 {
+		t.Error("Expected paging not to send new messages")
+	}
+}
+
+// Test 2: Search lists keep their query behind a callback token
+func TestSearch_PagingKeepsQuery(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b, jellyfinClient := newBrowseTestBot(t, api)
+	jellyfinClient.searchResults = jellyfinClient.recentItems
+	ctx := context.Background()
+
+	view := browseView{query: "title", filter: browseFilters[0], page: 1}
+	_, keyboard, total, err := b.browsePage(ctx, 200, &view, getTestLocalizer())
+	if err != nil || total != 7 {
+		t.Fatalf("Expected 7 results, got %d (%v)", total, err)
+	}
+
+	next := keyboard.InlineKeyboard[1][0].CallbackData
+	if len(next) > 64 || strings.Contains(next, "title") {
+		t.Fatalf("Expected a short callback without the query, got %q", next)
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, next))
+
+	if jellyfinClient.lastQuery.SearchTerm != "title" || jellyfinClient.lastQuery.StartIndex != browsePageSize {
+		t.Errorf("Expected the second page of the search, got %+v", jellyfinClient.lastQuery)
+	}
+	if edits := api.sent("editMessageText"); len(edits) != 1 || !strings.Contains(edits[0].text, "Results for 'title'") {
+		t.Errorf("Expected the search list to be edited, got %+v", edits)
+	}
+}
+
+// Test 3: Tapping a number sends the full card of the item
+func TestItemCallback_SendsCard(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b, _ := newBrowseTestBot(t, api)
+	ctx := context.Background()
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "item:item3"))
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "item:missing"))
+
+	if photos := len(api.sent("sendPhoto")); photos != 1 {
+		t.Errorf("Expected one card with poster, got %d", photos)
+	}
+}
@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	"gorm.io/gorm"
+)
+
+// defaultCallbackTTL is used when no configuration is loaded
+const defaultCallbackTTL = 30 * 24 * time.Hour
+
+// callbackCleanupInterval is how often expired callback tokens are deleted
+const callbackCleanupInterval = time.Hour
+
+// callbackTokenBytes is the random part of a callback token, encoded to callbackTokenLength characters
+const (
+	callbackTokenBytes  = 12
+	callbackTokenLength = 16
+)
+
+// errCallbackExpired is returned for tokens that expired or were already cleaned up
+var errCallbackExpired = errors.New("callback token expired")
+
+// callbackRoute handles the callback queries of one action; arg is the data after "action:"
+type callbackRoute func(ctx context.Context, botInstance *bot.Bot, update *botModels.Update, arg string)
+
+// registerCallbacks sets up the routes of all inline button actions
+// Actions with short fixed arguments keep them in the callback data; the others use stored tokens
+func (b *Bot) registerCallbacks() {
+	b.callbackRoutes = make(map[string]callbackRoute)
+
+	b.routeCallback("nav", b.handleNavigationCallback)
+	b.routeCallback("lang", b.handleLanguageCallback)
+	b.routeCallback("types", b.handleTypesCallback)
+	b.routeCallback("pref", b.handlePreferencesCallback)
+	b.routeCallback("subs", b.handleSubscribersCallback)
+	b.routeCallback("announce", b.handleAnnounceCallback)
+	b.routeCallback("access", b.handleAccessCallback)
+	b.routeCallback("browse", b.handleBrowseCallback)
+	b.routeCallback("item", b.handleItemCallback)
+	b.routeCallback("wish", b.handleWishCallback)
+	b.routeCallback("request", b.handleRequestCallback)
+	b.routeCallback("follow_mode", b.handleFollowModeCallback)
+	b.routeCallback("events", b.handleEventsCallback)
+
+	routeTokenCallback(b, "mute", legacySeriesRef, b.handleMuteCallback)
+	routeTokenCallback(b, "undo_mute", legacySeriesRef, b.handleUndoMuteCallback)
+	routeTokenCallback(b, "unmute", legacySeriesRef, b.handleUnmuteCallback)
+	routeTokenCallback(b, "follow", nil, b.handleFollowCallback)
+	routeTokenCallback(b, "unfollow", nil, b.handleUnfollowCallback)
+}
+
+// routeCallback registers a handler that parses the callback data itself
+func (b *Bot) routeCallback(action string, handler bot.HandlerFunc) {
+	b.callbackRoutes[action] = func(ctx context.Context, botInstance *bot.Bot, update *botModels.Update, _ string) {
+		handler(ctx, botInstance, update)
+	}
+}
+
+// routeTokenCallback registers a handler for callback data like "action:<token>"
+// The router loads the token's payload into T and answers expired buttons itself
+// legacy, if set, converts the argument of buttons sent before tokens existed
+func routeTokenCallback[T any](b *Bot, action string, legacy func(arg string) *T,
+	handler func(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, payload *T)) {
+	b.callbackRoutes[action] = func(ctx context.Context, botInstance *bot.Bot, update *botModels.Update, arg string) {
+		callbackQuery := update.CallbackQuery
+
+		// Check if message exists
+		if callbackQuery.Message.Message == nil {
+			slog.Warn("Callback query message is nil")
+			return
+		}
+
+		payload, err := loadCallbackPayload(b, action, arg, legacy)
+		if errors.Is(err, errCallbackExpired) {
+			slog.Info("Callback button expired",
+				"chat_id", callbackQuery.Message.Message.Chat.ID,
+				"action", action)
+			b.answerCallback(ctx, botInstance, callbackQuery, "error.callback_expired", true)
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to resolve callback token",
+				"chat_id", callbackQuery.Message.Message.Chat.ID,
+				"callback_data", callbackQuery.Data,
+				"error", err)
+			b.answerCallback(ctx, botInstance, callbackQuery, "error.request_processing", false)
+			return
+		}
+
+		handler(ctx, botInstance, callbackQuery, payload)
+	}
+}
+
+// dispatchCallback routes a callback query to the handler of its action
+func (b *Bot) dispatchCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	if update.CallbackQuery == nil {
+		return
+	}
+
+	action, arg, _ := strings.Cut(update.CallbackQuery.Data, ":")
+	route, ok := b.callbackRoutes[action]
+	if !ok {
+		// Inactive buttons like "muted" have no handler; answer to stop the loading indicator
+		slog.Debug("Ignoring callback without handler",
+			"callback_data", update.CallbackQuery.Data)
+		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: update.CallbackQuery.ID,
+		})
+		return
+	}
+
+	route(ctx, botInstance, update, arg)
+}
+
+// callbackAction returns the action of callback data like "mute:<token>"
+// Unknown data is grouped so user-controlled values can't create unbounded metric label values
+func (b *Bot) callbackAction(data string) string {
+	action, _, _ := strings.Cut(data, ":")
+	if _, ok := b.callbackRoutes[action]; !ok {
+		return "other"
+	}
+	return action
+}
+
+// answerCallback answers a callback query with a localized message
+func (b *Bot) answerCallback(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, messageID string, alert bool) {
+	chatID := callbackQuery.From.ID
+	if callbackQuery.Message.Message != nil {
+		chatID = callbackQuery.Message.Message.Chat.ID
+	}
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+		Text:            i18n.T(localizer, messageID),
+		ShowAlert:       alert,
+	})
+}
+
+// newCallbackData stores the payload under a random token and returns "action:<token>"
+// A token issued earlier for the same action and payload is reused while it has at least half its lifetime left,
+// so buttons repeated on every notification don't each add a row
+func (b *Bot) newCallbackData(action string, payload any) (string, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s callback: %w", action, err)
+	}
+
+	now := time.Now()
+	existing, err := b.db.FindCallbackToken(action, string(encoded), now.Add(b.callbackTTL()/2))
+	if err == nil {
+		return action + ":" + existing.Token, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.Warn("Failed to look up existing callback token", "action", action, "error", err)
+	}
+
+	token, err := generateCallbackToken()
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := now.Add(b.callbackTTL())
+	if err := b.db.SaveCallbackToken(&models.CallbackToken{
+		Token:     token,
+		Action:    action,
+		Payload:   string(encoded),
+		ExpiresAt: &expiresAt,
+	}); err != nil {
+		return "", err
+	}
+
+	return action + ":" + token, nil
+}
+
+// loadCallbackPayload decodes the payload stored for a callback token
+// Unknown tokens are reported as expired, since expired tokens are deleted by the cleanup
+func loadCallbackPayload[T any](b *Bot, action, token string, legacy func(arg string) *T) (*T, error) {
+	if token == "" {
+		return nil, fmt.Errorf("empty %s callback argument", action)
+	}
+
+	record, err := b.db.GetCallbackToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if legacy != nil && !isCallbackToken(token) {
+			return legacy(token), nil
+		}
+		return nil, errCallbackExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens stored before actions were recorded are accepted for any action
+	if record.Action != "" && record.Action != action {
+		return nil, fmt.Errorf("callback token issued for %q used for %q", record.Action, action)
+	}
+	if record.IsExpired(time.Now()) {
+		return nil, errCallbackExpired
+	}
+
+	var payload T
+	if err := json.Unmarshal([]byte(record.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s callback: %w", action, err)
+	}
+	return &payload, nil
+}
+
+// callbackTTL returns how long new callback tokens stay valid
+func (b *Bot) callbackTTL() time.Duration {
+	if b.config != nil && b.config.Telegram.CallbackTTL > 0 {
+		return b.config.Telegram.CallbackTTL
+	}
+	return defaultCallbackTTL
+}
+
+// RunCallbackCleanup deletes expired callback tokens periodically until the context is cancelled
+func (b *Bot) RunCallbackCleanup(ctx context.Context) {
+	ticker := time.NewTicker(callbackCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := b.db.DeleteExpiredCallbackTokens(time.Now())
+		if err != nil {
+			slog.Warn("Failed to delete expired callback tokens", "error", err)
+		} else if deleted > 0 {
+			slog.Debug("Deleted expired callback tokens", "count", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// generateCallbackToken creates a random URL-safe callback token
+func generateCallbackToken() (string, error) {
+	buf := make([]byte, callbackTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate callback token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// isCallbackToken reports whether a callback argument has the shape of a generated token
+func isCallbackToken(arg string) bool {
+	if len(arg) != callbackTokenLength {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(arg)
+	return err == nil
+}
@@ -0,0 +1,179 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+)
+
+func newCallbackTestBot(t *testing.T, api http.Handler, db *mockSubscriberDB) *Bot {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	b, err := bot.New("123:test", bot.WithSkipGetMe(), bot.WithServerURL(server.URL),
+		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *botModels.Update) {}))
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+
+	botInstance := &Bot{bot: b, db: db, i18nBundle: bundle}
+	botInstance.registerCallbacks()
+	return botInstance
+}
+
+func callbackUpdate(chatID int64, data string) *botModels.Update {
+	return &botModels.Update{
+		CallbackQuery: &botModels.CallbackQuery{
+			ID:   "query",
+			From: botModels.User{ID: chatID},
+			Message: botModels.MaybeInaccessibleMessage{
+				Message: &botModels.Message{ID: 1, Chat: botModels.Chat{ID: chatID}},
+			},
+			Data: data,
+		},
+	}
+}
+
+// Test 1: Stored payloads are returned only for their action and until they expire
+func TestLoadCallbackPayload_ActionAndExpiry(t *testing.T) {
+	db := newMockSubscriberDB()
+	b := &Bot{db: db}
+
+	data, err := b.newCallbackData("mute", seriesRef{ID: "bb-id", Name: "Breaking Bad"})
+	if err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	token := strings.TrimPrefix(data, "mute:")
+	if !isCallbackToken(token) {
+		t.Fatalf("Expected a generated token, got %q", data)
+	}
+
+	if _, err := loadCallbackPayload[seriesRef](b, "unmute", token, nil); err == nil {
+		t.Error("Expected a token to be rejected for another action")
+	}
+
+	past := time.Now().Add(-time.Second)
+	db.tokens[token].ExpiresAt = &past
+	if _, err := loadCallbackPayload[seriesRef](b, "mute", token, nil); !errors.Is(err, errCallbackExpired) {
+		t.Errorf("Expected an expired token, got %v", err)
+	}
+
+	// Cleaned up tokens look the same as expired ones, not like legacy arguments
+	if deleted, _ := db.DeleteExpiredCallbackTokens(time.Now()); deleted != 1 {
+		t.Fatalf("Expected the expired token to be deleted")
+	}
+	if _, err := loadCallbackPayload(b, "mute", token, legacySeriesRef); !errors.Is(err, errCallbackExpired) {
+		t.Errorf("Expected a deleted token to be reported as expired, got %v", err)
+	}
+}
+
+// Test 2: The router dispatches by action and answers expired buttons without running the handler
+func TestDispatchCallback(t *testing.T) {
+	api := &fakeTelegramAPI{}
+	db := newMockSubscriberDB()
+	b := newCallbackTestBot(t, api, db)
+
+	data, err := b.seriesCallbackData("mute", "bb-id", "Breaking Bad")
+	if err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	b.dispatchCallback(context.Background(), b.bot, callbackUpdate(100, data))
+	if !db.mutedSeries[100]["bb-id"] {
+		t.Error("Expected the mute handler to mute the series by ID")
+	}
+
+	expiredData, _ := b.seriesCallbackData("mute", "dark-id", "Dark")
+	past := time.Now().Add(-time.Second)
+	db.tokens[strings.TrimPrefix(expiredData, "mute:")].ExpiresAt = &past
+	b.dispatchCallback(context.Background(), b.bot, callbackUpdate(200, expiredData))
+	if len(db.mutedSeries[200]) != 0 {
+		t.Error("Expected an expired button not to mute anything")
+	}
+
+	calls := len(api.called())
+	b.dispatchCallback(context.Background(), b.bot, callbackUpdate(100, "muted"))
+	if got := api.called()[calls:]; !slices.Equal(got, []string{"answerCallbackQuery"}) {
+		t.Errorf("Expected inactive buttons to only be answered, got %v", got)
+	}
+
+	if b.callbackAction(data) != "mute" || b.callbackAction("unknown:x") != "other" {
+		t.Error("Expected metrics to use registered actions only")
+	}
+}
+
+// Test 3: New tokens expire after the configured time to live
+func TestNewCallbackData_UsesTTL(t *testing.T) {
+	db := newMockSubscriberDB()
+	b := &Bot{db: db}
+
+	before := time.Now()
+	data, err := b.newCallbackData("unmute", seriesRef{ID: "bb-id"})
+	if err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+
+	record := db.tokens[strings.TrimPrefix(data, "unmute:")]
+	if record == nil || record.Action != "unmute" || record.ExpiresAt == nil {
+		t.Fatalf("Unexpected stored token: %+v", record)
+	}
+	if record.ExpiresAt.Before(before.Add(defaultCallbackTTL)) {
+		t.Errorf("Expected the token to expire after %s, got %v", defaultCallbackTTL, record.ExpiresAt)
+	}
+	if record.IsExpired(time.Now()) {
+		t.Error("Expected a new token to be valid")
+	}
+}
+
+// Test 4: Identical buttons share a token until it is close to expiring
+func TestNewCallbackData_ReusesToken(t *testing.T) {
+	db := newMockSubscriberDB()
+	b := &Bot{db: db}
+
+	first, err := b.newCallbackData("mute", seriesRef{ID: "bb-id", Name: "Breaking Bad"})
+	if err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	second, err := b.newCallbackData("mute", seriesRef{ID: "bb-id", Name: "Breaking Bad"})
+	if err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	if first != second || len(db.tokens) != 1 {
+		t.Fatalf("Expected the token to be reused, got %q and %q with %d stored", first, second, len(db.tokens))
+	}
+
+	// Other actions and payloads get their own token
+	if _, err := b.newCallbackData("unmute", seriesRef{ID: "bb-id", Name: "Breaking Bad"}); err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	if _, err := b.newCallbackData("mute", seriesRef{ID: "dark-id", Name: "Dark"}); err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	if len(db.tokens) != 3 {
+		t.Errorf("Expected 3 stored tokens, got %d", len(db.tokens))
+	}
+
+	// A token with less than half its lifetime left is not handed out again
+	soon := time.Now().Add(defaultCallbackTTL / 4)
+	db.tokens[strings.TrimPrefix(first, "mute:")].ExpiresAt = &soon
+	third, err := b.newCallbackData("mute", seriesRef{ID: "bb-id", Name: "Breaking Bad"})
+	if err != nil {
+		t.Fatalf("Failed to create callback data: %v", err)
+	}
+	if third == first {
+		t.Error("Expected a new token once the old one is about to expire")
+	}
+}
@@ -94,32 +94,8 @@ func (b *Bot) handleNavigationRecent(ctx context.Context, botInstance *bot.Bot,
 		ShowAlert:       false,
 	})
 
-	// Fetch recent items from Jellyfin (reuse handleRecent logic)
-	items, err := b.jellyfinClient.GetRecentItems(ctx, 15)
-	if err != nil {
-		slog.Error("Failed to fetch recent items",
-			"chat_id", chatID,
-			"error", err)
-
-		errorMsg := i18n.T(localizer, "recent.error")
-		b.SendMessage(ctx, chatID, errorMsg)
-		return
-	}
-
-	// Handle empty results
-	if len(items) == 0 {
-		b.SendMessage(ctx, chatID, i18n.T(localizer, "recent.no_results"))
-		return
-	}
-
-	// Send each item with poster and formatted message
-	for _, item := range items {
-		b.sendContentItem(ctx, chatID, &item, localizer)
-	}
-
-	slog.Info("Sent recent items via navigation",
-		"chat_id", chatID,
-		"count", len(items))
+	// Same list as /recent
+	b.sendBrowseList(ctx, chatID, "", localizer)
 }
 
 // handleNavigationSearch handles nav:search callback
@@ -191,7 +167,7 @@ func (b *Bot) handleNavigationMutedList(ctx context.Context, botInstance *bot.Bo
 		buttons = append(buttons, []botModels.InlineKeyboardButton{
 			{
 				Text:         buttonText,
-				CallbackData: fmt.Sprintf("unmute:%s", series.SeriesID),
+				CallbackData: b.unmuteCallbackData(&series),
 			},
 		})
 	}
@@ -236,25 +212,12 @@ func (b *Bot) handleNavigationHelp(ctx context.Context, botInstance *bot.Bot, ch
 }
 
 // handleMuteCallback handles the mute button callback
-func (b *Bot) handleMuteCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
-	if update.CallbackQuery == nil {
-		return
-	}
-
-	callbackQuery := update.CallbackQuery
-
-	// Check if message exists
-	if callbackQuery.Message.Message == nil {
-		slog.Warn("Callback query message is nil")
-		return
-	}
-
+func (b *Bot) handleMuteCallback(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, series *seriesRef) {
 	chatID := callbackQuery.Message.Message.Chat.ID
-	callbackData := callbackQuery.Data
 
 	slog.Info("Processing mute callback",
 		"chat_id", chatID,
-		"callback_data", callbackData)
+		"series_id", series.ID)
 
 	// Get localizer for user
 	telegramLangCode := ""
@@ -263,21 +226,14 @@ func (b *Bot) handleMuteCallback(ctx context.Context, botInstance *bot.Bot, upda
 	}
 	localizer := b.getLocalizerForUser(ctx, chatID, telegramLangCode)
 
-	// Parse series name from callback data (format: "mute:{SeriesName}")
-	parts := strings.SplitN(callbackData, ":", 2)
-	if len(parts) != 2 {
-		slog.Error("Invalid callback data format",
-			"callback_data", callbackData)
-		return
-	}
-
-	seriesName := parts[1]
+	seriesName := series.Name
 
 	// Add muted series to database
-	err := b.db.AddMutedSeries(chatID, seriesName, seriesName)
+	err := b.db.AddMutedSeries(chatID, series.ID, seriesName)
 	if err != nil {
 		slog.Error("Failed to add muted series",
 			"chat_id", chatID,
+			"series_id", series.ID,
 			"series_name", seriesName,
 			"error", err)
 
@@ -303,12 +259,19 @@ func (b *Bot) handleMuteCallback(ctx context.Context, botInstance *bot.Bot, upda
 	})
 
 	// Create inline keyboard with undo button
+	undoData, err := b.seriesCallbackData("undo_mute", series.ID, seriesName)
+	if err != nil {
+		slog.Warn("Failed to store undo callback token",
+			"series_id", series.ID,
+			"error", err)
+		undoData = "undo_mute:" + series.ID
+	}
 	undoKeyboard := &botModels.InlineKeyboardMarkup{
 		InlineKeyboard: [][]botModels.InlineKeyboardButton{
 			{
 				{
 					Text:         i18n.T(localizer, "button.undo_mute"),
-					CallbackData: fmt.Sprintf("undo_mute:%s", seriesName),
+					CallbackData: undoData,
 				},
 			},
 		},
@@ -350,29 +313,17 @@ func (b *Bot) handleMuteCallback(ctx context.Context, botInstance *bot.Bot, upda
 
 	slog.Info("Successfully muted series",
 		"chat_id", chatID,
+		"series_id", series.ID,
 		"series_name", seriesName)
 }
 
 // handleUndoMuteCallback handles the undo mute button callback
-func (b *Bot) handleUndoMuteCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
-	if update.CallbackQuery == nil {
-		return
-	}
-
-	callbackQuery := update.CallbackQuery
-
-	// Check if message exists
-	if callbackQuery.Message.Message == nil {
-		slog.Warn("Callback query message is nil")
-		return
-	}
-
+func (b *Bot) handleUndoMuteCallback(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, series *seriesRef) {
 	chatID := callbackQuery.Message.Message.Chat.ID
-	callbackData := callbackQuery.Data
 
 	slog.Info("Processing undo mute callback",
 		"chat_id", chatID,
-		"callback_data", callbackData)
+		"series_id", series.ID)
 
 	// Get localizer for user
 	telegramLangCode := ""
@@ -381,25 +332,10 @@ func (b *Bot) handleUndoMuteCallback(ctx context.Context, botInstance *bot.Bot,
 	}
 	localizer := b.getLocalizerForUser(ctx, chatID, telegramLangCode)
 
-	// Parse series name from callback data (format: "undo_mute:{SeriesName}")
-	parts := strings.SplitN(callbackData, ":", 2)
-	if len(parts) != 2 {
-		slog.Error("Invalid callback data format",
-			"callback_data", callbackData)
-
-		// Always answer callback query
-		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
-			CallbackQueryID: callbackQuery.ID,
-			Text:            i18n.T(localizer, "error.request_processing"),
-			ShowAlert:       false,
-		})
-		return
-	}
-
-	seriesName := parts[1]
+	seriesName := series.Name
 
 	// Remove muted series from database (reuse unmute logic from handleUnmuteCallback)
-	err := b.db.RemoveMutedSeries(chatID, seriesName)
+	err := b.db.RemoveMutedSeries(chatID, series.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			slog.Debug("Series not found in muted list",
@@ -480,25 +416,12 @@ func (b *Bot) handleUndoMuteCallback(ctx context.Context, botInstance *bot.Bot,
 }
 
 // handleUnmuteCallback handles the unmute button callback
-func (b *Bot) handleUnmuteCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
-	if update.CallbackQuery == nil {
-		return
-	}
-
-	callbackQuery := update.CallbackQuery
-
-	// Check if message exists
-	if callbackQuery.Message.Message == nil {
-		slog.Warn("Callback query message is nil")
-		return
-	}
-
+func (b *Bot) handleUnmuteCallback(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, series *seriesRef) {
 	chatID := callbackQuery.Message.Message.Chat.ID
-	callbackData := callbackQuery.Data
 
 	slog.Info("Processing unmute callback",
 		"chat_id", chatID,
-		"callback_data", callbackData)
+		"series_id", series.ID)
 
 	// Get localizer for user
 	telegramLangCode := ""
@@ -507,19 +430,11 @@ func (b *Bot) handleUnmuteCallback(ctx context.Context, botInstance *bot.Bot, up
 	}
 	localizer := b.getLocalizerForUser(ctx, chatID, telegramLangCode)
 
-	// Parse series ID from callback data (format: "unmute:{SeriesID}")
-	parts := strings.SplitN(callbackData, ":", 2)
-	if len(parts) != 2 {
-		slog.Error("Invalid callback data format",
-			"callback_data", callbackData)
-		return
-	}
-
-	seriesID := parts[1]
+	seriesID := series.ID
 
 	// Get series name before removal (for confirmation message)
 	mutedSeries, err := b.db.GetMutedSeriesByUser(chatID)
-	seriesName := seriesID // Default to ID if we can't find the name
+	seriesName := series.Name
 	if err == nil {
 		for _, ms := range mutedSeries {
 			if ms.SeriesID == seriesID {
@@ -606,7 +521,7 @@ func (b *Bot) handleUnmuteCallback(ctx context.Context, botInstance *bot.Bot, up
 			buttons = append(buttons, []botModels.InlineKeyboardButton{
 				{
 					Text:         buttonText,
-					CallbackData: fmt.Sprintf("unmute:%s", series.SeriesID),
+					CallbackData: b.unmuteCallbackData(&series),
 				},
 			})
 		}
@@ -0,0 +1,522 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// digestCheckInterval is how often the scheduler looks for digests that are due
+	digestCheckInterval = time.Minute
+	// digestDefaultTime is the local time digests are sent at when the subscriber didn't choose one
+	digestDefaultTime = "20:00"
+	// digestMaxPosters is the most posters Telegram accepts in one media group
+	digestMaxPosters = 10
+)
+
+// weekdayKeys maps weekdays to their localized name keys
+var weekdayKeys = map[time.Weekday]string{
+	time.Sunday:    "weekday.sunday",
+	time.Monday:    "weekday.monday",
+	time.Tuesday:   "weekday.tuesday",
+	time.Wednesday: "weekday.wednesday",
+	time.Thursday:  "weekday.thursday",
+	time.Friday:    "weekday.friday",
+	time.Saturday:  "weekday.saturday",
+}
+
+// DigestStore defines the interface for the data digests are built from
+type DigestStore interface {
+	GetDigestSubscribers() ([]models.Subscriber, error)
+	GetContentSince(since time.Time) ([]models.ContentCache, error)
+	MarkDigestSent(chatID int64, sentAt time.Time) error
+}
+
+// digestDeliverer filters and sends digests (implemented by Bot)
+type digestDeliverer interface {
+	scheduleLocation(schedule *models.DeliverySchedule) *time.Location
+	wantsContent(ctx context.Context, chatID int64, content *NotificationContent) bool
+	deliverDigest(ctx context.Context, chatID int64, mode string, contents []NotificationContent) error
+	handleBlockedRecipient(chatID int64, sendErr error)
+}
+
+// DigestScheduler sends daily and weekly digests at each subscriber's local digest time
+type DigestScheduler struct {
+	deliverer     digestDeliverer
+	store         DigestStore
+	checkInterval time.Duration
+}
+
+// NewDigestScheduler creates a new digest scheduler delivering through the bot
+func NewDigestScheduler(bot *Bot, store DigestStore) *DigestScheduler {
+	return &DigestScheduler{
+		deliverer:     bot,
+		store:         store,
+		checkInterval: digestCheckInterval,
+	}
+}
+
+// Run sends due digests until the context is cancelled
+func (s *DigestScheduler) Run(ctx context.Context) {
+	slog.Info("Starting digest scheduler", "check_interval", s.checkInterval)
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDueDigests(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Digest scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDueDigests sends a digest to every subscriber whose digest time has passed
+func (s *DigestScheduler) sendDueDigests(ctx context.Context, now time.Time) {
+	subscribers, err := s.store.GetDigestSubscribers()
+	if err != nil {
+		slog.Error("Failed to load digest subscribers", "error", err)
+		return
+	}
+
+	for i := range subscribers {
+		if ctx.Err() != nil {
+			return
+		}
+		s.sendDigest(ctx, &subscribers[i], now)
+	}
+}
+
+// sendDigest sends one subscriber's digest if it is due
+func (s *DigestScheduler) sendDigest(ctx context.Context, subscriber *models.Subscriber, now time.Time) {
+	chatID := subscriber.ChatID
+	schedule := &subscriber.Schedule
+
+	// Without a previous digest there is no period to summarize yet
+	if schedule.LastDigestAt.IsZero() {
+		if err := s.store.MarkDigestSent(chatID, now); err != nil {
+			slog.Error("Failed to start digest period", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	loc := s.deliverer.scheduleLocation(schedule)
+	if now.Before(nextDigestTime(schedule, loc, schedule.LastDigestAt)) {
+		return
+	}
+
+	records, err := s.store.GetContentSince(schedule.LastDigestAt)
+	if err != nil {
+		slog.Error("Failed to load digest content", "chat_id", chatID, "error", err)
+		return
+	}
+
+	contents := make([]NotificationContent, 0, len(records))
+	for i := range records {
+		content := contentFromCache(&records[i])
+		if s.deliverer.wantsContent(ctx, chatID, &content) {
+			contents = append(contents, content)
+		}
+	}
+
+	if len(contents) > 0 {
+		// A digest that started sending is finished during shutdown so it isn't sent twice
+		sendErr := s.deliverer.deliverDigest(context.WithoutCancel(ctx), chatID, schedule.DeliveryMode, contents)
+		switch {
+		case sendErr == nil:
+			slog.Info("Digest sent",
+				"chat_id", chatID,
+				"mode", schedule.DeliveryMode,
+				"items", len(contents))
+		case isBlockedError(sendErr):
+			s.deliverer.handleBlockedRecipient(chatID, sendErr)
+			return
+		default:
+			// Retried on the next check
+			slog.Error("Failed to send digest",
+				"chat_id", chatID,
+				"error", sendErr)
+			return
+		}
+	}
+
+	if err := s.store.MarkDigestSent(chatID, now); err != nil {
+		slog.Error("Failed to mark digest as sent", "chat_id", chatID, "error", err)
+	}
+}
+
+// nextDigestTime returns the first digest time of the schedule after the given time
+func nextDigestTime(schedule *models.DeliverySchedule, loc *time.Location, after time.Time) time.Time {
+	clock, err := parseClock(schedule.DigestTime)
+	if err != nil {
+		clock, _ = parseClock(digestDefaultTime)
+	}
+
+	local := after.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock/60, clock%60, 0, 0, loc)
+
+	step := 1
+	if schedule.DeliveryMode == models.DeliveryModeWeekly {
+		step = 7
+		next = next.AddDate(0, 0, (int(schedule.DigestDay)-int(next.Weekday())+7)%7)
+	}
+	if !next.After(after) {
+		next = next.AddDate(0, 0, step)
+	}
+
+	return next
+}
+
+// contentFromCache converts a content cache record to notification content
+func contentFromCache(record *models.ContentCache) NotificationContent {
+	var genres []string
+	for _, genre := range strings.Split(record.Genres, ",") {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			genres = append(genres, genre)
+		}
+	}
+
+	return NotificationContent{
+		ItemID:         record.JellyfinID,
+		Type:           record.Type,
+		Title:          record.Title,
+		Year:           record.Year,
+		Rating:         record.Rating,
+		SeriesID:       record.SeriesID,
+		SeriesName:     record.SeriesName,
+		SeasonNumber:   record.SeasonNumber,
+		EpisodeNumber:  record.EpisodeNumber,
+		OfficialRating: record.OfficialRating,
+		Genres:         genres,
+	}
+}
+
+// wantsContent applies a subscriber's mutes or follows, item type opt-outs, content preferences and library
+// permissions to one item
+// Like resolveRecipients, a failed lookup includes the item rather than hiding it, except for permissions
+func (b *Bot) wantsContent(ctx context.Context, chatID int64, content *NotificationContent) bool {
+	if b.config != nil && b.config.Testing.NotifyOnlyTesters && !b.config.IsTester(chatID) {
+		return false
+	}
+
+	if isSeriesContent(content.Type) && content.SeriesName != "" {
+		if hidden, err := b.isSeriesHidden(chatID, content); err == nil && hidden {
+			return false
+		}
+	}
+
+	if enabled, err := b.db.IsItemTypeEnabled(chatID, content.Type); err == nil && !enabled {
+		return false
+	}
+
+	if prefs, err := b.db.GetContentPreferences(chatID); err == nil &&
+		!prefs.Allows(content.Rating, content.Year, content.OfficialRating, content.Genres) {
+		return false
+	}
+
+	return b.canSeeItem(ctx, chatID, content.ItemID)
+}
+
+// deliverDigest sends a digest as the summary message followed by a media group of posters
+// The posters are best effort, only a failed summary fails the digest
+func (b *Bot) deliverDigest(ctx context.Context, chatID int64, mode string, contents []NotificationContent) error {
+	localizer := b.getLocalizerForUser(ctx, chatID, "")
+	movies, series, others := groupDigestContent(contents)
+
+	// Posters of the movies first, then of one episode per series, then of everything else
+	var posters [][]byte
+	candidates := append(append(append([]NotificationContent{}, movies...), seriesFirstItems(series)...), others...)
+	for i := range candidates {
+		if len(posters) == digestMaxPosters {
+			break
+		}
+		if imageData := b.fetchPoster(ctx, &candidates[i]); len(imageData) > 0 {
+			posters = append(posters, imageData)
+		}
+	}
+
+	// The summary goes first: a failed digest is retried, and the posters alone would be posted again
+	if err := b.SendMessage(ctx, chatID, formatDigest(mode, contents, localizer)); err != nil {
+		return err
+	}
+
+	if err := b.SendPosterGroup(ctx, chatID, posters); err != nil {
+		slog.Warn("Failed to send digest posters", "chat_id", chatID, "error", err)
+	}
+	return nil
+}
+
+// SendPosterGroup sends posters as a single media group, or as a photo when there is only one
+func (b *Bot) SendPosterGroup(ctx context.Context, chatID int64, posters [][]byte) error {
+	switch len(posters) {
+	case 0:
+		return nil
+	case 1:
+		return b.SendPhotoBytes(ctx, chatID, posters[0], "")
+	}
+
+	media := make([]botModels.InputMedia, 0, len(posters))
+	for i, imageData := range posters {
+		filename := fmt.Sprintf("poster%d.jpg", i)
+		media = append(media, &botModels.InputMediaPhoto{
+			Media:           "attach://" + filename,
+			MediaAttachment: bytes.NewReader(imageData),
+		})
+	}
+
+	_, err := b.bot.SendMediaGroup(ctx, &bot.SendMediaGroupParams{
+		ChatID: chatID,
+		Media:  media,
+	})
+	return err
+}
+
+// digestSeries is the number of new episodes of one series in a digest
+type digestSeries struct {
+	Name      string
+	Count     int
+	FirstItem NotificationContent
+}
+
+// groupDigestContent splits digest content into movies, episodes per series and everything else
+// Series keep the order of their first new episode
+func groupDigestContent(contents []NotificationContent) ([]NotificationContent, []digestSeries, []NotificationContent) {
+	var movies, others []NotificationContent
+	var series []digestSeries
+	seriesIndex := make(map[string]int)
+
+	for _, content := range contents {
+		switch {
+		case content.Type == models.ItemTypeMovie:
+			movies = append(movies, content)
+		case content.Type == models.ItemTypeEpisode && content.SeriesName != "":
+			count := content.EpisodeCount
+			if count < 1 {
+				count = 1
+			}
+			if i, ok := seriesIndex[content.SeriesName]; ok {
+				series[i].Count += count
+				continue
+			}
+			seriesIndex[content.SeriesName] = len(series)
+			series = append(series, digestSeries{Name: content.SeriesName, Count: count, FirstItem: content})
+		default:
+			others = append(others, content)
+		}
+	}
+
+	return movies, series, others
+}
+
+// seriesFirstItems returns the first new episode of every series in a digest
+func seriesFirstItems(series []digestSeries) []NotificationContent {
+	items := make([]NotificationContent, 0, len(series))
+	for _, s := range series {
+		items = append(items, s.FirstItem)
+	}
+	return items
+}
+
+// formatDigest formats the digest message, grouped by movies, series and other content
+func formatDigest(mode string, contents []NotificationContent, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+
+	header := "digest.header.daily"
+	if mode == models.DeliveryModeWeekly {
+		header = "digest.header.weekly"
+	}
+	message.WriteString(i18n.TWithData(localizer, header, map[string]interface{}{
+		"Count": len(contents),
+	}))
+
+	movies, series, others := groupDigestContent(contents)
+
+	if len(movies) > 0 {
+		lines := make([]string, 0, len(movies))
+		for i := range movies {
+			title := movies[i].Title
+			if movies[i].Year > 0 {
+				title = fmt.Sprintf("%s (%d)", title, movies[i].Year)
+			}
+			lines = append(lines, title)
+		}
+		writeDigestSection(&message, i18n.T(localizer, "digest.section.movies"), lines, localizer)
+	}
+
+	if len(series) > 0 {
+		lines := make([]string, 0, len(series))
+		for _, s := range series {
+			key := "digest.series_line"
+			if s.Count == 1 {
+				key = "digest.series_line_one"
+			}
+			lines = append(lines, i18n.TWithData(localizer, key, map[string]interface{}{
+				"SeriesName": s.Name,
+				"Count":      s.Count,
+			}))
+		}
+		writeDigestSection(&message, i18n.T(localizer, "digest.section.series"), lines, localizer)
+	}
+
+	if len(others) > 0 {
+		lines := make([]string, 0, len(others))
+		for i := range others {
+			lines = append(lines, formatSummaryLine(&others[i], localizer))
+		}
+		writeDigestSection(&message, i18n.T(localizer, "digest.section.other"), lines, localizer)
+	}
+
+	return message.String()
+}
+
+// writeDigestSection writes a titled list of digest lines, capped to stay below Telegram's size limit
+func writeDigestSection(message *strings.Builder, title string, lines []string, localizer *goi18n.Localizer) {
+	message.WriteString("\n\n")
+	message.WriteString(title)
+
+	for i, line := range lines {
+		if i == catchUpMaxLines {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "catchup.more", map[string]interface{}{
+				"Count": len(lines) - catchUpMaxLines,
+			}))
+			break
+		}
+		message.WriteString("\n• ")
+		message.WriteString(line)
+	}
+}
+
+// handleDigest handles the /digest command
+// Usage: "/digest" shows the current mode, "/digest daily 20:00", "/digest weekly sun 20:00" or "/digest instant" change it
+func (b *Bot) handleDigest(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/digest"))
+
+	slog.Info("Processing /digest command",
+		"chat_id", chatID,
+		"argument", arg)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	schedule, err := b.db.GetDeliverySchedule(chatID)
+	if err != nil {
+		slog.Error("Failed to load delivery schedule",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "digest.error"))
+		return
+	}
+
+	if arg == "" {
+		b.sendReply(ctx, chatID, formatDigestSettings(schedule, b.scheduleLocation(schedule), "digest.status", localizer))
+		return
+	}
+
+	wasDigest := schedule.IsDigest()
+	if err := applyDigestArgs(schedule, strings.Fields(arg)); err != nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "digest.invalid"))
+		return
+	}
+
+	// The first digest covers what is added from now on
+	if schedule.IsDigest() && !wasDigest {
+		schedule.LastDigestAt = time.Now()
+	}
+
+	if err := b.db.SetDeliverySchedule(chatID, schedule); err != nil {
+		slog.Error("Failed to save digest settings",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "digest.error"))
+		return
+	}
+
+	b.sendReply(ctx, chatID, formatDigestSettings(schedule, b.scheduleLocation(schedule), "digest.set", localizer))
+}
+
+// applyDigestArgs applies the /digest arguments (mode, weekday and time, in any order) to the schedule
+func applyDigestArgs(schedule *models.DeliverySchedule, args []string) error {
+	for _, arg := range args {
+		lower := strings.ToLower(arg)
+		switch lower {
+		case models.DeliveryModeInstant, "off":
+			schedule.DeliveryMode = models.DeliveryModeInstant
+			continue
+		case models.DeliveryModeDaily, models.DeliveryModeWeekly:
+			schedule.DeliveryMode = lower
+			continue
+		}
+
+		if day, ok := parseWeekday(lower); ok {
+			schedule.DigestDay = day
+			schedule.DeliveryMode = models.DeliveryModeWeekly
+			continue
+		}
+
+		clock, err := parseClock(arg)
+		if err != nil {
+			return fmt.Errorf("invalid digest argument %q", arg)
+		}
+		schedule.DigestTime = formatClock(clock)
+		if !schedule.IsDigest() {
+			schedule.DeliveryMode = models.DeliveryModeDaily
+		}
+	}
+
+	return nil
+}
+
+// parseWeekday parses an English weekday name or its three-letter abbreviation
+func parseWeekday(value string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if value == name || value == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// formatDigestSettings describes the delivery mode; prefix selects the status or confirmation wording
+func formatDigestSettings(schedule *models.DeliverySchedule, loc *time.Location, prefix string, localizer *goi18n.Localizer) string {
+	digestTime := schedule.DigestTime
+	if digestTime == "" {
+		digestTime = digestDefaultTime
+	}
+	data := map[string]interface{}{
+		"Time":     digestTime,
+		"Day":      i18n.T(localizer, weekdayKeys[schedule.DigestDay]),
+		"Timezone": loc.String(),
+	}
+
+	switch schedule.DeliveryMode {
+	case models.DeliveryModeDaily:
+		return i18n.TWithData(localizer, prefix+"_daily", data)
+	case models.DeliveryModeWeekly:
+		return i18n.TWithData(localizer, prefix+"_weekly", data)
+	default:
+		return i18n.T(localizer, prefix+"_instant")
+	}
+}
@@ -0,0 +1,261 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// mockDigestStore implements DigestStore in memory for testing
+type mockDigestStore struct {
+	subscribers []models.Subscriber
+	content     []models.ContentCache
+	sentAt      map[int64]time.Time
+}
+
+func (m *mockDigestStore) GetDigestSubscribers() ([]models.Subscriber, error) {
+	return m.subscribers, nil
+}
+
+func (m *mockDigestStore) GetContentSince(since time.Time) ([]models.ContentCache, error) {
+	var result []models.ContentCache
+	for _, record := range m.content {
+		if record.CreatedAt.After(since) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDigestStore) MarkDigestSent(chatID int64, sentAt time.Time) error {
+	m.sentAt[chatID] = sentAt
+	return nil
+}
+
+// mockDigestDeliverer implements digestDeliverer for testing
+type mockDigestDeliverer struct {
+	unwanted map[string]bool // Item IDs filtered out for every subscriber
+	sendErr  error
+	digests  map[int64][]NotificationContent
+}
+
+func (m *mockDigestDeliverer) scheduleLocation(schedule *models.DeliverySchedule) *time.Location {
+	return time.UTC
+}
+
+func (m *mockDigestDeliverer) wantsContent(_ context.Context, chatID int64, content *NotificationContent) bool {
+	return !m.unwanted[content.ItemID]
+}
+
+func (m *mockDigestDeliverer) deliverDigest(ctx context.Context, chatID int64, mode string, contents []NotificationContent) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+	m.digests[chatID] = contents
+	return nil
+}
+
+func (m *mockDigestDeliverer) handleBlockedRecipient(chatID int64, sendErr error) {}
+
+func cachedContent(id, contentType, title string, createdAt time.Time) models.ContentCache {
+	record := models.ContentCache{JellyfinID: id, Type: contentType, Title: title}
+	record.CreatedAt = createdAt
+	return record
+}
+
+// Test 1: Digest times follow the daily or weekly schedule in the subscriber's time zone
+func TestNextDigestTime(t *testing.T) {
+	loc := time.FixedZone("UTC+3:30", 3*3600+1800)
+	after := time.Date(2024, 3, 6, 21, 0, 0, 0, loc) // Wednesday
+
+	daily := &models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily, DigestTime: "20:00"}
+	if got, want := nextDigestTime(daily, loc, after), time.Date(2024, 3, 7, 20, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Daily: got %v, want %v", got, want)
+	}
+
+	early := &models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily, DigestTime: "22:30"}
+	if got, want := nextDigestTime(early, loc, after), time.Date(2024, 3, 6, 22, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Daily later today: got %v, want %v", got, want)
+	}
+
+	weekly := &models.DeliverySchedule{DeliveryMode: models.DeliveryModeWeekly, DigestTime: "18:00", DigestDay: time.Friday}
+	if got, want := nextDigestTime(weekly, loc, after), time.Date(2024, 3, 8, 18, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Weekly: got %v, want %v", got, want)
+	}
+
+	sameDay := &models.DeliverySchedule{DeliveryMode: models.DeliveryModeWeekly, DigestTime: "20:00", DigestDay: time.Wednesday}
+	if got, want := nextDigestTime(sameDay, loc, after), time.Date(2024, 3, 13, 20, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Weekly already passed today: got %v, want %v", got, want)
+	}
+}
+
+// Test 2: Due digests contain the content added since the last digest that the subscriber wants
+func TestDigestScheduler_SendsDueDigests(t *testing.T) {
+	last := time.Date(2024, 3, 6, 20, 0, 0, 0, time.UTC)
+	store := &mockDigestStore{
+		subscribers: []models.Subscriber{
+			{ChatID: 100, Schedule: models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily, DigestTime: "20:00", LastDigestAt: last}},
+			{ChatID: 200, Schedule: models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily, DigestTime: "22:00", LastDigestAt: last.Add(2 * time.Hour)}},
+		},
+		content: []models.ContentCache{
+			cachedContent("old", "Movie", "Old", last.Add(-time.Hour)),
+			cachedContent("movie", "Movie", "Arrival", last.Add(time.Hour)),
+			cachedContent("hidden", "Movie", "Hidden", last.Add(2*time.Hour)),
+		},
+		sentAt: make(map[int64]time.Time),
+	}
+	deliverer := &mockDigestDeliverer{
+		unwanted: map[string]bool{"hidden": true},
+		digests:  make(map[int64][]NotificationContent),
+	}
+	scheduler := &DigestScheduler{deliverer: deliverer, store: store}
+
+	now := time.Date(2024, 3, 7, 20, 1, 0, 0, time.UTC)
+	scheduler.sendDueDigests(context.Background(), now)
+
+	if got := deliverer.digests[100]; len(got) != 1 || got[0].Title != "Arrival" {
+		t.Errorf("Expected user 100 to get a digest with Arrival only, got %+v", got)
+	}
+	if !store.sentAt[100].Equal(now) {
+		t.Errorf("Expected the digest time of user 100 to be recorded")
+	}
+	if _, ok := deliverer.digests[200]; ok {
+		t.Error("User 200's digest isn't due before 22:00")
+	}
+}
+
+// Test 3: A failed digest is retried and an empty digest isn't sent
+func TestDigestScheduler_FailureAndEmptyDigest(t *testing.T) {
+	last := time.Date(2024, 3, 6, 20, 0, 0, 0, time.UTC)
+	store := &mockDigestStore{
+		subscribers: []models.Subscriber{
+			{ChatID: 100, Schedule: models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily, LastDigestAt: last}},
+		},
+		content: []models.ContentCache{cachedContent("movie", "Movie", "Arrival", last.Add(time.Hour))},
+		sentAt:  make(map[int64]time.Time),
+	}
+	deliverer := &mockDigestDeliverer{sendErr: errors.New("timeout"), digests: make(map[int64][]NotificationContent)}
+	scheduler := &DigestScheduler{deliverer: deliverer, store: store}
+
+	now := time.Date(2024, 3, 7, 21, 0, 0, 0, time.UTC)
+	scheduler.sendDueDigests(context.Background(), now)
+	if _, ok := store.sentAt[100]; ok {
+		t.Fatal("A failed digest must not be marked as sent")
+	}
+
+	// Nothing new: the period is closed without sending anything
+	store.content = nil
+	deliverer.sendErr = nil
+	scheduler.sendDueDigests(context.Background(), now)
+	if _, ok := deliverer.digests[100]; ok {
+		t.Error("Empty digests should not be sent")
+	}
+	if !store.sentAt[100].Equal(now) {
+		t.Error("Expected the empty digest period to be closed")
+	}
+}
+
+// Test 4: Digest messages group movies, episodes per series and other content
+func TestFormatDigest(t *testing.T) {
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+	localizer := i18n.GetLocalizer(bundle, "en")
+
+	message := formatDigest(models.DeliveryModeWeekly, []NotificationContent{
+		{Type: "Movie", Title: "Arrival", Year: 2016},
+		{Type: "Episode", SeriesName: "Severance", EpisodeNumber: 1},
+		{Type: "Episode", SeriesName: "Severance", EpisodeNumber: 2},
+		{Type: "Episode", SeriesName: "Dark", EpisodeNumber: 1},
+		{Type: "Book", Title: "Dune"},
+	}, localizer)
+
+	for _, want := range []string{
+		"weekly digest — 5 new items",
+		"🎬 Movies\n• Arrival (2016)",
+		"• Severance: 2 new episodes\n• Dark: 1 new episode",
+		"• 📚 Book: Dune",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected digest to contain %q, got:\n%s", want, message)
+		}
+	}
+}
+
+// Test 5: /digest arguments set the mode, weekday and time
+func TestApplyDigestArgs(t *testing.T) {
+	schedule := &models.DeliverySchedule{}
+	if err := applyDigestArgs(schedule, []string{"weekly", "fri", "18:30"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if schedule.DeliveryMode != models.DeliveryModeWeekly || schedule.DigestDay != time.Friday || schedule.DigestTime != "18:30" {
+		t.Errorf("Unexpected schedule: %+v", schedule)
+	}
+
+	schedule = &models.DeliverySchedule{}
+	applyDigestArgs(schedule, []string{"7:00"})
+	if schedule.DeliveryMode != models.DeliveryModeDaily || schedule.DigestTime != "07:00" {
+		t.Errorf("A time alone should enable the daily digest, got %+v", schedule)
+	}
+
+	applyDigestArgs(schedule, []string{"instant"})
+	if schedule.IsDigest() {
+		t.Error("Expected instant mode")
+	}
+
+	if err := applyDigestArgs(schedule, []string{"hourly"}); err == nil {
+		t.Error("Expected an error for an unknown argument")
+	}
+}
+
+// Test 6: Digest subscribers don't receive instant notifications
+func TestResolveRecipients_SkipsDigestSubscribers(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{100, 200}
+	db.SetDeliverySchedule(200, &models.DeliverySchedule{DeliveryMode: models.DeliveryModeDaily})
+	bot := &Bot{db: db}
+
+	recipients, _, err := bot.resolveRecipients(context.Background(), &NotificationContent{ItemID: "movie", Type: "Movie"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != 100 {
+		t.Errorf("Expected only the instant subscriber, got %v", recipients)
+	}
+}
+
+// Test 7: The digest summary is sent before the posters, which aren't sent when the summary fails
+func TestDeliverDigest_SummaryBeforePosters(t *testing.T) {
+	contents := []NotificationContent{
+		{ItemID: "movie1", Type: "Movie", Title: "Dune"},
+		{ItemID: "movie2", Type: "Movie", Title: "Arrival"},
+	}
+
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = &mockJellyfinClient{posterData: []byte("poster")}
+	if err := b.deliverDigest(context.Background(), 100, models.DeliveryModeDaily, contents); err != nil {
+		t.Fatalf("Failed to deliver digest: %v", err)
+	}
+	if len(api.messages) != 2 || api.messages[0].method != "sendMessage" || api.messages[1].method != "sendMediaGroup" {
+		t.Errorf("Expected the summary followed by the posters, got %+v", api.messages)
+	}
+
+	// The fake API can't send messages, so the digest fails before any poster is posted
+	failing := &fakeTelegramAPI{}
+	b = newCallbackTestBot(t, failing, newMockSubscriberDB())
+	b.jellyfinClient = &mockJellyfinClient{posterData: []byte("poster")}
+	if err := b.deliverDigest(context.Background(), 100, models.DeliveryModeDaily, contents); err == nil {
+		t.Fatal("Expected the failed summary to fail the digest")
+	}
+	if called := failing.called(); slices.Contains(called, "sendMediaGroup") || slices.Contains(called, "sendPhoto") {
+		t.Errorf("Expected no posters after a failed summary, got %v", called)
+	}
+}
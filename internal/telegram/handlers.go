@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/internal/metrics"
+	"jellyfin-telegram-bot/pkg/models"
 
 	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
 
@@ -17,6 +19,7 @@ import (
 )
 
 // handleStart handles the /start command
+// In approval and invite mode it may carry an invite code: "/start <code>" (from a t.me deep link)
 func (b *Bot) handleStart(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
 	if update.Message == nil {
 		return
@@ -33,6 +36,10 @@ func (b *Bot) handleStart(ctx context.Context, _ *bot.Bot, update *botModels.Upd
 		"first_name", firstName,
 		"telegram_lang", telegramLangCode)
 
+	if !b.admitSubscriber(ctx, update) {
+		return
+	}
+
 	// Add subscriber to database
 	err := b.db.AddSubscriber(chatID, username, firstName)
 	if err != nil {
@@ -46,23 +53,44 @@ func (b *Bot) handleStart(ctx context.Context, _ *bot.Bot, update *botModels.Upd
 		return
 	}
 
-	// Only auto-detect and save language if user doesn't have a saved preference
-	savedLang, err := b.db.GetLanguage(chatID)
-	if err != nil || savedLang == "" {
-		// No saved preference, detect from Telegram and save it
-		detectedLang := i18n.DetectLanguage(telegramLangCode, i18n.SupportedLanguages)
-		if err := b.db.SetLanguage(chatID, detectedLang); err != nil {
-			slog.Warn("Failed to set language preference",
-				"chat_id", chatID,
-				"detected_lang", detectedLang,
-				"error", err)
-		}
-	}
+	b.saveDetectedLanguage(chatID, telegramLangCode)
 
 	// Get localizer for user (will use saved preference if exists)
 	localizer := b.getLocalizerForUser(ctx, chatID, telegramLangCode)
 
-	// Send welcome message
+	b.sendWelcome(ctx, chatID, localizer)
+
+	// Get final language for logging
+	finalLang, _ := b.db.GetLanguage(chatID)
+	if finalLang == "" {
+		finalLang = "unknown"
+	}
+
+	slog.Info("User subscribed successfully",
+		"chat_id", chatID,
+		"username", username,
+		"language", finalLang)
+}
+
+// saveDetectedLanguage stores the language detected from Telegram, unless the user already chose one
+func (b *Bot) saveDetectedLanguage(chatID int64, telegramLangCode string) {
+	savedLang, err := b.db.GetLanguage(chatID)
+	if err == nil && savedLang != "" {
+		return
+	}
+
+	// No saved preference, detect from Telegram and save it
+	detectedLang := i18n.DetectLanguage(telegramLangCode, i18n.SupportedLanguages)
+	if err := b.db.SetLanguage(chatID, detectedLang); err != nil {
+		slog.Warn("Failed to set language preference",
+			"chat_id", chatID,
+			"detected_lang", detectedLang,
+			"error", err)
+	}
+}
+
+// sendWelcome sends the welcome message with the main menu buttons
+func (b *Bot) sendWelcome(ctx context.Context, chatID int64, localizer *goi18n.Localizer) {
 	welcomeMessage := i18n.T(localizer, "welcome.message")
 
 	// Create inline keyboard with 2x2 button grid
@@ -80,7 +108,7 @@ func (b *Bot) handleStart(ctx context.Context, _ *bot.Bot, update *botModels.Upd
 	}
 
 	// Send message with inline keyboard
-	err = b.SendMessageWithKeyboard(ctx, chatID, welcomeMessage, keyboard)
+	err := b.SendMessageWithKeyboard(ctx, chatID, welcomeMessage, keyboard)
 	if err != nil {
 		slog.Error("Failed to send welcome message with keyboard",
 			"chat_id", chatID,
@@ -93,19 +121,7 @@ func (b *Bot) handleStart(ctx context.Context, _ *bot.Bot, update *botModels.Upd
 				"chat_id", chatID,
 				"error", err)
 		}
-		return
-	}
-
-	// Get final language for logging
-	finalLang, _ := b.db.GetLanguage(chatID)
-	if finalLang == "" {
-		finalLang = "unknown"
 	}
-
-	slog.Info("User subscribed successfully",
-		"chat_id", chatID,
-		"username", username,
-		"language", finalLang)
 }
 
 // handleRecent handles the /recent command
@@ -120,33 +136,7 @@ func (b *Bot) handleRecent(ctx context.Context, _ *bot.Bot, update *botModels.Up
 	slog.Info("Processing /recent command", "chat_id", chatID)
 
 	localizer := b.getLocalizerForUser(ctx, chatID, telegramLangCode)
-
-	// Fetch recent items from Jellyfin
-	items, err := b.jellyfinClient.GetRecentItems(ctx, 15)
-	if err != nil {
-		slog.Error("Failed to fetch recent items",
-			"chat_id", chatID,
-			"error", err)
-
-		errorMsg := i18n.T(localizer, "recent.error")
-		b.SendMessage(ctx, chatID, errorMsg)
-		return
-	}
-
-	// Handle empty results
-	if len(items) == 0 {
-		b.SendMessage(ctx, chatID, i18n.T(localizer, "recent.no_results"))
-		return
-	}
-
-	// Send each item with poster and formatted message
-	for _, item := range items {
-		b.sendContentItem(ctx, chatID, &item, localizer)
-	}
-
-	slog.Info("Sent recent items",
-		"chat_id", chatID,
-		"count", len(items))
+	b.sendBrowseList(ctx, chatID, "", localizer)
 }
 
 // handleSearch handles the /search command
@@ -175,37 +165,7 @@ func (b *Bot) handleSearch(ctx context.Context, _ *bot.Bot, update *botModels.Up
 		return
 	}
 
-	// Search content in Jellyfin
-	items, err := b.jellyfinClient.SearchContent(ctx, query, 10)
-	if err != nil {
-		slog.Error("Failed to search content",
-			"chat_id", chatID,
-			"query", query,
-			"error", err)
-
-		errorMsg := i18n.T(localizer, "search.error")
-		b.SendMessage(ctx, chatID, errorMsg)
-		return
-	}
-
-	// Handle empty results
-	if len(items) == 0 {
-		noResultsMsg := i18n.TWithData(localizer, "search.no_results", map[string]interface{}{
-			"Query": query,
-		})
-		b.SendMessage(ctx, chatID, noResultsMsg)
-		return
-	}
-
-	// Send each item with poster and formatted message
-	for _, item := range items {
-		b.sendContentItem(ctx, chatID, &item, localizer)
-	}
-
-	slog.Info("Sent search results",
-		"chat_id", chatID,
-		"query", query,
-		"count", len(items))
+	b.sendBrowseList(ctx, chatID, query, localizer)
 }
 
 // handleMutedList handles the /mutedlist command
@@ -259,7 +219,7 @@ func (b *Bot) handleMutedList(ctx context.Context, _ *bot.Bot, update *botModels
 		buttons = append(buttons, []botModels.InlineKeyboardButton{
 			{
 				Text:         buttonText,
-				CallbackData: fmt.Sprintf("unmute:%s", series.SeriesID),
+				CallbackData: b.unmuteCallbackData(&series),
 			},
 		})
 	}
@@ -412,15 +372,36 @@ func (b *Bot) sendContentItem(ctx context.Context, chatID int64, item *ContentIt
 	// Format message using i18n
 	message := FormatContentMessage(item, localizer)
 
+	// Share opens the chat picker with an inline query that posts this item
+	rows := b.itemLinkRows(item.ItemID, item.Type, item.TrailerURL, item.ProviderIDs, localizer)
+	var actions []botModels.InlineKeyboardButton
+	if follow := b.followButton(item.Type, item.ItemID, item.Name, item.SeriesID, item.SeriesName, localizer); follow != nil {
+		actions = append(actions, *follow)
+	}
+	if item.Name != "" {
+		actions = append(actions, botModels.InlineKeyboardButton{
+			Text:              i18n.T(localizer, "button.share"),
+			SwitchInlineQuery: item.Name,
+		})
+	}
+	if len(actions) > 0 {
+		rows = append(rows, actions)
+	}
+	var keyboard *botModels.InlineKeyboardMarkup
+	if len(rows) > 0 {
+		keyboard = &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}
+	}
+
 	// Try to fetch and send poster image
 	imageData, err := b.jellyfinClient.GetPosterImage(ctx, item.ItemID)
 	if err != nil {
+		metrics.PosterFetchErrors.Inc()
 		slog.Warn("Failed to fetch poster image, sending text only",
 			"item_id", item.ItemID,
 			"error", err)
 
 		// Send text message only if image fetch fails
-		if err := b.SendMessage(ctx, chatID, message); err != nil {
+		if err := b.sendContentText(ctx, chatID, message, keyboard); err != nil {
 			slog.Error("Failed to send content message",
 				"chat_id", chatID,
 				"item_id", item.ItemID,
@@ -430,14 +411,19 @@ func (b *Bot) sendContentItem(ctx context.Context, chatID int64, item *ContentIt
 	}
 
 	// Send photo with caption
-	if err := b.SendPhotoBytes(ctx, chatID, imageData, message); err != nil {
+	if keyboard != nil {
+		err = b.SendPhotoBytesWithKeyboard(ctx, chatID, imageData, message, keyboard)
+	} else {
+		err = b.SendPhotoBytes(ctx, chatID, imageData, message)
+	}
+	if err != nil {
 		slog.Error("Failed to send content photo",
 			"chat_id", chatID,
 			"item_id", item.ItemID,
 			"error", err)
 
 		// Fallback to text message if photo send fails
-		if err := b.SendMessage(ctx, chatID, message); err != nil {
+		if err := b.sendContentText(ctx, chatID, message, keyboard); err != nil {
 			slog.Error("Failed to send fallback content message",
 				"chat_id", chatID,
 				"item_id", item.ItemID,
@@ -446,6 +432,14 @@ func (b *Bot) sendContentItem(ctx context.Context, chatID int64, item *ContentIt
 	}
 }
 
+// sendContentText sends a content message without poster, with its link buttons if there are any
+func (b *Bot) sendContentText(ctx context.Context, chatID int64, message string, keyboard *botModels.InlineKeyboardMarkup) error {
+	if keyboard != nil {
+		return b.SendMessageWithKeyboard(ctx, chatID, message, keyboard)
+	}
+	return b.SendMessage(ctx, chatID, message)
+}
+
 // FormatContentMessage formats a content item for display using i18n
 func FormatContentMessage(item *ContentItem, localizer *goi18n.Localizer) string {
 	var message strings.Builder
@@ -476,6 +470,24 @@ func FormatContentMessage(item *ContentItem, localizer *goi18n.Localizer) string
 				"Name": item.Name,
 			}))
 		}
+	} else if indicator, ok := contentTypeIndicators[item.Type]; ok {
+		message.WriteString(i18n.T(localizer, indicator))
+		message.WriteString("\n\n")
+		if item.Type == models.ItemTypeSeason && item.SeriesName != "" {
+			message.WriteString(i18n.TWithData(localizer, "content.field.series", map[string]interface{}{
+				"SeriesName": item.SeriesName,
+			}))
+			message.WriteString("\n")
+		}
+		message.WriteString(i18n.TWithData(localizer, "content.field.name", map[string]interface{}{
+			"Name": item.Name,
+		}))
+		if item.Artist != "" {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "content.field.artist", map[string]interface{}{
+				"Artist": item.Artist,
+			}))
+		}
 	}
 
 	// Production year
@@ -509,3 +521,13 @@ func FormatContentMessage(item *ContentItem, localizer *goi18n.Localizer) string
 
 	return message.String()
 }
+
+// contentTypeIndicators maps the item types without a dedicated format above to their type indicator
+var contentTypeIndicators = map[string]string{
+	models.ItemTypeSeries:     "content.type.series",
+	models.ItemTypeSeason:     "content.type.season",
+	models.ItemTypeMusicAlbum: "content.type.music_album",
+	models.ItemTypeAudio:      "content.type.audio",
+	models.ItemTypeAudioBook:  "content.type.audiobook",
+	models.ItemTypeBook:       "content.type.book",
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/internal/metrics"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// inlineResultLimit is the number of search results offered for an inline query
+const inlineResultLimit = 20
+
+// inlineCacheTime is how many seconds Telegram may cache the results of an inline query
+const inlineCacheTime = 300
+
+// Telegram limits for inline results
+const (
+	maxCaptionLength     = 1024
+	maxDescriptionLength = 100
+)
+
+// PosterPath is the path the poster proxy is served on, reserved by the config validation
+const PosterPath = config.PosterPath
+
+// handleInlineQuery answers "@bot <query>" typed in any chat with matching library items
+// Choosing a result posts the item card, with poster when the poster proxy is reachable
+func (b *Bot) handleInlineQuery(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	inlineQuery := update.InlineQuery
+	query := strings.TrimSpace(inlineQuery.Query)
+
+	var userID int64
+	var languageCode string
+	if inlineQuery.From != nil {
+		userID = inlineQuery.From.ID
+		languageCode = inlineQuery.From.LanguageCode
+	}
+
+	slog.Info("Processing inline query",
+		"user_id", userID,
+		"query", query)
+
+	results := []botModels.InlineQueryResult{}
+	if query != "" {
+		localizer := b.getLocalizerForUser(ctx, userID, languageCode)
+
+		// The user's chat ID with the bot is their user ID, so linked accounts apply here too
+		items, err := b.searchItems(ctx, userID, query, inlineResultLimit)
+		if err != nil {
+			slog.Error("Failed to search content for inline query",
+				"user_id", userID,
+				"query", query,
+				"error", err)
+		}
+		for i := range items {
+			results = append(results, b.inlineResult(&items[i], localizer))
+		}
+	}
+
+	_, err := botInstance.AnswerInlineQuery(ctx, &bot.AnswerInlineQueryParams{
+		InlineQueryID: inlineQuery.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		// Results depend on the user's linked Jellyfin account
+		IsPersonal: true,
+	})
+	if err != nil {
+		slog.Error("Failed to answer inline query",
+			"user_id", userID,
+			"error", err)
+	}
+}
+
+// inlineResult builds the inline result for an item: a photo with the card as caption when
+// posters can be served, an article posting the card as text otherwise
+func (b *Bot) inlineResult(item *ContentItem, localizer *goi18n.Localizer) botModels.InlineQueryResult {
+	card := FormatContentMessage(item, localizer)
+	title := item.Name
+	if item.SeriesName != "" && item.SeriesName != item.Name {
+		title = item.SeriesName + " – " + item.Name
+	}
+	description := truncateText(item.Overview, maxDescriptionLength)
+
+	var keyboard botModels.ReplyMarkup
+	if rows := b.itemLinkRows(item.ItemID, item.Type, item.TrailerURL, item.ProviderIDs, localizer); len(rows) > 0 {
+		keyboard = &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}
+	}
+
+	if posterURL := b.posterURL(item.ItemID); posterURL != "" {
+		return &botModels.InlineQueryResultPhoto{
+			ID:           item.ItemID,
+			PhotoURL:     posterURL,
+			ThumbnailURL: posterURL,
+			Title:        title,
+			Description:  description,
+			Caption:      truncateText(card, maxCaptionLength),
+			ReplyMarkup:  keyboard,
+		}
+	}
+
+	return &botModels.InlineQueryResultArticle{
+		ID:                  item.ItemID,
+		Title:               title,
+		Description:         description,
+		InputMessageContent: &botModels.InputTextMessageContent{MessageText: card},
+		ReplyMarkup:         keyboard,
+	}
+}
+
+// truncateText shortens text to at most limit characters, ending it with an ellipsis
+func truncateText(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
+
+// posterURL returns the public URL of an item's poster on the proxy, or "" without a public URL
+// URLs are signed so the proxy can't be used to fetch arbitrary items
+func (b *Bot) posterURL(itemID string) string {
+	if b.config == nil || itemID == "" {
+		return ""
+	}
+	base := b.config.PublicBaseURL()
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s%s%s.jpg?sig=%s", base, PosterPath, url.PathEscape(itemID), b.posterSignature(itemID))
+}
+
+// posterSignature signs an item ID for the poster proxy
+func (b *Bot) posterSignature(itemID string) string {
+	mac := hmac.New(sha256.New, b.posterKey)
+	mac.Write([]byte(itemID))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// PosterHandler returns the HTTP handler serving item posters from Jellyfin to Telegram
+// It is mounted on PosterPath of the webhook server, since Jellyfin itself is usually not public
+func (b *Bot) PosterHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		itemID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, PosterPath), ".jpg")
+		signature := r.URL.Query().Get("sig")
+		if itemID == "" || !hmac.Equal([]byte(signature), []byte(b.posterSignature(itemID))) {
+			http.NotFound(w, r)
+			return
+		}
+
+		imageData, err := b.jellyfinClient.GetPosterImage(r.Context(), itemID)
+		if err != nil {
+			metrics.PosterFetchErrors.Inc()
+			slog.Warn("Failed to fetch poster for proxy",
+				"item_id", itemID,
+				"error", err)
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Write(imageData)
+	})
+}
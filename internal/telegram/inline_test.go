@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jellyfin-telegram-bot/internal/config"
+
+	botModels "github.com/go-telegram/bot/models"
+)
+
+// Test 1: Without a public URL results are articles, with one they are photos from the signed poster proxy
+func TestInlineResult_ArticleOrPhoto(t *testing.T) {
+	localizer := getTestLocalizer()
+	item := &ContentItem{
+		ItemID:   "movie2",
+		Name:     "Interstellar",
+		Type:     "Movie",
+		Overview: strings.Repeat("A space adventure. ", 20),
+	}
+
+	b := &Bot{config: &config.Config{}, posterKey: []byte("key")}
+	article, ok := b.inlineResult(item, localizer).(*botModels.InlineQueryResultArticle)
+	if !ok {
+		t.Fatal("Expected an article result without a public URL")
+	}
+	if content, ok := article.InputMessageContent.(*botModels.InputTextMessageContent); !ok || !strings.Contains(content.MessageText, "Interstellar") {
+		t.Errorf("Expected the article to post the item card, got %+v", article.InputMessageContent)
+	}
+	if len([]rune(article.Description)) > maxDescriptionLength {
+		t.Errorf("Expected the description to be truncated, got %d characters", len([]rune(article.Description)))
+	}
+
+	b.config.Webhook.PublicURL = "https://bot.example.com"
+	photo, ok := b.inlineResult(item, localizer).(*botModels.InlineQueryResultPhoto)
+	if !ok {
+		t.Fatal("Expected a photo result with a public URL")
+	}
+	want := "https://bot.example.com/posters/movie2.jpg?sig=" + b.posterSignature("movie2")
+	if photo.PhotoURL != want {
+		t.Errorf("Expected poster URL %q, got %q", want, photo.PhotoURL)
+	}
+}
+
+// Test 2: An inline query is answered with the search results
+func TestHandleInlineQuery(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = NewMockJellyfinClient()
+
+	b.handleInlineQuery(context.Background(), b.bot, &botModels.Update{
+		InlineQuery: &botModels.InlineQuery{ID: "q1", From: &botModels.User{ID: 200}, Query: "interstellar"},
+	})
+
+	if answers := len(api.sent("answerInlineQuery")); answers != 1 {
+		t.Errorf("Expected the inline query to be answered once, got %d", answers)
+	}
+}
+
+// Test 3: The poster proxy only serves items with a valid signature
+func TestPosterHandler(t *testing.T) {
+	b := &Bot{jellyfinClient: NewMockJellyfinClient(), posterKey: []byte("key")}
+	handler := b.PosterHandler()
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"valid signature", PosterPath + "movie2.jpg?sig=" + b.posterSignature("movie2"), http.StatusOK},
+		{"signature of another item", PosterPath + "movie2.jpg?sig=" + b.posterSignature("movie1"), http.StatusNotFound},
+		{"missing signature", PosterPath + "movie2.jpg", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if tt.wantStatus == http.StatusOK && recorder.Body.String() != "fake-image-data" {
+				t.Errorf("Expected the poster to be served, got %q", recorder.Body.String())
+			}
+		})
+	}
+}
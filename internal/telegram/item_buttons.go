@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"net/url"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// itemLinkRows returns the keyboard rows linking to an item: "Open in Jellyfin" and the trailer,
+// then IMDb and TMDB. Links that aren't known are left out
+func (b *Bot) itemLinkRows(itemID, itemType, trailerURL string, providerIDs map[string]string, localizer *goi18n.Localizer) [][]botModels.InlineKeyboardButton {
+	var watch, external []botModels.InlineKeyboardButton
+
+	if link := b.jellyfinItemURL(itemID); link != "" {
+		watch = append(watch, botModels.InlineKeyboardButton{
+			Text: i18n.T(localizer, "button.open_in_jellyfin"),
+			URL:  link,
+		})
+	}
+	// Telegram rejects the whole message if a button has an invalid URL
+	if isWebURL(trailerURL) {
+		watch = append(watch, botModels.InlineKeyboardButton{
+			Text: i18n.T(localizer, "button.trailer"),
+			URL:  trailerURL,
+		})
+	}
+
+	if id := providerID(providerIDs, "Imdb"); id != "" {
+		external = append(external, botModels.InlineKeyboardButton{
+			Text: i18n.T(localizer, "button.imdb"),
+			URL:  "https://www.imdb.com/title/" + url.PathEscape(id) + "/",
+		})
+	}
+	if link := tmdbURL(itemType, providerID(providerIDs, "Tmdb")); link != "" {
+		external = append(external, botModels.InlineKeyboardButton{
+			Text: i18n.T(localizer, "button.tmdb"),
+			URL:  link,
+		})
+	}
+
+	var rows [][]botModels.InlineKeyboardButton
+	for _, row := range [][]botModels.InlineKeyboardButton{watch, external} {
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// jellyfinItemURL returns the Jellyfin web page of an item, or "" without a public URL
+func (b *Bot) jellyfinItemURL(itemID string) string {
+	if b.config == nil || b.config.Jellyfin.PublicURL == "" || itemID == "" || strings.HasPrefix(itemID, "test-") {
+		return ""
+	}
+	return b.config.Jellyfin.PublicURL + "/web/#/details?id=" + url.QueryEscape(itemID)
+}
+
+// tmdbURL returns the TMDB page of a movie or series; TMDB IDs of other items don't have their own page
+func tmdbURL(itemType, id string) string {
+	if id == "" {
+		return ""
+	}
+	switch itemType {
+	case models.ItemTypeMovie:
+		return "https://www.themoviedb.org/movie/" + url.PathEscape(id)
+	case models.ItemTypeSeries:
+		return "https://www.themoviedb.org/tv/" + url.PathEscape(id)
+	default:
+		return ""
+	}
+}
+
+// providerID looks up an external ID; Jellyfin's provider names aren't consistently cased
+func providerID(providerIDs map[string]string, provider string) string {
+	for name, id := range providerIDs {
+		if strings.EqualFold(name, provider) {
+			return strings.TrimSpace(id)
+		}
+	}
+	return ""
+}
+
+// isWebURL reports whether a link is an absolute http or https URL
+func isWebURL(link string) bool {
+	parsed, err := url.Parse(link)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/internal/i18n"
+)
+
+// Test 1: Link buttons come first and the mute and follow buttons share the last row
+func TestNotificationKeyboard_LinksAndMute(t *testing.T) {
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+	localizer := i18n.GetLocalizer(bundle, "en")
+
+	bot := &Bot{
+		db:     newMockSubscriberDB(),
+		config: &config.Config{Jellyfin: config.JellyfinConfig{PublicURL: "https://watch.example.com"}},
+	}
+	content := &NotificationContent{
+		ItemID:      "ep1",
+		Type:        "Episode",
+		SeriesID:    "series1",
+		SeriesName:  "Severance",
+		TrailerURL:  "https://www.youtube.com/watch?v=xEQP4VVuyrY",
+		ProviderIDs: map[string]string{"imdb": "tt11280740", "Tmdb": "95396"},
+	}
+
+	keyboard := bot.notificationKeyboard(content, localizer)
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 3 {
+		t.Fatalf("Expected watch, external and mute rows, got %+v", keyboard)
+	}
+
+	watch := keyboard.InlineKeyboard[0]
+	if len(watch) != 2 || watch[0].URL != "https://watch.example.com/web/#/details?id=ep1" || watch[1].URL != content.TrailerURL {
+		t.Errorf("Unexpected watch row: %+v", watch)
+	}
+	// Episodes have no TMDB page of their own
+	external := keyboard.InlineKeyboard[1]
+	if len(external) != 1 || external[0].URL != "https://www.imdb.com/title/tt11280740/" {
+		t.Errorf("Unexpected external row: %+v", external)
+	}
+	series := keyboard.InlineKeyboard[2]
+	if len(series) != 2 || !strings.HasPrefix(series[0].CallbackData, "mute:") || !strings.HasPrefix(series[1].CallbackData, "follow:") {
+		t.Errorf("Expected the mute and follow buttons in the last row, got %+v", series)
+	}
+}
+
+// Test 2: Without a public URL or valid links a movie gets no keyboard at all
+func TestNotificationKeyboard_NoLinks(t *testing.T) {
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+	localizer := i18n.GetLocalizer(bundle, "en")
+
+	bot := &Bot{db: newMockSubscriberDB(), config: &config.Config{}}
+	content := &NotificationContent{
+		ItemID:     "movie1",
+		Type:       "Movie",
+		TrailerURL: "plugin://plugin.video.youtube/?action=play_video&videoid=abc",
+	}
+
+	if keyboard := bot.notificationKeyboard(content, localizer); keyboard != nil {
+		t.Errorf("Expected no keyboard, got %+v", keyboard.InlineKeyboard)
+	}
+
+	content.ProviderIDs = map[string]string{"Tmdb": "438631"}
+	keyboard := bot.notificationKeyboard(content, localizer)
+	if keyboard == nil || keyboard.InlineKeyboard[0][0].URL != "https://www.themoviedb.org/movie/438631" {
+		t.Errorf("Expected a TMDB movie link, got %+v", keyboard)
+	}
+}
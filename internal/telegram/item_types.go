@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// itemTypeLabels maps each supported item type to its display label
+var itemTypeLabels = map[string]string{
+	models.ItemTypeMovie:      "item_type.movie",
+	models.ItemTypeEpisode:    "item_type.episode",
+	models.ItemTypeSeries:     "item_type.series",
+	models.ItemTypeSeason:     "item_type.season",
+	models.ItemTypeMusicAlbum: "item_type.music_album",
+	models.ItemTypeAudio:      "item_type.audio",
+	models.ItemTypeAudioBook:  "item_type.audiobook",
+	models.ItemTypeBook:       "item_type.book",
+}
+
+// handleTypes handles the /types command
+func (b *Bot) handleTypes(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+
+	slog.Info("Processing /types command", "chat_id", chatID)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+	b.sendTypesKeyboard(ctx, chatID, localizer)
+}
+
+// sendTypesKeyboard sends the item type toggles to a chat
+func (b *Bot) sendTypesKeyboard(ctx context.Context, chatID int64, localizer *goi18n.Localizer) {
+	keyboard, err := b.createTypesKeyboard(chatID, localizer)
+	if err != nil {
+		slog.Error("Failed to load item type preferences",
+			"chat_id", chatID,
+			"error", err)
+
+		if err := b.SendMessage(ctx, chatID, i18n.T(localizer, "types.error")); err != nil {
+			slog.Error("Failed to send error message", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	if err := b.SendMessageWithKeyboard(ctx, chatID, i18n.T(localizer, "types.select"), keyboard); err != nil {
+		slog.Error("Failed to send item type selection",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handleTypesCallback toggles an item type from the /types keyboard
+func (b *Bot) handleTypesCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	if update.CallbackQuery == nil {
+		return
+	}
+
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	callbackData := callbackQuery.Data
+
+	slog.Info("Processing item type callback",
+		"chat_id", chatID,
+		"callback_data", callbackData)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	// Parse item type from callback data (format: "types:{ItemType}")
+	parts := strings.SplitN(callbackData, ":", 2)
+	if len(parts) != 2 || !models.IsSupportedItemType(parts[1]) {
+		slog.Error("Invalid item type callback data",
+			"callback_data", callbackData)
+
+		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: callbackQuery.ID,
+			Text:            i18n.T(localizer, "error.invalid_callback"),
+			ShowAlert:       false,
+		})
+		return
+	}
+
+	itemType := parts[1]
+
+	enabled, err := b.db.IsItemTypeEnabled(chatID, itemType)
+	if err == nil {
+		enabled = !enabled
+		err = b.db.SetItemTypeEnabled(chatID, itemType, enabled)
+	}
+	if err != nil {
+		slog.Error("Failed to toggle item type",
+			"chat_id", chatID,
+			"item_type", itemType,
+			"error", err)
+
+		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: callbackQuery.ID,
+			Text:            i18n.T(localizer, "types.error"),
+			ShowAlert:       false,
+		})
+		return
+	}
+
+	answerKey := "types.disabled"
+	if enabled {
+		answerKey = "types.enabled"
+	}
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+		Text: i18n.TWithData(localizer, answerKey, map[string]interface{}{
+			"Type": i18n.T(localizer, itemTypeLabels[itemType]),
+		}),
+		ShowAlert: false,
+	})
+
+	// Refresh the keyboard so it shows the new state
+	keyboard, err := b.createTypesKeyboard(chatID, localizer)
+	if err != nil {
+		slog.Warn("Failed to rebuild item type keyboard",
+			"chat_id", chatID,
+			"error", err)
+		return
+	}
+
+	_, err = botInstance.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:      chatID,
+		MessageID:   callbackQuery.Message.Message.ID,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to edit message markup",
+			"chat_id", chatID,
+			"message_id", callbackQuery.Message.Message.ID,
+			"error", err)
+	}
+
+	slog.Info("Item type preference updated",
+		"chat_id", chatID,
+		"item_type", itemType,
+		"enabled", enabled)
+}
+
+// createTypesKeyboard creates the inline keyboard showing the state of every item type
+func (b *Bot) createTypesKeyboard(chatID int64, localizer *goi18n.Localizer) (*botModels.InlineKeyboardMarkup, error) {
+	disabled, err := b.db.GetDisabledItemTypes(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	disabledSet := make(map[string]bool, len(disabled))
+	for _, itemType := range disabled {
+		disabledSet[itemType] = true
+	}
+
+	// Two buttons per row
+	rows := make([][]botModels.InlineKeyboardButton, 0, (len(models.SupportedItemTypes)+1)/2)
+	for i, itemType := range models.SupportedItemTypes {
+		buttonKey := "types.button.enabled"
+		if disabledSet[itemType] {
+			buttonKey = "types.button.disabled"
+		}
+
+		button := botModels.InlineKeyboardButton{
+			Text: i18n.TWithData(localizer, buttonKey, map[string]interface{}{
+				"Type": i18n.T(localizer, itemTypeLabels[itemType]),
+			}),
+			CallbackData: fmt.Sprintf("types:%s", itemType),
+		}
+
+		if i%2 == 0 {
+			rows = append(rows, []botModels.InlineKeyboardButton{button})
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+
+	return &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
@@ -0,0 +1,326 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/internal/jellyfin"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// quickConnectPollInterval is how often a pending Quick Connect request is checked
+const quickConnectPollInterval = 5 * time.Second
+
+// quickConnectTimeout is how long a user has to approve a Quick Connect code
+const quickConnectTimeout = 5 * time.Minute
+
+// JellyfinAccounts defines the interface for Jellyfin user authentication and per-user queries
+type JellyfinAccounts interface {
+	AuthenticateByName(ctx context.Context, deviceID, username, password string) (*models.JellyfinAuthResult, error)
+	InitiateQuickConnect(ctx context.Context, deviceID string) (*models.QuickConnectResult, error)
+	GetQuickConnectState(ctx context.Context, deviceID, secret string) (*models.QuickConnectResult, error)
+	AuthenticateWithQuickConnect(ctx context.Context, deviceID, secret string) (*models.JellyfinAuthResult, error)
+	Logout(ctx context.Context, deviceID, token string) error
+	IsItemVisible(ctx context.Context, userID, itemID string) (bool, error)
+	SearchContentForUser(ctx context.Context, userID, query string, limit int) ([]ContentItem, error)
+}
+
+// SetJellyfinAccounts enables /link and per-user library permissions
+func (b *Bot) SetJellyfinAccounts(accounts JellyfinAccounts) {
+	b.accounts = accounts
+}
+
+// linkDeviceID identifies a subscriber's chat as a Jellyfin device
+func linkDeviceID(chatID int64) string {
+	return fmt.Sprintf("telegram-%d", chatID)
+}
+
+// handleLink handles the /link command
+// Usage: "/link" shows the linked account, "/link quickconnect" links with a Quick Connect code and
+// "/link <username> <password>" links with the account's credentials
+func (b *Bot) handleLink(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+	fields := strings.Fields(update.Message.Text)
+
+	// The arguments may hold a password, so only the subcommand is logged
+	slog.Info("Processing /link command",
+		"chat_id", chatID,
+		"with_args", len(fields) > 1)
+
+	if b.accounts == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.unavailable"))
+		return
+	}
+
+	if len(fields) < 2 {
+		b.sendLinkStatus(ctx, chatID, localizer)
+		return
+	}
+
+	subscribed, err := b.db.IsSubscribed(chatID)
+	if err != nil || !subscribed {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.not_subscribed"))
+		return
+	}
+
+	if len(fields) == 2 && strings.EqualFold(fields[1], "quickconnect") {
+		b.linkWithQuickConnect(ctx, chatID, localizer)
+		return
+	}
+
+	if len(fields) < 3 {
+		b.sendLinkStatus(ctx, chatID, localizer)
+		return
+	}
+
+	// Remove the credentials from the chat before anything else
+	if _, err := b.bot.DeleteMessage(ctx, &bot.DeleteMessageParams{
+		ChatID:    chatID,
+		MessageID: update.Message.ID,
+	}); err != nil {
+		slog.Warn("Failed to delete /link message with credentials",
+			"chat_id", chatID,
+			"error", err)
+	}
+
+	// Passwords may contain spaces, so everything after the username is the password
+	username := fields[1]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(update.Message.Text), fields[0]))
+	password := strings.TrimSpace(strings.TrimPrefix(rest, username))
+
+	result, err := b.accounts.AuthenticateByName(ctx, linkDeviceID(chatID), username, password)
+	if err != nil {
+		if errors.Is(err, jellyfin.ErrUnauthorized) {
+			b.sendReply(ctx, chatID, i18n.T(localizer, "link.invalid_credentials"))
+			return
+		}
+		slog.Error("Failed to authenticate Jellyfin user",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.error"))
+		return
+	}
+
+	b.completeLink(ctx, chatID, result, localizer)
+}
+
+// sendLinkStatus shows the linked account and how to link one
+func (b *Bot) sendLinkStatus(ctx context.Context, chatID int64, localizer *goi18n.Localizer) {
+	status := i18n.T(localizer, "link.status_unlinked")
+	if link, err := b.db.GetJellyfinLink(chatID); err == nil && link.IsLinked() {
+		status = i18n.TWithData(localizer, "link.status_linked", map[string]interface{}{
+			"Name": link.JellyfinUserName,
+		})
+	}
+	b.sendReply(ctx, chatID, status+"\n\n"+i18n.T(localizer, "link.usage"))
+}
+
+// linkWithQuickConnect shows a Quick Connect code and links the account once the user approves it
+// Handlers run in their own goroutine, so waiting for the approval doesn't block other updates
+func (b *Bot) linkWithQuickConnect(ctx context.Context, chatID int64, localizer *goi18n.Localizer) {
+	deviceID := linkDeviceID(chatID)
+
+	request, err := b.accounts.InitiateQuickConnect(ctx, deviceID)
+	if err != nil {
+		slog.Error("Failed to initiate Quick Connect",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.quickconnect_unavailable"))
+		return
+	}
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "link.quickconnect_code", map[string]interface{}{
+		"Code":    request.Code,
+		"Minutes": int(quickConnectTimeout.Minutes()),
+	}))
+
+	ticker := time.NewTicker(quickConnectPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(quickConnectTimeout)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			b.sendReply(ctx, chatID, i18n.T(localizer, "link.quickconnect_expired"))
+			return
+		case <-ticker.C:
+		}
+
+		state, err := b.accounts.GetQuickConnectState(ctx, deviceID, request.Secret)
+		if err != nil {
+			slog.Warn("Quick Connect request no longer available",
+				"chat_id", chatID,
+				"error", err)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "link.quickconnect_expired"))
+			return
+		}
+		if !state.Authenticated {
+			continue
+		}
+
+		result, err := b.accounts.AuthenticateWithQuickConnect(ctx, deviceID, request.Secret)
+		if err != nil {
+			slog.Error("Failed to authenticate with Quick Connect",
+				"chat_id", chatID,
+				"error", err)
+			b.sendReply(ctx, chatID, i18n.T(localizer, "link.error"))
+			return
+		}
+
+		b.completeLink(ctx, chatID, result, localizer)
+		return
+	}
+}
+
+// completeLink stores the authenticated Jellyfin user for the subscriber
+// The bot only needs the user ID, so the session of the access token is ended right away
+func (b *Bot) completeLink(ctx context.Context, chatID int64, result *models.JellyfinAuthResult, localizer *goi18n.Localizer) {
+	if err := b.accounts.Logout(ctx, linkDeviceID(chatID), result.AccessToken); err != nil {
+		slog.Warn("Failed to end Jellyfin session after linking",
+			"chat_id", chatID,
+			"error", err)
+	}
+
+	link := &models.JellyfinLink{
+		JellyfinUserID:   result.User.ID,
+		JellyfinUserName: result.User.Name,
+	}
+	if err := b.db.SetJellyfinLink(chatID, link); err != nil {
+		slog.Error("Failed to save Jellyfin link",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.error"))
+		return
+	}
+
+	slog.Info("Linked Jellyfin account",
+		"chat_id", chatID,
+		"jellyfin_user", result.User.Name)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "link.success", map[string]interface{}{
+		"Name": result.User.Name,
+	}))
+}
+
+// handleUnlink handles the /unlink command
+func (b *Bot) handleUnlink(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	slog.Info("Processing /unlink command", "chat_id", chatID)
+
+	link, err := b.db.GetJellyfinLink(chatID)
+	if err != nil {
+		slog.Error("Failed to load Jellyfin link",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.error"))
+		return
+	}
+	if !link.IsLinked() {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.not_linked"))
+		return
+	}
+
+	if err := b.db.SetJellyfinLink(chatID, &models.JellyfinLink{}); err != nil {
+		slog.Error("Failed to remove Jellyfin link",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "link.error"))
+		return
+	}
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "link.unlinked", map[string]interface{}{
+		"Name": link.JellyfinUserName,
+	}))
+}
+
+// linkedUserID returns the Jellyfin user ID a subscriber linked, or "" when per-user access doesn't apply
+func (b *Bot) linkedUserID(chatID int64) (string, error) {
+	if b.accounts == nil {
+		return "", nil
+	}
+	link, err := b.db.GetJellyfinLink(chatID)
+	if err != nil {
+		return "", err
+	}
+	return link.JellyfinUserID, nil
+}
+
+// itemPage fetches one page of items and their total count, limited to the libraries of the
+// subscriber's linked account
+func (b *Bot) itemPage(ctx context.Context, chatID int64, query models.ItemQuery) ([]ContentItem, int, error) {
+	userID, err := b.linkedUserID(chatID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load jellyfin link: %w", err)
+	}
+	query.UserID = userID
+	return b.jellyfinClient.QueryItems(ctx, query)
+}
+
+// searchItems searches content, limited to the libraries of the subscriber's linked account
+func (b *Bot) searchItems(ctx context.Context, chatID int64, query string, limit int) ([]ContentItem, error) {
+	userID, err := b.linkedUserID(chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jellyfin link: %w", err)
+	}
+	if userID == "" {
+		return b.jellyfinClient.SearchContent(ctx, query, limit)
+	}
+	return b.accounts.SearchContentForUser(ctx, userID, query, limit)
+}
+
+// canSeeItem reports whether the subscriber's linked Jellyfin account may access an item
+// Subscribers without a linked account see everything; for linked ones a failed check hides the item,
+// since notifying about content outside their libraries would leak it
+func (b *Bot) canSeeItem(ctx context.Context, chatID int64, itemID string) bool {
+	visible, err := b.checkItemAccess(ctx, chatID, itemID)
+	if err != nil {
+		slog.Error("Failed to check item access, hiding item",
+			"chat_id", chatID,
+			"item_id", itemID,
+			"error", err)
+		return false
+	}
+	return visible
+}
+
+// checkItemAccess reports whether the subscriber's linked Jellyfin account may access an item
+// An error means the access is unknown, e.g. while Jellyfin or the database is unreachable
+func (b *Bot) checkItemAccess(ctx context.Context, chatID int64, itemID string) (bool, error) {
+	if itemID == "" || strings.HasPrefix(itemID, "test-") {
+		return true, nil
+	}
+
+	userID, err := b.linkedUserID(chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load jellyfin link: %w", err)
+	}
+	if userID == "" {
+		return true, nil
+	}
+
+	return b.accounts.IsItemVisible(ctx, userID, itemID)
+}
@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"jellyfin-telegram-bot/internal/jellyfin"
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// mockJellyfinAccounts implements JellyfinAccounts for testing
+type mockJellyfinAccounts struct {
+	passwords  map[string]string          // username -> password
+	visible    map[string]map[string]bool // userID -> itemID -> visible
+	visibleErr error
+	userErrs   map[string]error // Failed checks of single users, e.g. a stale link
+	loggedOut  []string
+	userQuery  string // User ID of the last per-user query
+}
+
+func (m *mockJellyfinAccounts) AuthenticateByName(ctx context.Context, deviceID, username, password string) (*models.JellyfinAuthResult, error) {
+	if m.passwords[username] != password {
+		return nil, jellyfin.ErrUnauthorized
+	}
+	return &models.JellyfinAuthResult{
+		User:        models.JellyfinUser{ID: "id-" + username, Name: username},
+		AccessToken: "token-" + username,
+	}, nil
+}
+
+func (m *mockJellyfinAccounts) InitiateQuickConnect(ctx context.Context, deviceID string) (*models.QuickConnectResult, error) {
+	return nil, errors.New("quick connect disabled")
+}
+
+func (m *mockJellyfinAccounts) GetQuickConnectState(ctx context.Context, deviceID, secret string) (*models.QuickConnectResult, error) {
+	return nil, errors.New("quick connect disabled")
+}
+
+func (m *mockJellyfinAccounts) AuthenticateWithQuickConnect(ctx context.Context, deviceID, secret string) (*models.JellyfinAuthResult, error) {
+	return nil, errors.New("quick connect disabled")
+}
+
+func (m *mockJellyfinAccounts) Logout(ctx context.Context, deviceID, token string) error {
+	m.loggedOut = append(m.loggedOut, token)
+	return nil
+}
+
+func (m *mockJellyfinAccounts) IsItemVisible(ctx context.Context, userID, itemID string) (bool, error) {
+	if m.visibleErr != nil {
+		return false, m.visibleErr
+	}
+	if err := m.userErrs[userID]; err != nil {
+		return false, err
+	}
+	return m.visible[userID][itemID], nil
+}
+
+func (m *mockJellyfinAccounts) SearchContentForUser(ctx context.Context, userID, query string, limit int) ([]ContentItem, error) {
+	m.userQuery = userID
+	return nil, nil
+}
+
+// Test 1: Linked subscribers only get notified about items their Jellyfin account can access
+func TestResolveRecipients_RespectsLibraryPermissions(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{100, 200, 300}
+	db.SetJellyfinLink(200, &models.JellyfinLink{JellyfinUserID: "kid"})
+	db.SetJellyfinLink(300, &models.JellyfinLink{JellyfinUserID: "adult"})
+
+	bot := &Bot{db: db}
+	bot.SetJellyfinAccounts(&mockJellyfinAccounts{
+		visible: map[string]map[string]bool{"adult": {"movie1": true}},
+	})
+
+	recipients, _, err := bot.resolveRecipients(context.Background(), &NotificationContent{
+		ItemID: "movie1",
+		Type:   "Movie",
+		Title:  "Alien",
+	})
+	if err != nil {
+		t.Fatalf("resolveRecipients failed: %v", err)
+	}
+
+	if !slices.Equal(recipients, []int64{100, 300}) {
+		t.Errorf("Expected the unlinked and the permitted user, got %v", recipients)
+	}
+}
+
+// Test 2: A failed permission check hides the item from linked subscribers only
+func TestCanSeeItem_FailsClosedForLinkedUsers(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.SetJellyfinLink(200, &models.JellyfinLink{JellyfinUserID: "kid"})
+
+	bot := &Bot{db: db}
+	bot.SetJellyfinAccounts(&mockJellyfinAccounts{visibleErr: errors.New("jellyfin down")})
+
+	if !bot.canSeeItem(context.Background(), 100, "movie1") {
+		t.Error("Expected unlinked subscribers to see every item")
+	}
+	if bot.canSeeItem(context.Background(), 200, "movie1") {
+		t.Error("Expected a failed check to hide the item from a linked subscriber")
+	}
+}
+
+// Test 3: /link with credentials deletes the message, stores the user and ends the session
+func TestLink_WithPassword(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{200}
+	b := newCallbackTestBot(t, api, db)
+	accounts := &mockJellyfinAccounts{passwords: map[string]string{"alice": "correct horse"}}
+	b.SetJellyfinAccounts(accounts)
+	ctx := context.Background()
+
+	b.handleLink(ctx, b.bot, commandUpdate(200, "/link alice wrong"))
+	if db.links[200] != nil {
+		t.Fatal("Expected a wrong password not to link the account")
+	}
+
+	b.handleLink(ctx, b.bot, commandUpdate(200, "/link alice correct horse"))
+
+	if link := db.links[200]; link == nil || link.JellyfinUserID != "id-alice" {
+		t.Fatalf("Expected the account to be linked, got %+v", link)
+	}
+	if !slices.Equal(accounts.loggedOut, []string{"token-alice"}) {
+		t.Errorf("Expected the session to be ended, got %v", accounts.loggedOut)
+	}
+	if deleted := len(api.sent("deleteMessage")); deleted != 2 {
+		t.Errorf("Expected both messages with credentials to be deleted, got %d", deleted)
+	}
+	for _, message := range api.sent("sendMessage") {
+		if strings.Contains(message.text, "correct horse") {
+			t.Errorf("Expected the password never to be echoed, got %q", message.text)
+		}
+	}
+
+	jellyfinClient := NewMockJellyfinClient()
+	b.jellyfinClient = jellyfinClient
+	b.handleRecent(ctx, b.bot, commandUpdate(200, "/recent"))
+	if jellyfinClient.lastQuery.UserID != "id-alice" {
+		t.Errorf("Expected /recent to run as the linked user, got %q", jellyfinClient.lastQuery.UserID)
+	}
+}
+
+// Test 4: A failed permission check only sets aside that subscriber, whose access is checked again before sending
+func TestResolveRecipients_SetsAsideFailedAccessCheck(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{100, 200, 300, 400}
+	db.SetJellyfinLink(200, &models.JellyfinLink{JellyfinUserID: "kid"})
+	db.SetJellyfinLink(300, &models.JellyfinLink{JellyfinUserID: "stale"})
+	db.SetJellyfinLink(400, &models.JellyfinLink{JellyfinUserID: "adult"})
+
+	bot := &Bot{db: db}
+	bot.SetJellyfinAccounts(&mockJellyfinAccounts{
+		visible:  map[string]map[string]bool{"kid": {"movie1": true}, "adult": {"movie1": true}},
+		userErrs: map[string]error{"stale": errors.New("401 Unauthorized")},
+	})
+	content := &NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Up"}
+
+	recipients, unchecked, err := bot.resolveRecipients(context.Background(), content)
+	if err != nil {
+		t.Fatalf("Expected a failing link not to fail the resolution, got %v", err)
+	}
+	if !slices.Equal(recipients, []int64{100, 200, 400}) {
+		t.Errorf("Expected the subscribers with known access, got %v", recipients)
+	}
+	if !slices.Equal(unchecked, []int64{300}) {
+		t.Errorf("Expected only the failing link to be checked again, got %v", unchecked)
+	}
+}
@@ -3,6 +3,7 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
@@ -10,7 +11,10 @@ import (
 	"time"
 
 	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/internal/metrics"
+	"jellyfin-telegram-bot/pkg/models"
 
+	"github.com/go-telegram/bot"
 	botModels "github.com/go-telegram/bot/models"
 	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
 )
@@ -18,14 +22,33 @@ import (
 // NotificationContent represents content to be broadcasted
 type NotificationContent struct {
 	ItemID        string
-	Type          string // "Movie" or "Episode"
+	Type          string // One of models.SupportedItemTypes
 	Title         string
 	Overview      string
 	Year          int
 	Rating        float64
+	SeriesID      string
 	SeriesName    string
 	SeasonNumber  int
 	EpisodeNumber int
+	Album         string
+	Artist        string
+
+	// Metadata used by subscriber content filters
+	OfficialRating string
+	Genres         []string
+
+	// Resolution, HDR and languages, shown when NOTIFICATION_MEDIA_INFO is enabled
+	Media *models.MediaInfo
+
+	// External pages linked from the notification's buttons
+	TrailerURL  string
+	ProviderIDs map[string]string
+
+	// Set when several episodes of one season are aggregated into a single notification
+	EpisodeCount int
+	FirstEpisode int
+	LastEpisode  int
 }
 
 // FormatNotification formats content for notification message using i18n
@@ -60,6 +83,20 @@ func FormatNotification(content *NotificationContent, localizer *goi18n.Localize
 				"Rating": fmt.Sprintf("%.1f", content.Rating),
 			}))
 		}
+	} else if content.Type == "Episode" && content.EpisodeCount > 1 {
+		// Aggregated season notification format
+		message.WriteString(i18n.T(localizer, "notification.episodes.header"))
+		message.WriteString("\n\n")
+		message.WriteString(i18n.TWithData(localizer, "content.field.series", map[string]interface{}{
+			"SeriesName": content.SeriesName,
+		}))
+		message.WriteString("\n")
+		message.WriteString(i18n.TWithData(localizer, "content.field.episode_batch", map[string]interface{}{
+			"SeasonNumber": content.SeasonNumber,
+			"Count":        content.EpisodeCount,
+			"First":        fmt.Sprintf("%02d", content.FirstEpisode),
+			"Last":         fmt.Sprintf("%02d", content.LastEpisode),
+		}))
 	} else if content.Type == "Episode" {
 		// Episode notification format
 		message.WriteString(i18n.T(localizer, "notification.episode.header"))
@@ -102,15 +139,148 @@ func FormatNotification(content *NotificationContent, localizer *goi18n.Localize
 				"Rating": fmt.Sprintf("%.1f", content.Rating),
 			}))
 		}
+	} else {
+		formatMediaNotification(&message, content, localizer)
 	}
 
 	return message.String()
 }
 
+// notificationHeaders maps the item types without a dedicated format above to their header
+var notificationHeaders = map[string]string{
+	models.ItemTypeSeries:     "notification.series.header",
+	models.ItemTypeSeason:     "notification.season.header",
+	models.ItemTypeMusicAlbum: "notification.music_album.header",
+	models.ItemTypeAudio:      "notification.audio.header",
+	models.ItemTypeAudioBook:  "notification.audiobook.header",
+	models.ItemTypeBook:       "notification.book.header",
+}
+
+// formatMediaNotification formats notifications for series, seasons, music and books
+func formatMediaNotification(message *strings.Builder, content *NotificationContent, localizer *goi18n.Localizer) {
+	header, ok := notificationHeaders[content.Type]
+	if !ok {
+		return
+	}
+
+	message.WriteString(i18n.T(localizer, header))
+	message.WriteString("\n\n")
+
+	switch content.Type {
+	case models.ItemTypeSeason:
+		message.WriteString(i18n.TWithData(localizer, "content.field.series", map[string]interface{}{
+			"SeriesName": content.SeriesName,
+		}))
+		message.WriteString("\n")
+		message.WriteString(i18n.TWithData(localizer, "content.field.season_number", map[string]interface{}{
+			"SeasonNumber": content.SeasonNumber,
+		}))
+	case models.ItemTypeMusicAlbum:
+		message.WriteString(i18n.TWithData(localizer, "content.field.album", map[string]interface{}{
+			"Album": content.Title,
+		}))
+		if content.Artist != "" {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "content.field.artist", map[string]interface{}{
+				"Artist": content.Artist,
+			}))
+		}
+	case models.ItemTypeAudio:
+		message.WriteString(i18n.TWithData(localizer, "content.field.track", map[string]interface{}{
+			"Name": content.Title,
+		}))
+		if content.Artist != "" {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "content.field.artist", map[string]interface{}{
+				"Artist": content.Artist,
+			}))
+		}
+		if content.Album != "" {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "content.field.album", map[string]interface{}{
+				"Album": content.Album,
+			}))
+		}
+	case models.ItemTypeAudioBook, models.ItemTypeBook:
+		message.WriteString(i18n.TWithData(localizer, "content.field.name", map[string]interface{}{
+			"Name": content.Title,
+		}))
+		if content.Artist != "" {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "content.field.author", map[string]interface{}{
+				"Author": content.Artist,
+			}))
+		}
+	default:
+		message.WriteString(i18n.TWithData(localizer, "content.field.name", map[string]interface{}{
+			"Name": content.Title,
+		}))
+	}
+
+	if content.Year > 0 {
+		message.WriteString("\n")
+		message.WriteString(i18n.TWithData(localizer, "content.field.year", map[string]interface{}{
+			"Year": content.Year,
+		}))
+	}
+
+	if content.Overview != "" {
+		message.WriteString("\n\n")
+		message.WriteString(i18n.TWithData(localizer, "content.field.description", map[string]interface{}{
+			"Description": content.Overview,
+		}))
+	}
+
+	if content.Rating > 0 {
+		message.WriteString("\n\n")
+		message.WriteString(i18n.TWithData(localizer, "content.field.rating", map[string]interface{}{
+			"Rating": fmt.Sprintf("%.1f", content.Rating),
+		}))
+	}
+}
+
+// formatMediaInfo formats the quality line of a notification, e.g. "2160p HDR10 · DTS-HD · Audio: EN, FA"
+// It returns "" when nothing is known about the media
+func formatMediaInfo(media *models.MediaInfo, localizer *goi18n.Localizer) string {
+	if media == nil {
+		return ""
+	}
+
+	var parts []string
+	if video := strings.TrimSpace(media.Resolution + " " + media.HDR); video != "" {
+		parts = append(parts, video)
+	}
+	if media.AudioCodec != "" {
+		parts = append(parts, media.AudioCodec)
+	}
+	if len(media.AudioLanguages) > 0 {
+		parts = append(parts, i18n.TWithData(localizer, "content.field.audio_languages", map[string]interface{}{
+			"Languages": strings.Join(media.AudioLanguages, ", "),
+		}))
+	}
+	if len(media.SubtitleLanguages) > 0 {
+		parts = append(parts, i18n.TWithData(localizer, "content.field.subtitle_languages", map[string]interface{}{
+			"Languages": strings.Join(media.SubtitleLanguages, ", "),
+		}))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return i18n.TWithData(localizer, "content.field.media_info", map[string]interface{}{
+		"Details": strings.Join(parts, " · "),
+	})
+}
+
+// isSeriesContent returns true for content that belongs to a series and can be muted
+func isSeriesContent(contentType string) bool {
+	return contentType == models.ItemTypeEpisode || contentType == models.ItemTypeSeason
+}
+
 // shouldShowMuteButton checks if mute button should be shown for this content
 func shouldShowMuteButton(content *NotificationContent) bool {
-	// Only show for episodes, not movies
-	if content.Type != "Episode" {
+	// Only show for episodes and seasons, not movies or other media
+	if !isSeriesContent(content.Type) {
 		return false
 	}
 
@@ -122,31 +292,119 @@ func shouldShowMuteButton(content *NotificationContent) bool {
 	return true
 }
 
+// notificationKeyboard combines the link buttons of the item with the mute and follow buttons of its series
+// It returns nil when the notification has no buttons
+func (b *Bot) notificationKeyboard(content *NotificationContent, localizer *goi18n.Localizer) *botModels.InlineKeyboardMarkup {
+	rows := b.itemLinkRows(content.ItemID, content.Type, content.TrailerURL, content.ProviderIDs, localizer)
+
+	// Mute and follow buttons for episodes with valid series name, follow for new series
+	var seriesRow []botModels.InlineKeyboardButton
+	if shouldShowMuteButton(content) {
+		if mute := b.createMuteButton(content, localizer); mute != nil {
+			seriesRow = append(seriesRow, mute.InlineKeyboard[0]...)
+		}
+	} else if isSeriesContent(content.Type) {
+		slog.Debug("Skipping mute button",
+			"reason", "invalid series name",
+			"series_name", content.SeriesName)
+	}
+	if follow := b.followButton(content.Type, content.ItemID, content.Title, content.SeriesID, content.SeriesName, localizer); follow != nil {
+		seriesRow = append(seriesRow, *follow)
+	}
+	if len(seriesRow) > 0 {
+		rows = append(rows, seriesRow)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
 // createMuteButton creates inline keyboard with mute button using i18n
-func (b *Bot) createMuteButton(seriesName string, localizer *goi18n.Localizer) *botModels.InlineKeyboardMarkup {
+// It returns nil when the series callback token can't be stored
+func (b *Bot) createMuteButton(content *NotificationContent, localizer *goi18n.Localizer) *botModels.InlineKeyboardMarkup {
+	callbackData, err := b.seriesCallbackData("mute", content.SeriesID, content.SeriesName)
+	if err != nil {
+		slog.Warn("Failed to create mute button, sending notification without it",
+			"series_name", content.SeriesName,
+			"error", err)
+		return nil
+	}
+
 	return &botModels.InlineKeyboardMarkup{
 		InlineKeyboard: [][]botModels.InlineKeyboardButton{
 			{
 				{
 					Text:         i18n.T(localizer, "button.mute"),
-					CallbackData: fmt.Sprintf("mute:%s", seriesName),
+					CallbackData: callbackData,
 				},
 			},
 		},
 	}
 }
 
-// BroadcastNotification sends a notification to all active subscribers
-func (b *Bot) BroadcastNotification(ctx context.Context, content *NotificationContent) error {
+// broadcastResult counts the outcome of sending to a list of recipients
+type broadcastResult struct {
+	success int
+	failed  int
+	blocked int
+}
+
+// broadcast calls send for every recipient while staying under Telegram's rate limit
+// Recipients who blocked the bot are marked inactive; progress, if set, is called after each recipient
+func (b *Bot) broadcast(ctx context.Context, recipients []int64, send func(chatID int64) error,
+	progress func(done int, result broadcastResult)) broadcastResult {
+	var result broadcastResult
+
+	for i, chatID := range recipients {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Handle Telegram rate limiting (max 30 messages/second)
+		// Add small delay to avoid hitting rate limits
+		time.Sleep(sendInterval)
+
+		sendErr := send(chatID)
+		if sendErr != nil {
+			if isBlockedError(sendErr) {
+				b.handleBlockedRecipient(chatID, sendErr)
+				result.blocked++
+			} else {
+				slog.Error("Failed to send message",
+					"chat_id", chatID,
+					"error", sendErr)
+				result.failed++
+			}
+		} else {
+			result.success++
+		}
+
+		if progress != nil {
+			progress(i+1, result)
+		}
+	}
+
+	return result
+}
+
+// sendInterval is the delay between two sends to stay under Telegram's rate limit
+const sendInterval = 35 * time.Millisecond
+
+// resolveRecipients returns the active subscribers that should receive the notification
+// Linked subscribers whose access to the item couldn't be checked are returned separately,
+// so their access is checked again before sending instead of holding up everyone else
+func (b *Bot) resolveRecipients(ctx context.Context, content *NotificationContent) ([]int64, []int64, error) {
 	// Get all active subscribers
 	subscribers, err := b.db.GetAllActiveSubscribers()
 	if err != nil {
-		return fmt.Errorf("failed to get subscribers: %w", err)
+		return nil, nil, fmt.Errorf("failed to get subscribers: %w", err)
 	}
 
 	if len(subscribers) == 0 {
 		slog.Info("No active subscribers to notify")
-		return nil
+		return nil, nil, nil
 	}
 
 	// Check if NotifyOnlyTesters mode is enabled (for debugging/testing)
@@ -182,15 +440,44 @@ func (b *Bot) BroadcastNotification(ctx context.Context, content *NotificationCo
 		}
 	}
 
-	// Filter out muted users for episode notifications
+	// Digest subscribers get this content with their next digest instead
+	digestCount := 0
+	tempSubscribers := make([]int64, 0, len(filteredSubscribers))
+	for _, chatID := range filteredSubscribers {
+		schedule, err := b.db.GetDeliverySchedule(chatID)
+		if err != nil {
+			slog.Error("Failed to load delivery mode, including subscriber",
+				"chat_id", chatID,
+				"error", err)
+			// Include subscriber if check fails to avoid missing notifications
+			tempSubscribers = append(tempSubscribers, chatID)
+			continue
+		}
+
+		if schedule.IsDigest() {
+			digestCount++
+		} else {
+			tempSubscribers = append(tempSubscribers, chatID)
+		}
+	}
+	filteredSubscribers = tempSubscribers
+
+	if digestCount > 0 {
+		slog.Info("Left content for digest subscribers",
+			"digest_count", digestCount,
+			"item_id", content.ItemID)
+	}
+
+	// Filter out users who muted the series, or don't follow it in followed-only mode
+	// (counted as muted)
 	mutedCount := 0
 
-	if content.Type == "Episode" && content.SeriesName != "" {
+	if isSeriesContent(content.Type) && content.SeriesName != "" {
 		tempSubscribers := make([]int64, 0, len(filteredSubscribers))
 		for _, chatID := range filteredSubscribers {
-			isMuted, err := b.db.IsSeriesMuted(chatID, content.SeriesName)
+			isHidden, err := b.isSeriesHidden(chatID, content)
 			if err != nil {
-				slog.Error("Failed to check if series is muted, including subscriber",
+				slog.Error("Failed to check if series is muted or followed, including subscriber",
 					"chat_id", chatID,
 					"series_name", content.SeriesName,
 					"error", err)
@@ -199,7 +486,7 @@ func (b *Bot) BroadcastNotification(ctx context.Context, content *NotificationCo
 				continue
 			}
 
-			if !isMuted {
+			if !isHidden {
 				tempSubscribers = append(tempSubscribers, chatID)
 			} else {
 				mutedCount++
@@ -208,143 +495,240 @@ func (b *Bot) BroadcastNotification(ctx context.Context, content *NotificationCo
 		filteredSubscribers = tempSubscribers
 
 		if mutedCount > 0 {
-			slog.Info("Filtered muted users",
-				"muted_count", mutedCount,
+			slog.Info("Filtered users who muted or don't follow the series",
+				"filtered_count", mutedCount,
 				"series_name", content.SeriesName)
 		}
 	}
 
-	if len(filteredSubscribers) == 0 {
-		slog.Info("No subscribers to notify after filtering",
-			"total_subscribers", len(subscribers),
-			"muted_count", mutedCount)
-		return nil
-	}
-
-	slog.Info("Broadcasting notification",
-		"content_type", content.Type,
-		"title", content.Title,
-		"subscriber_count", len(filteredSubscribers),
-		"filtered_count", mutedCount)
-
-	// Fetch poster image
-	var imageData []byte
-	if content.ItemID != "" {
-		imageData, err = b.jellyfinClient.GetPosterImage(ctx, content.ItemID)
+	// Filter out users who opted out of this item type
+	optedOutCount := 0
+	tempSubscribers = make([]int64, 0, len(filteredSubscribers))
+	for _, chatID := range filteredSubscribers {
+		enabled, err := b.db.IsItemTypeEnabled(chatID, content.Type)
 		if err != nil {
-			slog.Warn("Failed to fetch poster image for notification",
-				"item_id", content.ItemID,
+			slog.Error("Failed to check item type preference, including subscriber",
+				"chat_id", chatID,
+				"item_type", content.Type,
 				"error", err)
-			// Continue without image
+			// Include subscriber if check fails to avoid missing notifications
+			tempSubscribers = append(tempSubscribers, chatID)
+			continue
+		}
+
+		if enabled {
+			tempSubscribers = append(tempSubscribers, chatID)
+		} else {
+			optedOutCount++
 		}
 	}
+	filteredSubscribers = tempSubscribers
 
-	// Track broadcast statistics
-	successCount := 0
-	failureCount := 0
-	blockedCount := 0
+	if optedOutCount > 0 {
+		slog.Info("Filtered users who opted out of item type",
+			"opted_out_count", optedOutCount,
+			"item_type", content.Type)
+	}
 
-	// Broadcast to all filtered subscribers with their language preference
+	// Filter out users whose content preferences exclude this item
+	preferenceCount := 0
+	tempSubscribers = make([]int64, 0, len(filteredSubscribers))
 	for _, chatID := range filteredSubscribers {
-		// Handle Telegram rate limiting (max 30 messages/second)
-		// Add small delay to avoid hitting rate limits
-		time.Sleep(35 * time.Millisecond)
-
-		// Get user's language preference for localized message
-		localizer := b.getLocalizerForUser(ctx, chatID, "")
-
-		// Format notification message with user's language
-		message := FormatNotification(content, localizer)
-
-		// Create inline keyboard for episodes with valid series name
-		var keyboard *botModels.InlineKeyboardMarkup
-		if shouldShowMuteButton(content) {
-			keyboard = b.createMuteButton(content.SeriesName, localizer)
-		} else if !shouldShowMuteButton(content) && content.Type == "Episode" {
-			slog.Debug("Skipping mute button",
-				"reason", "invalid series name",
-				"series_name", content.SeriesName)
+		prefs, err := b.db.GetContentPreferences(chatID)
+		if err != nil {
+			slog.Error("Failed to load content preferences, including subscriber",
+				"chat_id", chatID,
+				"error", err)
+			// Include subscriber if check fails to avoid missing notifications
+			tempSubscribers = append(tempSubscribers, chatID)
+			continue
 		}
 
-		var sendErr error
-		if imageData != nil && len(imageData) > 0 {
-			// Send with image
-			if keyboard != nil {
-				sendErr = b.SendPhotoBytesWithKeyboard(ctx, chatID, imageData, message, keyboard)
-			} else {
-				sendErr = b.SendPhotoBytes(ctx, chatID, imageData, message)
-			}
+		if prefs.Allows(content.Rating, content.Year, content.OfficialRating, content.Genres) {
+			tempSubscribers = append(tempSubscribers, chatID)
 		} else {
-			// Send text only
-			if keyboard != nil {
-				sendErr = b.SendMessageWithKeyboard(ctx, chatID, message, keyboard)
-			} else {
-				sendErr = b.SendMessage(ctx, chatID, message)
-			}
+			preferenceCount++
 		}
+	}
+	filteredSubscribers = tempSubscribers
 
-		if sendErr != nil {
-			// Check if bot was blocked by user
-			errorStr := sendErr.Error()
-			if strings.Contains(errorStr, "blocked") || strings.Contains(errorStr, "user is deactivated") ||
-				strings.Contains(errorStr, "bot was blocked") || strings.Contains(errorStr, "chat not found") {
-				slog.Warn("Bot blocked by user or chat not found, marking inactive",
-					"chat_id", chatID,
-					"error", sendErr)
+	if preferenceCount > 0 {
+		slog.Info("Filtered users by content preferences",
+			"filtered_count", preferenceCount,
+			"item_id", content.ItemID)
+	}
 
-				// Mark subscriber as inactive
-				if err := b.db.RemoveSubscriber(chatID); err != nil {
-					slog.Error("Failed to mark subscriber as inactive",
-						"chat_id", chatID,
-						"error", err)
-				}
-				blockedCount++
-			} else {
-				slog.Error("Failed to send notification",
+	// Filter out users whose linked Jellyfin account can't access this item
+	hiddenCount := 0
+	var unchecked []int64
+	if b.accounts != nil {
+		tempSubscribers = make([]int64, 0, len(filteredSubscribers))
+		for _, chatID := range filteredSubscribers {
+			visible, err := b.checkItemAccess(ctx, chatID, content.ItemID)
+			if err != nil {
+				slog.Warn("Failed to check item access, checking again before sending",
 					"chat_id", chatID,
-					"error", sendErr)
-				failureCount++
+					"item_id", content.ItemID,
+					"error", err)
+				unchecked = append(unchecked, chatID)
+				continue
 			}
-		} else {
-			successCount++
+			if visible {
+				tempSubscribers = append(tempSubscribers, chatID)
+			} else {
+				hiddenCount++
+			}
+		}
+		filteredSubscribers = tempSubscribers
+
+		if hiddenCount > 0 {
+			slog.Info("Filtered users without access to item",
+				"filtered_count", hiddenCount,
+				"item_id", content.ItemID)
 		}
 	}
 
-	slog.Info("Broadcast completed",
-		"total_subscribers", len(subscribers),
-		"muted_subscribers", mutedCount,
-		"sent_to", len(filteredSubscribers),
-		"success", successCount,
-		"failures", failureCount,
-		"blocked", blockedCount)
+	if len(filteredSubscribers) == 0 && len(unchecked) == 0 {
+		slog.Info("No subscribers to notify after filtering",
+			"total_subscribers", len(subscribers),
+			"digest_count", digestCount,
+			"muted_count", mutedCount,
+			"opted_out_count", optedOutCount,
+			"preference_filtered_count", preferenceCount,
+			"hidden_count", hiddenCount)
+		return nil, nil, nil
+	}
+
+	slog.Info("Resolved notification recipients",
+		"content_type", content.Type,
+		"title", content.Title,
+		"subscriber_count", len(filteredSubscribers),
+		"unchecked_count", len(unchecked),
+		"filtered_count", digestCount+mutedCount+optedOutCount+preferenceCount+hiddenCount)
+
+	return filteredSubscribers, unchecked, nil
+}
+
+// fetchPoster fetches the poster image for the notification, returning nil if unavailable
+func (b *Bot) fetchPoster(ctx context.Context, content *NotificationContent) []byte {
+	if content.ItemID == "" {
+		return nil
+	}
+
+	imageData, err := b.jellyfinClient.GetPosterImage(ctx, content.ItemID)
+	if err != nil {
+		metrics.PosterFetchErrors.Inc()
+		slog.Warn("Failed to fetch poster image for notification",
+			"item_id", content.ItemID,
+			"error", err)
+		// Continue without image
+		return nil
+	}
 
-	return nil
+	return imageData
 }
 
-// BroadcastNotificationWithRetry sends notification with retry logic for failures
-func (b *Bot) BroadcastNotificationWithRetry(ctx context.Context, content *NotificationContent, maxRetries int) error {
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt) * time.Second
-			slog.Info("Retrying broadcast after backoff",
-				"attempt", attempt,
-				"backoff", backoff)
-			time.Sleep(backoff)
+// deliverNotification sends a notification to a single subscriber in their language
+// It returns the sent message so the notification can be revised later
+func (b *Bot) deliverNotification(ctx context.Context, chatID int64, content *NotificationContent, imageData []byte) (*botModels.Message, error) {
+	// Get user's language preference for localized message
+	localizer := b.getLocalizerForUser(ctx, chatID, "")
+	message := b.notificationMessage(content, localizer)
+	keyboard := b.notificationKeyboard(content, localizer)
+
+	if len(imageData) > 0 {
+		// Send with image
+		params := &bot.SendPhotoParams{
+			ChatID:  chatID,
+			Photo:   &botModels.InputFileUpload{Data: bytes.NewReader(imageData), Filename: "poster.jpg"},
+			Caption: message,
+		}
+		if keyboard != nil {
+			params.ReplyMarkup = keyboard
 		}
+		return b.bot.SendPhoto(ctx, params)
+	}
+
+	// Send text only
+	params := &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   message,
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	return b.bot.SendMessage(ctx, params)
+}
 
-		err := b.BroadcastNotification(ctx, content)
-		if err == nil {
-			return nil
+// notificationMessage formats the text or caption of a notification in the given language
+func (b *Bot) notificationMessage(content *NotificationContent, localizer *goi18n.Localizer) string {
+	message := FormatNotification(content, localizer)
+	if b.config != nil && b.config.Notification.ShowMediaInfo {
+		if line := formatMediaInfo(content.Media, localizer); line != "" {
+			message += "\n\n" + line
 		}
+	}
+	return message
+}
+
+// isBlockedError checks if a send error means the recipient can no longer be reached
+func isBlockedError(err error) bool {
+	errorStr := err.Error()
+	return strings.Contains(errorStr, "blocked") || strings.Contains(errorStr, "user is deactivated") ||
+		strings.Contains(errorStr, "bot was blocked") || strings.Contains(errorStr, "chat not found")
+}
 
-		lastErr = err
-		slog.Warn("Broadcast attempt failed",
-			"attempt", attempt,
+// handleBlockedRecipient marks a subscriber who blocked the bot as inactive
+func (b *Bot) handleBlockedRecipient(chatID int64, sendErr error) {
+	slog.Warn("Bot blocked by user or chat not found, marking inactive",
+		"chat_id", chatID,
+		"error", sendErr)
+
+	if err := b.db.RemoveSubscriber(chatID); err != nil {
+		slog.Error("Failed to mark subscriber as inactive",
+			"chat_id", chatID,
 			"error", err)
 	}
+}
+
+// formatSummaryLine formats content as a single line for catch-up and summary messages
+func formatSummaryLine(content *NotificationContent, localizer *goi18n.Localizer) string {
+	title := content.Title
+	if content.Year > 0 {
+		title = fmt.Sprintf("%s (%d)", title, content.Year)
+	}
+
+	switch {
+	case content.Type == models.ItemTypeMovie:
+		return i18n.TWithData(localizer, "summary.movie", map[string]interface{}{
+			"Title": title,
+		})
+	case content.Type == models.ItemTypeEpisode && content.EpisodeCount > 1:
+		return i18n.TWithData(localizer, "summary.episodes", map[string]interface{}{
+			"SeriesName": content.SeriesName,
+			"Season":     fmt.Sprintf("%02d", content.SeasonNumber),
+			"Count":      content.EpisodeCount,
+			"First":      fmt.Sprintf("%02d", content.FirstEpisode),
+			"Last":       fmt.Sprintf("%02d", content.LastEpisode),
+		})
+	case content.Type == models.ItemTypeEpisode:
+		return i18n.TWithData(localizer, "summary.episode", map[string]interface{}{
+			"SeriesName": content.SeriesName,
+			"Season":     fmt.Sprintf("%02d", content.SeasonNumber),
+			"Episode":    fmt.Sprintf("%02d", content.EpisodeNumber),
+		})
+	}
 
-	return fmt.Errorf("broadcast failed after %d attempts: %w", maxRetries, lastErr)
+	if content.Type == models.ItemTypeSeason && content.SeriesName != "" {
+		title = fmt.Sprintf("%s — %s", content.SeriesName, content.Title)
+	}
+
+	indicator, ok := contentTypeIndicators[content.Type]
+	if !ok {
+		return title
+	}
+	return i18n.TWithData(localizer, "summary.item", map[string]interface{}{
+		"Type":  i18n.T(localizer, indicator),
+		"Title": title,
+	})
 }
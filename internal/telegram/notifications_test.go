@@ -4,22 +4,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"jellyfin-telegram-bot/internal/config"
 	"jellyfin-telegram-bot/internal/i18n"
 	"jellyfin-telegram-bot/pkg/models"
 
 	botModels "github.com/go-telegram/bot/models"
 	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
 )
 
 // mockSubscriberDB implements SubscriberDB interface for testing
 type mockSubscriberDB struct {
-	subscribers  []int64
-	languages    map[int64]string          // chatID -> languageCode
-	mutedSeries  map[int64]map[string]bool // chatID -> seriesID -> isMuted
-	addSubErr    error
-	removeSubErr error
+	subscribers   []int64
+	languages     map[int64]string            // chatID -> languageCode
+	mutedSeries   map[int64]map[string]bool   // chatID -> seriesID -> isMuted
+	followed      map[int64]map[string]string // chatID -> seriesID -> seriesName
+	episodeModes  map[int64]string
+	disabledTypes map[int64]map[string]bool // chatID -> itemType -> isDisabled
+	preferences   map[int64]*models.ContentPreferences
+	schedules     map[int64]*models.DeliverySchedule
+	links         map[int64]*models.JellyfinLink
+	tokens        map[string]*models.CallbackToken
+	addSubErr     error
+	removeSubErr  error
 }
 
 func newMockSubscriberDB() *mockSubscriberDB {
@@ -112,6 +123,166 @@ func (m *mockSubscriberDB) IsSeriesMuted(chatID int64, seriesID string) (bool, e
 	return false, nil
 }
 
+func (m *mockSubscriberDB) AddFollowedSeries(chatID int64, seriesID string, seriesName string) error {
+	if m.followed == nil {
+		m.followed = make(map[int64]map[string]string)
+	}
+	if m.followed[chatID] == nil {
+		m.followed[chatID] = make(map[string]string)
+	}
+	m.followed[chatID][seriesID] = seriesName
+	delete(m.mutedSeries[chatID], seriesID)
+	return nil
+}
+
+func (m *mockSubscriberDB) RemoveFollowedSeries(chatID int64, seriesID string) error {
+	if _, ok := m.followed[chatID][seriesID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(m.followed[chatID], seriesID)
+	return nil
+}
+
+func (m *mockSubscriberDB) GetFollowedSeriesByUser(chatID int64) ([]models.MutedSeries, error) {
+	var result []models.MutedSeries
+	for seriesID, seriesName := range m.followed[chatID] {
+		result = append(result, models.MutedSeries{
+			ChatID:     chatID,
+			SeriesID:   seriesID,
+			SeriesName: seriesName,
+			Kind:       models.SeriesKindFollow,
+		})
+	}
+	return result, nil
+}
+
+func (m *mockSubscriberDB) IsSeriesFollowed(chatID int64, seriesID string) (bool, error) {
+	_, ok := m.followed[chatID][seriesID]
+	return ok, nil
+}
+
+func (m *mockSubscriberDB) GetEpisodeMode(chatID int64) (string, error) {
+	if mode := m.episodeModes[chatID]; mode != "" {
+		return mode, nil
+	}
+	return models.EpisodeModeAll, nil
+}
+
+func (m *mockSubscriberDB) SetEpisodeMode(chatID int64, mode string) error {
+	if m.episodeModes == nil {
+		m.episodeModes = make(map[int64]string)
+	}
+	m.episodeModes[chatID] = mode
+	return nil
+}
+
+func (m *mockSubscriberDB) GetDisabledItemTypes(chatID int64) ([]string, error) {
+	var result []string
+	for itemType, disabled := range m.disabledTypes[chatID] {
+		if disabled {
+			result = append(result, itemType)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSubscriberDB) IsItemTypeEnabled(chatID int64, itemType string) (bool, error) {
+	return !m.disabledTypes[chatID][itemType], nil
+}
+
+func (m *mockSubscriberDB) SetItemTypeEnabled(chatID int64, itemType string, enabled bool) error {
+	if m.disabledTypes == nil {
+		m.disabledTypes = make(map[int64]map[string]bool)
+	}
+	if m.disabledTypes[chatID] == nil {
+		m.disabledTypes[chatID] = make(map[string]bool)
+	}
+	m.disabledTypes[chatID][itemType] = !enabled
+	return nil
+}
+
+func (m *mockSubscriberDB) GetContentPreferences(chatID int64) (*models.ContentPreferences, error) {
+	if prefs, ok := m.preferences[chatID]; ok {
+		return prefs, nil
+	}
+	return &models.ContentPreferences{}, nil
+}
+
+func (m *mockSubscriberDB) SetContentPreferences(chatID int64, prefs *models.ContentPreferences) error {
+	if m.preferences == nil {
+		m.preferences = make(map[int64]*models.ContentPreferences)
+	}
+	m.preferences[chatID] = prefs
+	return nil
+}
+
+func (m *mockSubscriberDB) GetDeliverySchedule(chatID int64) (*models.DeliverySchedule, error) {
+	if schedule, ok := m.schedules[chatID]; ok {
+		copied := *schedule
+		return &copied, nil
+	}
+	return &models.DeliverySchedule{}, nil
+}
+
+func (m *mockSubscriberDB) SetDeliverySchedule(chatID int64, schedule *models.DeliverySchedule) error {
+	if m.schedules == nil {
+		m.schedules = make(map[int64]*models.DeliverySchedule)
+	}
+	m.schedules[chatID] = schedule
+	return nil
+}
+
+func (m *mockSubscriberDB) GetJellyfinLink(chatID int64) (*models.JellyfinLink, error) {
+	if link, ok := m.links[chatID]; ok {
+		return link, nil
+	}
+	return &models.JellyfinLink{}, nil
+}
+
+func (m *mockSubscriberDB) SetJellyfinLink(chatID int64, link *models.JellyfinLink) error {
+	if m.links == nil {
+		m.links = make(map[int64]*models.JellyfinLink)
+	}
+	m.links[chatID] = link
+	return nil
+}
+
+func (m *mockSubscriberDB) SaveCallbackToken(token *models.CallbackToken) error {
+	if m.tokens == nil {
+		m.tokens = make(map[string]*models.CallbackToken)
+	}
+	m.tokens[token.Token] = token
+	return nil
+}
+
+func (m *mockSubscriberDB) GetCallbackToken(token string) (*models.CallbackToken, error) {
+	record, ok := m.tokens[token]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return record, nil
+}
+
+func (m *mockSubscriberDB) FindCallbackToken(action, payload string, validUntil time.Time) (*models.CallbackToken, error) {
+	for _, record := range m.tokens {
+		if record.Action == action && record.Payload == payload && !record.IsExpired(validUntil) {
+			return record, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockSubscriberDB) DeleteExpiredCallbackTokens(now time.Time) (int64, error) {
+	var deleted int64
+	for token, record := range m.tokens {
+		if record.IsExpired(now) {
+			delete(m.tokens, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 // mockJellyfinClient implements JellyfinClient interface for testing
 type mockJellyfinClient struct {
 	posterData []byte
@@ -130,6 +301,10 @@ func (m *mockJellyfinClient) GetPosterImage(ctx context.Context, itemID string)
 	return m.posterData, m.posterErr
 }
 
+func (m *mockJellyfinClient) QueryItems(ctx context.Context, query models.ItemQuery) ([]ContentItem, int, error) {
+	return nil, 0, nil
+}
+
 // Helper function to get Persian localizer for testing (notifications test version)
 func getPersianTestLocalizer() *goi18n.Localizer {
 	bundle, err := i18n.InitBundle()
@@ -195,7 +370,7 @@ func (tb *testBotWrapper) SendPhotoBytesWithKeyboard(ctx context.Context, chatID
 	return nil
 }
 
-// broadcastNotificationForTest is a test-friendly version of BroadcastNotification
+// broadcastNotificationForTest mirrors how a notification is filtered and sent to subscribers
 func (tb *testBotWrapper) broadcastNotificationForTest(ctx context.Context, content *NotificationContent) error {
 	// Get all active subscribers
 	subscribers, err := tb.db.GetAllActiveSubscribers()
@@ -267,7 +442,7 @@ func (tb *testBotWrapper) broadcastNotificationForTest(ctx context.Context, cont
 	return nil
 }
 
-// Test 1: BroadcastNotification excludes muted users from subscriber list
+// Test 1: Broadcasts exclude muted users from subscriber list
 func TestBroadcastNotification_ExcludesMutedUsers(t *testing.T) {
 	db := newMockSubscriberDB()
 	db.subscribers = []int64{100, 200, 300}
@@ -289,7 +464,7 @@ func TestBroadcastNotification_ExcludesMutedUsers(t *testing.T) {
 	ctx := context.Background()
 	err := bot.broadcastNotificationForTest(ctx, content)
 	if err != nil {
-		t.Fatalf("BroadcastNotification failed: %v", err)
+		t.Fatalf("Broadcast failed: %v", err)
 	}
 
 	// Check that only users 100 and 300 received the notification
@@ -324,7 +499,7 @@ func TestBroadcastNotification_MutedUserDoesNotReceive(t *testing.T) {
 	ctx := context.Background()
 	err := bot.broadcastNotificationForTest(ctx, content)
 	if err != nil {
-		t.Fatalf("BroadcastNotification failed: %v", err)
+		t.Fatalf("Broadcast failed: %v", err)
 	}
 
 	if len(bot.sentMessages[123]) != 0 {
@@ -351,7 +526,7 @@ func TestBroadcastNotification_NonMutedUsersReceive(t *testing.T) {
 	ctx := context.Background()
 	err := bot.broadcastNotificationForTest(ctx, content)
 	if err != nil {
-		t.Fatalf("BroadcastNotification failed: %v", err)
+		t.Fatalf("Broadcast failed: %v", err)
 	}
 
 	if len(bot.sentMessages[100]) != 1 {
@@ -382,7 +557,7 @@ func TestBroadcastNotification_MovieNotAffectedByMuting(t *testing.T) {
 	ctx := context.Background()
 	err := bot.broadcastNotificationForTest(ctx, content)
 	if err != nil {
-		t.Fatalf("BroadcastNotification failed: %v", err)
+		t.Fatalf("Broadcast failed: %v", err)
 	}
 
 	// User should receive the movie notification despite having muted series
@@ -410,7 +585,7 @@ func TestBroadcastNotification_InlineMuteButtonOnEpisodes(t *testing.T) {
 	ctx := context.Background()
 	err := bot.broadcastNotificationForTest(ctx, content)
 	if err != nil {
-		t.Fatalf("BroadcastNotification failed: %v", err)
+		t.Fatalf("Broadcast failed: %v", err)
 	}
 
 	// Check that keyboard was sent
@@ -425,8 +600,8 @@ func TestBroadcastNotification_InlineMuteButtonOnEpisodes(t *testing.T) {
 
 	button := keyboard.InlineKeyboard[0][0]
 	// Button text should be "Mute" in Persian
-	if button.Text != "دنبال نکردن" {
-		t.Errorf("Expected button text 'دنبال نکردن', got '%s'", button.Text)
+	if button.Text != "🔕 بی‌صدا کردن" {
+		t.Errorf("Expected button text '🔕 بی‌صدا کردن', got '%s'", button.Text)
 	}
 	expectedCallback := "mute:Breaking Bad"
 	if button.CallbackData != expectedCallback {
@@ -464,7 +639,7 @@ func TestBroadcastNotification_NoMuteButtonForInvalidSeries(t *testing.T) {
 			ctx := context.Background()
 			err := bot.broadcastNotificationForTest(ctx, content)
 			if err != nil {
-				t.Fatalf("BroadcastNotification failed: %v", err)
+				t.Fatalf("Broadcast failed: %v", err)
 			}
 
 			// Check that no keyboard was sent
@@ -514,6 +689,22 @@ func TestShouldShowMuteButton(t *testing.T) {
 			},
 			shouldShow: false,
 		},
+		{
+			name: "season",
+			content: &NotificationContent{
+				Type:       "Season",
+				SeriesName: "Breaking Bad",
+			},
+			shouldShow: true,
+		},
+		{
+			name: "music album",
+			content: &NotificationContent{
+				Type:  "MusicAlbum",
+				Title: "Abbey Road",
+			},
+			shouldShow: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -549,7 +740,7 @@ func TestBroadcastNotification_HandlesMuteCheckError(t *testing.T) {
 	ctx := context.Background()
 	err := bot.broadcastNotificationForTest(ctx, content)
 	if err != nil {
-		t.Fatalf("BroadcastNotification failed: %v", err)
+		t.Fatalf("Broadcast failed: %v", err)
 	}
 
 	// Even with error, user should still receive notification (fail-safe behavior)
@@ -558,6 +749,123 @@ func TestBroadcastNotification_HandlesMuteCheckError(t *testing.T) {
 	}
 }
 
+// Test 9: Users who opted out of an item type are not notified about it
+func TestResolveRecipients_ExcludesOptedOutTypes(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{100, 200}
+	db.SetItemTypeEnabled(200, "Book", false)
+
+	bot := &Bot{db: db}
+
+	recipients, _, err := bot.resolveRecipients(context.Background(), &NotificationContent{
+		ItemID: "book1",
+		Type:   "Book",
+		Title:  "Dune",
+	})
+	if err != nil {
+		t.Fatalf("resolveRecipients failed: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != 100 {
+		t.Errorf("Expected only user 100 to be notified about books, got %v", recipients)
+	}
+
+	// Other types are unaffected
+	recipients, _, _ = bot.resolveRecipients(context.Background(), &NotificationContent{
+		ItemID: "movie1",
+		Type:   "Movie",
+		Title:  "Dune",
+	})
+	if len(recipients) != 2 {
+		t.Errorf("Expected both users to be notified about movies, got %v", recipients)
+	}
+}
+
+// Test 10: Every supported item type has its own notification format
+func TestFormatNotification_MediaTypes(t *testing.T) {
+	localizer := getTestLocalizer()
+	if localizer == nil {
+		t.Fatal("Failed to initialize test localizer")
+	}
+
+	testCases := []struct {
+		content  *NotificationContent
+		expected []string
+	}{
+		{
+			content:  &NotificationContent{Type: "Series", Title: "Severance", Year: 2022},
+			expected: []string{"New Series", "Severance", "2022"},
+		},
+		{
+			content:  &NotificationContent{Type: "Season", Title: "Season 2", SeriesName: "Severance", SeasonNumber: 2},
+			expected: []string{"New Season", "Series: Severance", "Season 2"},
+		},
+		{
+			content:  &NotificationContent{Type: "MusicAlbum", Title: "Abbey Road", Artist: "The Beatles"},
+			expected: []string{"New Album", "Album: Abbey Road", "Artist: The Beatles"},
+		},
+		{
+			content:  &NotificationContent{Type: "Audio", Title: "Come Together", Artist: "The Beatles", Album: "Abbey Road"},
+			expected: []string{"New Track", "Track: Come Together", "Album: Abbey Road"},
+		},
+		{
+			content:  &NotificationContent{Type: "AudioBook", Title: "Project Hail Mary", Artist: "Andy Weir"},
+			expected: []string{"New Audiobook", "Project Hail Mary", "Author: Andy Weir"},
+		},
+		{
+			content:  &NotificationContent{Type: "Book", Title: "Dune", Overview: "Desert planet"},
+			expected: []string{"New Book", "Dune", "Desert planet"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.content.Type, func(t *testing.T) {
+			message := FormatNotification(tc.content, localizer)
+			for _, expected := range tc.expected {
+				if !contains(message, expected) {
+					t.Errorf("Expected notification to contain %q, got %q", expected, message)
+				}
+			}
+		})
+	}
+}
+
+// Test 11: The quality line is only added when NOTIFICATION_MEDIA_INFO is enabled
+func TestDeliverNotification_MediaInfo(t *testing.T) {
+	media := &models.MediaInfo{
+		Resolution:        "2160p",
+		HDR:               "HDR10",
+		AudioCodec:        "DTS-HD",
+		AudioLanguages:    []string{"EN", "FA"},
+		SubtitleLanguages: []string{"EN", "FA"},
+	}
+	localizer := getTestLocalizer()
+	if line := formatMediaInfo(media, localizer); line != "🎞 2160p HDR10 · DTS-HD · Audio: EN, FA · Subs: EN, FA" {
+		t.Errorf("Unexpected quality line: %q", line)
+	}
+	if line := formatMediaInfo(&models.MediaInfo{}, localizer); line != "" {
+		t.Errorf("Expected no quality line without details, got %q", line)
+	}
+
+	content := &NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune", Media: media}
+	for _, enabled := range []bool{false, true} {
+		api := &recordingTelegramAPI{}
+		b := newCallbackTestBot(t, api, newMockSubscriberDB())
+		b.config = &config.Config{Notification: config.NotificationConfig{ShowMediaInfo: enabled}}
+
+		if _, err := b.deliverNotification(context.Background(), 100, content, nil); err != nil {
+			t.Fatalf("deliverNotification failed: %v", err)
+		}
+
+		sent := api.sent("sendMessage")
+		if len(sent) != 1 {
+			t.Fatalf("Expected 1 message, got %d", len(sent))
+		}
+		if shown := strings.Contains(sent[0].text, "2160p HDR10"); shown != enabled {
+			t.Errorf("With media info enabled=%v, got message %q", enabled, sent[0].text)
+		}
+	}
+}
+
 // errorMockDB is a mock that can return errors for specific methods
 type errorMockDB struct {
 	*mockSubscriberDB
@@ -0,0 +1,684 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/internal/metrics"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+)
+
+const (
+	// outboxPollInterval is how often the worker looks for unfinished jobs without being woken up
+	outboxPollInterval = 30 * time.Second
+	// outboxMaxAttempts is how many times a delivery is tried before it is given up
+	outboxMaxAttempts = 5
+	// outboxRetryBaseDelay is how long a failed delivery waits before its first retry; the delay doubles with every attempt
+	outboxRetryBaseDelay = time.Minute
+	// outboxRetryMaxDelay caps the delay between two attempts of a delivery
+	outboxRetryMaxDelay = 30 * time.Minute
+	// outboxBatchMaxWindows caps how many batch windows a batch is held back after its first episode
+	outboxBatchMaxWindows = 2
+)
+
+// OutboxStore defines the interface for durable notification outbox operations
+type OutboxStore interface {
+	EnqueueNotification(jellyfinID, batchKey, payload string, availableAt time.Time) error
+	GetDueJobs(now time.Time) ([]models.NotificationJob, error)
+	GetBatchJobs(batchKey string) ([]models.NotificationJob, error)
+	MergeNotificationJobs(primaryID uint, payload string, mergedIDs []uint) error
+	ExpandNotificationJob(jobID uint, chatIDs []int64, uncheckedIDs []int64) error
+	GetPendingDeliveries(jobID uint) ([]models.OutboxEntry, error)
+	MarkDeliverySent(entryID uint, messageID int, photo bool) error
+	MarkDeliveryFailed(entryID uint, lastError string, maxAttempts int, retryAt time.Time) error
+	MarkDeliveryBlocked(entryID uint, lastError string) error
+	CompleteNotificationJob(jobID uint) error
+	CancelDelivery(entryID uint) error
+	DeferDelivery(entryID uint, until time.Time) error
+	GetDueDeferredDeliveries(now time.Time) ([]models.OutboxEntry, error)
+	GetNotificationJobs(ids []uint) ([]models.NotificationJob, error)
+	EnqueueRevision(jellyfinID, kind, payload string) error
+	GetItemNotificationJobs(jellyfinID string, since time.Time) ([]models.NotificationJob, error)
+	SetNotificationPayload(jobID uint, payload string) error
+	GetSentDeliveries(jobID uint) ([]models.OutboxEntry, error)
+	ForgetDeliveryMessage(entryID uint) error
+	CancelDeliveries(jobID uint) (int64, error)
+}
+
+// notificationDeliverer resolves recipients and sends notifications to them (implemented by Bot)
+type notificationDeliverer interface {
+	resolveRecipients(ctx context.Context, content *NotificationContent) ([]int64, []int64, error)
+	checkItemAccess(ctx context.Context, chatID int64, itemID string) (bool, error)
+	fetchPoster(ctx context.Context, content *NotificationContent) []byte
+	deliverNotification(ctx context.Context, chatID int64, content *NotificationContent, imageData []byte) (*botModels.Message, error)
+	handleBlockedRecipient(chatID int64, sendErr error)
+	quietHoursEnd(chatID int64, now time.Time) (time.Time, bool)
+	deliverCatchUp(ctx context.Context, chatID int64, contents []NotificationContent) error
+	announceArrival(ctx context.Context, content *NotificationContent)
+	reviseDelivery(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent, imageData []byte) error
+	retractDelivery(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent, deleteMessage bool) error
+	annotateRemovedEpisode(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent) error
+}
+
+// Outbox persists notifications before delivering them, so deliveries survive restarts
+// It implements the handlers.NotificationQueue and handlers.NotificationReviser interfaces
+type Outbox struct {
+	deliverer    notificationDeliverer
+	store        OutboxStore
+	wake         chan struct{}
+	pollInterval time.Duration
+	sendInterval time.Duration
+	maxAttempts  int
+	retryDelay   time.Duration // Delay before the first retry of a failed delivery
+	batchWindow  time.Duration // Episodes of one season arriving within this window are aggregated
+	editWindow   time.Duration // Notifications this recent are revised when their item changes (0 disables)
+	deleteMode   string        // What happens to notifications of removed items, one of the config.DeleteMode* constants
+
+	// throttledUntil holds back all sends after Telegram rate limited the bot; only used by the worker
+	throttledUntil time.Time
+}
+
+// NewOutbox creates a new notification outbox delivering through the bot
+// A zero batchWindow disables episode aggregation
+func NewOutbox(bot *Bot, store OutboxStore, batchWindow time.Duration) *Outbox {
+	return &Outbox{
+		deliverer:    bot,
+		store:        store,
+		wake:         make(chan struct{}, 1),
+		pollInterval: outboxPollInterval,
+		sendInterval: sendInterval,
+		maxAttempts:  outboxMaxAttempts,
+		retryDelay:   outboxRetryBaseDelay,
+		batchWindow:  batchWindow,
+	}
+}
+
+// EnqueueNotification stores the notification in the outbox and wakes up the worker
+// Once it returns nil the notification is guaranteed to be delivered eventually
+func (o *Outbox) EnqueueNotification(ctx context.Context, content *handlers.NotificationContent) error {
+	payload, err := json.Marshal(convertNotificationContent(content))
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	// Episodes wait for the batch window so a season import becomes a single notification
+	batchKey := ""
+	availableAt := time.Now()
+	if o.batchWindow > 0 {
+		batchKey = episodeBatchKey(content)
+		if batchKey != "" {
+			availableAt = o.batchAvailableAt(batchKey, availableAt)
+		}
+	}
+
+	if err := o.store.EnqueueNotification(content.ItemID, batchKey, string(payload), availableAt); err != nil {
+		return err
+	}
+
+	o.wakeUp()
+	return nil
+}
+
+// batchAvailableAt returns when a batch receiving a new episode now is processed
+// Every episode extends the window, but a slow import doesn't hold back the episodes that
+// arrived first for longer than outboxBatchMaxWindows windows
+func (o *Outbox) batchAvailableAt(batchKey string, now time.Time) time.Time {
+	availableAt := now.Add(o.batchWindow)
+
+	batch, err := o.store.GetBatchJobs(batchKey)
+	if err != nil {
+		slog.Warn("Failed to load notification batch, extending its window", "batch_key", batchKey, "error", err)
+		return availableAt
+	}
+	for _, job := range batch {
+		if deadline := job.CreatedAt.Add(outboxBatchMaxWindows * o.batchWindow); deadline.Before(availableAt) {
+			availableAt = deadline
+		}
+	}
+	return availableAt
+}
+
+// wakeUp makes the worker look for due jobs
+func (o *Outbox) wakeUp() {
+	// Non-blocking wake up: a pending signal already guarantees another pass
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run drains the outbox until the context is cancelled
+// Unfinished jobs from a previous run are resumed immediately
+func (o *Outbox) Run(ctx context.Context) {
+	slog.Info("Starting notification outbox worker",
+		"poll_interval", o.pollInterval,
+		"max_attempts", o.maxAttempts)
+
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		o.processJobs(ctx)
+		o.processCatchUps(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Notification outbox worker stopped")
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// processJobs processes every due job in the outbox
+func (o *Outbox) processJobs(ctx context.Context) {
+	jobs, err := o.store.GetDueJobs(time.Now())
+	if err != nil {
+		slog.Error("Failed to load notification jobs", "error", err)
+		return
+	}
+
+	// Jobs folded into an earlier job of their batch during this pass
+	merged := make(map[uint]bool)
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if merged[jobs[i].ID] {
+			continue
+		}
+		for _, id := range o.processJob(ctx, &jobs[i]) {
+			merged[id] = true
+		}
+	}
+}
+
+// processJob resolves recipients for a new job and delivers all of its pending deliveries
+// It returns the IDs of the jobs that were merged into this one
+func (o *Outbox) processJob(ctx context.Context, job *models.NotificationJob) []uint {
+	if job.Kind != "" {
+		o.processRevision(ctx, job)
+		if err := o.store.CompleteNotificationJob(job.ID); err != nil {
+			slog.Error("Failed to complete revision job", "job_id", job.ID, "error", err)
+		}
+		return nil
+	}
+
+	var content NotificationContent
+	if err := json.Unmarshal([]byte(job.Payload), &content); err != nil {
+		slog.Error("Dropping notification job with invalid payload",
+			"job_id", job.ID,
+			"error", err)
+		if err := o.store.CompleteNotificationJob(job.ID); err != nil {
+			slog.Error("Failed to complete notification job", "job_id", job.ID, "error", err)
+		}
+		return nil
+	}
+
+	var mergedIDs []uint
+
+	// Resolve recipients once; they are persisted so a restart doesn't re-evaluate them
+	if job.Status == models.JobStatusPending {
+		if job.BatchKey != "" {
+			var err error
+			mergedIDs, err = o.mergeBatch(job, &content)
+			if err != nil {
+				slog.Error("Failed to merge notification batch",
+					"job_id", job.ID,
+					"batch_key", job.BatchKey,
+					"error", err)
+				return nil
+			}
+		}
+
+		recipients, unchecked, err := o.deliverer.resolveRecipients(ctx, &content)
+		if err != nil {
+			slog.Error("Failed to resolve notification recipients",
+				"job_id", job.ID,
+				"error", err)
+			return mergedIDs
+		}
+
+		if err := o.store.ExpandNotificationJob(job.ID, recipients, unchecked); err != nil {
+			slog.Error("Failed to expand notification job",
+				"job_id", job.ID,
+				"error", err)
+			return mergedIDs
+		}
+
+		// Wishes and title requests are personal messages outside the subscriber filters; they are
+		// announced once the job left the pending state, so a job retried above never announces them again
+		o.deliverer.announceArrival(ctx, &content)
+	}
+
+	deliveries, err := o.store.GetPendingDeliveries(job.ID)
+	if err != nil {
+		slog.Error("Failed to load pending deliveries",
+			"job_id", job.ID,
+			"error", err)
+		return mergedIDs
+	}
+
+	// Failed deliveries wait for their retry; they keep the job open meanwhile
+	now := time.Now()
+	due := deliveries[:0]
+	for _, delivery := range deliveries {
+		if delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	remaining := len(deliveries) - len(due)
+	deliveries = due
+
+	successCount := 0
+	failureCount := 0
+	blockedCount := 0
+	deferredCount := 0
+	hiddenCount := 0
+
+	var imageData []byte
+	if len(deliveries) > 0 {
+		metrics.BroadcastsStarted.Inc()
+		imageData = o.deliverer.fetchPoster(ctx, &content)
+	}
+
+	// A send that already started is allowed to finish during shutdown so its outcome
+	// is recorded; the loop checkpoints between deliveries instead
+	sendCtx := context.WithoutCancel(ctx)
+
+	for i, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Shutting down: leave the remaining deliveries pending for the next run
+			slog.Info("Broadcast interrupted by shutdown, resuming on next start",
+				"job_id", job.ID,
+				"item_id", content.ItemID,
+				"delivered", i,
+				"pending", len(deliveries)-i)
+			return mergedIDs
+		}
+
+		// Access that couldn't be checked when the job was expanded is checked first; while it still
+		// can't be, only this delivery is retried like a failed send and given up after maxAttempts
+		if delivery.AccessUnchecked {
+			visible, err := o.deliverer.checkItemAccess(ctx, delivery.ChatID, content.ItemID)
+			if err != nil {
+				failureCount++
+				slog.Warn("Failed to check item access before sending",
+					"chat_id", delivery.ChatID,
+					"item_id", content.ItemID,
+					"attempt", delivery.Attempts+1,
+					"error", err)
+				if o.recordFailure(&delivery, err) {
+					remaining++
+				}
+				continue
+			}
+			if !visible {
+				hiddenCount++
+				if err := o.store.CancelDelivery(delivery.ID); err != nil {
+					slog.Error("Failed to cancel delivery", "delivery_id", delivery.ID, "error", err)
+				}
+				continue
+			}
+		}
+
+		// Recipients in their quiet hours get a catch-up message once the window ends
+		if until, quiet := o.deliverer.quietHoursEnd(delivery.ChatID, time.Now()); quiet {
+			deferredCount++
+			if err := o.store.DeferDelivery(delivery.ID, until); err != nil {
+				slog.Error("Failed to defer delivery", "delivery_id", delivery.ID, "error", err)
+			}
+			continue
+		}
+
+		// Telegram rate limited the bot, so sending now would only use up attempts
+		if time.Now().Before(o.throttledUntil) {
+			remaining++
+			continue
+		}
+
+		// Handle Telegram rate limiting (max 30 messages/second)
+		time.Sleep(o.sendInterval)
+
+		message, sendErr := o.deliverer.deliverNotification(sendCtx, delivery.ChatID, &content, imageData)
+		switch {
+		case sendErr == nil:
+			successCount++
+			metrics.NotificationSends.WithLabelValues(metrics.ResultSuccess).Inc()
+			messageID := 0
+			if message != nil {
+				messageID = message.ID
+			}
+			if err := o.store.MarkDeliverySent(delivery.ID, messageID, len(imageData) > 0); err != nil {
+				slog.Error("Failed to mark delivery as sent", "delivery_id", delivery.ID, "error", err)
+			}
+		case isBlockedError(sendErr):
+			blockedCount++
+			metrics.NotificationSends.WithLabelValues(metrics.ResultBlocked).Inc()
+			o.deliverer.handleBlockedRecipient(delivery.ChatID, sendErr)
+			if err := o.store.MarkDeliveryBlocked(delivery.ID, sendErr.Error()); err != nil {
+				slog.Error("Failed to mark delivery as blocked", "delivery_id", delivery.ID, "error", err)
+			}
+		default:
+			failureCount++
+			metrics.NotificationSends.WithLabelValues(metrics.ResultFailure).Inc()
+			slog.Error("Failed to send notification",
+				"chat_id", delivery.ChatID,
+				"attempt", delivery.Attempts+1,
+				"error", sendErr)
+			if o.recordFailure(&delivery, sendErr) {
+				remaining++
+			}
+		}
+	}
+
+	if len(deliveries) > 0 {
+		slog.Info("Broadcast completed",
+			"job_id", job.ID,
+			"item_id", content.ItemID,
+			"sent_to", len(deliveries),
+			"success", successCount,
+			"failures", failureCount,
+			"blocked", blockedCount,
+			"deferred", deferredCount,
+			"hidden", hiddenCount,
+			"retry_pending", remaining)
+	}
+
+	// Keep the job open while failed deliveries still have attempts left
+	if remaining == 0 {
+		if err := o.store.CompleteNotificationJob(job.ID); err != nil {
+			slog.Error("Failed to complete notification job", "job_id", job.ID, "error", err)
+		}
+	}
+
+	return mergedIDs
+}
+
+// recordFailure records a failed attempt of a delivery and reports whether it will be retried
+func (o *Outbox) recordFailure(delivery *models.OutboxEntry, sendErr error) bool {
+	retryAt := o.retryAt(delivery.Attempts+1, sendErr)
+	if err := o.store.MarkDeliveryFailed(delivery.ID, sendErr.Error(), o.maxAttempts, retryAt); err != nil {
+		slog.Error("Failed to record delivery failure", "delivery_id", delivery.ID, "error", err)
+	}
+	return delivery.Attempts+1 < o.maxAttempts
+}
+
+// processCatchUps delivers the deliveries held back by quiet hours that ended
+// Each recipient gets a single catch-up message covering all of their held notifications
+func (o *Outbox) processCatchUps(ctx context.Context) {
+	entries, err := o.store.GetDueDeferredDeliveries(time.Now())
+	if err != nil {
+		slog.Error("Failed to load deferred deliveries", "error", err)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	jobIDs := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		jobIDs = append(jobIDs, entry.JobID)
+	}
+	jobs, err := o.store.GetNotificationJobs(jobIDs)
+	if err != nil {
+		slog.Error("Failed to load deferred notification jobs", "error", err)
+		return
+	}
+	payloads := make(map[uint]string, len(jobs))
+	for _, job := range jobs {
+		payloads[job.ID] = job.Payload
+	}
+
+	// Entries are ordered by recipient, so each recipient's deliveries are adjacent
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].ChatID == entries[start].ChatID {
+			end++
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		o.deliverCatchUp(ctx, entries[start:end], payloads)
+		start = end
+	}
+}
+
+// deliverCatchUp sends one recipient's held deliveries and records the outcome on each of them
+func (o *Outbox) deliverCatchUp(ctx context.Context, entries []models.OutboxEntry, payloads map[uint]string) {
+	chatID := entries[0].ChatID
+
+	contents := make([]NotificationContent, 0, len(entries))
+	for _, entry := range entries {
+		var content NotificationContent
+		if err := json.Unmarshal([]byte(payloads[entry.JobID]), &content); err != nil {
+			slog.Warn("Skipping deferred delivery with invalid payload",
+				"delivery_id", entry.ID,
+				"job_id", entry.JobID,
+				"error", err)
+			continue
+		}
+		contents = append(contents, content)
+	}
+
+	if time.Now().Before(o.throttledUntil) {
+		return
+	}
+
+	var sendErr error
+	if len(contents) > 0 {
+		time.Sleep(o.sendInterval)
+		// Let an in-flight catch-up finish during shutdown, like regular deliveries
+		sendErr = o.deliverer.deliverCatchUp(context.WithoutCancel(ctx), chatID, contents)
+	}
+
+	switch {
+	case sendErr == nil:
+		metrics.NotificationSends.WithLabelValues(metrics.ResultSuccess).Inc()
+		// Catch-ups summarize several notifications, so they aren't revised
+		for _, entry := range entries {
+			if err := o.store.MarkDeliverySent(entry.ID, 0, false); err != nil {
+				slog.Error("Failed to mark delivery as sent", "delivery_id", entry.ID, "error", err)
+			}
+		}
+		slog.Info("Catch-up delivered",
+			"chat_id", chatID,
+			"notifications", len(contents))
+	case isBlockedError(sendErr):
+		metrics.NotificationSends.WithLabelValues(metrics.ResultBlocked).Inc()
+		o.deliverer.handleBlockedRecipient(chatID, sendErr)
+		for _, entry := range entries {
+			if err := o.store.MarkDeliveryBlocked(entry.ID, sendErr.Error()); err != nil {
+				slog.Error("Failed to mark delivery as blocked", "delivery_id", entry.ID, "error", err)
+			}
+		}
+	default:
+		metrics.NotificationSends.WithLabelValues(metrics.ResultFailure).Inc()
+		slog.Error("Failed to send catch-up",
+			"chat_id", chatID,
+			"attempt", entries[0].Attempts+1,
+			"error", sendErr)
+		retryAt := o.retryAt(entries[0].Attempts+1, sendErr)
+		for _, entry := range entries {
+			if err := o.store.MarkDeliveryFailed(entry.ID, sendErr.Error(), o.maxAttempts, retryAt); err != nil {
+				slog.Error("Failed to record delivery failure", "delivery_id", entry.ID, "error", err)
+			}
+		}
+	}
+}
+
+// retryAt returns when a delivery that failed for the attempt-th time is tried again
+// The delay doubles with every attempt; when Telegram rate limits the bot, all sends pause
+// for at least the time it asked for
+func (o *Outbox) retryAt(attempt int, sendErr error) time.Time {
+	now := time.Now()
+
+	delay := o.retryDelay
+	for i := 1; i < attempt && delay < outboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, outboxRetryMaxDelay)
+
+	if wait := retryAfter(sendErr); wait > 0 {
+		o.throttledUntil = now.Add(wait)
+		slog.Warn("Telegram rate limit reached, pausing notifications", "retry_after", wait)
+		delay = max(delay, wait)
+	}
+
+	return now.Add(delay)
+}
+
+// retryAfter returns how long Telegram asked to wait after rate limiting a request, or 0 for other errors
+func retryAfter(err error) time.Duration {
+	var tooMany *bot.TooManyRequestsError
+	if errors.As(err, &tooMany) {
+		return time.Duration(tooMany.RetryAfter) * time.Second
+	}
+	return 0
+}
+
+// mergeBatch folds the other pending jobs of the job's batch into it
+// The aggregated content replaces content and is persisted before any delivery
+func (o *Outbox) mergeBatch(job *models.NotificationJob, content *NotificationContent) ([]uint, error) {
+	batch, err := o.store.GetBatchJobs(job.BatchKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(batch) < 2 {
+		return nil, nil
+	}
+
+	episodes := []NotificationContent{*content}
+	var mergedIDs []uint
+	for _, other := range batch {
+		if other.ID == job.ID {
+			continue
+		}
+
+		var episode NotificationContent
+		if err := json.Unmarshal([]byte(other.Payload), &episode); err != nil {
+			slog.Warn("Skipping batch job with invalid payload", "job_id", other.ID, "error", err)
+			continue
+		}
+		episodes = append(episodes, episode)
+		mergedIDs = append(mergedIDs, other.ID)
+	}
+
+	if len(mergedIDs) == 0 {
+		return nil, nil
+	}
+
+	aggregated := aggregateEpisodes(episodes)
+	payload, err := json.Marshal(aggregated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode aggregated notification: %w", err)
+	}
+
+	if err := o.store.MergeNotificationJobs(job.ID, string(payload), mergedIDs); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Aggregated episode notifications",
+		"job_id", job.ID,
+		"series", aggregated.SeriesName,
+		"season", aggregated.SeasonNumber,
+		"episodes", aggregated.EpisodeCount)
+
+	*content = *aggregated
+	return mergedIDs, nil
+}
+
+// aggregateEpisodes builds a single season notification out of several episode notifications
+// The earliest episode provides the poster and the series metadata; inputs may themselves be aggregates
+func aggregateEpisodes(episodes []NotificationContent) *NotificationContent {
+	sort.Slice(episodes, func(i, j int) bool {
+		return firstEpisode(&episodes[i]) < firstEpisode(&episodes[j])
+	})
+
+	aggregated := episodes[0]
+	aggregated.Title = ""
+	aggregated.Overview = ""
+	aggregated.EpisodeCount = 0
+	aggregated.FirstEpisode = firstEpisode(&episodes[0])
+	aggregated.LastEpisode = 0
+
+	for i := range episodes {
+		if episodes[i].EpisodeCount > 1 {
+			aggregated.EpisodeCount += episodes[i].EpisodeCount
+		} else {
+			aggregated.EpisodeCount++
+		}
+		if last := lastEpisode(&episodes[i]); last > aggregated.LastEpisode {
+			aggregated.LastEpisode = last
+		}
+	}
+
+	return &aggregated
+}
+
+func firstEpisode(content *NotificationContent) int {
+	if content.EpisodeCount > 1 {
+		return content.FirstEpisode
+	}
+	return content.EpisodeNumber
+}
+
+func lastEpisode(content *NotificationContent) int {
+	if content.EpisodeCount > 1 {
+		return content.LastEpisode
+	}
+	return content.EpisodeNumber
+}
+
+// episodeBatchKey returns the key grouping episodes of the same season, or "" for other content
+// Series are told apart by ID, so two series sharing a name aren't merged; the name is only used without one
+func episodeBatchKey(content *handlers.NotificationContent) string {
+	if content.Type != "Episode" {
+		return ""
+	}
+	series := content.SeriesID
+	if series == "" {
+		series = content.SeriesName
+	}
+	if series == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s|S%d", series, content.SeasonNumber)
+}
+
+// convertNotificationContent converts webhook notification content to bot notification content
+func convertNotificationContent(content *handlers.NotificationContent) *NotificationContent {
+	return &NotificationContent{
+		ItemID:         content.ItemID,
+		Type:           content.Type,
+		Title:          content.Title,
+		Overview:       content.Overview,
+		Year:           content.Year,
+		Rating:         content.Rating,
+		SeriesID:       content.SeriesID,
+		SeriesName:     content.SeriesName,
+		SeasonNumber:   content.SeasonNumber,
+		EpisodeNumber:  content.EpisodeNumber,
+		Album:          content.Album,
+		Artist:         content.Artist,
+		OfficialRating: content.OfficialRating,
+		Genres:         content.Genres,
+		Media:          content.Media,
+		TrailerURL:     content.TrailerURL,
+		ProviderIDs:    content.ProviderIDs,
+	}
+}
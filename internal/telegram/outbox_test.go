@@ -0,0 +1,764 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+)
+
+// mockOutboxStore implements OutboxStore in memory for testing
+type mockOutboxStore struct {
+	jobs       []models.NotificationJob
+	deliveries []models.OutboxEntry
+}
+
+func (m *mockOutboxStore) EnqueueNotification(jellyfinID, batchKey, payload string, availableAt time.Time) error {
+	if batchKey != "" {
+		for i := range m.jobs {
+			if m.jobs[i].BatchKey == batchKey && m.jobs[i].Status == models.JobStatusPending {
+				m.jobs[i].AvailableAt = availableAt
+			}
+		}
+	}
+	m.jobs = append(m.jobs, models.NotificationJob{
+		JellyfinID:  jellyfinID,
+		Payload:     payload,
+		Status:      models.JobStatusPending,
+		BatchKey:    batchKey,
+		AvailableAt: availableAt,
+	})
+	m.jobs[len(m.jobs)-1].ID = uint(len(m.jobs))
+	m.jobs[len(m.jobs)-1].CreatedAt = time.Now()
+	return nil
+}
+
+func (m *mockOutboxStore) GetDueJobs(now time.Time) ([]models.NotificationJob, error) {
+	var result []models.NotificationJob
+	for _, job := range m.jobs {
+		if job.Status == models.JobStatusExpanded ||
+			(job.Status == models.JobStatusPending && !job.AvailableAt.After(now)) {
+			result = append(result, job)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOutboxStore) GetBatchJobs(batchKey string) ([]models.NotificationJob, error) {
+	var result []models.NotificationJob
+	for _, job := range m.jobs {
+		if job.BatchKey == batchKey && job.Status == models.JobStatusPending {
+			result = append(result, job)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOutboxStore) MergeNotificationJobs(primaryID uint, payload string, mergedIDs []uint) error {
+	m.jobs[primaryID-1].Payload = payload
+	for _, id := range mergedIDs {
+		m.jobs[id-1].Status = models.JobStatusMerged
+		m.jobs[id-1].MergedInto = primaryID
+	}
+	return nil
+}
+
+func (m *mockOutboxStore) ExpandNotificationJob(jobID uint, chatIDs []int64, uncheckedIDs []int64) error {
+	for _, chatID := range chatIDs {
+		entry := models.OutboxEntry{JobID: jobID, ChatID: chatID, Status: models.DeliveryStatusPending}
+		entry.ID = uint(len(m.deliveries) + 1)
+		m.deliveries = append(m.deliveries, entry)
+	}
+	for _, chatID := range uncheckedIDs {
+		entry := models.OutboxEntry{JobID: jobID, ChatID: chatID, Status: models.DeliveryStatusPending, AccessUnchecked: true}
+		entry.ID = uint(len(m.deliveries) + 1)
+		m.deliveries = append(m.deliveries, entry)
+	}
+	m.jobs[jobID-1].Status = models.JobStatusExpanded
+	return nil
+}
+
+func (m *mockOutboxStore) GetPendingDeliveries(jobID uint) ([]models.OutboxEntry, error) {
+	var result []models.OutboxEntry
+	for _, entry := range m.deliveries {
+		if entry.JobID == jobID && entry.Status == models.DeliveryStatusPending {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOutboxStore) MarkDeliverySent(entryID uint, messageID int, photo bool) error {
+	m.deliveries[entryID-1].Status = models.DeliveryStatusSent
+	m.deliveries[entryID-1].Attempts++
+	m.deliveries[entryID-1].MessageID = messageID
+	m.deliveries[entryID-1].PhotoMessage = photo
+	m.deliveries[entryID-1].UpdatedAt = time.Now()
+	return nil
+}
+
+func (m *mockOutboxStore) MarkDeliveryFailed(entryID uint, lastError string, maxAttempts int, retryAt time.Time) error {
+	entry := &m.deliveries[entryID-1]
+	entry.Attempts++
+	entry.LastError = lastError
+	entry.NextAttemptAt = &retryAt
+	if entry.Attempts >= maxAttempts {
+		entry.Status = models.DeliveryStatusFailed
+	}
+	return nil
+}
+
+func (m *mockOutboxStore) MarkDeliveryBlocked(entryID uint, lastError string) error {
+	m.deliveries[entryID-1].Status = models.DeliveryStatusBlocked
+	m.deliveries[entryID-1].LastError = lastError
+	return nil
+}
+
+func (m *mockOutboxStore) CompleteNotificationJob(jobID uint) error {
+	m.jobs[jobID-1].Status = models.JobStatusDone
+	return nil
+}
+
+func (m *mockOutboxStore) CancelDelivery(entryID uint) error {
+	m.deliveries[entryID-1].Status = models.DeliveryStatusCancelled
+	return nil
+}
+
+func (m *mockOutboxStore) DeferDelivery(entryID uint, until time.Time) error {
+	m.deliveries[entryID-1].Status = models.DeliveryStatusDeferred
+	m.deliveries[entryID-1].DeliverAfter = until
+	return nil
+}
+
+func (m *mockOutboxStore) GetDueDeferredDeliveries(now time.Time) ([]models.OutboxEntry, error) {
+	var result []models.OutboxEntry
+	for _, entry := range m.deliveries {
+		if entry.Status == models.DeliveryStatusDeferred && !entry.DeliverAfter.After(now) &&
+			(entry.NextAttemptAt == nil || !entry.NextAttemptAt.After(now)) {
+			result = append(result, entry)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].ChatID < result[j].ChatID })
+	return result, nil
+}
+
+func (m *mockOutboxStore) GetNotificationJobs(ids []uint) ([]models.NotificationJob, error) {
+	var result []models.NotificationJob
+	for _, id := range ids {
+		result = append(result, m.jobs[id-1])
+	}
+	return result, nil
+}
+
+func (m *mockOutboxStore) EnqueueRevision(jellyfinID, kind, payload string) error {
+	m.jobs = append(m.jobs, models.NotificationJob{
+		JellyfinID:  jellyfinID,
+		Payload:     payload,
+		Status:      models.JobStatusPending,
+		AvailableAt: time.Now(),
+		Kind:        kind,
+	})
+	m.jobs[len(m.jobs)-1].ID = uint(len(m.jobs))
+	return nil
+}
+
+func (m *mockOutboxStore) GetItemNotificationJobs(jellyfinID string, since time.Time) ([]models.NotificationJob, error) {
+	var result []models.NotificationJob
+	for _, job := range m.jobs {
+		if job.JellyfinID != jellyfinID || job.Kind != "" {
+			continue
+		}
+		if job.Status == models.JobStatusMerged {
+			job = m.jobs[job.MergedInto-1]
+		}
+		result = append(result, job)
+	}
+	return result, nil
+}
+
+func (m *mockOutboxStore) SetNotificationPayload(jobID uint, payload string) error {
+	m.jobs[jobID-1].Payload = payload
+	return nil
+}
+
+func (m *mockOutboxStore) GetSentDeliveries(jobID uint) ([]models.OutboxEntry, error) {
+	var result []models.OutboxEntry
+	for _, entry := range m.deliveries {
+		if entry.JobID == jobID && entry.Status == models.DeliveryStatusSent && entry.MessageID != 0 {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOutboxStore) ForgetDeliveryMessage(entryID uint) error {
+	m.deliveries[entryID-1].MessageID = 0
+	return nil
+}
+
+func (m *mockOutboxStore) CancelDeliveries(jobID uint) (int64, error) {
+	var cancelled int64
+	for i := range m.deliveries {
+		entry := &m.deliveries[i]
+		if entry.JobID == jobID && (entry.Status == models.DeliveryStatusPending || entry.Status == models.DeliveryStatusDeferred) {
+			entry.Status = models.DeliveryStatusCancelled
+			cancelled++
+		}
+	}
+	return cancelled, nil
+}
+
+// mockDeliverer implements notificationDeliverer for testing
+type mockDeliverer struct {
+	recipients []int64
+	resolveErr error
+	announced  int             // Number of announceArrival calls
+	unchecked  []int64         // Recipients whose access couldn't be checked during resolution
+	access     map[int64]bool  // Access of the unchecked recipients once checked again
+	accessErr  map[int64]error // Failing access checks
+	sendErrors map[int64]error
+	sent       map[int64]int
+	blocked    []int64
+	contents   []NotificationContent
+	quietUntil map[int64]time.Time // Recipients currently in their quiet hours
+	catchUps   map[int64][]NotificationContent
+	revised    map[int]NotificationContent // Edited messages by message ID
+	retracted  map[int]bool                // Retracted messages by message ID, true if deleted
+	annotated  map[int]int                 // Season messages marked for a removed episode, by message ID
+	reviseErr  error
+}
+
+func newMockDeliverer(recipients ...int64) *mockDeliverer {
+	return &mockDeliverer{
+		recipients: recipients,
+		sendErrors: make(map[int64]error),
+		access:     make(map[int64]bool),
+		accessErr:  make(map[int64]error),
+		sent:       make(map[int64]int),
+		quietUntil: make(map[int64]time.Time),
+		catchUps:   make(map[int64][]NotificationContent),
+		revised:    make(map[int]NotificationContent),
+		retracted:  make(map[int]bool),
+		annotated:  make(map[int]int),
+	}
+}
+
+func (m *mockDeliverer) resolveRecipients(ctx context.Context, content *NotificationContent) ([]int64, []int64, error) {
+	if m.resolveErr != nil {
+		return nil, nil, m.resolveErr
+	}
+	return m.recipients, m.unchecked, nil
+}
+
+func (m *mockDeliverer) checkItemAccess(ctx context.Context, chatID int64, itemID string) (bool, error) {
+	if err := m.accessErr[chatID]; err != nil {
+		return false, err
+	}
+	return m.access[chatID], nil
+}
+
+func (m *mockDeliverer) fetchPoster(ctx context.Context, content *NotificationContent) []byte {
+	return nil
+}
+
+func (m *mockDeliverer) deliverNotification(ctx context.Context, chatID int64, content *NotificationContent, imageData []byte) (*botModels.Message, error) {
+	if err := m.sendErrors[chatID]; err != nil {
+		return nil, err
+	}
+	m.sent[chatID]++
+	m.contents = append(m.contents, *content)
+	return &botModels.Message{ID: len(m.contents)}, nil
+}
+
+func (m *mockDeliverer) reviseDelivery(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent, imageData []byte) error {
+	if m.reviseErr != nil {
+		return m.reviseErr
+	}
+	m.revised[entry.MessageID] = *content
+	return nil
+}
+
+func (m *mockDeliverer) retractDelivery(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent, deleteMessage bool) error {
+	if m.reviseErr != nil {
+		return m.reviseErr
+	}
+	m.retracted[entry.MessageID] = deleteMessage
+	return nil
+}
+
+func (m *mockDeliverer) annotateRemovedEpisode(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent) error {
+	if m.reviseErr != nil {
+		return m.reviseErr
+	}
+	m.annotated[entry.MessageID]++
+	return nil
+}
+
+func (m *mockDeliverer) handleBlockedRecipient(chatID int64, sendErr error) {
+	m.blocked = append(m.blocked, chatID)
+}
+
+func (m *mockDeliverer) quietHoursEnd(chatID int64, now time.Time) (time.Time, bool) {
+	until, ok := m.quietUntil[chatID]
+	return until, ok && until.After(now)
+}
+
+func (m *mockDeliverer) announceArrival(ctx context.Context, content *NotificationContent) {
+	m.announced++
+}
+
+func (m *mockDeliverer) deliverCatchUp(ctx context.Context, chatID int64, contents []NotificationContent) error {
+	if err := m.sendErrors[chatID]; err != nil {
+		return err
+	}
+	m.catchUps[chatID] = append(m.catchUps[chatID], contents...)
+	return nil
+}
+
+func newTestOutbox(deliverer notificationDeliverer, store OutboxStore) *Outbox {
+	return &Outbox{
+		deliverer:   deliverer,
+		store:       store,
+		wake:        make(chan struct{}, 1),
+		maxAttempts: 2,
+	}
+}
+
+// Test 1: Enqueued notifications are delivered to every recipient and the job completes
+func TestOutbox_DeliversToAllRecipients(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200, 300)
+	outbox := newTestOutbox(deliverer, store)
+
+	err := outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{
+		ItemID: "movie1",
+		Type:   "Movie",
+		Title:  "Arrival",
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue notification: %v", err)
+	}
+
+	outbox.processJobs(context.Background())
+
+	for _, chatID := range []int64{100, 200, 300} {
+		if deliverer.sent[chatID] != 1 {
+			t.Errorf("Expected user %d to receive 1 notification, got %d", chatID, deliverer.sent[chatID])
+		}
+	}
+	if store.jobs[0].Status != models.JobStatusDone {
+		t.Errorf("Expected job to be done, got %q", store.jobs[0].Status)
+	}
+}
+
+// Test 2: Failed deliveries are retried on the next pass without resending to others
+func TestOutbox_RetriesFailedDeliveries(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	deliverer.sendErrors[200] = errors.New("connection reset")
+	outbox := newTestOutbox(deliverer, store)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie2", Type: "Movie"})
+	outbox.processJobs(context.Background())
+
+	if store.jobs[0].Status == models.JobStatusDone {
+		t.Fatal("Job should stay open while a delivery can be retried")
+	}
+
+	// Recipient recovers before the next pass
+	delete(deliverer.sendErrors, 200)
+	outbox.processJobs(context.Background())
+
+	if deliverer.sent[100] != 1 {
+		t.Errorf("Expected user 100 to receive exactly 1 notification, got %d", deliverer.sent[100])
+	}
+	if deliverer.sent[200] != 1 {
+		t.Errorf("Expected user 200 to receive the retried notification, got %d", deliverer.sent[200])
+	}
+	if store.jobs[0].Status != models.JobStatusDone {
+		t.Errorf("Expected job to be done after retry, got %q", store.jobs[0].Status)
+	}
+}
+
+// Test 3: Blocked recipients are deactivated and not retried
+func TestOutbox_BlockedRecipient(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	deliverer.sendErrors[100] = errors.New("Forbidden: bot was blocked by the user")
+	outbox := newTestOutbox(deliverer, store)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie3", Type: "Movie"})
+	outbox.processJobs(context.Background())
+
+	if len(deliverer.blocked) != 1 || deliverer.blocked[0] != 100 {
+		t.Errorf("Expected user 100 to be handled as blocked, got %v", deliverer.blocked)
+	}
+	if store.deliveries[0].Status != models.DeliveryStatusBlocked {
+		t.Errorf("Expected delivery status %q, got %q", models.DeliveryStatusBlocked, store.deliveries[0].Status)
+	}
+	if store.jobs[0].Status != models.JobStatusDone {
+		t.Errorf("Expected job to be done, got %q", store.jobs[0].Status)
+	}
+}
+
+// Test 4: Expanded jobs resume without resolving recipients again (restart scenario)
+func TestOutbox_ResumesExpandedJob(t *testing.T) {
+	store := &mockOutboxStore{}
+	store.EnqueueNotification("episode1", "", `{"ItemID":"episode1","Type":"Episode","SeriesName":"Dark"}`, time.Now())
+	store.ExpandNotificationJob(1, []int64{100, 200}, nil)
+	store.MarkDeliverySent(1, 1, false) // User 100 was notified before the restart
+
+	// After the restart the subscriber list no longer matters for this job
+	deliverer := newMockDeliverer(999)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.processJobs(context.Background())
+
+	if deliverer.sent[100] != 0 {
+		t.Error("User 100 should not be notified twice")
+	}
+	if deliverer.sent[200] != 1 {
+		t.Error("User 200 should receive the resumed notification")
+	}
+	if deliverer.sent[999] != 0 {
+		t.Error("Recipients must not be re-resolved for an expanded job")
+	}
+}
+
+// Test 5: Episodes of one season within the batch window are sent as a single notification
+func TestOutbox_AggregatesSeasonEpisodes(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.batchWindow = time.Minute
+
+	for _, episode := range []int{3, 1, 2} {
+		outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{
+			ItemID:        fmt.Sprintf("episode%d", episode),
+			Type:          "Episode",
+			SeriesName:    "Severance",
+			SeasonNumber:  2,
+			EpisodeNumber: episode,
+		})
+	}
+
+	// Nothing is sent while the window is still open
+	outbox.processJobs(context.Background())
+	if deliverer.sent[100] != 0 {
+		t.Fatalf("Expected no notification before the batch window closes, got %d", deliverer.sent[100])
+	}
+
+	// Close the window
+	for i := range store.jobs {
+		store.jobs[i].AvailableAt = time.Now().Add(-time.Second)
+	}
+	outbox.processJobs(context.Background())
+
+	if deliverer.sent[100] != 1 {
+		t.Fatalf("Expected exactly 1 aggregated notification, got %d", deliverer.sent[100])
+	}
+
+	content := deliverer.contents[0]
+	if content.EpisodeCount != 3 || content.FirstEpisode != 1 || content.LastEpisode != 3 {
+		t.Errorf("Expected 3 episodes E1-E3, got %d episodes E%d-E%d",
+			content.EpisodeCount, content.FirstEpisode, content.LastEpisode)
+	}
+	if content.ItemID != "episode1" {
+		t.Errorf("Expected poster of the first episode, got %q", content.ItemID)
+	}
+
+	for _, job := range store.jobs[1:] {
+		if job.Status != models.JobStatusMerged && job.Status != models.JobStatusDone {
+			t.Errorf("Expected other batch jobs to be merged, got %q", job.Status)
+		}
+	}
+}
+
+// Test 6: A single episode is sent as a regular notification once the window closes
+func TestOutbox_SingleEpisodeNotAggregated(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.batchWindow = time.Minute
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{
+		ItemID:        "episode1",
+		Type:          "Episode",
+		Title:         "Hello, Ms. Cobel",
+		SeriesName:    "Severance",
+		SeasonNumber:  2,
+		EpisodeNumber: 1,
+	})
+	store.jobs[0].AvailableAt = time.Now().Add(-time.Second)
+	outbox.processJobs(context.Background())
+
+	if deliverer.sent[100] != 1 {
+		t.Fatalf("Expected 1 notification, got %d", deliverer.sent[100])
+	}
+	if deliverer.contents[0].EpisodeCount != 0 || deliverer.contents[0].Title != "Hello, Ms. Cobel" {
+		t.Errorf("Expected the episode to be sent unchanged, got %+v", deliverer.contents[0])
+	}
+}
+
+// Test 7: Deliveries during quiet hours are held back and sent as one catch-up when the window ends
+func TestOutbox_DefersDeliveriesDuringQuietHours(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	deliverer.quietUntil[200] = time.Now().Add(time.Hour)
+	outbox := newTestOutbox(deliverer, store)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Arrival"})
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie2", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(context.Background())
+
+	if deliverer.sent[100] != 2 {
+		t.Errorf("Expected user 100 to receive 2 notifications, got %d", deliverer.sent[100])
+	}
+	if deliverer.sent[200] != 0 {
+		t.Errorf("Expected user 200 to receive nothing during quiet hours, got %d", deliverer.sent[200])
+	}
+	for _, job := range store.jobs {
+		if job.Status != models.JobStatusDone {
+			t.Errorf("Expected job %d to be done, got %q", job.ID, job.Status)
+		}
+	}
+
+	// Still inside the window: nothing is sent
+	outbox.processCatchUps(context.Background())
+	if len(deliverer.catchUps[200]) != 0 {
+		t.Fatalf("Catch-up sent before quiet hours ended")
+	}
+
+	// Window over: both notifications arrive in a single catch-up
+	for i := range store.deliveries {
+		if store.deliveries[i].Status == models.DeliveryStatusDeferred {
+			store.deliveries[i].DeliverAfter = time.Now().Add(-time.Minute)
+		}
+	}
+	outbox.processCatchUps(context.Background())
+
+	if len(deliverer.catchUps[200]) != 2 {
+		t.Fatalf("Expected a catch-up with 2 notifications, got %d", len(deliverer.catchUps[200]))
+	}
+	if deliverer.catchUps[200][0].Title != "Arrival" || deliverer.catchUps[200][1].Title != "Dune" {
+		t.Errorf("Unexpected catch-up content: %+v", deliverer.catchUps[200])
+	}
+	for _, entry := range store.deliveries {
+		if entry.Status != models.DeliveryStatusSent {
+			t.Errorf("Expected delivery %d to be sent, got %q", entry.ID, entry.Status)
+		}
+	}
+}
+
+// Test 8: A failed catch-up stays deferred for a retry
+func TestOutbox_RetriesFailedCatchUp(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(200)
+	deliverer.quietUntil[200] = time.Now().Add(time.Hour)
+	outbox := newTestOutbox(deliverer, store)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie1", Type: "Movie"})
+	outbox.processJobs(context.Background())
+	store.deliveries[0].DeliverAfter = time.Now().Add(-time.Minute)
+
+	deliverer.sendErrors[200] = errors.New("connection reset")
+	outbox.processCatchUps(context.Background())
+
+	if store.deliveries[0].Status != models.DeliveryStatusDeferred {
+		t.Fatalf("Expected delivery to stay deferred after one failure, got %q", store.deliveries[0].Status)
+	}
+
+	delete(deliverer.sendErrors, 200)
+	outbox.processCatchUps(context.Background())
+
+	if store.deliveries[0].Status != models.DeliveryStatusSent || len(deliverer.catchUps[200]) != 1 {
+		t.Errorf("Expected catch-up to be sent on retry, got status %q", store.deliveries[0].Status)
+	}
+}
+
+// Test 9: Failed deliveries wait longer after every attempt instead of being retried on every pass
+func TestOutbox_BacksOffFailedDeliveries(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	deliverer.sendErrors[100] = errors.New("connection reset")
+	outbox := newTestOutbox(deliverer, store)
+	outbox.maxAttempts = 5
+	outbox.retryDelay = time.Minute
+	ctx := context.Background()
+
+	outbox.EnqueueNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	start := time.Now()
+	for range 3 {
+		outbox.processJobs(ctx)
+	}
+
+	entry := store.deliveries[0]
+	if entry.Attempts != 1 || entry.Status != models.DeliveryStatusPending {
+		t.Fatalf("Expected one attempt before the retry is due, got %+v", entry)
+	}
+	if entry.NextAttemptAt == nil || entry.NextAttemptAt.Before(start.Add(time.Minute)) {
+		t.Errorf("Expected the retry a minute later, got %v", entry.NextAttemptAt)
+	}
+	if store.jobs[0].Status != models.JobStatusExpanded {
+		t.Errorf("Expected the job to stay open for the retry, got %q", store.jobs[0].Status)
+	}
+
+	// The second retry waits twice as long
+	past := time.Now().Add(-time.Second)
+	store.deliveries[0].NextAttemptAt = &past
+	outbox.processJobs(ctx)
+	if delay := store.deliveries[0].NextAttemptAt.Sub(time.Now()); store.deliveries[0].Attempts != 2 || delay < 110*time.Second {
+		t.Errorf("Expected a second attempt with a 2 minute delay, got %+v", store.deliveries[0])
+	}
+
+	delete(deliverer.sendErrors, 100)
+	store.deliveries[0].NextAttemptAt = &past
+	outbox.processJobs(ctx)
+	if deliverer.sent[100] != 1 || store.jobs[0].Status != models.JobStatusDone {
+		t.Errorf("Expected the retry to succeed, got %d sends and job %q", deliverer.sent[100], store.jobs[0].Status)
+	}
+}
+
+// Test 10: Rate limiting pauses all deliveries for the time Telegram asked for
+func TestOutbox_HonoursRetryAfter(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	deliverer.sendErrors[100] = &bot.TooManyRequestsError{Message: "too many requests", RetryAfter: 600}
+	outbox := newTestOutbox(deliverer, store)
+	outbox.maxAttempts = 5
+	ctx := context.Background()
+
+	outbox.EnqueueNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(ctx)
+
+	if retry := store.deliveries[0].NextAttemptAt; retry == nil || time.Until(*retry) < 9*time.Minute {
+		t.Errorf("Expected the rate limited delivery to wait for retry_after, got %v", retry)
+	}
+	if deliverer.sent[200] != 0 || store.deliveries[1].Attempts != 0 {
+		t.Errorf("Expected no send to other recipients while rate limited, got %+v", store.deliveries[1])
+	}
+
+	// Webhooks waking the worker up don't use up attempts during the pause
+	outbox.processJobs(ctx)
+	outbox.processJobs(ctx)
+	if store.deliveries[0].Attempts != 1 || store.deliveries[1].Attempts != 0 {
+		t.Errorf("Expected no further attempts during the pause, got %+v", store.deliveries)
+	}
+
+	outbox.throttledUntil = time.Time{}
+	outbox.processJobs(ctx)
+	if deliverer.sent[200] != 1 {
+		t.Error("Expected the other recipient to get the notification after the pause")
+	}
+}
+
+// Test 11: Recipients whose access couldn't be checked are retried alone and given up after the last attempt
+func TestOutbox_RetriesUncheckedAccessPerRecipient(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	deliverer.unchecked = []int64{300, 400, 500}
+	deliverer.accessErr[300] = errors.New("jellyfin down")
+	deliverer.accessErr[400] = errors.New("jellyfin down")
+	deliverer.access[400] = true
+	deliverer.access[500] = false
+	outbox := newTestOutbox(deliverer, store)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie1", Type: "Movie"})
+	outbox.processJobs(context.Background())
+
+	if deliverer.sent[100] != 1 || deliverer.sent[200] != 1 {
+		t.Fatalf("Expected the checked recipients to be notified right away, got %v", deliverer.sent)
+	}
+	if deliverer.sent[300]+deliverer.sent[400]+deliverer.sent[500] != 0 {
+		t.Fatalf("Expected nothing for recipients without confirmed access, got %v", deliverer.sent)
+	}
+	if store.deliveries[4].Status != models.DeliveryStatusCancelled {
+		t.Errorf("Expected the delivery to a recipient without access to be cancelled, got %q", store.deliveries[4].Status)
+	}
+	if store.jobs[0].Status == models.JobStatusDone {
+		t.Fatal("Expected the job to stay open while access checks are retried")
+	}
+
+	// Jellyfin answers for one of them again
+	delete(deliverer.accessErr, 400)
+	outbox.processJobs(context.Background())
+	if deliverer.sent[400] != 1 || deliverer.sent[100] != 1 {
+		t.Errorf("Expected only the recipient with confirmed access to be notified, got %v", deliverer.sent)
+	}
+
+	// The last attempt gives up on the recipient whose check keeps failing
+	if store.deliveries[2].Status != models.DeliveryStatusFailed || deliverer.sent[300] != 0 {
+		t.Errorf("Expected the failing recipient to be given up, got %q", store.deliveries[2].Status)
+	}
+	if store.jobs[0].Status != models.JobStatusDone {
+		t.Errorf("Expected the job to be done, got %q", store.jobs[0].Status)
+	}
+}
+
+// Test 12: Wishes and requests are announced once, after the recipients were resolved
+func TestOutbox_AnnouncesArrivalOnce(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	deliverer.resolveErr = errors.New("database is locked")
+	outbox := newTestOutbox(deliverer, store)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie1", Type: "Movie"})
+	outbox.processJobs(context.Background())
+	outbox.processJobs(context.Background())
+	if deliverer.announced != 0 {
+		t.Fatalf("Expected no announcement while recipients can't be resolved, got %d", deliverer.announced)
+	}
+
+	deliverer.resolveErr = nil
+	deliverer.sendErrors[100] = errors.New("connection reset")
+	outbox.processJobs(context.Background())
+	outbox.processJobs(context.Background())
+	if deliverer.announced != 1 {
+		t.Errorf("Expected a single announcement while deliveries are retried, got %d", deliverer.announced)
+	}
+}
+
+// Test 13: A slow season import doesn't hold back its first episodes for more than two batch windows,
+// and series sharing a name are batched separately
+func TestOutbox_CapsBatchDelay(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.batchWindow = 10 * time.Minute
+
+	episode := func(number int, seriesID string) *handlers.NotificationContent {
+		return &handlers.NotificationContent{
+			ItemID:        fmt.Sprintf("%s-episode%d", seriesID, number),
+			Type:          "Episode",
+			SeriesID:      seriesID,
+			SeriesName:    "The Office",
+			SeasonNumber:  1,
+			EpisodeNumber: number,
+		}
+	}
+
+	outbox.EnqueueNotification(context.Background(), episode(1, "office-us"))
+	// The import has been going on for 25 minutes when the next episode arrives
+	store.jobs[0].CreatedAt = time.Now().Add(-25 * time.Minute)
+	outbox.EnqueueNotification(context.Background(), episode(2, "office-us"))
+	outbox.EnqueueNotification(context.Background(), episode(1, "office-uk"))
+
+	if store.jobs[0].BatchKey == store.jobs[2].BatchKey {
+		t.Fatalf("Expected series sharing a name to get their own batch, got %q", store.jobs[0].BatchKey)
+	}
+
+	outbox.processJobs(context.Background())
+	if len(deliverer.contents) != 1 || deliverer.contents[0].EpisodeCount != 2 {
+		t.Fatalf("Expected the capped batch of 2 episodes to be sent, got %+v", deliverer.contents)
+	}
+	if store.jobs[2].Status != models.JobStatusPending {
+		t.Errorf("Expected the other series to wait for its own window, got %q", store.jobs[2].Status)
+	}
+}
@@ -0,0 +1,428 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// ratingOptions are the minimum community ratings cycled through by the rating button (0 = any)
+var ratingOptions = []float64{0, 6, 7, 8}
+
+// yearRange is a production year filter; zero bounds are open
+type yearRange struct {
+	From int
+	To   int
+}
+
+// yearOptions are the production year ranges cycled through by the years button
+var yearOptions = []yearRange{
+	{0, 0},
+	{2000, 0},
+	{2010, 0},
+	{2020, 0},
+	{0, 1999},
+}
+
+// preferenceGenres are the genres offered in the genre filter menu
+var preferenceGenres = []string{
+	"Action", "Adventure", "Animation", "Comedy",
+	"Crime", "Documentary", "Drama", "Family",
+	"Fantasy", "Horror", "Mystery", "Romance",
+	"Science Fiction", "Thriller",
+}
+
+// handlePreferences handles the /preferences command
+func (b *Bot) handlePreferences(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+
+	slog.Info("Processing /preferences command", "chat_id", chatID)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	prefs, err := b.db.GetContentPreferences(chatID)
+	if err != nil {
+		slog.Error("Failed to load content preferences",
+			"chat_id", chatID,
+			"error", err)
+
+		if err := b.SendMessage(ctx, chatID, i18n.T(localizer, "preferences.error")); err != nil {
+			slog.Error("Failed to send error message", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	message := formatPreferences(prefs, localizer)
+	if err := b.SendMessageWithKeyboard(ctx, chatID, message, createPreferencesKeyboard(prefs, localizer)); err != nil {
+		slog.Error("Failed to send preferences",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handlePreferencesCallback handles the buttons of the /preferences menu
+// Callback data format: "pref:{action}" or "pref:genre:{Genre}"
+func (b *Bot) handlePreferencesCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	if update.CallbackQuery == nil {
+		return
+	}
+
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	messageID := callbackQuery.Message.Message.ID
+	callbackData := callbackQuery.Data
+
+	slog.Info("Processing preferences callback",
+		"chat_id", chatID,
+		"callback_data", callbackData)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	answer := func(text string) {
+		botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: callbackQuery.ID,
+			Text:            text,
+			ShowAlert:       false,
+		})
+	}
+
+	prefs, err := b.db.GetContentPreferences(chatID)
+	if err != nil {
+		slog.Error("Failed to load content preferences",
+			"chat_id", chatID,
+			"error", err)
+		answer(i18n.T(localizer, "preferences.error"))
+		return
+	}
+
+	parts := strings.SplitN(callbackData, ":", 3)
+	if len(parts) < 2 {
+		slog.Error("Invalid preferences callback data", "callback_data", callbackData)
+		answer(i18n.T(localizer, "error.invalid_callback"))
+		return
+	}
+
+	showGenres := false
+	changed := true
+
+	switch parts[1] {
+	case "rating":
+		prefs.MinRating = nextRating(prefs.MinRating)
+	case "years":
+		next := nextYearRange(yearRange{prefs.MinYear, prefs.MaxYear})
+		prefs.MinYear, prefs.MaxYear = next.From, next.To
+	case "parental":
+		prefs.MaxParentalRating = nextParentalRating(prefs.MaxParentalRating)
+	case "genre":
+		if len(parts) != 3 {
+			answer(i18n.T(localizer, "error.invalid_callback"))
+			return
+		}
+		cycleGenre(prefs, parts[2])
+		showGenres = true
+	case "genres":
+		showGenres = true
+		changed = false
+	case "back":
+		changed = false
+	case "reset":
+		prefs = &models.ContentPreferences{}
+	case "types":
+		answer("")
+		b.sendTypesKeyboard(ctx, chatID, localizer)
+		return
+	default:
+		slog.Error("Unknown preferences action", "callback_data", callbackData)
+		answer(i18n.T(localizer, "error.invalid_callback"))
+		return
+	}
+
+	if changed {
+		if err := b.db.SetContentPreferences(chatID, prefs); err != nil {
+			slog.Error("Failed to save content preferences",
+				"chat_id", chatID,
+				"error", err)
+			answer(i18n.T(localizer, "preferences.error"))
+			return
+		}
+		answer(i18n.T(localizer, "preferences.saved"))
+	} else {
+		answer("")
+	}
+
+	// Redraw the menu in place
+	text := formatPreferences(prefs, localizer)
+	keyboard := createPreferencesKeyboard(prefs, localizer)
+	if showGenres {
+		text = i18n.T(localizer, "preferences.genres_prompt")
+		keyboard = createGenresKeyboard(prefs, localizer)
+	}
+
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to update preferences message",
+			"chat_id", chatID,
+			"message_id", messageID,
+			"error", err)
+	}
+
+	if changed {
+		slog.Info("Content preferences updated",
+			"chat_id", chatID,
+			"min_rating", prefs.MinRating,
+			"min_year", prefs.MinYear,
+			"max_year", prefs.MaxYear,
+			"max_parental_rating", prefs.MaxParentalRating,
+			"allowed_genres", prefs.AllowedGenres,
+			"blocked_genres", prefs.BlockedGenres)
+	}
+}
+
+// formatPreferences renders a summary of the subscriber's content filters
+func formatPreferences(prefs *models.ContentPreferences, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+
+	message.WriteString(i18n.T(localizer, "preferences.title"))
+	message.WriteString("\n\n")
+	message.WriteString(i18n.TWithData(localizer, "preferences.min_rating", map[string]interface{}{
+		"Value": ratingLabel(prefs.MinRating, localizer),
+	}))
+	message.WriteString("\n")
+	message.WriteString(i18n.TWithData(localizer, "preferences.years", map[string]interface{}{
+		"Value": yearRangeLabel(yearRange{prefs.MinYear, prefs.MaxYear}, localizer),
+	}))
+	message.WriteString("\n")
+	message.WriteString(i18n.TWithData(localizer, "preferences.parental", map[string]interface{}{
+		"Value": parentalLabel(prefs.MaxParentalRating, localizer),
+	}))
+	message.WriteString("\n")
+	message.WriteString(i18n.TWithData(localizer, "preferences.genres", map[string]interface{}{
+		"Value": genresLabel(prefs, localizer),
+	}))
+
+	return message.String()
+}
+
+// createPreferencesKeyboard creates the main /preferences keyboard
+func createPreferencesKeyboard(prefs *models.ContentPreferences, localizer *goi18n.Localizer) *botModels.InlineKeyboardMarkup {
+	return &botModels.InlineKeyboardMarkup{
+		InlineKeyboard: [][]botModels.InlineKeyboardButton{
+			{
+				{
+					Text: i18n.TWithData(localizer, "button.preferences.rating", map[string]interface{}{
+						"Value": ratingLabel(prefs.MinRating, localizer),
+					}),
+					CallbackData: "pref:rating",
+				},
+			},
+			{
+				{
+					Text: i18n.TWithData(localizer, "button.preferences.years", map[string]interface{}{
+						"Value": yearRangeLabel(yearRange{prefs.MinYear, prefs.MaxYear}, localizer),
+					}),
+					CallbackData: "pref:years",
+				},
+			},
+			{
+				{
+					Text: i18n.TWithData(localizer, "button.preferences.parental", map[string]interface{}{
+						"Value": parentalLabel(prefs.MaxParentalRating, localizer),
+					}),
+					CallbackData: "pref:parental",
+				},
+			},
+			{
+				{
+					Text:         i18n.T(localizer, "button.preferences.genres"),
+					CallbackData: "pref:genres",
+				},
+				{
+					Text:         i18n.T(localizer, "button.preferences.types"),
+					CallbackData: "pref:types",
+				},
+			},
+			{
+				{
+					Text:         i18n.T(localizer, "button.preferences.reset"),
+					CallbackData: "pref:reset",
+				},
+			},
+		},
+	}
+}
+
+// createGenresKeyboard creates the genre filter keyboard, two genres per row
+func createGenresKeyboard(prefs *models.ContentPreferences, localizer *goi18n.Localizer) *botModels.InlineKeyboardMarkup {
+	allowed := prefs.AllowedGenreList()
+	blocked := prefs.BlockedGenreList()
+
+	rows := make([][]botModels.InlineKeyboardButton, 0, len(preferenceGenres)/2+2)
+	for i, genre := range preferenceGenres {
+		text := genre
+		if containsGenre(allowed, genre) {
+			text = i18n.TWithData(localizer, "preferences.genre.allowed", map[string]interface{}{"Genre": genre})
+		} else if containsGenre(blocked, genre) {
+			text = i18n.TWithData(localizer, "preferences.genre.blocked", map[string]interface{}{"Genre": genre})
+		}
+
+		button := botModels.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: fmt.Sprintf("pref:genre:%s", genre),
+		}
+
+		if i%2 == 0 {
+			rows = append(rows, []botModels.InlineKeyboardButton{button})
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+
+	rows = append(rows, []botModels.InlineKeyboardButton{
+		{
+			Text:         i18n.T(localizer, "button.back"),
+			CallbackData: "pref:back",
+		},
+	})
+
+	return &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// cycleGenre moves a genre through neutral → allowed → blocked → neutral
+func cycleGenre(prefs *models.ContentPreferences, genre string) {
+	allowed := prefs.AllowedGenreList()
+	blocked := prefs.BlockedGenreList()
+
+	switch {
+	case containsGenre(allowed, genre):
+		allowed = removeGenre(allowed, genre)
+		blocked = append(blocked, genre)
+	case containsGenre(blocked, genre):
+		blocked = removeGenre(blocked, genre)
+	default:
+		allowed = append(allowed, genre)
+	}
+
+	prefs.AllowedGenres = strings.Join(allowed, ",")
+	prefs.BlockedGenres = strings.Join(blocked, ",")
+}
+
+func containsGenre(genres []string, genre string) bool {
+	for _, g := range genres {
+		if strings.EqualFold(g, genre) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeGenre(genres []string, genre string) []string {
+	result := make([]string, 0, len(genres))
+	for _, g := range genres {
+		if !strings.EqualFold(g, genre) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// nextRating returns the rating option following current, wrapping around
+func nextRating(current float64) float64 {
+	for i, option := range ratingOptions {
+		if option == current {
+			return ratingOptions[(i+1)%len(ratingOptions)]
+		}
+	}
+	return ratingOptions[0]
+}
+
+// nextYearRange returns the year range option following current, wrapping around
+func nextYearRange(current yearRange) yearRange {
+	for i, option := range yearOptions {
+		if option == current {
+			return yearOptions[(i+1)%len(yearOptions)]
+		}
+	}
+	return yearOptions[0]
+}
+
+// nextParentalRating returns the parental rating ceiling following current, wrapping around to no ceiling
+func nextParentalRating(current string) string {
+	if current == "" {
+		return models.ParentalRatings[0]
+	}
+	for i, option := range models.ParentalRatings {
+		if option == current && i+1 < len(models.ParentalRatings) {
+			return models.ParentalRatings[i+1]
+		}
+	}
+	return ""
+}
+
+func ratingLabel(rating float64, localizer *goi18n.Localizer) string {
+	if rating <= 0 {
+		return i18n.T(localizer, "preferences.any")
+	}
+	return i18n.TWithData(localizer, "preferences.rating_value", map[string]interface{}{
+		"Rating": strconv.FormatFloat(rating, 'f', -1, 64),
+	})
+}
+
+func yearRangeLabel(r yearRange, localizer *goi18n.Localizer) string {
+	switch {
+	case r.From > 0 && r.To > 0:
+		return fmt.Sprintf("%d–%d", r.From, r.To)
+	case r.From > 0:
+		return i18n.TWithData(localizer, "preferences.years_from", map[string]interface{}{"Year": r.From})
+	case r.To > 0:
+		return i18n.TWithData(localizer, "preferences.years_until", map[string]interface{}{"Year": r.To})
+	default:
+		return i18n.T(localizer, "preferences.any")
+	}
+}
+
+func parentalLabel(rating string, localizer *goi18n.Localizer) string {
+	if rating == "" {
+		return i18n.T(localizer, "preferences.any")
+	}
+	return rating
+}
+
+func genresLabel(prefs *models.ContentPreferences, localizer *goi18n.Localizer) string {
+	var parts []string
+	for _, genre := range prefs.AllowedGenreList() {
+		parts = append(parts, i18n.TWithData(localizer, "preferences.genre.allowed", map[string]interface{}{"Genre": genre}))
+	}
+	for _, genre := range prefs.BlockedGenreList() {
+		parts = append(parts, i18n.TWithData(localizer, "preferences.genre.blocked", map[string]interface{}{"Genre": genre}))
+	}
+
+	if len(parts) == 0 {
+		return i18n.T(localizer, "preferences.any")
+	}
+	return strings.Join(parts, ", ")
+}
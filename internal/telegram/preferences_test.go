@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Test 1: Subscribers only receive content matching their preferences
+func TestResolveRecipients_AppliesContentPreferences(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{100, 200, 300, 400}
+	db.SetContentPreferences(200, &models.ContentPreferences{MinRating: 8})
+	db.SetContentPreferences(300, &models.ContentPreferences{BlockedGenres: "Horror"})
+	db.SetContentPreferences(400, &models.ContentPreferences{MaxParentalRating: "PG-13"})
+
+	bot := &Bot{db: db}
+
+	recipients, _, err := bot.resolveRecipients(context.Background(), &NotificationContent{
+		ItemID:         "movie1",
+		Type:           "Movie",
+		Title:          "Alien",
+		Rating:         7.5,
+		OfficialRating: "R",
+		Genres:         []string{"Horror", "Science Fiction"},
+	})
+	if err != nil {
+		t.Fatalf("resolveRecipients failed: %v", err)
+	}
+
+	if len(recipients) != 1 || recipients[0] != 100 {
+		t.Errorf("Expected only the unfiltered user to be notified, got %v", recipients)
+	}
+}
+
+// Test 2: Missing metadata never filters content out, apart from the parental rating (Test 6)
+func TestContentPreferences_UnknownMetadataPasses(t *testing.T) {
+	prefs := &models.ContentPreferences{
+		MinRating:     8,
+		AllowedGenres: "Drama",
+		MinYear:       2010,
+	}
+
+	if !prefs.Allows(0, 0, "", nil) {
+		t.Error("Content without metadata should pass every filter")
+	}
+	if prefs.Allows(0, 0, "", []string{"Comedy"}) {
+		t.Error("Content outside the allowed genres should be filtered")
+	}
+	if !prefs.Allows(0, 0, "", []string{"comedy", "drama"}) {
+		t.Error("Genre matching should ignore case")
+	}
+	if prefs.Allows(0, 2005, "", nil) {
+		t.Error("Content before the minimum year should be filtered")
+	}
+}
+
+// Test 3: Genre buttons cycle through allowed, blocked and neutral
+func TestCycleGenre(t *testing.T) {
+	prefs := &models.ContentPreferences{}
+
+	cycleGenre(prefs, "Drama")
+	if prefs.AllowedGenres != "Drama" || prefs.BlockedGenres != "" {
+		t.Errorf("Expected Drama to be allowed, got %+v", prefs)
+	}
+
+	cycleGenre(prefs, "Drama")
+	if prefs.AllowedGenres != "" || prefs.BlockedGenres != "Drama" {
+		t.Errorf("Expected Drama to be blocked, got %+v", prefs)
+	}
+
+	cycleGenre(prefs, "Drama")
+	if prefs.AllowedGenres != "" || prefs.BlockedGenres != "" {
+		t.Errorf("Expected Drama to be cleared, got %+v", prefs)
+	}
+}
+
+// Test 4: Option buttons wrap around to "any"
+func TestPreferenceOptionsWrapAround(t *testing.T) {
+	rating := 0.0
+	for range ratingOptions {
+		rating = nextRating(rating)
+	}
+	if rating != 0 {
+		t.Errorf("Expected rating to wrap around to any, got %v", rating)
+	}
+
+	parental := ""
+	for i := 0; i <= len(models.ParentalRatings); i++ {
+		parental = nextParentalRating(parental)
+	}
+	if parental != "" {
+		t.Errorf("Expected parental rating to wrap around to any, got %q", parental)
+	}
+
+	r := yearRange{}
+	for range yearOptions {
+		r = nextYearRange(r)
+	}
+	if r != (yearRange{}) {
+		t.Errorf("Expected year range to wrap around to any, got %+v", r)
+	}
+}
+
+// Test 5: Types keyboard reflects the disabled item types
+func TestCreateTypesKeyboard(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.SetItemTypeEnabled(100, "Book", false)
+
+	bot := &Bot{db: db}
+	keyboard, err := bot.createTypesKeyboard(100, getTestLocalizer())
+	if err != nil {
+		t.Fatalf("createTypesKeyboard failed: %v", err)
+	}
+
+	buttons := 0
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			buttons++
+			if button.CallbackData == "types:Book" && !contains(button.Text, "❌") {
+				t.Errorf("Expected Book to be shown as disabled, got %q", button.Text)
+			}
+			if button.CallbackData == "types:Movie" && !contains(button.Text, "✅") {
+				t.Errorf("Expected Movie to be shown as enabled, got %q", button.Text)
+			}
+		}
+	}
+
+	if buttons != len(models.SupportedItemTypes) {
+		t.Errorf("Expected %d buttons, got %d", len(models.SupportedItemTypes), buttons)
+	}
+}
+
+// Test 6: A parental rating ceiling understands non-US ratings and keeps unrated content out
+func TestContentPreferences_ParentalCeiling(t *testing.T) {
+	prefs := &models.ContentPreferences{MaxParentalRating: "PG-13"}
+
+	tests := []struct {
+		rating string
+		want   bool
+	}{
+		{"PG", true},
+		{"TV-14", true},
+		{"GB-12A", true},
+		{"DE-12", true},
+		{"FSK-6", true},
+		{"U", true},
+		{"R", false},
+		{"NC-17", false},
+		{"18", false},
+		{"FSK-18", false},
+		{"GB-18", false},
+		{"GB-15", false},
+		{"DE-16", false},
+		{"AU-MA15+", false},
+		{"", false},
+		{"NR", false},
+		{"Unrated", false},
+		{"Not Rated", false},
+	}
+
+	for _, tt := range tests {
+		if got := prefs.Allows(0, 0, tt.rating, nil); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.rating, got, tt.want)
+		}
+	}
+
+	// Without a ceiling unrated content isn't filtered
+	if !(&models.ContentPreferences{}).Allows(0, 0, "NR", nil) {
+		t.Error("Expected unrated content to pass without a ceiling")
+	}
+}
@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// catchUpMaxLines caps the number of items listed in a catch-up message to stay below Telegram's size limit
+const catchUpMaxLines = 30
+
+// handleQuiet handles the /quiet command
+// Usage: "/quiet" shows the current window, "/quiet 23:00-08:00" sets it, "/quiet off" disables it
+func (b *Bot) handleQuiet(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/quiet"))
+
+	slog.Info("Processing /quiet command",
+		"chat_id", chatID,
+		"argument", arg)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	schedule, err := b.db.GetDeliverySchedule(chatID)
+	if err != nil {
+		slog.Error("Failed to load delivery schedule",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "quiet.error"))
+		return
+	}
+
+	var message string
+	switch {
+	case arg == "":
+		if schedule.QuietStart == "" {
+			message = i18n.T(localizer, "quiet.status_off")
+		} else {
+			message = i18n.TWithData(localizer, "quiet.status", map[string]interface{}{
+				"Start":    schedule.QuietStart,
+				"End":      schedule.QuietEnd,
+				"Timezone": b.scheduleLocation(schedule).String(),
+			})
+		}
+		b.sendReply(ctx, chatID, message)
+		return
+	case strings.EqualFold(arg, "off"):
+		schedule.QuietStart = ""
+		schedule.QuietEnd = ""
+		message = i18n.T(localizer, "quiet.disabled")
+	default:
+		start, end, parseErr := parseQuietHours(arg)
+		if parseErr != nil {
+			b.sendReply(ctx, chatID, i18n.T(localizer, "quiet.invalid"))
+			return
+		}
+		schedule.QuietStart = start
+		schedule.QuietEnd = end
+		message = i18n.TWithData(localizer, "quiet.set", map[string]interface{}{
+			"Start":    start,
+			"End":      end,
+			"Timezone": b.scheduleLocation(schedule).String(),
+		})
+	}
+
+	if err := b.db.SetDeliverySchedule(chatID, schedule); err != nil {
+		slog.Error("Failed to save quiet hours",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "quiet.error"))
+		return
+	}
+
+	b.sendReply(ctx, chatID, message)
+}
+
+// handleTimezone handles the /timezone command
+// Usage: "/timezone" shows the current time zone, "/timezone Europe/Berlin" sets it
+func (b *Bot) handleTimezone(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/timezone"))
+
+	slog.Info("Processing /timezone command",
+		"chat_id", chatID,
+		"argument", arg)
+
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	schedule, err := b.db.GetDeliverySchedule(chatID)
+	if err != nil {
+		slog.Error("Failed to load delivery schedule",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "timezone.error"))
+		return
+	}
+
+	if arg == "" {
+		loc := b.scheduleLocation(schedule)
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "timezone.status", map[string]interface{}{
+			"Timezone": loc.String(),
+			"Time":     time.Now().In(loc).Format("15:04"),
+		}))
+		return
+	}
+
+	loc, err := time.LoadLocation(arg)
+	if err != nil || arg == "Local" {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "timezone.invalid", map[string]interface{}{
+			"Timezone": arg,
+		}))
+		return
+	}
+
+	schedule.Timezone = loc.String()
+	if err := b.db.SetDeliverySchedule(chatID, schedule); err != nil {
+		slog.Error("Failed to save time zone",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "timezone.error"))
+		return
+	}
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "timezone.set", map[string]interface{}{
+		"Timezone": loc.String(),
+		"Time":     time.Now().In(loc).Format("15:04"),
+	}))
+}
+
+// sendReply sends a text message and logs a failure
+func (b *Bot) sendReply(ctx context.Context, chatID int64, text string) {
+	if err := b.SendMessage(ctx, chatID, text); err != nil {
+		slog.Error("Failed to send message",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// scheduleLocation returns the time zone of a subscriber, falling back to the configured default
+func (b *Bot) scheduleLocation(schedule *models.DeliverySchedule) *time.Location {
+	name := schedule.Timezone
+	if name == "" && b.config != nil {
+		name = b.config.Notification.DefaultTimezone
+	}
+	if name == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		slog.Warn("Unknown time zone, using UTC",
+			"timezone", name,
+			"error", err)
+		return time.UTC
+	}
+	return loc
+}
+
+// quietHoursEnd reports whether now falls into the subscriber's quiet hours and when they end
+func (b *Bot) quietHoursEnd(chatID int64, now time.Time) (time.Time, bool) {
+	schedule, err := b.db.GetDeliverySchedule(chatID)
+	if err != nil {
+		// Deliver rather than hold back notifications we can't classify
+		slog.Warn("Failed to load delivery schedule, delivering immediately",
+			"chat_id", chatID,
+			"error", err)
+		return time.Time{}, false
+	}
+
+	return quietWindowEnd(schedule, b.scheduleLocation(schedule), now)
+}
+
+// quietWindowEnd returns the end of the quiet window containing now, if any
+// Windows may wrap around midnight (e.g. 23:00-08:00); equal start and end disable them
+func quietWindowEnd(schedule *models.DeliverySchedule, loc *time.Location, now time.Time) (time.Time, bool) {
+	if schedule.QuietStart == "" || schedule.QuietEnd == "" {
+		return time.Time{}, false
+	}
+
+	start, err := parseClock(schedule.QuietStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(schedule.QuietEnd)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+
+	if start < end {
+		if minute >= start && minute < end {
+			return endToday, true
+		}
+		return time.Time{}, false
+	}
+
+	// Window wraps around midnight
+	if minute >= start {
+		return endToday.AddDate(0, 0, 1), true
+	}
+	if minute < end {
+		return endToday, true
+	}
+	return time.Time{}, false
+}
+
+// parseQuietHours parses a window like "23:00-08:00" into normalized start and end times
+func parseQuietHours(value string) (string, string, error) {
+	parts := strings.Split(strings.ReplaceAll(value, "–", "-"), "-")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid quiet hours %q", value)
+	}
+
+	start, err := parseClock(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return "", "", err
+	}
+	end, err := parseClock(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return "", "", err
+	}
+	if start == end {
+		return "", "", fmt.Errorf("quiet hours %q have no length", value)
+	}
+
+	return formatClock(start), formatClock(end), nil
+}
+
+// parseClock parses an "HH:MM" time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock formats minutes after midnight as "HH:MM"
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// deliverCatchUp sends the notifications held back during a subscriber's quiet hours
+// A single notification is sent as usual; several are summarized in one message
+func (b *Bot) deliverCatchUp(ctx context.Context, chatID int64, contents []NotificationContent) error {
+	if len(contents) == 1 {
+		_, err := b.deliverNotification(ctx, chatID, &contents[0], b.fetchPoster(ctx, &contents[0]))
+		return err
+	}
+
+	localizer := b.getLocalizerForUser(ctx, chatID, "")
+	return b.SendMessage(ctx, chatID, formatCatchUp(contents, localizer))
+}
+
+// formatCatchUp formats the summary of notifications held back during quiet hours
+func formatCatchUp(contents []NotificationContent, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+
+	message.WriteString(i18n.TWithData(localizer, "catchup.header", map[string]interface{}{
+		"Count": len(contents),
+	}))
+	message.WriteString("\n")
+
+	for i := range contents {
+		if i == catchUpMaxLines {
+			message.WriteString("\n")
+			message.WriteString(i18n.TWithData(localizer, "catchup.more", map[string]interface{}{
+				"Count": len(contents) - catchUpMaxLines,
+			}))
+			break
+		}
+		message.WriteString("\n")
+		message.WriteString(formatSummaryLine(&contents[i], localizer))
+	}
+
+	return message.String()
+}
@@ -0,0 +1,111 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Test 1: Quiet hours arguments are validated and normalized
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantStart string
+		wantEnd   string
+		wantErr   bool
+	}{
+		{"23:00-08:00", "23:00", "08:00", false},
+		{"9:30 - 17:00", "09:30", "17:00", false},
+		{"22:00–07:00", "22:00", "07:00", false},
+		{"08:00-08:00", "", "", true},
+		{"25:00-08:00", "", "", true},
+		{"tonight", "", "", true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := parseQuietHours(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseQuietHours(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("parseQuietHours(%q) = %s-%s, want %s-%s", tt.input, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+// Test 2: Quiet windows wrapping around midnight end on the right day
+func TestQuietWindowEnd(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tehran")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	schedule := &models.DeliverySchedule{QuietStart: "23:00", QuietEnd: "08:00"}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		quiet   bool
+		wantEnd time.Time
+	}{
+		{"before midnight", time.Date(2024, 3, 1, 23, 30, 0, 0, loc), true, time.Date(2024, 3, 2, 8, 0, 0, 0, loc)},
+		{"after midnight", time.Date(2024, 3, 2, 2, 0, 0, 0, loc), true, time.Date(2024, 3, 2, 8, 0, 0, 0, loc)},
+		{"daytime", time.Date(2024, 3, 2, 12, 0, 0, 0, loc), false, time.Time{}},
+		{"window end", time.Date(2024, 3, 2, 8, 0, 0, 0, loc), false, time.Time{}},
+		{"other time zone", time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC), true, time.Date(2024, 3, 2, 8, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		end, quiet := quietWindowEnd(schedule, loc, tt.now)
+		if quiet != tt.quiet || !end.Equal(tt.wantEnd) {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", tt.name, end, quiet, tt.wantEnd, tt.quiet)
+		}
+	}
+
+	// Same-day window
+	daytime := &models.DeliverySchedule{QuietStart: "09:00", QuietEnd: "17:00"}
+	if _, quiet := quietWindowEnd(daytime, loc, time.Date(2024, 3, 2, 20, 0, 0, 0, loc)); quiet {
+		t.Error("20:00 should be outside 09:00-17:00")
+	}
+	if _, quiet := quietWindowEnd(&models.DeliverySchedule{}, loc, time.Now()); quiet {
+		t.Error("Empty schedule should never be quiet")
+	}
+}
+
+// Test 3: Subscribers without a time zone use the configured default
+func TestQuietHoursEnd_UsesSubscriberSchedule(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.SetDeliverySchedule(100, &models.DeliverySchedule{Timezone: "UTC", QuietStart: "22:00", QuietEnd: "06:00"})
+	bot := &Bot{db: db}
+
+	if _, quiet := bot.quietHoursEnd(100, time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)); !quiet {
+		t.Error("Expected 23:00 UTC to be inside the quiet hours")
+	}
+	if _, quiet := bot.quietHoursEnd(200, time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)); quiet {
+		t.Error("Subscribers without quiet hours should never be deferred")
+	}
+}
+
+// Test 4: Catch-up messages list every held notification
+func TestFormatCatchUp(t *testing.T) {
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+	localizer := i18n.GetLocalizer(bundle, "en")
+
+	message := formatCatchUp([]NotificationContent{
+		{Type: "Movie", Title: "Arrival", Year: 2016},
+		{Type: "Episode", SeriesName: "Severance", SeasonNumber: 2, EpisodeCount: 3, FirstEpisode: 1, LastEpisode: 3},
+		{Type: "MusicAlbum", Title: "Blue"},
+	}, localizer)
+
+	for _, want := range []string{"3 new items", "Arrival (2016)", "Severance — S02, 3 episodes (E01–E03)", "Album: Blue"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected catch-up to contain %q, got:\n%s", want, message)
+		}
+	}
+}
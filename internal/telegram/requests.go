@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
+)
+
+// maxOpenRequestsPerUser bounds the requests of a single user waiting for a decision or the title
+const maxOpenRequestsPerUser = 10
+
+// RequestStore defines the interface for title request operations
+type RequestStore interface {
+	AddTitleRequest(request *models.TitleRequest) error
+	GetOpenTitleRequests() ([]models.TitleRequest, error)
+	GetOpenTitleRequestsByUser(chatID int64) ([]models.TitleRequest, error)
+	DecideTitleRequest(id uint, status string, adminChatID int64) (*models.TitleRequest, error)
+	SetTitleRequestExternalID(id uint, externalID string) error
+	MarkTitleRequestAvailable(id uint, itemID string) error
+}
+
+// RequestForwarder passes approved title requests on to a request manager like Overseerr or Jellyseerr
+type RequestForwarder interface {
+	ForwardRequest(ctx context.Context, request *models.TitleRequest) (string, error)
+}
+
+// SetRequestStore enables /request
+func (b *Bot) SetRequestStore(store RequestStore) {
+	b.requests = store
+}
+
+// SetRequestForwarder forwards approved title requests; without one admins add the titles themselves
+func (b *Bot) SetRequestForwarder(forwarder RequestForwarder) {
+	b.forwarder = forwarder
+}
+
+// handleRequest handles the /request command
+// Usage: "/request <title> [year]" asks the admins to add a title, "/request" lists open requests
+func (b *Bot) handleRequest(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	// Registered by prefix, so other commands starting with /request land here too
+	args, ok := b.matchCommand(ctx, update, "/request")
+	if !ok {
+		b.defaultHandler(ctx, botInstance, update)
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	slog.Info("Processing /request command", "chat_id", chatID)
+
+	if b.requests == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "error.generic"))
+		return
+	}
+
+	existing, err := b.requests.GetOpenTitleRequestsByUser(chatID)
+	if err != nil {
+		slog.Error("Failed to load title requests",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "request.error"))
+		return
+	}
+
+	wanted, ok := parseWantedTitle(strings.Join(args, " "))
+	if !ok {
+		usage := i18n.T(localizer, "request.usage")
+		if len(existing) > 0 {
+			usage = formatTitleRequests(existing, localizer) + "\n\n" + usage
+		}
+		b.sendReply(ctx, chatID, usage)
+		return
+	}
+	label := titleLabel(wanted)
+
+	// Overseerr can only look titles up by name or TMDB ID
+	if b.forwarder != nil && wanted.Title == "" && wanted.TmdbID == "" {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "request.imdb_only"))
+		return
+	}
+
+	for i := range existing {
+		if sameTitle(&existing[i].WantedTitle, wanted) {
+			b.sendReply(ctx, chatID, i18n.TWithData(localizer, "request.duplicate", map[string]interface{}{
+				"Title": label,
+			}))
+			return
+		}
+	}
+	if len(existing) >= maxOpenRequestsPerUser {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "request.limit", map[string]interface{}{
+			"Max": maxOpenRequestsPerUser,
+		}))
+		return
+	}
+
+	// Don't bother the admins with titles that are already there
+	if item := b.findWantedItem(ctx, chatID, wanted); item != nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "request.available"))
+		b.sendContentItem(ctx, chatID, item, localizer)
+		return
+	}
+
+	request := &models.TitleRequest{ChatID: chatID, WantedTitle: *wanted}
+	if err := b.requests.AddTitleRequest(request); err != nil {
+		slog.Error("Failed to add title request",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "request.error"))
+		return
+	}
+
+	slog.Info("Title request created",
+		"chat_id", chatID,
+		"request_id", request.ID,
+		"title", request.Title,
+		"year", request.Year)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "request.created", map[string]interface{}{
+		"Title": label,
+	}))
+
+	from := update.Message.From
+	b.notifyAdminsOfTitleRequest(ctx, request, &models.Subscriber{ChatID: chatID, Username: from.Username, FirstName: from.FirstName})
+}
+
+// formatTitleRequests formats the open requests of a user with their status
+func formatTitleRequests(requests []models.TitleRequest, localizer *goi18n.Localizer) string {
+	var message strings.Builder
+	message.WriteString(i18n.T(localizer, "request.list_title"))
+	message.WriteString("\n")
+	for i := range requests {
+		message.WriteString(fmt.Sprintf("\n%d. %s — %s", i+1,
+			titleLabel(&requests[i].WantedTitle),
+			i18n.T(localizer, "request.status_"+requests[i].Status)))
+	}
+	return message.String()
+}
+
+// notifyAdminsOfTitleRequest sends every admin a title request with approve and decline buttons
+func (b *Bot) notifyAdminsOfTitleRequest(ctx context.Context, request *models.TitleRequest, requester *models.Subscriber) {
+	if b.admin == nil {
+		return
+	}
+
+	admins, err := b.admin.GetAdmins()
+	if err != nil {
+		slog.Error("Failed to load admins for title request", "error", err)
+		return
+	}
+	if len(admins) == 0 {
+		slog.Warn("Title request can't be approved: no admins configured",
+			"request_id", request.ID)
+		return
+	}
+
+	for _, admin := range admins {
+		localizer := b.getLocalizerForUser(ctx, admin.ChatID, "")
+		text := i18n.TWithData(localizer, "request.admin", map[string]interface{}{
+			"Name":   subscriberName(requester),
+			"ChatID": requester.ChatID,
+			"Title":  titleLabel(&request.WantedTitle),
+		})
+		keyboard := &botModels.InlineKeyboardMarkup{
+			InlineKeyboard: [][]botModels.InlineKeyboardButton{
+				{
+					{
+						Text:         i18n.T(localizer, "button.approve"),
+						CallbackData: fmt.Sprintf("request:approve:%d", request.ID),
+					},
+					{
+						Text:         i18n.T(localizer, "button.decline"),
+						CallbackData: fmt.Sprintf("request:decline:%d", request.ID),
+					},
+				},
+			},
+		}
+
+		if err := b.SendMessageWithKeyboard(ctx, admin.ChatID, text, keyboard); err != nil {
+			slog.Warn("Failed to send title request to admin",
+				"admin", admin.ChatID,
+				"error", err)
+		}
+	}
+}
+
+// handleRequestCallback handles the approve and decline buttons of title requests
+// Callback data format: "request:approve:{id}" or "request:decline:{id}"
+func (b *Bot) handleRequestCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	adminChatID := callbackQuery.Message.Message.Chat.ID
+	if !b.isAdmin(adminChatID) || b.requests == nil {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	decision, arg, _ := strings.Cut(strings.TrimPrefix(callbackQuery.Data, "request:"), ":")
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || (decision != "approve" && decision != "decline") {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	status := models.RequestStatusApproved
+	if decision == "decline" {
+		status = models.RequestStatusDeclined
+	}
+	request, err := b.requests.DecideTitleRequest(uint(id), status, adminChatID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Another admin already decided, or the title arrived in the meantime
+		b.answerCallback(ctx, botInstance, callbackQuery, "request.already_handled", true)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to decide title request",
+			"request_id", id,
+			"decision", decision,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "admin.error", false)
+		return
+	}
+
+	slog.Info("Title request decided",
+		"admin", adminChatID,
+		"request_id", request.ID,
+		"decision", decision)
+
+	botInstance.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQuery.ID,
+	})
+
+	adminLocalizer := b.getLocalizerForUser(ctx, adminChatID, callbackQuery.From.LanguageCode)
+	result := i18n.T(adminLocalizer, "request.approved_admin")
+	if status == models.RequestStatusDeclined {
+		result = i18n.T(adminLocalizer, "request.declined_admin")
+	} else if b.forwarder != nil {
+		result += "\n" + b.forwardTitleRequest(ctx, request, adminLocalizer)
+	}
+
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    adminChatID,
+		MessageID: callbackQuery.Message.Message.ID,
+		Text:      callbackQuery.Message.Message.Text + "\n\n" + result,
+	})
+	if err != nil {
+		slog.Warn("Failed to update title request", "admin", adminChatID, "error", err)
+	}
+
+	userLocalizer := b.getLocalizerForUser(ctx, request.ChatID, "")
+	b.sendReply(ctx, request.ChatID, i18n.TWithData(userLocalizer, "request."+status, map[string]interface{}{
+		"Title": titleLabel(&request.WantedTitle),
+	}))
+}
+
+// forwardTitleRequest passes an approved request to the request manager and returns the outcome for the admin
+func (b *Bot) forwardTitleRequest(ctx context.Context, request *models.TitleRequest, localizer *goi18n.Localizer) string {
+	externalID, err := b.forwarder.ForwardRequest(ctx, request)
+	if err != nil {
+		slog.Error("Failed to forward title request",
+			"request_id", request.ID,
+			"error", err)
+		return i18n.T(localizer, "request.forward_failed_admin")
+	}
+
+	if err := b.requests.SetTitleRequestExternalID(request.ID, externalID); err != nil {
+		slog.Error("Failed to store forwarded request ID",
+			"request_id", request.ID,
+			"error", err)
+	}
+
+	slog.Info("Title request forwarded",
+		"request_id", request.ID,
+		"external_id", externalID)
+
+	return i18n.TWithData(localizer, "request.forwarded_admin", map[string]interface{}{
+		"ID": externalID,
+	})
+}
+
+// fulfillRequests tells every user who requested the content that it arrived and marks their requests available
+// Requests that weren't decided yet are closed as well, since the title is there anyway
+func (b *Bot) fulfillRequests(ctx context.Context, content *NotificationContent) {
+	if b.requests == nil || strings.HasPrefix(content.ItemID, "test-") {
+		return
+	}
+
+	requests, err := b.requests.GetOpenTitleRequests()
+	if err != nil {
+		slog.Error("Failed to load open title requests",
+			"item_id", content.ItemID,
+			"error", err)
+		return
+	}
+
+	target := notificationTarget(content)
+
+	for i := range requests {
+		request := &requests[i]
+		if !matchTitle(&request.WantedTitle, target) || !b.canSeeItem(ctx, request.ChatID, content.ItemID) {
+			continue
+		}
+
+		// The request stays open if the message can't be sent, so a later match can try again
+		header := i18n.TWithData(b.getLocalizerForUser(ctx, request.ChatID, ""), "request.arrived", map[string]interface{}{
+			"Title": titleLabel(&request.WantedTitle),
+		})
+		if err := b.sendArrival(ctx, request.ChatID, header, content); err != nil {
+			slog.Error("Failed to send title request notification",
+				"chat_id", request.ChatID,
+				"request_id", request.ID,
+				"error", err)
+			if isBlockedError(err) {
+				b.handleBlockedRecipient(request.ChatID, err)
+			}
+			continue
+		}
+
+		if err := b.requests.MarkTitleRequestAvailable(request.ID, content.ItemID); err != nil {
+			slog.Error("Failed to mark title request available",
+				"request_id", request.ID,
+				"error", err)
+		}
+
+		slog.Info("Title request fulfilled",
+			"chat_id", request.ChatID,
+			"request_id", request.ID,
+			"item_id", content.ItemID)
+	}
+}
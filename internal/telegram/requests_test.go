@@ -0,0 +1,264 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// mockRequestStore is an in-memory RequestStore
+type mockRequestStore struct {
+	requests []models.TitleRequest
+	nextID   uint
+}
+
+func (m *mockRequestStore) AddTitleRequest(request *models.TitleRequest) error {
+	m.nextID++
+	request.ID = m.nextID
+	request.Status = models.RequestStatusPending
+	m.requests = append(m.requests, *request)
+	return nil
+}
+
+func (m *mockRequestStore) GetOpenTitleRequests() ([]models.TitleRequest, error) {
+	var open []models.TitleRequest
+	for _, request := range m.requests {
+		if request.IsOpen() {
+			open = append(open, request)
+		}
+	}
+	return open, nil
+}
+
+func (m *mockRequestStore) GetOpenTitleRequestsByUser(chatID int64) ([]models.TitleRequest, error) {
+	var open []models.TitleRequest
+	for _, request := range m.requests {
+		if request.ChatID == chatID && request.IsOpen() {
+			open = append(open, request)
+		}
+	}
+	return open, nil
+}
+
+func (m *mockRequestStore) find(id uint) *models.TitleRequest {
+	for i := range m.requests {
+		if m.requests[i].ID == id {
+			return &m.requests[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockRequestStore) DecideTitleRequest(id uint, status string, adminChatID int64) (*models.TitleRequest, error) {
+	request := m.find(id)
+	if request == nil || request.Status != models.RequestStatusPending {
+		return nil, gorm.ErrRecordNotFound
+	}
+	request.Status = status
+	request.DecidedBy = adminChatID
+	decided := *request
+	return &decided, nil
+}
+
+func (m *mockRequestStore) SetTitleRequestExternalID(id uint, externalID string) error {
+	if request := m.find(id); request != nil {
+		request.ExternalID = externalID
+	}
+	return nil
+}
+
+func (m *mockRequestStore) MarkTitleRequestAvailable(id uint, itemID string) error {
+	request := m.find(id)
+	if request == nil || !request.IsOpen() {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	request.Status = models.RequestStatusAvailable
+	request.AvailableItemID = itemID
+	request.AvailableAt = &now
+	return nil
+}
+
+// mockRequestForwarder records forwarded requests
+type mockRequestForwarder struct {
+	forwarded []string
+	err       error
+}
+
+func (m *mockRequestForwarder) ForwardRequest(ctx context.Context, request *models.TitleRequest) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	m.forwarded = append(m.forwarded, request.Title)
+	return "7", nil
+}
+
+// Test 1: /request notifies the admins, an approval is forwarded and the requester is told,
+// a second admin decision is rejected
+func TestRequest_ApproveAndForward(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = NewMockJellyfinClient()
+	b.SetAdminStore(newMockAdminStore(100))
+	store := &mockRequestStore{}
+	b.SetRequestStore(store)
+	forwarder := &mockRequestForwarder{}
+	b.SetRequestForwarder(forwarder)
+	ctx := context.Background()
+
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request interstellar"))
+	if len(store.requests) != 0 {
+		t.Fatalf("Expected no request for an available title, got %+v", store.requests)
+	}
+
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request notfound 2021"))
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request NotFound (2021)"))
+	if len(store.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %+v", store.requests)
+	}
+
+	notified := slices.ContainsFunc(api.sent("sendMessage"), func(message sentMessage) bool {
+		return message.chatID == 100 && strings.Contains(message.text, "notfound (2021)")
+	})
+	if !notified {
+		t.Fatal("Expected the admin to get the request")
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "request:approve:1"))
+	if store.requests[0].Status != models.RequestStatusApproved || store.requests[0].ExternalID != "7" {
+		t.Errorf("Expected an approved and forwarded request, got %+v", store.requests[0])
+	}
+	if len(forwarder.forwarded) != 1 {
+		t.Errorf("Expected the request to be forwarded once, got %v", forwarder.forwarded)
+	}
+	edits := api.sent("editMessageText")
+	if len(edits) != 1 || !strings.Contains(edits[0].text, "Approved") || !strings.Contains(edits[0].text, "#7") {
+		t.Errorf("Expected the admin message to show the decision, got %+v", edits)
+	}
+	told := slices.ContainsFunc(api.sent("sendMessage"), func(message sentMessage) bool {
+		return message.chatID == 200 && strings.Contains(message.text, "was approved")
+	})
+	if !told {
+		t.Error("Expected the requester to be told about the approval")
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(100, "request:decline:1"))
+	if store.requests[0].Status != models.RequestStatusApproved || len(api.sent("editMessageText")) != 1 {
+		t.Error("Expected a decided request not to be decided again")
+	}
+
+	// Only admins decide
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request Dune"))
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "request:approve:2"))
+	if store.requests[1].Status != models.RequestStatusPending {
+		t.Errorf("Expected a non-admin decision to be ignored, got %q", store.requests[1].Status)
+	}
+}
+
+// Test 2: A failed forward still approves the request and tells the admin
+func TestRequest_ForwardFailure(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.SetAdminStore(newMockAdminStore(100))
+	store := &mockRequestStore{}
+	b.SetRequestStore(store)
+	b.SetRequestForwarder(&mockRequestForwarder{err: errors.New("connection refused")})
+	store.AddTitleRequest(&models.TitleRequest{ChatID: 200, WantedTitle: models.WantedTitle{Title: "Dune"}})
+
+	b.dispatchCallback(context.Background(), b.bot, callbackUpdate(100, "request:approve:1"))
+
+	if store.requests[0].Status != models.RequestStatusApproved || store.requests[0].ExternalID != "" {
+		t.Errorf("Expected an approved request without external ID, got %+v", store.requests[0])
+	}
+	edits := api.sent("editMessageText")
+	if len(edits) != 1 || !strings.Contains(edits[0].text, "Couldn't forward") {
+		t.Errorf("Expected the admin to be told about the failure, got %+v", edits)
+	}
+}
+
+// Test 3: A matching item makes open requests available and tells the requester once, declined ones stay closed
+func TestFulfillRequests(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	store := &mockRequestStore{}
+	b.SetRequestStore(store)
+	store.AddTitleRequest(&models.TitleRequest{ChatID: 200, WantedTitle: models.WantedTitle{Title: "Dune", Year: 2021}})
+	store.AddTitleRequest(&models.TitleRequest{ChatID: 300, WantedTitle: models.WantedTitle{Title: "Dune"}})
+	store.DecideTitleRequest(2, models.RequestStatusDeclined, 100)
+	store.AddTitleRequest(&models.TitleRequest{ChatID: 400, WantedTitle: models.WantedTitle{Title: "Toy Story 3"}})
+
+	// A sequel with a similar name is not the requested title
+	b.announceArrival(context.Background(), &NotificationContent{ItemID: "movie2", Type: "Movie", Title: "Toy Story 4", Year: 2019})
+	if len(api.sent("sendMessage")) != 0 || store.requests[2].Status != models.RequestStatusPending {
+		t.Fatalf("Expected a sequel not to fulfill the request, got %+v", store.requests[2])
+	}
+
+	content := &NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune", Year: 2021}
+	b.announceArrival(context.Background(), content)
+
+	sent := api.sent("sendMessage")
+	if len(sent) != 1 || sent[0].chatID != 200 || !strings.Contains(sent[0].text, "Your requested title has arrived: Dune (2021)") {
+		t.Fatalf("Expected one message to the requester, got %+v", sent)
+	}
+	if store.requests[0].Status != models.RequestStatusAvailable || store.requests[0].AvailableItemID != "movie1" {
+		t.Errorf("Expected the request to be available, got %+v", store.requests[0])
+	}
+
+	b.announceArrival(context.Background(), content)
+	if len(api.sent("sendMessage")) != 1 {
+		t.Error("Expected an available request not to notify twice")
+	}
+}
+
+// Test 4: Requests by IMDb ID alone are refused when they would be forwarded to Overseerr
+func TestRequest_ImdbOnlyWithForwarder(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = NewMockJellyfinClient()
+	b.SetAdminStore(newMockAdminStore(100))
+	store := &mockRequestStore{}
+	b.SetRequestStore(store)
+	b.SetRequestForwarder(&mockRequestForwarder{})
+	ctx := context.Background()
+
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request tt1160419"))
+	sent := api.sent("sendMessage")
+	if len(store.requests) != 0 || len(sent) != 1 || !strings.Contains(sent[0].text, "can't look up IMDb IDs") {
+		t.Fatalf("Expected the IMDb-only request to be refused, got %+v", sent)
+	}
+
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request notfound tt1160419"))
+	if len(store.requests) != 1 {
+		t.Errorf("Expected a request with a title and IMDb ID, got %+v", store.requests)
+	}
+}
+
+// Test 5: Only /request itself, optionally addressed to this bot, files a request
+func TestRequest_MatchesCommandExactly(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = NewMockJellyfinClient()
+	b.SetAdminStore(newMockAdminStore(100))
+	store := &mockRequestStore{}
+	b.SetRequestStore(store)
+	ctx := context.Background()
+
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/requests"))
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request@other_bot notfound"))
+	sent := api.sent("sendMessage")
+	if len(store.requests) != 0 || len(sent) != 2 || !strings.HasPrefix(sent[0].text, "Invalid command") {
+		t.Fatalf("Expected other commands to be answered as unknown, got requests %+v and messages %+v", store.requests, sent)
+	}
+
+	b.handleRequest(ctx, b.bot, commandUpdate(200, "/request@jellyfin_bot notfound 2021"))
+	if len(store.requests) != 1 || store.requests[0].Title != "notfound" || store.requests[0].Year != 2021 {
+		t.Errorf("Expected a request for notfound (2021), got %+v", store.requests)
+	}
+}
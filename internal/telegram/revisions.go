@@ -0,0 +1,399 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+)
+
+// messageDeleteWindow is how long Telegram lets bots delete their messages
+const messageDeleteWindow = 48 * time.Hour
+
+// SetRevisionPolicy enables revising notifications of items that were updated or removed in Jellyfin
+// Notifications older than editWindow are left alone; a zero editWindow disables revisions
+func (o *Outbox) SetRevisionPolicy(editWindow time.Duration, deleteMode string) {
+	o.editWindow = editWindow
+	o.deleteMode = deleteMode
+}
+
+// ReviseNotification queues an edit of the notifications sent for the content's item
+func (o *Outbox) ReviseNotification(ctx context.Context, content *handlers.NotificationContent) error {
+	if o.editWindow <= 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(convertNotificationContent(content))
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	if err := o.store.EnqueueRevision(content.ItemID, models.JobKindUpdate, string(payload)); err != nil {
+		return err
+	}
+
+	o.wakeUp()
+	return nil
+}
+
+// RetractNotification queues the retraction of the notifications sent for a removed item
+func (o *Outbox) RetractNotification(ctx context.Context, itemID string) error {
+	if o.editWindow <= 0 || o.deleteMode == "" || o.deleteMode == config.DeleteModeOff {
+		return nil
+	}
+
+	if err := o.store.EnqueueRevision(itemID, models.JobKindDelete, ""); err != nil {
+		return err
+	}
+
+	o.wakeUp()
+	return nil
+}
+
+// processRevision edits or retracts the recent notifications of a revision job's item
+// Notifications still waiting for delivery get the new content, or are dropped if the item was removed
+func (o *Outbox) processRevision(ctx context.Context, revision *models.NotificationJob) {
+	jobs, err := o.store.GetItemNotificationJobs(revision.JellyfinID, time.Now().Add(-o.editWindow))
+	if err != nil {
+		slog.Error("Failed to load notifications to revise",
+			"job_id", revision.ID,
+			"item_id", revision.JellyfinID,
+			"error", err)
+		return
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job := &jobs[i]
+		var content NotificationContent
+		if err := json.Unmarshal([]byte(job.Payload), &content); err != nil {
+			slog.Warn("Skipping notification with invalid payload", "job_id", job.ID, "error", err)
+			continue
+		}
+
+		// A season notification covers other episodes too, so one episode doesn't rewrite or remove it;
+		// it doesn't show episode titles either, but a removed episode is pointed out on it
+		if content.EpisodeCount > 1 {
+			if revision.Kind == models.JobKindDelete {
+				o.annotateBatchJob(ctx, job, &content)
+			}
+			continue
+		}
+
+		if revision.Kind == models.JobKindDelete {
+			o.retractJob(ctx, job, &content)
+		} else {
+			o.updateJob(ctx, job, &content, revision.Payload)
+		}
+	}
+}
+
+// updateJob stores the new content of a notification and edits the messages already sent
+func (o *Outbox) updateJob(ctx context.Context, job *models.NotificationJob, content *NotificationContent, payload string) {
+	var updated NotificationContent
+	if err := json.Unmarshal([]byte(payload), &updated); err != nil {
+		slog.Error("Dropping revision with invalid payload", "item_id", job.JellyfinID, "error", err)
+		return
+	}
+
+	// Jellyfin sends ItemUpdated for changes the notification doesn't show, like play counts
+	current, _ := json.Marshal(content)
+	revised, _ := json.Marshal(&updated)
+	if bytes.Equal(current, revised) {
+		return
+	}
+
+	if err := o.store.SetNotificationPayload(job.ID, string(revised)); err != nil {
+		slog.Error("Failed to update notification", "job_id", job.ID, "error", err)
+		return
+	}
+	if job.Status == models.JobStatusPending {
+		return
+	}
+
+	entries, err := o.store.GetSentDeliveries(job.ID)
+	if err != nil {
+		slog.Error("Failed to load sent deliveries", "job_id", job.ID, "error", err)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	imageData := o.deliverer.fetchPoster(ctx, &updated)
+	edited := 0
+	for i := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !o.pause(ctx) {
+			return
+		}
+		if err := o.deliverer.reviseDelivery(ctx, &entries[i], &updated, imageData); err != nil {
+			o.handleRevisionError(&entries[i], err)
+			continue
+		}
+		edited++
+	}
+
+	slog.Info("Notification edited",
+		"job_id", job.ID,
+		"item_id", job.JellyfinID,
+		"edited", edited,
+		"messages", len(entries))
+}
+
+// retractJob deletes or annotates the messages of a notification whose item was removed
+// and cancels its deliveries that weren't sent yet
+func (o *Outbox) retractJob(ctx context.Context, job *models.NotificationJob, content *NotificationContent) {
+	// Nobody was notified yet, so nobody needs to be
+	if job.Status == models.JobStatusPending {
+		if err := o.store.CompleteNotificationJob(job.ID); err != nil {
+			slog.Error("Failed to drop notification of removed item", "job_id", job.ID, "error", err)
+		}
+		return
+	}
+
+	// Recipients still waiting for the notification, or for their quiet hours catch-up, don't get it
+	cancelled, err := o.store.CancelDeliveries(job.ID)
+	if err != nil {
+		slog.Error("Failed to cancel deliveries of removed item", "job_id", job.ID, "error", err)
+		return
+	}
+	if err := o.store.CompleteNotificationJob(job.ID); err != nil {
+		slog.Error("Failed to complete notification of removed item", "job_id", job.ID, "error", err)
+	}
+
+	entries, err := o.store.GetSentDeliveries(job.ID)
+	if err != nil {
+		slog.Error("Failed to load sent deliveries", "job_id", job.ID, "error", err)
+		return
+	}
+
+	retracted := 0
+	for i := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		entry := &entries[i]
+		deleteMessage := o.deleteMode == config.DeleteModeDelete && time.Since(entry.UpdatedAt) < messageDeleteWindow
+
+		if !o.pause(ctx) {
+			return
+		}
+		if err := o.deliverer.retractDelivery(ctx, entry, content, deleteMessage); err != nil {
+			o.handleRevisionError(entry, err)
+			continue
+		}
+		retracted++
+
+		if err := o.store.ForgetDeliveryMessage(entry.ID); err != nil {
+			slog.Error("Failed to forget retracted message", "delivery_id", entry.ID, "error", err)
+		}
+	}
+
+	slog.Info("Notification retracted",
+		"job_id", job.ID,
+		"item_id", job.JellyfinID,
+		"retracted", retracted,
+		"messages", len(entries),
+		"cancelled", cancelled)
+}
+
+// annotateBatchJob marks the sent messages of a season notification as covering a removed episode
+// Its other episodes are still there, so the messages are kept and deliveries still to come go out
+func (o *Outbox) annotateBatchJob(ctx context.Context, job *models.NotificationJob, content *NotificationContent) {
+	if job.Status == models.JobStatusPending {
+		return
+	}
+
+	entries, err := o.store.GetSentDeliveries(job.ID)
+	if err != nil {
+		slog.Error("Failed to load sent deliveries", "job_id", job.ID, "error", err)
+		return
+	}
+
+	annotated := 0
+	for i := range entries {
+		if !o.pause(ctx) {
+			return
+		}
+		if err := o.deliverer.annotateRemovedEpisode(ctx, &entries[i], content); err != nil {
+			o.handleRevisionError(&entries[i], err)
+			continue
+		}
+		annotated++
+	}
+
+	slog.Info("Season notification annotated",
+		"job_id", job.ID,
+		"item_id", job.JellyfinID,
+		"annotated", annotated,
+		"messages", len(entries))
+}
+
+// pause waits between two edits to stay under Telegram's rate limit
+// It returns false as soon as the context is cancelled
+func (o *Outbox) pause(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(o.sendInterval):
+		return true
+	}
+}
+
+// handleRevisionError logs a failed edit and stops revising messages that are gone for good
+func (o *Outbox) handleRevisionError(entry *models.OutboxEntry, err error) {
+	slog.Warn("Failed to revise notification",
+		"delivery_id", entry.ID,
+		"chat_id", entry.ChatID,
+		"message_id", entry.MessageID,
+		"error", err)
+
+	if isMessageGoneError(err) || isBlockedError(err) {
+		if err := o.store.ForgetDeliveryMessage(entry.ID); err != nil {
+			slog.Error("Failed to forget delivery message", "delivery_id", entry.ID, "error", err)
+		}
+	}
+}
+
+// isMessageGoneError checks if an edit failed because the message was deleted or can't be edited anymore
+func isMessageGoneError(err error) bool {
+	errorStr := err.Error()
+	return strings.Contains(errorStr, "message to edit not found") ||
+		strings.Contains(errorStr, "message to delete not found") ||
+		strings.Contains(errorStr, "message can't be edited") ||
+		strings.Contains(errorStr, "MESSAGE_ID_INVALID")
+}
+
+// isNotModifiedError checks if an edit failed only because the message already shows the new content
+func isNotModifiedError(err error) bool {
+	return strings.Contains(err.Error(), "message is not modified")
+}
+
+// reviseDelivery edits a sent notification to show new content in the recipient's language
+// A new poster replaces the photo of photo messages; text messages stay text
+func (b *Bot) reviseDelivery(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent, imageData []byte) error {
+	localizer := b.getLocalizerForUser(ctx, entry.ChatID, "")
+	message := b.notificationMessage(content, localizer)
+	keyboard := b.notificationKeyboard(content, localizer)
+	if keyboard == nil {
+		keyboard = &botModels.InlineKeyboardMarkup{InlineKeyboard: [][]botModels.InlineKeyboardButton{}}
+	}
+
+	var err error
+	switch {
+	case entry.PhotoMessage && len(imageData) > 0:
+		_, err = b.bot.EditMessageMedia(ctx, &bot.EditMessageMediaParams{
+			ChatID:    entry.ChatID,
+			MessageID: entry.MessageID,
+			Media: &botModels.InputMediaPhoto{
+				Media:           "attach://poster.jpg",
+				Caption:         message,
+				MediaAttachment: bytes.NewReader(imageData),
+			},
+			ReplyMarkup: keyboard,
+		})
+	case entry.PhotoMessage:
+		_, err = b.bot.EditMessageCaption(ctx, &bot.EditMessageCaptionParams{
+			ChatID:      entry.ChatID,
+			MessageID:   entry.MessageID,
+			Caption:     message,
+			ReplyMarkup: keyboard,
+		})
+	default:
+		_, err = b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      entry.ChatID,
+			MessageID:   entry.MessageID,
+			Text:        message,
+			ReplyMarkup: keyboard,
+		})
+	}
+
+	if err != nil && !isNotModifiedError(err) {
+		return err
+	}
+	return nil
+}
+
+// retractDelivery deletes a sent notification of a removed item, or marks it as removed
+// Messages Telegram refuses to delete are marked instead
+func (b *Bot) retractDelivery(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent, deleteMessage bool) error {
+	if deleteMessage {
+		_, err := b.bot.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    entry.ChatID,
+			MessageID: entry.MessageID,
+		})
+		if err == nil || isBlockedError(err) {
+			return err
+		}
+		slog.Debug("Failed to delete notification, marking it as removed instead",
+			"chat_id", entry.ChatID,
+			"message_id", entry.MessageID,
+			"error", err)
+	}
+
+	localizer := b.getLocalizerForUser(ctx, entry.ChatID, "")
+	message := i18n.T(localizer, "notification.removed") + "\n\n" + b.notificationMessage(content, localizer)
+	// The buttons lead to an item that no longer exists
+	noButtons := &botModels.InlineKeyboardMarkup{InlineKeyboard: [][]botModels.InlineKeyboardButton{}}
+
+	return b.editDeliveryText(ctx, entry, message, noButtons)
+}
+
+// annotateRemovedEpisode marks a sent season notification as covering an episode that was removed
+// The buttons lead to the series and stay
+func (b *Bot) annotateRemovedEpisode(ctx context.Context, entry *models.OutboxEntry, content *NotificationContent) error {
+	localizer := b.getLocalizerForUser(ctx, entry.ChatID, "")
+	message := i18n.T(localizer, "notification.episode_removed") + "\n\n" + b.notificationMessage(content, localizer)
+	keyboard := b.notificationKeyboard(content, localizer)
+	if keyboard == nil {
+		keyboard = &botModels.InlineKeyboardMarkup{InlineKeyboard: [][]botModels.InlineKeyboardButton{}}
+	}
+
+	return b.editDeliveryText(ctx, entry, message, keyboard)
+}
+
+// editDeliveryText replaces the text, or the caption of a photo, of a sent notification
+func (b *Bot) editDeliveryText(ctx context.Context, entry *models.OutboxEntry, message string, keyboard *botModels.InlineKeyboardMarkup) error {
+	var err error
+	if entry.PhotoMessage {
+		_, err = b.bot.EditMessageCaption(ctx, &bot.EditMessageCaptionParams{
+			ChatID:      entry.ChatID,
+			MessageID:   entry.MessageID,
+			Caption:     message,
+			ReplyMarkup: keyboard,
+		})
+	} else {
+		_, err = b.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      entry.ChatID,
+			MessageID:   entry.MessageID,
+			Text:        message,
+			ReplyMarkup: keyboard,
+		})
+	}
+
+	if err != nil && !isNotModifiedError(err) {
+		return err
+	}
+	return nil
+}
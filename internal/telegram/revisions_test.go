@@ -0,0 +1,265 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/internal/config"
+	"jellyfin-telegram-bot/internal/handlers"
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Test 1: An updated item edits the messages already sent and changes the notification still to come
+func TestOutbox_RevisesUpdatedItem(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeOff)
+	ctx := context.Background()
+
+	outbox.EnqueueNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(ctx)
+
+	// Unchanged content doesn't touch the messages
+	outbox.ReviseNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(ctx)
+	if len(deliverer.revised) != 0 {
+		t.Fatalf("Expected no edits for unchanged content, got %+v", deliverer.revised)
+	}
+
+	outbox.ReviseNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune: Part One", Year: 2021})
+	outbox.processJobs(ctx)
+	if len(deliverer.revised) != 2 || deliverer.revised[1].Title != "Dune: Part One" || deliverer.revised[2].Year != 2021 {
+		t.Errorf("Expected both messages to be edited, got %+v", deliverer.revised)
+	}
+	if !strings.Contains(store.jobs[0].Payload, "Dune: Part One") {
+		t.Errorf("Expected the stored notification to be updated, got %s", store.jobs[0].Payload)
+	}
+	for _, job := range store.jobs {
+		if job.Status != models.JobStatusDone {
+			t.Errorf("Expected job %d to be done, got %q", job.ID, job.Status)
+		}
+	}
+
+	// A notification still waiting for its batch window goes out with the new content
+	store.EnqueueNotification("episode1", "Dark|S1", `{"ItemID":"episode1","Type":"Episode","SeriesName":"Dark","Title":"Secrets"}`, time.Now().Add(time.Hour))
+	outbox.ReviseNotification(ctx, &handlers.NotificationContent{ItemID: "episode1", Type: "Episode", SeriesName: "Dark", Title: "Lies"})
+	outbox.processJobs(ctx)
+	if !strings.Contains(store.jobs[3].Payload, "Lies") || deliverer.sent[100] != 1 {
+		t.Errorf("Expected the pending notification to be updated but not sent, got %s", store.jobs[3].Payload)
+	}
+}
+
+// Test 2: A removed item deletes recent messages and drops notifications that weren't sent yet
+func TestOutbox_RetractsDeletedItem(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	outbox := newTestOutbox(deliverer, store)
+	ctx := context.Background()
+
+	outbox.EnqueueNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(ctx)
+
+	// Retractions are off by default
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeOff)
+	outbox.RetractNotification(ctx, "movie1")
+	if len(store.jobs) != 1 {
+		t.Fatalf("Expected no revision job with delete mode off, got %d jobs", len(store.jobs))
+	}
+
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeDelete)
+	store.deliveries[1].UpdatedAt = time.Now().Add(-72 * time.Hour) // Too old for Telegram to delete
+	outbox.RetractNotification(ctx, "movie1")
+	outbox.processJobs(ctx)
+
+	if len(deliverer.retracted) != 2 || !deliverer.retracted[1] || deliverer.retracted[2] {
+		t.Errorf("Expected the recent message deleted and the old one annotated, got %+v", deliverer.retracted)
+	}
+	for _, entry := range store.deliveries {
+		if entry.MessageID != 0 {
+			t.Errorf("Expected retracted delivery %d to be forgotten", entry.ID)
+		}
+	}
+
+	store.EnqueueNotification("movie2", "", `{"ItemID":"movie2","Type":"Movie","Title":"Arrival"}`, time.Now().Add(time.Hour))
+	outbox.RetractNotification(ctx, "movie2")
+	outbox.processJobs(ctx)
+	if store.jobs[2].Status != models.JobStatusDone || deliverer.sent[100] != 1 {
+		t.Errorf("Expected the unsent notification to be dropped, got %q", store.jobs[2].Status)
+	}
+}
+
+// Test 3: Messages that can no longer be edited are not revised again
+func TestOutbox_ForgetsGoneMessages(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeOff)
+	ctx := context.Background()
+
+	outbox.EnqueueNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(ctx)
+
+	deliverer.reviseErr = errors.New("Bad Request: message to edit not found")
+	outbox.ReviseNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune", Year: 2021})
+	outbox.processJobs(ctx)
+
+	if store.deliveries[0].MessageID != 0 {
+		t.Error("Expected the deleted message to be forgotten")
+	}
+}
+
+// Test 4: Photo notifications get their caption edited, removed items are marked and lose their buttons
+func TestBot_ReviseAndRetractDelivery(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	ctx := context.Background()
+	content := &NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune", Year: 2021}
+
+	photo := &models.OutboxEntry{ChatID: 100, MessageID: 5, PhotoMessage: true}
+	if err := b.reviseDelivery(ctx, photo, content, nil); err != nil {
+		t.Fatalf("reviseDelivery failed: %v", err)
+	}
+	edits := api.sent("editMessageCaption")
+	if len(edits) != 1 || !strings.Contains(edits[0].text, "Dune") {
+		t.Errorf("Expected the caption to be edited, got %+v", edits)
+	}
+
+	text := &models.OutboxEntry{ChatID: 100, MessageID: 6}
+	if err := b.retractDelivery(ctx, text, content, false); err != nil {
+		t.Fatalf("retractDelivery failed: %v", err)
+	}
+	edits = api.sent("editMessageText")
+	if len(edits) != 1 || !strings.HasPrefix(edits[0].text, "🗑") {
+		t.Errorf("Expected the message to be marked as removed, got %+v", edits)
+	}
+
+	if err := b.retractDelivery(ctx, text, content, true); err != nil {
+		t.Fatalf("retractDelivery failed: %v", err)
+	}
+	if len(api.sent("deleteMessage")) != 1 || len(api.sent("editMessageText")) != 1 {
+		t.Error("Expected the message to be deleted without editing it")
+	}
+}
+
+// Test 5: A removed item cancels deliveries held back by quiet hours or waiting for a retry
+func TestOutbox_RetractCancelsUnsentDeliveries(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200, 300)
+	deliverer.quietUntil[200] = time.Now().Add(time.Hour)
+	deliverer.sendErrors[300] = errors.New("Too Many Requests: retry after 5")
+	outbox := newTestOutbox(deliverer, store)
+	outbox.maxAttempts = 5
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeAnnotate)
+	ctx := context.Background()
+
+	outbox.EnqueueNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(ctx)
+	if store.deliveries[1].Status != models.DeliveryStatusDeferred || store.deliveries[2].Status != models.DeliveryStatusPending {
+		t.Fatalf("Expected a deferred and a pending delivery, got %+v", store.deliveries)
+	}
+
+	outbox.RetractNotification(ctx, "movie1")
+	outbox.processJobs(ctx)
+
+	if len(deliverer.retracted) != 1 {
+		t.Errorf("Expected only the sent message to be retracted, got %+v", deliverer.retracted)
+	}
+	for _, entry := range store.deliveries[1:] {
+		if entry.Status != models.DeliveryStatusCancelled {
+			t.Errorf("Expected delivery to %d to be cancelled, got %q", entry.ChatID, entry.Status)
+		}
+	}
+	if store.jobs[0].Status != models.JobStatusDone {
+		t.Errorf("Expected the notification job to be done, got %q", store.jobs[0].Status)
+	}
+
+	// Once quiet hours end, the recipient gets no catch-up for the removed item
+	deliverer.quietUntil[200] = time.Time{}
+	store.deliveries[1].DeliverAfter = time.Now().Add(-time.Minute)
+	delete(deliverer.sendErrors, 300)
+	outbox.processCatchUps(ctx)
+	outbox.processJobs(ctx)
+	if len(deliverer.catchUps[200]) != 0 || deliverer.sent[300] != 0 {
+		t.Errorf("Expected no delivery for the removed item, got catch-ups %+v and %d sends", deliverer.catchUps, deliverer.sent[300])
+	}
+}
+
+// Test 6: A removed episode marks the season notification it was batched into without removing it
+func TestOutbox_AnnotatesBatchForRemovedEpisode(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.batchWindow = 10 * time.Minute
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeDelete)
+	ctx := context.Background()
+
+	for number := 1; number <= 2; number++ {
+		outbox.EnqueueNotification(ctx, &handlers.NotificationContent{
+			ItemID:        fmt.Sprintf("episode%d", number),
+			Type:          "Episode",
+			SeriesID:      "dark",
+			SeriesName:    "Dark",
+			SeasonNumber:  1,
+			EpisodeNumber: number,
+		})
+	}
+	for i := range store.jobs {
+		store.jobs[i].AvailableAt = time.Now().Add(-time.Minute)
+	}
+	outbox.processJobs(ctx)
+	if len(deliverer.contents) != 2 || deliverer.contents[0].EpisodeCount != 2 {
+		t.Fatalf("Expected a season notification for each recipient, got %+v", deliverer.contents)
+	}
+
+	// Neither an edited episode nor a removed one rewrites the season notification
+	outbox.ReviseNotification(ctx, &handlers.NotificationContent{ItemID: "episode2", Type: "Episode", SeriesID: "dark", SeriesName: "Dark", Title: "Lies"})
+	outbox.RetractNotification(ctx, "episode2")
+	outbox.processJobs(ctx)
+
+	if len(deliverer.annotated) != 2 || deliverer.annotated[1] != 1 || deliverer.annotated[2] != 1 {
+		t.Errorf("Expected both season messages to be annotated once, got %+v", deliverer.annotated)
+	}
+	if len(deliverer.revised) != 0 || len(deliverer.retracted) != 0 {
+		t.Errorf("Expected the season messages to be kept as they are, got edits %+v and retractions %+v", deliverer.revised, deliverer.retracted)
+	}
+	for _, entry := range store.deliveries {
+		if entry.MessageID == 0 {
+			t.Errorf("Expected delivery %d to keep its message", entry.ID)
+		}
+	}
+}
+
+// Test 7: Revisions stop waiting between messages when the context is cancelled
+func TestOutbox_RevisionStopsOnCancel(t *testing.T) {
+	store := &mockOutboxStore{}
+	deliverer := newMockDeliverer(100, 200)
+	outbox := newTestOutbox(deliverer, store)
+	outbox.SetRevisionPolicy(48*time.Hour, config.DeleteModeOff)
+
+	outbox.EnqueueNotification(context.Background(), &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune"})
+	outbox.processJobs(context.Background())
+
+	outbox.sendInterval = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	outbox.ReviseNotification(ctx, &handlers.NotificationContent{ItemID: "movie1", Type: "Movie", Title: "Dune", Year: 2021})
+
+	done := make(chan struct{})
+	go func() {
+		outbox.processJobs(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the revision to stop when the context was cancelled")
+	}
+	if len(deliverer.revised) != 0 {
+		t.Errorf("Expected no edits after cancellation, got %+v", deliverer.revised)
+	}
+}
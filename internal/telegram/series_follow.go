@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
+)
+
+// isSeriesFollowed checks whether a subscriber follows the series of the content
+func (b *Bot) isSeriesFollowed(chatID int64, content *NotificationContent) (bool, error) {
+	return matchSeriesEntry(chatID, content, b.db.IsSeriesFollowed)
+}
+
+// isSeriesHidden applies the subscriber's episode mode to episodes and seasons:
+// in the default mode muted series are hidden, in followed-only mode every series that isn't followed
+func (b *Bot) isSeriesHidden(chatID int64, content *NotificationContent) (bool, error) {
+	mode, err := b.db.GetEpisodeMode(chatID)
+	if err != nil {
+		return false, err
+	}
+
+	if mode == models.EpisodeModeFollowed {
+		followed, err := b.isSeriesFollowed(chatID, content)
+		return !followed, err
+	}
+	return b.isSeriesMuted(chatID, content)
+}
+
+// followSeries returns the key and name an item's series is followed under
+// Episodes and seasons follow their series, a series item follows itself
+func followSeries(itemType, itemID, title, seriesID, seriesName string) (string, string, bool) {
+	switch {
+	case itemType == models.ItemTypeSeries && itemID != "" && title != "":
+		return itemID, title, true
+	case isSeriesContent(itemType) && seriesName != "" && seriesName != "Unknown Series":
+		return seriesMuteKey(seriesID, seriesName), seriesName, true
+	default:
+		return "", "", false
+	}
+}
+
+// followButton returns the Follow button of an item's series, or nil if the item has no series
+// or the callback token can't be stored
+func (b *Bot) followButton(itemType, itemID, title, seriesID, seriesName string, localizer *goi18n.Localizer) *botModels.InlineKeyboardButton {
+	key, name, ok := followSeries(itemType, itemID, title, seriesID, seriesName)
+	if !ok {
+		return nil
+	}
+
+	callbackData, err := b.newCallbackData("follow", seriesRef{ID: key, Name: name})
+	if err != nil {
+		slog.Warn("Failed to create follow button",
+			"series_name", name,
+			"error", err)
+		return nil
+	}
+
+	return &botModels.InlineKeyboardButton{
+		Text:         i18n.T(localizer, "button.follow"),
+		CallbackData: callbackData,
+	}
+}
+
+// handleFollowing handles the /following command
+func (b *Bot) handleFollowing(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	slog.Info("Processing /following command", "chat_id", chatID)
+
+	text, keyboard, err := b.followingList(chatID, localizer)
+	if err != nil {
+		slog.Error("Failed to get followed series",
+			"chat_id", chatID,
+			"error", err)
+		b.SendMessage(ctx, chatID, i18n.T(localizer, "following.error"))
+		return
+	}
+
+	if err := b.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+		slog.Error("Failed to send following list",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// followingList formats the followed series of a subscriber with an unfollow button for each
+// and a button switching the episode mode
+func (b *Bot) followingList(chatID int64, localizer *goi18n.Localizer) (string, *botModels.InlineKeyboardMarkup, error) {
+	followed, err := b.db.GetFollowedSeriesByUser(chatID)
+	if err != nil {
+		return "", nil, err
+	}
+	mode, err := b.db.GetEpisodeMode(chatID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var messageText strings.Builder
+	var buttons [][]botModels.InlineKeyboardButton
+
+	if len(followed) == 0 {
+		messageText.WriteString(i18n.T(localizer, "following.empty"))
+	} else {
+		messageText.WriteString(i18n.T(localizer, "following.title"))
+		messageText.WriteString("\n\n")
+
+		for i, series := range followed {
+			messageText.WriteString(fmt.Sprintf("%d. %s\n", i+1, series.SeriesName))
+
+			callbackData, err := b.newCallbackData("unfollow", seriesRef{ID: series.SeriesID, Name: series.SeriesName})
+			if err != nil {
+				slog.Warn("Failed to store unfollow callback token",
+					"series_id", series.SeriesID,
+					"error", err)
+				continue
+			}
+			buttons = append(buttons, []botModels.InlineKeyboardButton{{
+				Text: i18n.TWithData(localizer, "button.unfollow", map[string]interface{}{
+					"SeriesName": series.SeriesName,
+				}),
+				CallbackData: callbackData,
+			}})
+		}
+	}
+
+	// The current mode and a button switching to the other one
+	modeKey, switchKey, switchTo := "following.mode_all", "button.episode_mode_followed", models.EpisodeModeFollowed
+	if mode == models.EpisodeModeFollowed {
+		modeKey, switchKey, switchTo = "following.mode_followed", "button.episode_mode_all", models.EpisodeModeAll
+	}
+	messageText.WriteString("\n\n")
+	messageText.WriteString(i18n.T(localizer, modeKey))
+	buttons = append(buttons, []botModels.InlineKeyboardButton{{
+		Text:         i18n.T(localizer, switchKey),
+		CallbackData: "follow_mode:" + switchTo,
+	}})
+
+	return strings.TrimSpace(messageText.String()), &botModels.InlineKeyboardMarkup{InlineKeyboard: buttons}, nil
+}
+
+// refreshFollowingList edits a /following message to the current list
+func (b *Bot) refreshFollowingList(ctx context.Context, botInstance *bot.Bot, chatID int64, messageID int, localizer *goi18n.Localizer) {
+	text, keyboard, err := b.followingList(chatID, localizer)
+	if err != nil {
+		slog.Error("Failed to get updated following list",
+			"chat_id", chatID,
+			"error", err)
+		return
+	}
+
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to refresh following message",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handleFollowCallback handles the follow button of notifications and item cards
+func (b *Bot) handleFollowCallback(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, series *seriesRef) {
+	chatID := callbackQuery.Message.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	slog.Info("Processing follow callback",
+		"chat_id", chatID,
+		"series_id", series.ID)
+
+	if err := b.db.AddFollowedSeries(chatID, series.ID, series.Name); err != nil {
+		slog.Error("Failed to add followed series",
+			"chat_id", chatID,
+			"series_id", series.ID,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "follow.error", false)
+		return
+	}
+
+	b.answerCallback(ctx, botInstance, callbackQuery, "follow.callback_success", false)
+
+	// Following only changes what arrives once the subscriber switches to followed series only
+	confirmation := i18n.TWithData(localizer, "follow.success", map[string]interface{}{
+		"SeriesName": series.Name,
+	})
+	if mode, err := b.db.GetEpisodeMode(chatID); err == nil && mode != models.EpisodeModeFollowed {
+		confirmation += "\n\n" + i18n.T(localizer, "follow.mode_hint")
+	}
+	if err := b.SendMessage(ctx, chatID, confirmation); err != nil {
+		slog.Error("Failed to send follow confirmation",
+			"chat_id", chatID,
+			"error", err)
+	}
+
+	slog.Info("Successfully followed series",
+		"chat_id", chatID,
+		"series_id", series.ID,
+		"series_name", series.Name)
+}
+
+// handleUnfollowCallback handles the unfollow buttons of the /following list
+func (b *Bot) handleUnfollowCallback(ctx context.Context, botInstance *bot.Bot, callbackQuery *botModels.CallbackQuery, series *seriesRef) {
+	chatID := callbackQuery.Message.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	slog.Info("Processing unfollow callback",
+		"chat_id", chatID,
+		"series_id", series.ID)
+
+	if err := b.db.RemoveFollowedSeries(chatID, series.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			b.answerCallback(ctx, botInstance, callbackQuery, "unfollow.not_found", false)
+		} else {
+			slog.Error("Failed to remove followed series",
+				"chat_id", chatID,
+				"series_id", series.ID,
+				"error", err)
+			b.answerCallback(ctx, botInstance, callbackQuery, "unfollow.error", false)
+			return
+		}
+	} else {
+		b.answerCallback(ctx, botInstance, callbackQuery, "unfollow.callback_success", false)
+		slog.Info("Successfully unfollowed series",
+			"chat_id", chatID,
+			"series_id", series.ID)
+	}
+
+	b.refreshFollowingList(ctx, botInstance, chatID, callbackQuery.Message.Message.ID, localizer)
+}
+
+// handleFollowModeCallback switches between notifying all series and followed series only
+// Callback data format: "follow_mode:{all|followed}"
+func (b *Bot) handleFollowModeCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	mode := strings.TrimPrefix(callbackQuery.Data, "follow_mode:")
+	if mode != models.EpisodeModeAll && mode != models.EpisodeModeFollowed {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	if err := b.db.SetEpisodeMode(chatID, mode); err != nil {
+		slog.Error("Failed to set episode mode",
+			"chat_id", chatID,
+			"mode", mode,
+			"error", err)
+		messageID := "following.error"
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			messageID = "following.not_subscribed"
+		}
+		b.answerCallback(ctx, botInstance, callbackQuery, messageID, true)
+		return
+	}
+
+	b.answerCallback(ctx, botInstance, callbackQuery, "following.mode_updated", false)
+
+	slog.Info("Episode mode updated",
+		"chat_id", chatID,
+		"mode", mode)
+
+	b.refreshFollowingList(ctx, botInstance, chatID, callbackQuery.Message.Message.ID, localizer)
+}
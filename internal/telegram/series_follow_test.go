@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Test 1: Followed-only subscribers get episodes of followed series and every new series,
+// the others keep getting everything they didn't mute
+func TestResolveRecipients_EpisodeMode(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.subscribers = []int64{100, 200, 300}
+	db.AddMutedSeries(100, "sev-id", "Severance")
+	db.SetEpisodeMode(200, models.EpisodeModeFollowed)
+	db.AddFollowedSeries(200, "sev-id", "Severance")
+	db.SetEpisodeMode(300, models.EpisodeModeFollowed)
+	bot := &Bot{db: db}
+	ctx := context.Background()
+
+	episode := &NotificationContent{ItemID: "ep1", Type: "Episode", SeriesID: "sev-id", SeriesName: "Severance"}
+	recipients, _, err := bot.resolveRecipients(ctx, episode)
+	if err != nil {
+		t.Fatalf("resolveRecipients failed: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != 200 {
+		t.Errorf("Expected only the follower, got %v", recipients)
+	}
+
+	other := &NotificationContent{ItemID: "ep2", Type: "Episode", SeriesID: "bear-id", SeriesName: "The Bear"}
+	recipients, _, _ = bot.resolveRecipients(ctx, other)
+	if len(recipients) != 1 || recipients[0] != 100 {
+		t.Errorf("Expected only the subscriber in the default mode, got %v", recipients)
+	}
+
+	series := &NotificationContent{ItemID: "new-id", Type: "Series", Title: "Pluribus"}
+	recipients, _, _ = bot.resolveRecipients(ctx, series)
+	if len(recipients) != 3 {
+		t.Errorf("Expected a new series to reach everyone, got %v", recipients)
+	}
+}
+
+// Test 2: The follow button follows the series, /following lists it, switches the mode and unfollows in place
+func TestFollowing_FollowListAndUnfollow(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	b := newCallbackTestBot(t, api, db)
+	ctx := context.Background()
+
+	button := b.followButton("Episode", "ep1", "Good News About Hell", "sev-id", "Severance", getTestLocalizer())
+	if button == nil {
+		t.Fatal("Expected a follow button")
+	}
+	if b.followButton("Movie", "movie1", "Dune", "", "", getTestLocalizer()) != nil {
+		t.Error("Expected no follow button for a movie")
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, button.CallbackData))
+	if followed, _ := db.IsSeriesFollowed(200, "sev-id"); !followed {
+		t.Fatal("Expected the series to be followed")
+	}
+	sent := api.sent("sendMessage")
+	if len(sent) != 1 || !strings.Contains(sent[0].text, "You follow Severance") || !strings.Contains(sent[0].text, "/following") {
+		t.Errorf("Expected a confirmation with the mode hint, got %+v", sent)
+	}
+
+	b.handleFollowing(ctx, b.bot, commandUpdate(200, "/following"))
+	sent = api.sent("sendMessage")
+	if list := sent[len(sent)-1].text; !strings.Contains(list, "1. Severance") || !strings.Contains(list, "all series except muted") {
+		t.Fatalf("Unexpected following list: %q", list)
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "follow_mode:followed"))
+	if mode, _ := db.GetEpisodeMode(200); mode != models.EpisodeModeFollowed {
+		t.Errorf("Expected followed-only mode, got %q", mode)
+	}
+
+	unfollow, err := b.newCallbackData("unfollow", seriesRef{ID: "sev-id", Name: "Severance"})
+	if err != nil {
+		t.Fatalf("Failed to create callback: %v", err)
+	}
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, unfollow))
+	if followed, _ := db.IsSeriesFollowed(200, "sev-id"); followed {
+		t.Error("Expected the series to be unfollowed")
+	}
+
+	edits := api.sent("editMessageText")
+	if len(edits) != 2 {
+		t.Fatalf("Expected the list to be edited twice, got %d", len(edits))
+	}
+	if !strings.Contains(edits[0].text, "only followed series") {
+		t.Errorf("Expected the new mode in the list, got %q", edits[0].text)
+	}
+	if strings.Contains(edits[1].text, "1. Severance") || !strings.Contains(edits[1].text, "don't follow any series") {
+		t.Errorf("Expected an empty list after unfollowing, got %q", edits[1].text)
+	}
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"log/slog"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// seriesRef identifies a series in mute, undo and unmute callbacks
+type seriesRef struct {
+	ID   string `json:"id"` // Key the series is muted under, see seriesMuteKey
+	Name string `json:"name"`
+}
+
+// seriesMuteKey returns the key a series is muted under: its Jellyfin ID,
+// or its name when the webhook carried no ID and the Jellyfin lookup failed
+func seriesMuteKey(seriesID, seriesName string) string {
+	if seriesID != "" {
+		return seriesID
+	}
+	return seriesName
+}
+
+// seriesCallbackData returns callback data like "mute:<token>" for an action on a series
+// The token is resolved server-side, keeping long and non-Latin names out of the 64-byte limit
+func (b *Bot) seriesCallbackData(action, seriesID, seriesName string) (string, error) {
+	return b.newCallbackData(action, seriesRef{ID: seriesMuteKey(seriesID, seriesName), Name: seriesName})
+}
+
+// unmuteCallbackData returns the callback data of a /mutedlist unmute button
+// If the token can't be stored the raw key is used, which legacySeriesRef also accepts
+func (b *Bot) unmuteCallbackData(series *models.MutedSeries) string {
+	callbackData, err := b.seriesCallbackData("unmute", series.SeriesID, series.SeriesName)
+	if err != nil {
+		slog.Warn("Failed to store unmute callback token",
+			"series_id", series.SeriesID,
+			"error", err)
+		return "unmute:" + series.SeriesID
+	}
+	return callbackData
+}
+
+// legacySeriesRef returns the series of a button sent before tokens were introduced,
+// whose callback data carries the series name itself
+func legacySeriesRef(arg string) *seriesRef {
+	return &seriesRef{ID: arg, Name: arg}
+}
+
+// isSeriesMuted checks whether a subscriber muted the series of the content
+// Mutes that couldn't be migrated to series IDs yet are matched by name
+func (b *Bot) isSeriesMuted(chatID int64, content *NotificationContent) (bool, error) {
+	return matchSeriesEntry(chatID, content, b.db.IsSeriesMuted)
+}
+
+// matchSeriesEntry looks up a mute or follow of the content's series by series ID, then by name
+func matchSeriesEntry(chatID int64, content *NotificationContent, lookup func(chatID int64, seriesID string) (bool, error)) (bool, error) {
+	if content.SeriesID != "" {
+		found, err := lookup(chatID, content.SeriesID)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	if content.SeriesName == "" {
+		return false, nil
+	}
+	return lookup(chatID, content.SeriesName)
+}
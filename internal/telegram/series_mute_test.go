@@ -0,0 +1,81 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"jellyfin-telegram-bot/internal/i18n"
+)
+
+// Test 1: Mute buttons carry a short token that resolves to the series ID and name
+func TestCreateMuteButton_UsesSeriesToken(t *testing.T) {
+	bundle, err := i18n.InitBundle()
+	if err != nil {
+		t.Fatalf("Failed to init bundle: %v", err)
+	}
+	localizer := i18n.GetLocalizer(bundle, "fa")
+
+	bot := &Bot{db: newMockSubscriberDB()}
+	content := &NotificationContent{
+		Type:       "Episode",
+		SeriesID:   "f1e2d3c4b5a697887766554433221100",
+		SeriesName: strings.Repeat("سریال بسیار طولانی ", 5),
+	}
+
+	keyboard := bot.createMuteButton(content, localizer)
+	if keyboard == nil {
+		t.Fatal("Expected a mute button")
+	}
+	data := keyboard.InlineKeyboard[0][0].CallbackData
+	if len(data) > 64 || !strings.HasPrefix(data, "mute:") {
+		t.Fatalf("Expected short mute callback data, got %q (%d bytes)", data, len(data))
+	}
+
+	series, err := loadCallbackPayload(bot, "mute", strings.TrimPrefix(data, "mute:"), legacySeriesRef)
+	if err != nil {
+		t.Fatalf("Failed to resolve callback: %v", err)
+	}
+	if series.ID != content.SeriesID || series.Name != content.SeriesName {
+		t.Errorf("Unexpected series %+v", series)
+	}
+}
+
+// Test 2: Buttons from before tokens existed still resolve to the series name
+func TestLoadCallbackPayload_LegacySeriesName(t *testing.T) {
+	bot := &Bot{db: newMockSubscriberDB()}
+
+	series, err := loadCallbackPayload(bot, "mute", "Breaking Bad", legacySeriesRef)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if series.ID != "Breaking Bad" || series.Name != "Breaking Bad" {
+		t.Errorf("Expected the legacy series name, got %+v", series)
+	}
+
+	if _, err := loadCallbackPayload(bot, "mute", "", legacySeriesRef); err == nil {
+		t.Error("Expected an error for empty callback data")
+	}
+}
+
+// Test 3: Mutes match by series ID and fall back to unmigrated name-based mutes
+func TestIsSeriesMuted_IDAndLegacyName(t *testing.T) {
+	db := newMockSubscriberDB()
+	db.AddMutedSeries(100, "bb-id", "Breaking Bad")
+	db.AddMutedSeries(200, "Breaking Bad", "Breaking Bad")
+	bot := &Bot{db: db}
+
+	content := &NotificationContent{Type: "Episode", SeriesID: "bb-id", SeriesName: "Breaking Bad"}
+	for _, chatID := range []int64{100, 200} {
+		if muted, _ := bot.isSeriesMuted(chatID, content); !muted {
+			t.Errorf("Expected user %d to have the series muted", chatID)
+		}
+	}
+
+	renamed := &NotificationContent{Type: "Episode", SeriesID: "bb-id", SeriesName: "Breaking Bad (2008)"}
+	if muted, _ := bot.isSeriesMuted(100, renamed); !muted {
+		t.Error("Expected an ID-based mute to survive a rename")
+	}
+	if muted, _ := bot.isSeriesMuted(300, content); muted {
+		t.Error("Expected user 300 not to have the series muted")
+	}
+}
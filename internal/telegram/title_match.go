@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	botModels "github.com/go-telegram/bot/models"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// titleSimilarity is how similar two normalized titles must be to count as the same title,
+// so typos and punctuation differences ("Spiderman" for "Spider-Man") still match
+const titleSimilarity = 0.85
+
+var (
+	// imdbIDPattern finds IMDb IDs, also inside IMDb links
+	imdbIDPattern = regexp.MustCompile(`\btt\d{7,10}\b`)
+	// tmdbIDPattern matches "tmdb:<id>" and TMDB movie and series links
+	tmdbIDPattern = regexp.MustCompile(`^(?:tmdb:|https?://(?:www\.)?themoviedb\.org/(?:movie|tv)/)(\d+)`)
+	// titleYearPattern matches a release year like "2021" or "(2021)"
+	titleYearPattern = regexp.MustCompile(`^\(?((?:19|20)\d{2})\)?$`)
+	// romanNumeralPattern matches the roman numerals sequels are numbered with (i to xxxix)
+	romanNumeralPattern = regexp.MustCompile(`^x{0,3}(?:ix|iv|v?i{0,3})$`)
+)
+
+// romanDigits are the values of the letters in romanNumeralPattern
+var romanDigits = map[rune]int{'i': 1, 'v': 5, 'x': 10}
+
+// titleArticles are the leading words ignored when comparing titles
+var titleArticles = map[string]bool{"the": true, "a": true, "an": true}
+
+// titleTarget is what a wanted title is matched against: an incoming notification or a library item
+type titleTarget struct {
+	itemType    string
+	title       string
+	seriesName  string
+	year        int
+	providerIDs map[string]string
+}
+
+// findWantedItem searches the library for a title a user is about to wish for or request
+// Search failures are ignored; the title is stored and matched later instead
+func (b *Bot) findWantedItem(ctx context.Context, chatID int64, wanted *models.WantedTitle) *ContentItem {
+	if wanted.Title == "" {
+		return nil
+	}
+
+	items, err := b.searchItems(ctx, chatID, wanted.Title, 10)
+	if err != nil {
+		slog.Warn("Failed to search library for wanted title",
+			"chat_id", chatID,
+			"title", wanted.Title,
+			"error", err)
+		return nil
+	}
+
+	for i := range items {
+		item := &items[i]
+		if matchTitle(wanted, titleTarget{
+			itemType:    item.Type,
+			title:       item.Name,
+			seriesName:  item.SeriesName,
+			year:        item.ProductionYear,
+			providerIDs: item.ProviderIDs,
+		}) {
+			return item
+		}
+	}
+	return nil
+}
+
+// announceArrival tells the users who wished for or requested the content that it arrived
+func (b *Bot) announceArrival(ctx context.Context, content *NotificationContent) {
+	b.fulfillWishes(ctx, content)
+	b.fulfillRequests(ctx, content)
+}
+
+// notificationTarget returns what wanted titles are matched against for a notification
+func notificationTarget(content *NotificationContent) titleTarget {
+	return titleTarget{
+		itemType:    content.Type,
+		title:       content.Title,
+		seriesName:  content.SeriesName,
+		year:        content.Year,
+		providerIDs: content.ProviderIDs,
+	}
+}
+
+// sendArrival sends a personal message that a wanted title was added, followed by the notification card
+func (b *Bot) sendArrival(ctx context.Context, chatID int64, header string, content *NotificationContent) error {
+	localizer := b.getLocalizerForUser(ctx, chatID, "")
+	message := header + "\n\n" + FormatNotification(content, localizer)
+
+	var keyboard *botModels.InlineKeyboardMarkup
+	if rows := b.itemLinkRows(content.ItemID, content.Type, content.TrailerURL, content.ProviderIDs, localizer); len(rows) > 0 {
+		keyboard = &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}
+	}
+	return b.sendContentText(ctx, chatID, message, keyboard)
+}
+
+// parseWantedTitle reads the argument of /wish and /request: free text with an optional trailing year,
+// optionally containing an IMDb ID ("tt1160419"), "tmdb:<id>" or an IMDb or TMDB link
+func parseWantedTitle(text string) (*models.WantedTitle, bool) {
+	var wanted models.WantedTitle
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if id := imdbIDPattern.FindString(field); id != "" {
+			wanted.ImdbID = id
+			continue
+		}
+		if match := tmdbIDPattern.FindStringSubmatch(strings.ToLower(field)); match != nil {
+			wanted.TmdbID = match[1]
+			continue
+		}
+		words = append(words, field)
+	}
+
+	// A year on its own is a title ("1917"), after other words it's the release year
+	if len(words) > 1 {
+		if match := titleYearPattern.FindStringSubmatch(words[len(words)-1]); match != nil {
+			wanted.Year, _ = strconv.Atoi(match[1])
+			words = words[:len(words)-1]
+		}
+	}
+	wanted.Title = strings.Join(words, " ")
+
+	if wanted.Title == "" && wanted.ImdbID == "" && wanted.TmdbID == "" {
+		return nil, false
+	}
+	return &wanted, true
+}
+
+// titleLabel returns how a wanted title is shown to users
+func titleLabel(wanted *models.WantedTitle) string {
+	label := wanted.Title
+	switch {
+	case label == "" && wanted.ImdbID != "":
+		label = "IMDb " + wanted.ImdbID
+	case label == "":
+		label = "TMDB " + wanted.TmdbID
+	}
+	if wanted.Year > 0 {
+		label += fmt.Sprintf(" (%d)", wanted.Year)
+	}
+	return label
+}
+
+// sameTitle reports whether two wanted titles ask for the same title
+func sameTitle(a, b *models.WantedTitle) bool {
+	return normalizeTitle(a.Title) == normalizeTitle(b.Title) &&
+		a.Year == b.Year && a.ImdbID == b.ImdbID && a.TmdbID == b.TmdbID
+}
+
+// matchTitle reports whether an item is the title a user asked for
+// Movies and series are matched by title and year, episodes and seasons by series name
+func matchTitle(wanted *models.WantedTitle, target titleTarget) bool {
+	// External IDs are exact: an item with a different ID is a different title
+	// Episodes have IDs of their own, so only movies and series are compared by ID
+	if target.itemType == models.ItemTypeMovie || target.itemType == models.ItemTypeSeries {
+		if id := providerID(target.providerIDs, "Imdb"); wanted.ImdbID != "" && id != "" {
+			return strings.EqualFold(id, wanted.ImdbID)
+		}
+		if id := providerID(target.providerIDs, "Tmdb"); wanted.TmdbID != "" && id != "" {
+			return id == wanted.TmdbID
+		}
+	}
+
+	if wanted.Title == "" {
+		return false
+	}
+
+	name := target.title
+	if target.itemType == models.ItemTypeEpisode || target.itemType == models.ItemTypeSeason {
+		name = target.seriesName
+	}
+	if !similarTitles(wanted.Title, name) {
+		return false
+	}
+
+	// Years differ by one between release dates in different countries
+	if wanted.Year > 0 && target.year > 0 &&
+		(target.itemType == models.ItemTypeMovie || target.itemType == models.ItemTypeSeries) {
+		diff := wanted.Year - target.year
+		return diff >= -1 && diff <= 1
+	}
+	return true
+}
+
+// similarTitles reports whether two titles are the same apart from case, accents, punctuation,
+// a leading article and small typos
+func similarTitles(a, b string) bool {
+	na, nb := normalizeTitle(a), normalizeTitle(b)
+	if na == "" || nb == "" {
+		return false
+	}
+	if na == nb {
+		return true
+	}
+
+	// "Toy Story 3" and "Toy Story 4" differ by a single letter, but they are different films
+	na, numbersA := titleNumbers(na)
+	nb, numbersB := titleNumbers(nb)
+	if !slices.Equal(numbersA, numbersB) {
+		return false
+	}
+
+	ra, rb := []rune(na), []rune(nb)
+	longest := max(len(ra), len(rb))
+	return 1-float64(levenshtein(ra, rb))/float64(longest) >= titleSimilarity
+}
+
+// normalizeTitle lowercases a title and strips accents, punctuation and a leading article
+func normalizeTitle(title string) string {
+	// Transformers keep state, so each call gets its own
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	title, _, err := transform.String(stripAccents, strings.ToLower(title))
+	if err != nil {
+		title = strings.ToLower(title)
+	}
+
+	title = strings.ReplaceAll(title, "&", " and ")
+	// Apostrophes and zero-width joiners (common in Persian) join words instead of splitting them
+	title = strings.Map(func(r rune) rune {
+		if r == '\'' || r == '’' || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, title)
+
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) > 1 && titleArticles[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// titleNumbers returns the numbers in a normalized title, and the title with roman numerals
+// written as digits, so "part ii" and "part 2" both give "part 2" and [2]
+func titleNumbers(title string) (string, []int) {
+	var numbers []int
+	words := strings.Fields(title)
+	for i, word := range words {
+		if number, err := strconv.Atoi(word); err == nil {
+			numbers = append(numbers, number)
+			continue
+		}
+		if romanNumeralPattern.MatchString(word) {
+			number := romanValue(word)
+			numbers = append(numbers, number)
+			words[i] = strconv.Itoa(number)
+		}
+	}
+	return strings.Join(words, " "), numbers
+}
+
+// romanValue returns the value of a roman numeral matched by romanNumeralPattern
+func romanValue(numeral string) int {
+	value := 0
+	letters := []rune(numeral)
+	for i, letter := range letters {
+		if i+1 < len(letters) && romanDigits[letter] < romanDigits[letters[i+1]] {
+			value -= romanDigits[letter]
+		} else {
+			value += romanDigits[letter]
+		}
+	}
+	return value
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
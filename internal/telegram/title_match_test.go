@@ -0,0 +1,106 @@
+package telegram
+
+import (
+	"testing"
+
+	"jellyfin-telegram-bot/pkg/models"
+)
+
+// Test 1: /wish and /request arguments are split into title, year and external IDs
+func TestParseWantedTitle(t *testing.T) {
+	tests := []struct {
+		input  string
+		title  string
+		year   int
+		imdbID string
+		tmdbID string
+	}{
+		{" Dune 2021", "Dune", 2021, "", ""},
+		{" Blade Runner (1982)", "Blade Runner", 1982, "", ""},
+		{" 1917", "1917", 0, "", ""},
+		{" tt1160419", "", 0, "tt1160419", ""},
+		{" https://www.imdb.com/title/tt1160419/ Dune", "Dune", 0, "tt1160419", ""},
+		{" TMDB:438631", "", 0, "", "438631"},
+		{" https://www.themoviedb.org/tv/95396-severance", "", 0, "", "95396"},
+	}
+
+	for _, tt := range tests {
+		wanted, ok := parseWantedTitle(tt.input)
+		if !ok {
+			t.Errorf("parseWantedTitle(%q) failed", tt.input)
+			continue
+		}
+		if wanted.Title != tt.title || wanted.Year != tt.year || wanted.ImdbID != tt.imdbID || wanted.TmdbID != tt.tmdbID {
+			t.Errorf("parseWantedTitle(%q) = %+v", tt.input, wanted)
+		}
+	}
+
+	if _, ok := parseWantedTitle("   "); ok {
+		t.Error("Expected an empty title to be rejected")
+	}
+}
+
+// Test 2: Titles match despite case, accents, articles, punctuation and typos, but years and IDs must agree
+func TestMatchTitle(t *testing.T) {
+	movie := func(title string, year int, ids map[string]string) titleTarget {
+		return titleTarget{itemType: "Movie", title: title, year: year, providerIDs: ids}
+	}
+
+	tests := []struct {
+		name   string
+		wanted models.WantedTitle
+		target titleTarget
+		want   bool
+	}{
+		{"exact", models.WantedTitle{Title: "Dune"}, movie("Dune", 2021, nil), true},
+		{"leading article", models.WantedTitle{Title: "Matrix"}, movie("The Matrix", 1999, nil), true},
+		{"accents", models.WantedTitle{Title: "amelie"}, movie("Amélie", 2001, nil), true},
+		{"punctuation", models.WantedTitle{Title: "spiderman into the spiderverse"}, movie("Spider-Man: Into the Spider-Verse", 2018, nil), true},
+		{"typo", models.WantedTitle{Title: "Interstelar"}, movie("Interstellar", 2014, nil), true},
+		{"different title", models.WantedTitle{Title: "Dune"}, movie("Dunkirk", 2017, nil), false},
+		{"year off by one", models.WantedTitle{Title: "Dune", Year: 2020}, movie("Dune", 2021, nil), true},
+		{"other remake", models.WantedTitle{Title: "Dune", Year: 1984}, movie("Dune", 2021, nil), false},
+		{"imdb id", models.WantedTitle{ImdbID: "tt1160419"}, movie("Dune", 2021, map[string]string{"Imdb": "tt1160419"}), true},
+		{"other imdb id", models.WantedTitle{Title: "Dune", ImdbID: "tt0087182"}, movie("Dune", 2021, map[string]string{"Imdb": "tt1160419"}), false},
+		{"sequel number", models.WantedTitle{Title: "Toy Story 3"}, movie("Toy Story 4", 2019, nil), false},
+		{"sequel roman numeral", models.WantedTitle{Title: "The Godfather Part II"}, movie("The Godfather Part III", 1990, nil), false},
+		{"roman numeral for digit", models.WantedTitle{Title: "Godfather Part 2"}, movie("The Godfather Part II", 1974, nil), true},
+		{"first film", models.WantedTitle{Title: "Shrek"}, movie("Shrek 2", 2004, nil), false},
+		{"episode by series name", models.WantedTitle{Title: "severance"},
+			titleTarget{itemType: "Episode", title: "Good News About Hell", seriesName: "Severance", year: 2022}, true},
+		{"episode ignores year", models.WantedTitle{Title: "Severance", Year: 2022},
+			titleTarget{itemType: "Episode", title: "Hello, Ms. Cobel", seriesName: "Severance", year: 2025}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchTitle(&tt.wanted, tt.target); got != tt.want {
+				t.Errorf("matchTitle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test 3: Numbered sequels never count as the same title, however close their names are
+func TestSimilarTitles_Sequels(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Toy Story 3", "Toy Story 4", false},
+		{"Godfather Part II", "Godfather Part III", false},
+		{"Rocky IV", "Rocky V", false},
+		{"Star Wars Episode I", "Star Wars Episode II", false},
+		{"Mission Impossible 2", "Mission Impossible", false},
+		{"28 Days Later", "28 Weeks Later", false},
+		{"Rocky II", "Rocky 2", true},
+		{"Toy Story 3", "Toy Story 3", true},
+		{"Terminator 2 Judgement Day", "Terminator 2: Judgment Day", true},
+	}
+
+	for _, tt := range tests {
+		if got := similarTitles(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarTitles(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+// webhookDeregisterTimeout bounds the deleteWebhook call made while shutting down
+const webhookDeregisterTimeout = 5 * time.Second
+
+// webhookSecretHeader is the header Telegram uses to send the webhook secret token
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// IsWebhookMode reports whether the bot receives updates via webhook instead of long polling
+func (b *Bot) IsWebhookMode() bool {
+	return b.webhookURL != ""
+}
+
+// WebhookHandler returns the HTTP handler receiving Telegram updates in webhook mode
+// Requests without the secret token are rejected before they reach the bot
+func (b *Bot) WebhookHandler() http.Handler {
+	updates := b.bot.WebhookHandler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		provided := r.Header.Get(webhookSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(b.webhookSecret)) != 1 {
+			slog.Warn("Telegram webhook request with invalid or missing secret token",
+				"remote_addr", r.RemoteAddr)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		updates(w, r)
+	})
+}
+
+// startWebhook registers the webhook with Telegram, processes updates until the context
+// is cancelled and removes the webhook again so a later start can fall back to polling
+func (b *Bot) startWebhook(ctx context.Context) error {
+	_, err := b.bot.SetWebhook(ctx, &bot.SetWebhookParams{
+		URL:         b.webhookURL,
+		SecretToken: b.webhookSecret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register Telegram webhook: %w", err)
+	}
+
+	slog.Info("Telegram webhook registered", "url", b.webhookURL)
+
+	b.bot.StartWebhook(ctx)
+
+	// The run context is already cancelled at this point
+	deregisterCtx, cancel := context.WithTimeout(context.Background(), webhookDeregisterTimeout)
+	defer cancel()
+
+	if _, err := b.bot.DeleteWebhook(deregisterCtx, &bot.DeleteWebhookParams{}); err != nil {
+		slog.Warn("Failed to remove Telegram webhook", "error", err)
+	} else {
+		slog.Info("Telegram webhook removed")
+	}
+
+	return nil
+}
+
+// generateWebhookSecret creates a random secret token for the Telegram webhook
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
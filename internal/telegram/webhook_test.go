@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+)
+
+// fakeTelegramAPI records the Bot API methods called by the bot
+type fakeTelegramAPI struct {
+	mu      sync.Mutex
+	methods []string
+}
+
+func (f *fakeTelegramAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.methods = append(f.methods, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+	f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"ok":true,"result":true}`))
+}
+
+func (f *fakeTelegramAPI) called() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.methods...)
+}
+
+func newWebhookTestBot(t *testing.T, api *fakeTelegramAPI) *Bot {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	b, err := bot.New("123:test", bot.WithSkipGetMe(), bot.WithServerURL(server.URL),
+		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *botModels.Update) {}))
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+
+	return &Bot{
+		bot:           b,
+		webhookURL:    "https://bot.example.com/telegram",
+		webhookSecret: "s3cret",
+	}
+}
+
+// Test 1: Telegram updates without the secret token are rejected
+func TestWebhookHandler_RequiresSecretToken(t *testing.T) {
+	b := newWebhookTestBot(t, &fakeTelegramAPI{})
+	handler := b.WebhookHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		secret string
+		want   int
+	}{
+		{"missing secret", http.MethodPost, "", http.StatusUnauthorized},
+		{"wrong secret", http.MethodPost, "guess", http.StatusUnauthorized},
+		{"wrong method", http.MethodGet, "s3cret", http.StatusMethodNotAllowed},
+		{"valid", http.MethodPost, "s3cret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/telegram", strings.NewReader(`{"update_id":1}`))
+		if tt.secret != "" {
+			req.Header.Set(webhookSecretHeader, tt.secret)
+		}
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+}
+
+// Test 2: The webhook is registered on start and removed on shutdown
+func TestStart_RegistersAndRemovesWebhook(t *testing.T) {
+	api := &fakeTelegramAPI{}
+	b := newWebhookTestBot(t, api)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.Start(ctx)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Bot did not stop after the context was cancelled")
+	}
+
+	called := strings.Join(api.called(), ",")
+	if called != "setWebhook,deleteWebhook" {
+		t.Errorf("Expected setWebhook then deleteWebhook, got %s", called)
+	}
+}
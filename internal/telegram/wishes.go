@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: MIT
+
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"jellyfin-telegram-bot/internal/i18n"
+	"jellyfin-telegram-bot/pkg/models"
+
+	"github.com/go-telegram/bot"
+	botModels "github.com/go-telegram/bot/models"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"gorm.io/gorm"
+)
+
+// maxWishesPerUser bounds the wishlist of a single user
+const maxWishesPerUser = 50
+
+// wishButtonTitleLength is the number of characters of a wish shown on its cancel button
+const wishButtonTitleLength = 30
+
+// WishStore defines the interface for wishlist operations
+type WishStore interface {
+	AddWish(wish *models.Wish) error
+	GetWishesByUser(chatID int64) ([]models.Wish, error)
+	GetOpenWishes() ([]models.Wish, error)
+	FulfillWish(id uint, itemID string) error
+	DeleteWish(chatID int64, id uint) error
+}
+
+// SetWishStore enables /wish and /wishes
+func (b *Bot) SetWishStore(store WishStore) {
+	b.wishes = store
+}
+
+// handleWish handles the /wish command
+// Usage: "/wish <title> [year]", where the title may also be or contain an IMDb or TMDB ID
+func (b *Bot) handleWish(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	// Registered by prefix, so other commands starting with /wish land here too
+	args, ok := b.matchCommand(ctx, update, "/wish")
+	if !ok {
+		b.defaultHandler(ctx, botInstance, update)
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	slog.Info("Processing /wish command", "chat_id", chatID)
+
+	if b.wishes == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "error.generic"))
+		return
+	}
+
+	wanted, ok := parseWantedTitle(strings.Join(args, " "))
+	if !ok {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "wish.usage"))
+		return
+	}
+	wish := &models.Wish{ChatID: chatID, WantedTitle: *wanted}
+	label := titleLabel(wanted)
+
+	existing, err := b.wishes.GetWishesByUser(chatID)
+	if err != nil {
+		slog.Error("Failed to load wishes",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "wish.error"))
+		return
+	}
+	for i := range existing {
+		if sameTitle(&existing[i].WantedTitle, wanted) {
+			b.sendReply(ctx, chatID, i18n.TWithData(localizer, "wish.duplicate", map[string]interface{}{
+				"Title": label,
+			}))
+			return
+		}
+	}
+	if len(existing) >= maxWishesPerUser {
+		b.sendReply(ctx, chatID, i18n.TWithData(localizer, "wish.limit", map[string]interface{}{
+			"Max": maxWishesPerUser,
+		}))
+		return
+	}
+
+	// Users mostly want to know whether the title is already there
+	if item := b.findWantedItem(ctx, chatID, wanted); item != nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "wish.available"))
+		b.sendContentItem(ctx, chatID, item, localizer)
+		return
+	}
+
+	if err := b.wishes.AddWish(wish); err != nil {
+		slog.Error("Failed to add wish",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "wish.error"))
+		return
+	}
+
+	slog.Info("Wish added",
+		"chat_id", chatID,
+		"title", wish.Title,
+		"year", wish.Year)
+
+	b.sendReply(ctx, chatID, i18n.TWithData(localizer, "wish.added", map[string]interface{}{
+		"Title": label,
+	}))
+}
+
+// handleWishes handles the /wishes command
+func (b *Bot) handleWishes(ctx context.Context, _ *bot.Bot, update *botModels.Update) {
+	if update.Message == nil {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, update.Message.From.LanguageCode)
+
+	slog.Info("Processing /wishes command", "chat_id", chatID)
+
+	if b.wishes == nil {
+		b.sendReply(ctx, chatID, i18n.T(localizer, "error.generic"))
+		return
+	}
+
+	text, keyboard, err := b.wishList(chatID, localizer)
+	if err != nil {
+		slog.Error("Failed to load wishes",
+			"chat_id", chatID,
+			"error", err)
+		b.sendReply(ctx, chatID, i18n.T(localizer, "wish.error"))
+		return
+	}
+
+	if keyboard == nil {
+		b.sendReply(ctx, chatID, text)
+		return
+	}
+	if err := b.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+		slog.Error("Failed to send wishlist",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// handleWishCallback cancels a wish from the /wishes list and shows the updated list
+// Callback data format: "wish:cancel:{id}"
+func (b *Bot) handleWishCallback(ctx context.Context, botInstance *bot.Bot, update *botModels.Update) {
+	callbackQuery := update.CallbackQuery
+	if callbackQuery.Message.Message == nil {
+		slog.Warn("Callback query message is nil")
+		return
+	}
+
+	chatID := callbackQuery.Message.Message.Chat.ID
+	localizer := b.getLocalizerForUser(ctx, chatID, callbackQuery.From.LanguageCode)
+
+	arg, ok := strings.CutPrefix(callbackQuery.Data, "wish:cancel:")
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if !ok || err != nil || b.wishes == nil {
+		b.answerCallback(ctx, botInstance, callbackQuery, "error.invalid_callback", false)
+		return
+	}
+
+	// A wish that is already gone was cancelled from another copy of the list or fulfilled
+	if err := b.wishes.DeleteWish(chatID, uint(id)); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.Error("Failed to cancel wish",
+			"chat_id", chatID,
+			"wish_id", id,
+			"error", err)
+		b.answerCallback(ctx, botInstance, callbackQuery, "wish.error", false)
+		return
+	}
+
+	b.answerCallback(ctx, botInstance, callbackQuery, "wish.cancelled", false)
+
+	text, keyboard, err := b.wishList(chatID, localizer)
+	if err != nil {
+		slog.Error("Failed to load wishes",
+			"chat_id", chatID,
+			"error", err)
+		return
+	}
+
+	_, err = botInstance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   callbackQuery.Message.Message.ID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		slog.Warn("Failed to update wishlist",
+			"chat_id", chatID,
+			"error", err)
+	}
+}
+
+// wishList formats the open wishes of a user with a cancel button for each
+// The keyboard is nil when the wishlist is empty
+func (b *Bot) wishList(chatID int64, localizer *goi18n.Localizer) (string, *botModels.InlineKeyboardMarkup, error) {
+	wishes, err := b.wishes.GetWishesByUser(chatID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(wishes) == 0 {
+		return i18n.T(localizer, "wish.empty"), nil, nil
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(localizer, "wish.list_title"))
+	message.WriteString("\n")
+
+	var rows [][]botModels.InlineKeyboardButton
+	for i := range wishes {
+		label := titleLabel(&wishes[i].WantedTitle)
+		message.WriteString(fmt.Sprintf("\n%d. %s", i+1, label))
+		rows = append(rows, []botModels.InlineKeyboardButton{{
+			Text: i18n.TWithData(localizer, "wish.cancel_button", map[string]interface{}{
+				"Title": truncateText(label, wishButtonTitleLength),
+			}),
+			CallbackData: fmt.Sprintf("wish:cancel:%d", wishes[i].ID),
+		}})
+	}
+
+	return message.String(), &botModels.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// fulfillWishes tells every user who wished for the content that it arrived and closes their wishes
+// Wishes bypass mutes, type and content filters, but not the library permissions of linked accounts
+func (b *Bot) fulfillWishes(ctx context.Context, content *NotificationContent) {
+	if b.wishes == nil || strings.HasPrefix(content.ItemID, "test-") {
+		return
+	}
+
+	wishes, err := b.wishes.GetOpenWishes()
+	if err != nil {
+		slog.Error("Failed to load open wishes",
+			"item_id", content.ItemID,
+			"error", err)
+		return
+	}
+
+	target := notificationTarget(content)
+
+	for i := range wishes {
+		wish := &wishes[i]
+		if !matchTitle(&wish.WantedTitle, target) || !b.canSeeItem(ctx, wish.ChatID, content.ItemID) {
+			continue
+		}
+
+		// The wish stays open if the message can't be sent, so a later match can try again
+		header := i18n.TWithData(b.getLocalizerForUser(ctx, wish.ChatID, ""), "wish.arrived", map[string]interface{}{
+			"Title": titleLabel(&wish.WantedTitle),
+		})
+		if err := b.sendArrival(ctx, wish.ChatID, header, content); err != nil {
+			slog.Error("Failed to send wish notification",
+				"chat_id", wish.ChatID,
+				"wish_id", wish.ID,
+				"error", err)
+			if isBlockedError(err) {
+				b.handleBlockedRecipient(wish.ChatID, err)
+			}
+			continue
+		}
+
+		if err := b.wishes.FulfillWish(wish.ID, content.ItemID); err != nil {
+			slog.Error("Failed to close wish",
+				"wish_id", wish.ID,
+				"error", err)
+		}
+
+		slog.Info("Wish fulfilled",
+			"chat_id", wish.ChatID,
+			"wish_id", wish.ID,
+			"item_id", content.ItemID)
+	}
+}
@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"jellyfin-telegram-bot/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// mockWishStore is an in-memory WishStore
+type mockWishStore struct {
+	wishes []models.Wish
+	nextID uint
+}
+
+func (m *mockWishStore) AddWish(wish *models.Wish) error {
+	m.nextID++
+	wish.ID = m.nextID
+	m.wishes = append(m.wishes, *wish)
+	return nil
+}
+
+func (m *mockWishStore) GetWishesByUser(chatID int64) ([]models.Wish, error) {
+	var wishes []models.Wish
+	for _, wish := range m.wishes {
+		if wish.ChatID == chatID && wish.FulfilledAt == nil {
+			wishes = append(wishes, wish)
+		}
+	}
+	return wishes, nil
+}
+
+func (m *mockWishStore) GetOpenWishes() ([]models.Wish, error) {
+	var wishes []models.Wish
+	for _, wish := range m.wishes {
+		if wish.FulfilledAt == nil {
+			wishes = append(wishes, wish)
+		}
+	}
+	return wishes, nil
+}
+
+func (m *mockWishStore) FulfillWish(id uint, itemID string) error {
+	for i := range m.wishes {
+		if m.wishes[i].ID == id {
+			now := time.Now()
+			m.wishes[i].FulfilledAt = &now
+			m.wishes[i].FulfilledItemID = itemID
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *mockWishStore) DeleteWish(chatID int64, id uint) error {
+	for i := range m.wishes {
+		if m.wishes[i].ID == id && m.wishes[i].ChatID == chatID {
+			m.wishes = append(m.wishes[:i], m.wishes[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// Test 1: A wish is delivered to a user who muted the series and disabled episodes, then closed
+func TestFulfillWishes_BypassesFilters(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	db := newMockSubscriberDB()
+	db.mutedSeries[200] = map[string]bool{"series1": true}
+	db.disabledTypes = map[int64]map[string]bool{200: {"Episode": true}}
+	b := newCallbackTestBot(t, api, db)
+	store := &mockWishStore{}
+	b.SetWishStore(store)
+	store.AddWish(&models.Wish{ChatID: 200, WantedTitle: models.WantedTitle{Title: "Severance"}})
+	store.AddWish(&models.Wish{ChatID: 300, WantedTitle: models.WantedTitle{Title: "Dune"}})
+
+	content := &NotificationContent{
+		ItemID:        "ep1",
+		Type:          "Episode",
+		Title:         "Good News About Hell",
+		SeriesID:      "series1",
+		SeriesName:    "Severance",
+		SeasonNumber:  1,
+		EpisodeNumber: 1,
+	}
+	b.fulfillWishes(context.Background(), content)
+
+	sent := api.sent("sendMessage")
+	if len(sent) != 1 || sent[0].chatID != 200 {
+		t.Fatalf("Expected one message to the wishing user, got %+v", sent)
+	}
+	if !strings.Contains(sent[0].text, "Your wished title has arrived: Severance") {
+		t.Errorf("Unexpected message: %q", sent[0].text)
+	}
+
+	open, _ := store.GetOpenWishes()
+	if len(open) != 1 || open[0].ChatID != 300 {
+		t.Errorf("Expected only the unmatched wish to stay open, got %+v", open)
+	}
+
+	// The next episode doesn't notify again
+	b.fulfillWishes(context.Background(), content)
+	if len(api.sent("sendMessage")) != 1 {
+		t.Error("Expected a fulfilled wish not to notify twice")
+	}
+}
+
+// Test 2: /wish of a title already in the library shows it instead of storing a wish,
+// /wishes lists the wishes and the cancel button removes one in place
+func TestWishes_ListAndCancel(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = NewMockJellyfinClient()
+	store := &mockWishStore{}
+	b.SetWishStore(store)
+	ctx := context.Background()
+
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wish interstellar 2014"))
+	if len(store.wishes) != 0 || len(api.sent("sendPhoto")) != 1 {
+		t.Fatalf("Expected the available title to be shown, got wishes %+v", store.wishes)
+	}
+
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wish notfound"))
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wish Dune 2021"))
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wish dune (2021)"))
+	if len(store.wishes) != 2 {
+		t.Fatalf("Expected 2 wishes, got %+v", store.wishes)
+	}
+
+	b.handleWishes(ctx, b.bot, commandUpdate(200, "/wishes"))
+	sent := api.sent("sendMessage")
+	list := sent[len(sent)-1].text
+	if !strings.Contains(list, "1. notfound") || !strings.Contains(list, "2. Dune (2021)") {
+		t.Fatalf("Unexpected wishlist: %q", list)
+	}
+
+	b.dispatchCallback(ctx, b.bot, callbackUpdate(200, "wish:cancel:1"))
+	edits := api.sent("editMessageText")
+	if len(edits) != 1 || strings.Contains(edits[0].text, "notfound") || !strings.Contains(edits[0].text, "1. Dune (2021)") {
+		t.Errorf("Expected the list without the cancelled wish, got %+v", edits)
+	}
+	if len(store.wishes) != 1 {
+		t.Errorf("Expected one wish left, got %+v", store.wishes)
+	}
+}
+
+// Test 3: Only /wish itself, optionally addressed to this bot, adds a wish
+func TestWish_MatchesCommandExactly(t *testing.T) {
+	api := &recordingTelegramAPI{}
+	b := newCallbackTestBot(t, api, newMockSubscriberDB())
+	b.jellyfinClient = NewMockJellyfinClient()
+	store := &mockWishStore{}
+	b.SetWishStore(store)
+	ctx := context.Background()
+
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wishlist"))
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wish@other_bot Dune"))
+	if len(store.wishes) != 0 {
+		t.Fatalf("Expected other commands not to add wishes, got %+v", store.wishes)
+	}
+	sent := api.sent("sendMessage")
+	if len(sent) != 2 || !strings.HasPrefix(sent[0].text, "Invalid command") || !strings.HasPrefix(sent[1].text, "Invalid command") {
+		t.Errorf("Expected other commands to be answered as unknown, got %+v", sent)
+	}
+
+	b.handleWish(ctx, b.bot, commandUpdate(200, "/wish@Jellyfin_Bot Dune 2021"))
+	if len(store.wishes) != 1 || store.wishes[0].Title != "Dune" || store.wishes[0].Year != 2021 {
+		t.Errorf("Expected a wish for Dune (2021), got %+v", store.wishes)
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Admin represents a bot operator allowed to use the admin commands
+type Admin struct {
+	gorm.Model
+	ChatID     int64  `gorm:"uniqueIndex;not null" json:"chat_id"`
+	FromConfig bool   `json:"from_config"` // Seeded from ADMIN_CHAT_IDS; can't be removed from Telegram
+	AddedBy    int64  `json:"added_by"`    // Admin who added this one; zero for config admins
+	Events     string `json:"events"`      // Comma-separated server events (AdminEventTypes) the admin subscribed to
+}
+
+// EventTypes returns the server events the admin subscribed to
+func (a *Admin) EventTypes() []string {
+	if a.Events == "" {
+		return nil
+	}
+	return strings.Split(a.Events, ",")
+}
+
+// WantsEvent reports whether the admin subscribed to a server event
+func (a *Admin) WantsEvent(eventType string) bool {
+	for _, subscribed := range a.EventTypes() {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName specifies the table name for Admin model
+func (Admin) TableName() string {
+	return "admins"
+}
+
+// BotStats summarizes the subscriber base and recent delivery activity for /stats
+type BotStats struct {
+	TotalSubscribers    int64
+	ActiveSubscribers   int64
+	InactiveSubscribers int64 // Unsubscribed or blocked the bot; banned and pending users are counted on their own
+	BannedSubscribers   int64
+	PendingSubscribers  int64            // Waiting for an admin to approve them
+	Languages           map[string]int64 // Active subscribers by language code
+	MutedSeries         int64            // Mutes across all subscribers
+	MutingSubscribers   int64            // Subscribers with at least one mute
+	NotificationsSent   int64            // Deliveries sent since the requested time
+}
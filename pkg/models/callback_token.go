@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CallbackToken maps a short token used in inline button callback data to its payload
+// Telegram limits callback data to 64 bytes, too little for names or several arguments
+type CallbackToken struct {
+	gorm.Model
+	Token     string     `gorm:"uniqueIndex;not null" json:"token"`
+	Action    string     `gorm:"not null;default:'';index:idx_callback_action_payload" json:"action"` // Callback action the token was issued for
+	Payload   string     `gorm:"type:text;not null;index:idx_callback_action_payload" json:"payload"` // JSON-encoded callback arguments
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"`                                             // Nil for tokens that never expire
+}
+
+// TableName specifies the table name for CallbackToken model
+func (CallbackToken) TableName() string {
+	return "callback_tokens"
+}
+
+// IsExpired reports whether the token can no longer be used at the given time
+func (t *CallbackToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -3,11 +3,21 @@ package models
 import "gorm.io/gorm"
 
 // ContentCache represents cached content to prevent duplicate notifications
+// It also records what was added, which digests are built from
 type ContentCache struct {
 	gorm.Model
 	JellyfinID string `gorm:"uniqueIndex;not null" json:"jellyfin_id"`
 	Title      string `json:"title"`
-	Type       string `json:"type"` // "Movie" or "Episode"
+	Type       string `json:"type"` // One of SupportedItemTypes
+
+	SeriesID       string  `json:"series_id"`
+	SeriesName     string  `json:"series_name"`
+	SeasonNumber   int     `json:"season_number"`
+	EpisodeNumber  int     `json:"episode_number"`
+	Year           int     `json:"year"`
+	Rating         float64 `json:"rating"`
+	OfficialRating string  `json:"official_rating"`
+	Genres         string  `json:"genres"` // Comma-separated
 }
 
 // TableName specifies the table name for ContentCache model
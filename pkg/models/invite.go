@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// Invite is a code admins hand out so users can subscribe in invite-only mode
+type Invite struct {
+	gorm.Model
+	Code      string `gorm:"uniqueIndex;not null" json:"code"`
+	MaxUses   int    `gorm:"not null" json:"max_uses"` // Number of users who can subscribe with the code
+	Uses      int    `json:"uses"`
+	CreatedBy int64  `json:"created_by"` // Admin who created the invite
+}
+
+// TableName specifies the table name for Invite model
+func (Invite) TableName() string {
+	return "invites"
+}
+
+// Remaining returns how many more users can subscribe with the invite
+func (i *Invite) Remaining() int {
+	return max(i.MaxUses-i.Uses, 0)
+}
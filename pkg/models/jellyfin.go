@@ -1,19 +1,48 @@
 package models
 
-// ContentItem represents a movie or episode from Jellyfin
+// ContentItem represents a media item from Jellyfin
 type ContentItem struct {
-	ItemID          string  `json:"Id"`
-	Name            string  `json:"Name"`
-	Type            string  `json:"Type"` // "Movie" or "Episode"
-	Overview        string  `json:"Overview"`
-	CommunityRating float64 `json:"CommunityRating"`
-	OfficialRating  string  `json:"OfficialRating"`
-	ProductionYear  int     `json:"ProductionYear"`
+	ItemID          string   `json:"Id"`
+	Name            string   `json:"Name"`
+	Type            string   `json:"Type"` // One of SupportedItemTypes
+	Overview        string   `json:"Overview"`
+	CommunityRating float64  `json:"CommunityRating"`
+	OfficialRating  string   `json:"OfficialRating"`
+	ProductionYear  int      `json:"ProductionYear"`
+	Genres          []string `json:"Genres,omitempty"`
 
 	// Episode-specific fields
+	SeriesID      string `json:"SeriesId,omitempty"`
 	SeriesName    string `json:"SeriesName,omitempty"`
 	SeasonNumber  int    `json:"ParentIndexNumber,omitempty"`
 	EpisodeNumber int    `json:"IndexNumber,omitempty"`
+
+	// Music and audiobook fields
+	Album       string `json:"Album,omitempty"`
+	AlbumArtist string `json:"AlbumArtist,omitempty"`
+
+	// Files and streams of the item, only filled in by GetItem
+	MediaSources []MediaSource `json:"MediaSources,omitempty"`
+
+	// External pages of the item
+	RemoteTrailers []RemoteTrailer   `json:"RemoteTrailers,omitempty"`
+	ProviderIDs    map[string]string `json:"ProviderIds,omitempty"` // e.g. "Imdb" -> "tt1160419"
+}
+
+// RemoteTrailer is a trailer hosted outside Jellyfin, e.g. on YouTube
+type RemoteTrailer struct {
+	URL  string `json:"Url"`
+	Name string `json:"Name,omitempty"`
+}
+
+// TrailerURL returns the first remote trailer, or "" if the item has none
+func (c *ContentItem) TrailerURL() string {
+	for _, trailer := range c.RemoteTrailers {
+		if trailer.URL != "" {
+			return trailer.URL
+		}
+	}
+	return ""
 }
 
 // JellyfinItemsResponse represents the response from Jellyfin Items API
@@ -22,6 +51,42 @@ type JellyfinItemsResponse struct {
 	TotalRecordCount int           `json:"TotalRecordCount"`
 }
 
+// ItemQuery selects one page of library items
+type ItemQuery struct {
+	UserID     string   // Limits results to the libraries of this Jellyfin user; empty for all libraries
+	SearchTerm string   // Empty lists items by date added, newest first
+	ItemTypes  []string // Empty includes every supported type
+	ItemIDs    []string // Fetches these items only
+	StartIndex int
+	Limit      int
+}
+
+// JellyfinSystemInfo represents the public system information of a Jellyfin server
+type JellyfinSystemInfo struct {
+	ServerName string `json:"ServerName"`
+	Version    string `json:"Version"`
+	ID         string `json:"Id"`
+}
+
+// JellyfinUser identifies a Jellyfin user account
+type JellyfinUser struct {
+	ID   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+// JellyfinAuthResult is the response of a successful Jellyfin user authentication
+type JellyfinAuthResult struct {
+	User        JellyfinUser `json:"User"`
+	AccessToken string       `json:"AccessToken"`
+}
+
+// QuickConnectResult is the state of a Jellyfin Quick Connect request
+type QuickConnectResult struct {
+	Secret        string `json:"Secret"`
+	Code          string `json:"Code"` // Code the user enters in a signed-in Jellyfin app
+	Authenticated bool   `json:"Authenticated"`
+}
+
 // GetDisplayTitle returns the appropriate title for display
 func (c *ContentItem) GetDisplayTitle() string {
 	if c.Type == "Episode" && c.SeriesName != "" {
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Media stream types reported by Jellyfin
+const (
+	StreamTypeVideo    = "Video"
+	StreamTypeAudio    = "Audio"
+	StreamTypeSubtitle = "Subtitle"
+)
+
+// MediaSource represents one file or version of a Jellyfin item
+type MediaSource struct {
+	ID           string        `json:"Id"`
+	MediaStreams []MediaStream `json:"MediaStreams"`
+}
+
+// MediaStream represents a video, audio or subtitle stream of a media source
+type MediaStream struct {
+	Type           string `json:"Type"` // One of the StreamType* constants
+	Codec          string `json:"Codec"`
+	Profile        string `json:"Profile,omitempty"`
+	Language       string `json:"Language,omitempty"` // ISO 639-2 code, e.g. "eng"
+	Width          int    `json:"Width,omitempty"`
+	Height         int    `json:"Height,omitempty"`
+	VideoRangeType string `json:"VideoRangeType,omitempty"` // e.g. "SDR", "HDR10", "DOVI"
+	IsDefault      bool   `json:"IsDefault"`
+}
+
+// MediaInfo summarizes the technical details subscribers care about
+type MediaInfo struct {
+	Resolution        string   `json:"resolution,omitempty"` // e.g. "2160p"
+	HDR               string   `json:"hdr,omitempty"`        // e.g. "HDR10", "Dolby Vision"; empty for SDR
+	AudioCodec        string   `json:"audio_codec,omitempty"`
+	AudioLanguages    []string `json:"audio_languages,omitempty"`    // Two-letter upper case codes where known
+	SubtitleLanguages []string `json:"subtitle_languages,omitempty"` // Two-letter upper case codes where known
+}
+
+// MediaInfo summarizes the first media source of a video item
+// It returns nil for items without a video stream, such as music and books
+func (c *ContentItem) MediaInfo() *MediaInfo {
+	if len(c.MediaSources) == 0 {
+		return nil
+	}
+
+	var info MediaInfo
+	var video, audio *MediaStream
+	streams := c.MediaSources[0].MediaStreams
+	for i := range streams {
+		stream := &streams[i]
+		switch stream.Type {
+		case StreamTypeVideo:
+			if video == nil {
+				video = stream
+			}
+		case StreamTypeAudio:
+			if audio == nil || (stream.IsDefault && !audio.IsDefault) {
+				audio = stream
+			}
+			info.AudioLanguages = appendLanguage(info.AudioLanguages, stream.Language)
+		case StreamTypeSubtitle:
+			info.SubtitleLanguages = appendLanguage(info.SubtitleLanguages, stream.Language)
+		}
+	}
+
+	if video == nil {
+		return nil
+	}
+	info.Resolution = resolutionLabel(video.Width, video.Height)
+	info.HDR = hdrLabel(video.VideoRangeType)
+	if audio != nil {
+		info.AudioCodec = audioCodecLabel(audio.Codec, audio.Profile)
+	}
+
+	return &info
+}
+
+// resolutionLabel names a video resolution by its common height, using the width so that
+// cropped widescreen video (e.g. 1920x800) still counts as 1080p
+func resolutionLabel(width, height int) string {
+	switch {
+	case width >= 3200 || height >= 2000:
+		return "2160p"
+	case width >= 1800 || height >= 1000:
+		return "1080p"
+	case width >= 1200 || height >= 700:
+		return "720p"
+	case height > 0:
+		return fmt.Sprintf("%dp", height)
+	default:
+		return ""
+	}
+}
+
+// hdrLabel names the HDR format of a Jellyfin video range type, or "" for SDR
+func hdrLabel(rangeType string) string {
+	switch {
+	case strings.HasPrefix(rangeType, "DOVI"):
+		return "Dolby Vision"
+	case rangeType == "HDR10Plus":
+		return "HDR10+"
+	case rangeType == "HDR10", rangeType == "HLG":
+		return rangeType
+	case rangeType == "HDR":
+		return "HDR"
+	default:
+		return ""
+	}
+}
+
+// audioCodecNames maps Jellyfin audio codec names to their display names
+var audioCodecNames = map[string]string{
+	"aac":    "AAC",
+	"ac3":    "AC3",
+	"eac3":   "E-AC3",
+	"dts":    "DTS",
+	"truehd": "TrueHD",
+	"flac":   "FLAC",
+	"mp3":    "MP3",
+	"opus":   "Opus",
+	"vorbis": "Vorbis",
+}
+
+// audioCodecLabel names an audio codec, telling DTS-HD and Atmos tracks apart by their profile
+func audioCodecLabel(codec, profile string) string {
+	name, ok := audioCodecNames[strings.ToLower(codec)]
+	if !ok {
+		name = strings.ToUpper(codec)
+	}
+	if strings.EqualFold(codec, "dts") && strings.Contains(profile, "HD") {
+		name = "DTS-HD"
+	}
+	if strings.Contains(profile, "Atmos") {
+		name += " Atmos"
+	}
+	return name
+}
+
+// languageCodes maps ISO 639-2 codes of common languages to their two-letter ISO 639-1 code
+var languageCodes = map[string]string{
+	"ara": "AR",
+	"chi": "ZH",
+	"zho": "ZH",
+	"dan": "DA",
+	"dut": "NL",
+	"nld": "NL",
+	"eng": "EN",
+	"fin": "FI",
+	"fre": "FR",
+	"fra": "FR",
+	"ger": "DE",
+	"deu": "DE",
+	"gre": "EL",
+	"ell": "EL",
+	"heb": "HE",
+	"hin": "HI",
+	"ita": "IT",
+	"jpn": "JA",
+	"kor": "KO",
+	"nor": "NO",
+	"per": "FA",
+	"fas": "FA",
+	"pol": "PL",
+	"por": "PT",
+	"rus": "RU",
+	"spa": "ES",
+	"swe": "SV",
+	"tur": "TR",
+	"ukr": "UK",
+}
+
+// appendLanguage adds a stream language to a list once, skipping unknown languages
+func appendLanguage(languages []string, code string) []string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || code == "und" {
+		return languages
+	}
+
+	label, ok := languageCodes[code]
+	if !ok {
+		label = strings.ToUpper(code)
+	}
+	for _, existing := range languages {
+		if existing == label {
+			return languages
+		}
+	}
+	return append(languages, label)
+}
@@ -2,14 +2,22 @@ package models
 
 import "gorm.io/gorm"
 
-// MutedSeries represents a series that a user has muted
+// MutedSeries represents a series that a user has muted or follows
+// A series is either muted or followed, so following a muted series replaces the mute
 type MutedSeries struct {
 	gorm.Model
 	ChatID     int64  `gorm:"uniqueIndex:idx_chat_series;not null" json:"chat_id"`
-	SeriesID   string `gorm:"uniqueIndex:idx_chat_series;not null" json:"series_id"`
+	SeriesID   string `gorm:"uniqueIndex:idx_chat_series;not null" json:"series_id"` // Jellyfin SeriesId; the name in rows from before IDs were known
 	SeriesName string `json:"series_name"`
+	Kind       string `gorm:"not null;default:'mute'" json:"kind"` // SeriesKindMute or SeriesKindFollow
 }
 
+// Kinds of per-series entries
+const (
+	SeriesKindMute   = "mute"
+	SeriesKindFollow = "follow"
+)
+
 // TableName specifies the table name for MutedSeries model
 func (MutedSeries) TableName() string {
 	return "muted_series"
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification job statuses
+const (
+	JobStatusPending  = "pending"  // Accepted, recipients not resolved yet
+	JobStatusExpanded = "expanded" // Recipients resolved, deliveries in progress
+	JobStatusDone     = "done"     // Every delivery reached a final state
+	JobStatusMerged   = "merged"   // Folded into another job of the same batch
+)
+
+// Notification job kinds; jobs without a kind announce new content
+const (
+	JobKindUpdate = "update" // Edits the notifications already sent for the item
+	JobKindDelete = "delete" // Retracts the notifications already sent for the item
+)
+
+// Outbox delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSent      = "sent"
+	DeliveryStatusFailed    = "failed"
+	DeliveryStatusBlocked   = "blocked"
+	DeliveryStatusDeferred  = "deferred"  // Held back by quiet hours until DeliverAfter
+	DeliveryStatusCancelled = "cancelled" // The item was removed, or turned out hidden from the recipient, before the delivery was sent
+)
+
+// NotificationJob represents a notification accepted for durable delivery
+type NotificationJob struct {
+	gorm.Model
+	JellyfinID  string    `gorm:"index;not null" json:"jellyfin_id"`
+	Payload     string    `gorm:"type:text;not null" json:"payload"` // JSON-encoded notification content
+	Status      string    `gorm:"index;default:'pending'" json:"status"`
+	BatchKey    string    `gorm:"index" json:"batch_key"`       // Jobs sharing a key are aggregated (e.g. one season)
+	AvailableAt time.Time `gorm:"index" json:"available_at"`    // Job isn't processed before this time
+	Kind        string    `gorm:"index;default:''" json:"kind"` // Empty for new content, JobKindUpdate or JobKindDelete for revisions
+	MergedInto  uint      `gorm:"index" json:"merged_into"`     // Job of the batch a merged job was folded into
+}
+
+// TableName specifies the table name for NotificationJob model
+func (NotificationJob) TableName() string {
+	return "notification_jobs"
+}
+
+// OutboxEntry represents the delivery of one notification job to one recipient
+type OutboxEntry struct {
+	gorm.Model
+	JobID     uint   `gorm:"uniqueIndex:idx_job_chat;not null" json:"job_id"`
+	ChatID    int64  `gorm:"uniqueIndex:idx_job_chat;not null" json:"chat_id"`
+	Status    string `gorm:"index;default:'pending'" json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+
+	DeliverAfter  time.Time  `gorm:"index" json:"deliver_after"`   // Set for deferred deliveries
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at"` // A failed delivery isn't retried before this time
+
+	AccessUnchecked bool `json:"access_unchecked"` // The recipient's access to the item couldn't be checked when the job was expanded
+
+	MessageID    int        `json:"message_id"`           // Telegram message of a sent delivery; 0 for catch-ups and retracted messages
+	PhotoMessage bool       `json:"photo_message"`        // The message is a poster with a caption rather than text
+	SentAt       *time.Time `gorm:"index" json:"sent_at"` // When the delivery was sent; later edits of the message don't change it
+}
+
+// OutboxBacklog summarizes the work waiting in the outbox
+type OutboxBacklog struct {
+	PendingJobs        int64 `json:"pending_jobs"`        // Jobs not fully delivered yet
+	PendingDeliveries  int64 `json:"pending_deliveries"`  // Deliveries waiting to be sent or retried
+	DeferredDeliveries int64 `json:"deferred_deliveries"` // Deliveries held back by quiet hours
+}
+
+// TableName specifies the table name for OutboxEntry model
+func (OutboxEntry) TableName() string {
+	return "outbox_entries"
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ContentPreferences holds a subscriber's content filters
+// Zero values disable a filter, and content missing the filtered metadata is let through,
+// except for the parental rating ceiling where unrated content counts as too mature
+type ContentPreferences struct {
+	MinRating         float64 `json:"min_rating"`          // Minimum community rating (0-10)
+	AllowedGenres     string  `json:"allowed_genres"`      // Comma-separated; empty allows every genre
+	BlockedGenres     string  `json:"blocked_genres"`      // Comma-separated
+	MinYear           int     `json:"min_year"`            // Earliest production year
+	MaxYear           int     `json:"max_year"`            // Latest production year
+	MaxParentalRating string  `json:"max_parental_rating"` // Highest allowed official rating (e.g. "PG-13")
+}
+
+// ParentalRatings lists the parental rating ceilings a subscriber can choose, from least to most mature
+var ParentalRatings = []string{"G", "PG", "PG-13", "R"}
+
+// parentalRatingLevels maps official ratings (MPAA, US TV and common non-US systems) to a comparable maturity level
+// Age-based ratings such as "DE-12", "FSK-16" or "18" are mapped by their age instead
+var parentalRatingLevels = map[string]int{
+	"G":        1,
+	"TV-Y":     1,
+	"TV-Y7":    1,
+	"TV-G":     1,
+	"U":        1,
+	"GB-U":     1,
+	"AL":       1,
+	"NL-AL":    1,
+	"AU-G":     1,
+	"CA-G":     1,
+	"PG":       2,
+	"TV-PG":    2,
+	"GB-PG":    2,
+	"AU-PG":    2,
+	"CA-PG":    2,
+	"PG-13":    3,
+	"TV-14":    3,
+	"12A":      3,
+	"GB-12A":   3,
+	"AU-M":     3,
+	"CA-14A":   3,
+	"R":        4,
+	"TV-MA":    4,
+	"MA15+":    4,
+	"AU-MA15+": 4,
+	"CA-18A":   4,
+	"NC-17":    5,
+	"R18":      5,
+	"R18+":     5,
+	"GB-R18":   5,
+	"AU-R18+":  5,
+	"CA-R":     5,
+	"X":        5,
+	"XXX":      5,
+}
+
+// ParentalRatingLevel returns the maturity level of an official rating, or 0 if it is unknown
+func ParentalRatingLevel(officialRating string) int {
+	rating := strings.ToUpper(strings.TrimSpace(officialRating))
+	if level, ok := parentalRatingLevels[rating]; ok {
+		return level
+	}
+	if rating == "" {
+		return 0
+	}
+
+	// "DE-16", "FSK-16", "GB-15" and plain "16" are minimum ages
+	if _, suffix, ok := strings.Cut(rating, "-"); ok {
+		rating = suffix
+	}
+	age, err := strconv.Atoi(strings.TrimSuffix(rating, "+"))
+	if err != nil || age < 0 {
+		return 0
+	}
+	switch {
+	case age <= 6:
+		return 1
+	case age <= 11:
+		return 2
+	case age <= 14:
+		return 3
+	case age <= 17:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// AllowedGenreList returns the allowed genres as a slice
+func (p *ContentPreferences) AllowedGenreList() []string {
+	return splitList(p.AllowedGenres)
+}
+
+// BlockedGenreList returns the blocked genres as a slice
+func (p *ContentPreferences) BlockedGenreList() []string {
+	return splitList(p.BlockedGenres)
+}
+
+// Allows reports whether content with the given metadata passes the filters
+func (p *ContentPreferences) Allows(rating float64, year int, officialRating string, genres []string) bool {
+	if p.MinRating > 0 && rating > 0 && rating < p.MinRating {
+		return false
+	}
+
+	if year > 0 {
+		if p.MinYear > 0 && year < p.MinYear {
+			return false
+		}
+		if p.MaxYear > 0 && year > p.MaxYear {
+			return false
+		}
+	}
+
+	// Unrated content (missing, "NR", "Unrated" or an unknown system) could be anything, so it is
+	// kept away from a subscriber with a ceiling
+	if p.MaxParentalRating != "" {
+		level := ParentalRatingLevel(officialRating)
+		if level == 0 || level > ParentalRatingLevel(p.MaxParentalRating) {
+			return false
+		}
+	}
+
+	if len(genres) > 0 {
+		for _, genre := range genres {
+			if containsFold(p.BlockedGenreList(), genre) {
+				return false
+			}
+		}
+
+		allowed := p.AllowedGenreList()
+		if len(allowed) > 0 {
+			for _, genre := range genres {
+				if containsFold(allowed, genre) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitList parses a comma-separated list, ignoring empty entries
+func splitList(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// containsFold checks if list contains value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Title request statuses
+const (
+	RequestStatusPending   = "pending"   // Waiting for an admin
+	RequestStatusApproved  = "approved"  // An admin will add the title
+	RequestStatusDeclined  = "declined"  // An admin won't add the title
+	RequestStatusAvailable = "available" // A matching item was added to the library
+)
+
+// TitleRequest is a title a user asked admins to add to the library
+type TitleRequest struct {
+	gorm.Model
+	ChatID      int64 `gorm:"index;not null" json:"chat_id"` // Requester
+	WantedTitle `gorm:"embedded"`
+
+	Status          string     `gorm:"index;not null;default:'pending'" json:"status"`
+	DecidedBy       int64      `json:"decided_by"`        // Admin who approved or declined the request
+	ExternalID      string     `json:"external_id"`       // ID of the request in Overseerr or Jellyseerr once forwarded
+	AvailableItemID string     `json:"available_item_id"` // Jellyfin item that fulfilled the request
+	AvailableAt     *time.Time `json:"available_at"`
+}
+
+// TableName specifies the table name for TitleRequest model
+func (TitleRequest) TableName() string {
+	return "title_requests"
+}
+
+// IsOpen reports whether the request still waits for the title to arrive
+func (r *TitleRequest) IsOpen() bool {
+	return r.Status == RequestStatusPending || r.Status == RequestStatusApproved
+}
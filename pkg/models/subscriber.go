@@ -1,15 +1,69 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Subscriber represents a user subscribed to notifications
 type Subscriber struct {
 	gorm.Model
-	ChatID       int64  `gorm:"uniqueIndex;not null" json:"chat_id"`
-	Username     string `json:"username"`
-	FirstName    string `json:"first_name"`
-	IsActive     bool   `gorm:"default:true" json:"is_active"`
-	LanguageCode string `gorm:"default:'en'" json:"language_code"` // User's preferred language (en, fa, etc.)
+	ChatID        int64  `gorm:"uniqueIndex;not null" json:"chat_id"`
+	Username      string `json:"username"`
+	FirstName     string `json:"first_name"`
+	IsActive      bool   `gorm:"default:true" json:"is_active"`
+	IsBanned      bool   `gorm:"default:false" json:"is_banned"`    // Banned by an admin; the bot ignores the user
+	IsPending     bool   `gorm:"default:false" json:"is_pending"`   // Waiting for an admin to approve the subscription
+	LanguageCode  string `gorm:"default:'en'" json:"language_code"` // User's preferred language (en, fa, etc.)
+	DisabledTypes string `json:"disabled_types"`                    // Comma-separated item types the user opted out of
+	EpisodeMode   string `json:"episode_mode"`                      // One of the EpisodeMode* constants; empty means all
+
+	Preferences ContentPreferences `gorm:"embedded" json:"preferences"`
+	Schedule    DeliverySchedule   `gorm:"embedded" json:"schedule"`
+	Jellyfin    JellyfinLink       `gorm:"embedded" json:"jellyfin"`
+}
+
+// JellyfinLink associates a subscriber with a Jellyfin user account
+// Only the user ID is kept; access tokens are discarded after linking
+type JellyfinLink struct {
+	JellyfinUserID   string `json:"jellyfin_user_id"`
+	JellyfinUserName string `json:"jellyfin_user_name"`
+}
+
+// IsLinked reports whether the subscriber linked a Jellyfin account
+func (l *JellyfinLink) IsLinked() bool {
+	return l.JellyfinUserID != ""
+}
+
+// Subscriber episode modes
+const (
+	EpisodeModeAll      = "all"      // Episodes of every series except muted ones
+	EpisodeModeFollowed = "followed" // Only episodes of followed series
+)
+
+// DeliverySchedule holds when a subscriber wants to receive notifications
+type DeliverySchedule struct {
+	Timezone   string `json:"timezone"`    // IANA time zone name; empty uses the bot default
+	QuietStart string `json:"quiet_start"` // Start of quiet hours as "HH:MM" local time; empty disables them
+	QuietEnd   string `json:"quiet_end"`   // End of quiet hours as "HH:MM" local time
+
+	DeliveryMode string       `json:"delivery_mode"`  // One of the DeliveryMode* constants; empty means instant
+	DigestTime   string       `json:"digest_time"`    // Local "HH:MM" digests are sent at
+	DigestDay    time.Weekday `json:"digest_day"`     // Day weekly digests are sent on
+	LastDigestAt time.Time    `json:"last_digest_at"` // Content added after this goes into the next digest
+}
+
+// Subscriber delivery modes
+const (
+	DeliveryModeInstant = "instant"
+	DeliveryModeDaily   = "daily"
+	DeliveryModeWeekly  = "weekly"
+)
+
+// IsDigest reports whether the subscriber receives digests instead of instant notifications
+func (s *DeliverySchedule) IsDigest() bool {
+	return s.DeliveryMode == DeliveryModeDaily || s.DeliveryMode == DeliveryModeWeekly
 }
 
 // TableName specifies the table name for Subscriber model
@@ -5,6 +5,68 @@ import (
 	"time"
 )
 
+// Jellyfin item types the bot sends notifications for
+const (
+	ItemTypeMovie      = "Movie"
+	ItemTypeEpisode    = "Episode"
+	ItemTypeSeries     = "Series"
+	ItemTypeSeason     = "Season"
+	ItemTypeMusicAlbum = "MusicAlbum"
+	ItemTypeAudio      = "Audio"
+	ItemTypeAudioBook  = "AudioBook"
+	ItemTypeBook       = "Book"
+)
+
+// SupportedItemTypes lists every item type the bot sends notifications for, in display order
+var SupportedItemTypes = []string{
+	ItemTypeMovie,
+	ItemTypeEpisode,
+	ItemTypeSeries,
+	ItemTypeSeason,
+	ItemTypeMusicAlbum,
+	ItemTypeAudio,
+	ItemTypeAudioBook,
+	ItemTypeBook,
+}
+
+// IsSupportedItemType returns true if notifications are sent for the item type
+func IsSupportedItemType(itemType string) bool {
+	for _, supported := range SupportedItemTypes {
+		if itemType == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Jellyfin server events admins can subscribe to with /events
+const (
+	EventPlaybackStart         = "PlaybackStart"
+	EventPlaybackStop          = "PlaybackStop"
+	EventUserCreated           = "UserCreated"
+	EventAuthenticationFailure = "AuthenticationFailure"
+	EventPluginInstalled       = "PluginInstalled"
+)
+
+// AdminEventTypes lists every server event admins can subscribe to, in display order
+var AdminEventTypes = []string{
+	EventPlaybackStart,
+	EventPlaybackStop,
+	EventUserCreated,
+	EventAuthenticationFailure,
+	EventPluginInstalled,
+}
+
+// IsAdminEventType returns true if admins can subscribe to the notification type
+func IsAdminEventType(notificationType string) bool {
+	for _, eventType := range AdminEventTypes {
+		if notificationType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
 // JellyfinWebhook represents the payload received from Jellyfin webhook plugin
 type JellyfinWebhook struct {
 	NotificationType string    `json:"NotificationType"`
@@ -23,19 +85,42 @@ type JellyfinWebhook struct {
 	UserID           string    `json:"UserId"`
 
 	// Episode-specific fields
+	SeriesID      string `json:"SeriesId,omitempty"`
 	SeriesName    string `json:"SeriesName,omitempty"`
 	SeasonNumber  int    `json:"SeasonNumber,omitempty"`
 	EpisodeNumber int    `json:"EpisodeNumber,omitempty"`
+
+	// Music and audiobook fields
+	Album  string `json:"Album,omitempty"`
+	Artist string `json:"Artist,omitempty"`
+
+	// Session fields of playback, user and authentication events
+	NotificationUsername string `json:"NotificationUsername,omitempty"`
+	ClientName           string `json:"ClientName,omitempty"`
+	App                  string `json:"App,omitempty"` // Client of authentication events
+	DeviceName           string `json:"DeviceName,omitempty"`
+	RemoteEndPoint       string `json:"RemoteEndPoint,omitempty"`
+	PlayMethod           string `json:"PlayMethod,omitempty"` // Transcode, DirectStream or DirectPlay
+	PlayedToCompletion   bool   `json:"PlayedToCompletion,omitempty"`
+
+	// Plugin event fields
+	PluginName    string `json:"PluginName,omitempty"`
+	PluginVersion string `json:"PluginVersion,omitempty"`
 }
 
 // IsMovie returns true if the webhook is for a movie
 func (w *JellyfinWebhook) IsMovie() bool {
-	return w.ItemType == "Movie"
+	return w.ItemType == ItemTypeMovie
 }
 
 // IsEpisode returns true if the webhook is for a TV episode
 func (w *JellyfinWebhook) IsEpisode() bool {
-	return w.ItemType == "Episode"
+	return w.ItemType == ItemTypeEpisode
+}
+
+// IsSeason returns true if the webhook is for a TV season
+func (w *JellyfinWebhook) IsSeason() bool {
+	return w.ItemType == ItemTypeSeason
 }
 
 // IsItemAdded returns true if the notification type is ItemAdded
@@ -43,9 +128,24 @@ func (w *JellyfinWebhook) IsItemAdded() bool {
 	return w.NotificationType == "ItemAdded"
 }
 
+// IsItemUpdated returns true if the notification type is ItemUpdated
+func (w *JellyfinWebhook) IsItemUpdated() bool {
+	return w.NotificationType == "ItemUpdated"
+}
+
+// IsItemDeleted returns true if the notification type is ItemDeleted
+func (w *JellyfinWebhook) IsItemDeleted() bool {
+	return w.NotificationType == "ItemDeleted"
+}
+
+// IsAdminEvent returns true if the webhook is a server event for admins rather than new content
+func (w *JellyfinWebhook) IsAdminEvent() bool {
+	return IsAdminEventType(w.NotificationType)
+}
+
 // IsValid returns true if the webhook is valid for processing
 func (w *JellyfinWebhook) IsValid() bool {
-	return w.IsItemAdded() && (w.IsMovie() || w.IsEpisode())
+	return w.IsItemAdded() && IsSupportedItemType(w.ItemType)
 }
 
 // DecodeHTMLEntities decodes HTML entities in text fields
@@ -54,4 +154,9 @@ func (w *JellyfinWebhook) DecodeHTMLEntities() {
 	w.ItemName = html.UnescapeString(w.ItemName)
 	w.Overview = html.UnescapeString(w.Overview)
 	w.SeriesName = html.UnescapeString(w.SeriesName)
+	w.Album = html.UnescapeString(w.Album)
+	w.Artist = html.UnescapeString(w.Artist)
+	w.NotificationUsername = html.UnescapeString(w.NotificationUsername)
+	w.UserName = html.UnescapeString(w.UserName)
+	w.DeviceName = html.UnescapeString(w.DeviceName)
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WantedTitle describes a title a user asked for, matched against items added to the library
+type WantedTitle struct {
+	Title  string `json:"title"` // As the user typed it, without year and external IDs; may be empty with an ID
+	Year   int    `json:"year"`  // Release year the user gave; 0 matches any year
+	ImdbID string `json:"imdb_id"`
+	TmdbID string `json:"tmdb_id"`
+}
+
+// Wish is a title a user wants to hear about once it is added to the library
+type Wish struct {
+	gorm.Model
+	ChatID      int64 `gorm:"index;not null" json:"chat_id"`
+	WantedTitle `gorm:"embedded"`
+
+	FulfilledAt     *time.Time `gorm:"index" json:"fulfilled_at"` // Set once the user was told about a matching item
+	FulfilledItemID string     `json:"fulfilled_item_id"`
+}
+
+// TableName specifies the table name for Wish model
+func (Wish) TableName() string {
+	return "wishes"
+}
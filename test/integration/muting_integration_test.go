@@ -34,9 +34,9 @@ func TestMuteWorkflow_EndToEnd(t *testing.T) {
 	var broadcasts []BroadcastRecord
 	var mu sync.Mutex
 
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
-			// Simulate filtering logic from BroadcastNotification
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+			// Simulate the recipient filtering of the outbox
 			subscribers, _ := db.GetAllActiveSubscribers()
 
 			filtered := make([]int64, 0)
@@ -67,7 +67,7 @@ func TestMuteWorkflow_EndToEnd(t *testing.T) {
 		SeriesName: "Breaking Bad",
 		Title:      "Pilot",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), content1)
+	mockQueue.EnqueueNotification(context.Background(), content1)
 
 	// Verify both users received the notification
 	if len(broadcasts) != 1 {
@@ -89,7 +89,7 @@ func TestMuteWorkflow_EndToEnd(t *testing.T) {
 		SeriesName: "Breaking Bad",
 		Title:      "Cat's in the Bag...",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), content2)
+	mockQueue.EnqueueNotification(context.Background(), content2)
 
 	// Verify only user 2 received the notification
 	if len(broadcasts) != 2 {
@@ -131,8 +131,8 @@ func TestUnmuteRestoresNotifications_EndToEnd(t *testing.T) {
 
 	// Track notifications
 	notificationCount := 0
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
 			subscribers, _ := db.GetAllActiveSubscribers()
 			for _, chatID := range subscribers {
 				isMuted, _ := db.IsSeriesMuted(chatID, content.SeriesName)
@@ -150,7 +150,7 @@ func TestUnmuteRestoresNotifications_EndToEnd(t *testing.T) {
 		SeriesName: "Game of Thrones",
 		Title:      "Winter Is Coming",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), content1)
+	mockQueue.EnqueueNotification(context.Background(), content1)
 
 	if notificationCount != 0 {
 		t.Errorf("Expected 0 notifications while muted, got %d", notificationCount)
@@ -174,7 +174,7 @@ func TestUnmuteRestoresNotifications_EndToEnd(t *testing.T) {
 		SeriesName: "Game of Thrones",
 		Title:      "The Kingsroad",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), content2)
+	mockQueue.EnqueueNotification(context.Background(), content2)
 
 	if notificationCount != 1 {
 		t.Errorf("Expected 1 notification after unmute, got %d", notificationCount)
@@ -460,8 +460,8 @@ func TestSeriesMuting_DoesNotAffectMovies(t *testing.T) {
 	var receivedNotifications []string
 	var mu sync.Mutex
 
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
 			subscribers, _ := db.GetAllActiveSubscribers()
 
 			for _, chatID := range subscribers {
@@ -487,14 +487,14 @@ func TestSeriesMuting_DoesNotAffectMovies(t *testing.T) {
 		SeriesName: "Breaking Bad",
 		Title:      "Pilot",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), episodeContent)
+	mockQueue.EnqueueNotification(context.Background(), episodeContent)
 
 	// Send movie notification
 	movieContent := &handlers.NotificationContent{
 		Type:  "Movie",
 		Title: "Interstellar",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), movieContent)
+	mockQueue.EnqueueNotification(context.Background(), movieContent)
 
 	// Verify only movie notification was received
 	if len(receivedNotifications) != 1 {
@@ -597,8 +597,8 @@ func TestEmptySeriesName_NotificationFiltering(t *testing.T) {
 
 	// Track notifications
 	notificationCount := 0
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
 			subscribers, _ := db.GetAllActiveSubscribers()
 
 			for _, chatID := range subscribers {
@@ -623,7 +623,7 @@ func TestEmptySeriesName_NotificationFiltering(t *testing.T) {
 		SeriesName: "",
 		Title:      "Episode with no series",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), emptyContent)
+	mockQueue.EnqueueNotification(context.Background(), emptyContent)
 
 	// User should receive notification (not filtered)
 	if notificationCount != 1 {
@@ -639,7 +639,7 @@ func TestEmptySeriesName_NotificationFiltering(t *testing.T) {
 		SeriesName: "Unknown Series",
 		Title:      "Unknown episode",
 	}
-	mockBroadcaster.BroadcastNotification(context.Background(), unknownContent)
+	mockQueue.EnqueueNotification(context.Background(), unknownContent)
 
 	// User should receive notification (not filtered)
 	if notificationCount != 1 {
@@ -34,7 +34,7 @@ func TestWebhookToNotificationPipeline(t *testing.T) {
 		t.Fatalf("Failed to add subscriber: %v", err)
 	}
 
-	// Create a mock broadcaster that records calls
+	// Create a mock queue that records calls
 	type BroadcastCall struct {
 		ItemID   string
 		Title    string
@@ -42,8 +42,8 @@ func TestWebhookToNotificationPipeline(t *testing.T) {
 	}
 	var mu sync.Mutex
 	var broadcastCalls []BroadcastCall
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
 			mu.Lock()
 			broadcastCalls = append(broadcastCalls, BroadcastCall{
 				ItemID:   content.ItemID,
@@ -57,7 +57,7 @@ func TestWebhookToNotificationPipeline(t *testing.T) {
 
 	// Create webhook handler
 	webhookHandler := handlers.NewWebhookHandler(db, "test-secret")
-	webhookHandler.SetBroadcaster(mockBroadcaster)
+	webhookHandler.SetQueue(mockQueue)
 
 	// Create test webhook payload (movie)
 	payload := models.JellyfinWebhook{
@@ -139,11 +139,11 @@ func TestWebhookDuplicatePrevention(t *testing.T) {
 		t.Fatalf("Failed to add subscriber: %v", err)
 	}
 
-	// Create a mock broadcaster that counts calls
+	// Create a mock queue that counts calls
 	var mu2 sync.Mutex
 	callCount := 0
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
 			mu2.Lock()
 			callCount++
 			mu2.Unlock()
@@ -153,7 +153,7 @@ func TestWebhookDuplicatePrevention(t *testing.T) {
 
 	// Create webhook handler
 	webhookHandler := handlers.NewWebhookHandler(db, "test-secret")
-	webhookHandler.SetBroadcaster(mockBroadcaster)
+	webhookHandler.SetQueue(mockQueue)
 
 	// Create test webhook payload
 	payload := models.JellyfinWebhook{
@@ -212,15 +212,15 @@ func TestEpisodeNotificationFlow(t *testing.T) {
 		t.Fatalf("Failed to add subscriber: %v", err)
 	}
 
-	// Create a mock broadcaster that records calls
+	// Create a mock queue that records calls
 	type BroadcastCall struct {
 		Title    string
 		ItemType string
 	}
 	var mu3 sync.Mutex
 	var broadcastCalls []BroadcastCall
-	mockBroadcaster := &mockBroadcaster{
-		broadcastFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
+	mockQueue := &mockQueue{
+		enqueueFunc: func(ctx context.Context, content *handlers.NotificationContent) error {
 			mu3.Lock()
 			broadcastCalls = append(broadcastCalls, BroadcastCall{
 				Title:    content.Title,
@@ -233,7 +233,7 @@ func TestEpisodeNotificationFlow(t *testing.T) {
 
 	// Create webhook handler
 	webhookHandler := handlers.NewWebhookHandler(db, "test-secret")
-	webhookHandler.SetBroadcaster(mockBroadcaster)
+	webhookHandler.SetQueue(mockQueue)
 
 	// Create test webhook payload (episode)
 	payload := models.JellyfinWebhook{
@@ -370,14 +370,14 @@ func TestJellyfinAPIIntegration(t *testing.T) {
 	}
 }
 
-// Mock broadcaster for testing
-type mockBroadcaster struct {
-	broadcastFunc func(ctx context.Context, content *handlers.NotificationContent) error
+// Mock notification queue for testing
+type mockQueue struct {
+	enqueueFunc func(ctx context.Context, content *handlers.NotificationContent) error
 }
 
-func (m *mockBroadcaster) BroadcastNotification(ctx context.Context, content *handlers.NotificationContent) error {
-	if m.broadcastFunc != nil {
-		return m.broadcastFunc(ctx, content)
+func (m *mockQueue) EnqueueNotification(ctx context.Context, content *handlers.NotificationContent) error {
+	if m.enqueueFunc != nil {
+		return m.enqueueFunc(ctx, content)
 	}
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 
 	"jellyfin-telegram-bot/internal/database"
 	"jellyfin-telegram-bot/internal/telegram"
+	"jellyfin-telegram-bot/pkg/models"
 )
 
 // Test 1: Complete flow - /start displays welcome menu with navigation buttons
@@ -484,3 +485,7 @@ func (m *mockJellyfinClient) SearchContent(ctx context.Context, query string, li
 func (m *mockJellyfinClient) GetPosterImage(ctx context.Context, itemID string) ([]byte, error) {
 	return []byte{}, nil
 }
+
+func (m *mockJellyfinClient) QueryItems(ctx context.Context, query models.ItemQuery) ([]telegram.ContentItem, int, error) {
+	return m.recentItems, len(m.recentItems), nil
+}